@@ -15,29 +15,32 @@ import (
 )
 
 type AppConfig struct {
-	SessionSecret      string `env:"SESSION_SECRET"`
-	CSRFSecret         string `env:"CSRF_SECRET"`
-	DomainName         string `env:"DOMAIN_NAME" envDefault:"localhost"`
-	AppPort            int    `env:"APP_PORT" envDefault:"3000"`
-	AppOuterPort       int    `env:"APP_OUTER_PORT" envDefault:"3000"`
-	NginxPort          int    `env:"NGINX_PORT" envDefault:"80"`
-	NginxSSLPort       int    `env:"NGINX_SSL_PORT" envDefault:"443"`
-	Debug              bool   `env:"DEBUG" envDefault:"false"`
-	BrandName          string `env:"BRAND_NAME"`
-	BrandDomainName    string `env:"BRAND_DOMAIN_NAME"`
-	Slogan             string `env:"SLOGAN"`
-	DB                 *DBConfig
-	ReplyDepthPageSize int
-	AdminEmail         string `env:"ADMIN_EMAIL"`
-	Redis              *RedisConfig
-	SMTP               *SMTPConfig
-	Testing            bool   `env:"TEST"`
-	GoogleClientID     string `env:"GOOGLE_CLIENT_ID"`
-	GoogleClientSecret string `env:"GOOGLE_CLIENT_SECRET"`
-	GithubClientID     string `env:"GITHUB_CLIENT_ID"`
-	GithubClientSecret string `env:"GITHUB_CLIENT_SECRET"`
-	CloudflareSiteKey  string `env:"CLOUDFLARE_SITE_KEY"`
-	CloudflareSecret   string `env:"CLOUDFLARE_SECRET"`
+	SessionSecret        string `env:"SESSION_SECRET"`
+	CSRFSecret           string `env:"CSRF_SECRET"`
+	DomainName           string `env:"DOMAIN_NAME" envDefault:"localhost"`
+	AppPort              int    `env:"APP_PORT" envDefault:"3000"`
+	AppOuterPort         int    `env:"APP_OUTER_PORT" envDefault:"3000"`
+	NginxPort            int    `env:"NGINX_PORT" envDefault:"80"`
+	NginxSSLPort         int    `env:"NGINX_SSL_PORT" envDefault:"443"`
+	Debug                bool   `env:"DEBUG" envDefault:"false"`
+	BrandName            string `env:"BRAND_NAME"`
+	BrandDomainName      string `env:"BRAND_DOMAIN_NAME"`
+	Slogan               string `env:"SLOGAN"`
+	DB                   *DBConfig
+	ReplyDepthPageSize   int
+	AdminEmail           string `env:"ADMIN_EMAIL"`
+	Redis                *RedisConfig
+	SMTP                 *SMTPConfig
+	Storage              *StorageConfig
+	DraftExpireDays      int    `env:"DRAFT_EXPIRE_DAYS" envDefault:"30"`
+	AccountDeletionDays  int    `env:"ACCOUNT_DELETION_DAYS" envDefault:"14"`
+	UsernameCooldownDays int    `env:"USERNAME_COOLDOWN_DAYS" envDefault:"30"`
+	UsernameRedirectDays int    `env:"USERNAME_REDIRECT_DAYS" envDefault:"90"`
+	CacheEnabled         bool   `env:"CACHE_ENABLED" envDefault:"false"`
+	CacheTTLSeconds      int    `env:"CACHE_TTL_SECONDS" envDefault:"300"`
+	Testing              bool   `env:"TEST"`
+	CloudflareSiteKey    string `env:"CLOUDFLARE_SITE_KEY"`
+	CloudflareSecret     string `env:"CLOUDFLARE_SECRET"`
 }
 
 func (ac *AppConfig) GetServerURL() string {
@@ -85,6 +88,18 @@ type RedisConfig struct {
 	Password string `env:"REDIS_PASSWORD"`
 }
 
+// Storage of uploaded files, type is local or s3, the s3 type works with any
+// S3 compatible service
+type StorageConfig struct {
+	Type        string `env:"STORAGE_TYPE" envDefault:"local"`
+	LocalDir    string `env:"STORAGE_LOCAL_DIR" envDefault:"./uploads"`
+	S3Endpoint  string `env:"S3_ENDPOINT"`
+	S3Region    string `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket    string `env:"S3_BUCKET"`
+	S3AccessKey string `env:"S3_ACCESS_KEY"`
+	S3SecretKey string `env:"S3_SECRET_KEY"`
+}
+
 type SMTPConfig struct {
 	Server     string `env:"SMTP_SERVER"`
 	ServerPort string `env:"SMTP_SERVER_PORT"`
@@ -168,6 +183,11 @@ func ParseFromEnv() (*AppConfig, error) {
 		return nil, err
 	}
 
+	storageCfg := &StorageConfig{}
+	if err := env.Parse(storageCfg); err != nil {
+		return nil, err
+	}
+
 	cfg := &AppConfig{
 		DB:                 dbCfg,
 		BrandName:          BrandName,
@@ -176,6 +196,7 @@ func ParseFromEnv() (*AppConfig, error) {
 		ReplyDepthPageSize: ReplyDepthPageSize,
 		Redis:              rdbCfg,
 		SMTP:               smtpCfg,
+		Storage:            storageCfg,
 	}
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
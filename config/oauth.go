@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+type OAuthProviderType string
+
+const (
+	OAuthProviderTypeOAuth2 OAuthProviderType = "oauth2"
+	OAuthProviderTypeOIDC   OAuthProviderType = "oidc"
+)
+
+// Names of the claims in the user info response, nested claims are joined
+// by dots
+type OAuthClaims struct {
+	Subject       string `yaml:"subject"`
+	Email         string `yaml:"email"`
+	EmailVerified string `yaml:"email_verified"`
+	Name          string `yaml:"name"`
+}
+
+type OAuthProvider struct {
+	// Used in the routes, such as /auth/{name}/callback
+	Name        string            `yaml:"name"`
+	DisplayName string            `yaml:"display_name"`
+	Type        OAuthProviderType `yaml:"type"`
+	// Users registered with the provider are saved with the auth type, the
+	// providers not known by the auth types use oidc
+	AuthType     string `yaml:"auth_type"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// Endpoints of oidc providers are discovered from the issuer if not set
+	Issuer      string      `yaml:"issuer"`
+	AuthURL     string      `yaml:"auth_url"`
+	TokenURL    string      `yaml:"token_url"`
+	UserInfoURL string      `yaml:"userinfo_url"`
+	Scopes      []string    `yaml:"scopes,flow"`
+	PKCE        bool        `yaml:"pkce"`
+	Claims      OAuthClaims `yaml:"claims"`
+	// Emails listed here are used when the user info has no verified email,
+	// such as the emails api of GitHub
+	EmailsURL string `yaml:"emails_url"`
+	// Emails of the provider are always verified, the email verified claim
+	// is ignored
+	TrustEmail bool `yaml:"trust_email"`
+}
+
+type OAuthData struct {
+	Providers []*OAuthProvider `yaml:"providers"`
+}
+
+var oauthProviderNameRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+func (op *OAuthProvider) Valid() error {
+	if !oauthProviderNameRegexp.MatchString(op.Name) {
+		return fmt.Errorf("oauth provider name %q is invalid", op.Name)
+	}
+
+	switch op.Type {
+	case OAuthProviderTypeOAuth2:
+		if op.AuthURL == "" || op.TokenURL == "" || op.UserInfoURL == "" {
+			return fmt.Errorf("oauth provider %s: auth_url, token_url and userinfo_url are required", op.Name)
+		}
+	case OAuthProviderTypeOIDC:
+		if op.Issuer == "" && (op.AuthURL == "" || op.TokenURL == "" || op.UserInfoURL == "") {
+			return fmt.Errorf("oauth provider %s: issuer or endpoints are required", op.Name)
+		}
+	default:
+		return fmt.Errorf("oauth provider %s: type %q is not supported", op.Name, op.Type)
+	}
+
+	return nil
+}
+
+// ParseOAuthData parses the providers config, environment variables in the
+// file such as ${GOOGLE_CLIENT_ID} are expanded, providers without client id
+// are disabled
+func ParseOAuthData(filePath string) (*OAuthData, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOAuthData(data)
+}
+
+func parseOAuthData(data []byte) (*OAuthData, error) {
+	out := OAuthData{}
+	err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &out)
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []*OAuthProvider
+	names := make(map[string]bool)
+	for _, item := range out.Providers {
+		if item.ClientID == "" {
+			continue
+		}
+
+		err := item.Valid()
+		if err != nil {
+			return nil, err
+		}
+
+		if names[item.Name] {
+			return nil, fmt.Errorf("oauth provider %s is duplicated", item.Name)
+		}
+		names[item.Name] = true
+
+		providers = append(providers, item)
+	}
+	out.Providers = providers
+
+	return &out, nil
+}
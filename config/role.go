@@ -12,6 +12,10 @@ type RoleItem struct {
 	Name        string   `yaml:"name"`
 	AdaptId     string   `yaml:"adapt_id"`
 	Permissions []string `yaml:"permissions,flow"`
+	// Total size of attachments uploaded by the users of the role
+	UploadQuotaMB int64 `yaml:"upload_quota_mb"`
+	// Users of the role must enable two-factor authentication
+	Require2FA bool `yaml:"require_2fa"`
 }
 
 type RoleId string
@@ -30,6 +34,32 @@ func (rd RoleData) Valid(roleId string) bool {
 	return ok
 }
 
+// UploadQuota returns upload quota of the role in bytes, roles created at
+// runtime that are not in the config use the quota of common user
+func (rd RoleData) UploadQuota(roleFrontId string) int64 {
+	for _, item := range rd.Data {
+		if item.AdaptId == roleFrontId {
+			return item.UploadQuotaMB << 20
+		}
+	}
+
+	if item, ok := rd.Data["common_user"]; ok {
+		return item.UploadQuotaMB << 20
+	}
+	return 0
+}
+
+// Require2FA reports whether users of the role must enable two-factor
+// authentication
+func (rd RoleData) Require2FA(roleFrontId string) bool {
+	for _, item := range rd.Data {
+		if item.AdaptId == roleFrontId {
+			return item.Require2FA
+		}
+	}
+	return false
+}
+
 func ParseRoleData(filePath string) (*RoleData, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -7,7 +7,7 @@ compose_file=${2:-./docker-compose.dev.yml}
 
 export DEV=1
 
-source ./scripts/encrypt_user_pass.sh $env_file
+source ./scripts/init_env.sh $env_file
 source ./scripts/run_docker.sh $env_file $compose_file
 source ./scripts/pre_test.sh 
 
@@ -18,6 +18,19 @@ cleanup() {
     exit 1
 }
 
+migrate_db() {
+    # Database may not accept connections right after the container started
+    for i in $(seq 1 10); do
+        go run . migrate up && return 0
+        sleep 2
+    done
+
+    echo "Migrate database failed"
+    stop_docker
+    exit 1
+}
+
 run_docker
 trap cleanup SIGINT
+migrate_db
 fresh
@@ -152,4 +152,114 @@ func (ic *I18nCustom) AddBtnConfigs() {
 		ID:    "BtnConfirm",
 		Other: "Confirm",
 	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnRevoke",
+		Other: "Revoke",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnApprove",
+		Other: "Approve",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnReject",
+		Other: "Reject",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnAdd",
+		Other: "Add",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnRemove",
+		Other: "Remove",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnIgnore",
+		Other: "Ignore",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnUnignore",
+		Other: "Unignore",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnReport",
+		Other: "Report",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnResolve",
+		Other: "Resolve",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnPreview",
+		Other: "Preview",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnJoinDiscussion",
+		Other: "Join the discussion",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnMarkDuplicate",
+		Other: "Mark duplicate",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnVote",
+		Other: "Vote",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnDiscardDraft",
+		Other: "Discard draft",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnRevokeOtherSessions",
+		Other: "Log out other devices",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnEnableTwoFactor",
+		Other: "Enable",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnDisableTwoFactor",
+		Other: "Disable two-factor authentication",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnRegenerateRecoveryCodes",
+		Other: "Regenerate recovery codes",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnLink",
+		Other: "Link",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnUnlink",
+		Other: "Unlink",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnExportData",
+		Other: "Export Data",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BtnCancelDeletion",
+		Other: "Cancel Deletion",
+	})
 }
@@ -906,4 +906,861 @@ func (ic *I18nCustom) AddConfigs() {
 		ID:    "SearchSite",
 		Other: "Search",
 	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiToken",
+		One:   "API Token",
+		Other: "API Tokens",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiTokenName",
+		Other: "Token Name",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiTokenCreatedTip",
+		Other: "Make sure to copy the token now, you won't be able to see it again",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiTokenUsageTip",
+		Other: "Send the token in the request header as Authorization: Bearer <token>",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiTokenCountLimit",
+		Other: "No more than {{.Num}} tokens",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApiTokenRevokeSuccess",
+		Other: "Token has been revoked",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LastUsedAt",
+		Other: "Last Used",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "NeverUsed",
+		Other: "Never used",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Search",
+		Other: "Search",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "SearchResultOf",
+		Other: "Search results of \"{{.Keywords}}\"",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "SearchResultCount",
+		One:   "{{.Count}} result",
+		Other: "{{.Count}} results",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "SearchLanguageTip",
+		Other: "Language of the keywords, affects how words are matched",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "StartDate",
+		Other: "Start date",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "EndDate",
+		Other: "End date",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DateRangeInvalid",
+		Other: "End date can not be earlier than start date",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Tag",
+		One:   "Tag",
+		Other: "Tags",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TagFrontId",
+		Other: "Tag ID",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TagFrontIdTip",
+		Other: "Lowercase letters and numbers, separated by hyphens or underscores, used in the URL and can not be changed later",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TagName",
+		Other: "Tag name",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Description",
+		Other: "Description",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ArticleTagTip",
+		Other: "Select at most {{.Num}} tags",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TagSaveSuccess",
+		Other: "Tag saved successfully",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryFrontId",
+		Other: "Category ID",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryFrontIdTip",
+		Other: "Lowercase letters and numbers, separated by hyphens or underscores, used in the URL and can not be changed later",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryName",
+		Other: "Category Name",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ApprovalComment",
+		Other: "Review Comment",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ProposeCategory",
+		Other: "Propose Category",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ProposeCategoryTip",
+		Other: "The category will be listed after approved by moderators, you will be notified of the result",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryProposeSuccess",
+		Other: "Category proposed, please wait for the review",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryReview",
+		Other: "Category Review",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryPending",
+		Other: "Pending",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryRejected",
+		Other: "Rejected",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryAlreadyApproved",
+		Other: "The category has been approved",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryReviewSuccess",
+		Other: "Category reviewed successfully",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryApprovedMessage",
+		Other: "Your proposed category {{.CategoryName}} has been approved",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryRejectedMessage",
+		Other: "Your proposed category {{.CategoryName}} has been rejected",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CategoryModerator",
+		One:   "Category Moderator",
+		Other: "Category Moderators",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ModeratorSaveSuccess",
+		Other: "Category moderators updated",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlock",
+		One:   "Blocked User",
+		Other: "Blocked Users",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlockType",
+		Other: "Type",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlockTypeBlock",
+		Other: "Block",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlockTypeMute",
+		Other: "Mute",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlockTip",
+		Other: "Articles of blocked users are hidden and their replies are collapsed. Replies of both blocked and muted users will not send you messages.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UserBlockSaveSuccess",
+		Other: "Blocked users updated",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CannotBlockSelf",
+		Other: "You cannot block yourself",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "BlockedUserContent",
+		Other: "Content of blocked user",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "IgnoredCategory",
+		One:   "Ignored Category",
+		Other: "Ignored Categories",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "IgnoredCategoryTip",
+		Other: "Articles in ignored categories are hidden from the home page, tag pages and RSS feed",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportReason",
+		Other: "Reason",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportComment",
+		Other: "Comment",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportCommentTip",
+		Other: "required if the reason is other",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportSuccess",
+		Other: "Reported, moderators will review it soon",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportQueue",
+		Other: "Reports",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportPending",
+		Other: "Pending",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolved",
+		Other: "Resolved",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportNum",
+		One:   "{{.Count}} report",
+		Other: "{{.Count}} reports",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolvedInfo",
+		Other: "Resolved by {{.Username}} as {{.Resolution}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolveSuccess",
+		Other: "Reports resolved",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportReason_spam",
+		Other: "Spam",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportReason_abuse",
+		Other: "Abuse",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportReason_off_topic",
+		Other: "Off-topic",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportReason_other",
+		Other: "Other",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolution_dismiss",
+		Other: "Dismiss",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolution_delete",
+		Other: "Delete",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolution_lock",
+		Other: "Lock",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolution_fade_out",
+		Other: "Fade out",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ReportResolution_ban",
+		Other: "Ban author",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "FadedOut",
+		Other: "Faded out",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "MarkdownContentTip",
+		Other: "Markdown is supported.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "MentionedYou",
+		Other: "{{.Username}} mentioned you in {{.ArticleTitle}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "MentionNotify",
+		Other: "Notify me when someone mentions me",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Notification",
+		One:   "Notification",
+		Other: "Notifications",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Attachment",
+		One:   "Attachment",
+		Other: "Attachments",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AttachmentTip",
+		Other: "Up to {{.Num}} files, {{.Size}} each, supported types: {{.Types}}. Metadata such as location is removed from images.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AttachmentCountLimit",
+		Other: "No more than {{.Num}} attachments",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AttachmentSizeLimit",
+		Other: "File size must not exceed {{.Size}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AttachmentTypeLimit",
+		Other: "Supported file types: {{.Types}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "File",
+		Other: "File",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "FileName",
+		Other: "File name",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ArticleTitleFromLinkTip",
+		Other: "It's filled with the title of the linked page when left blank.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DuplicateLinkTip",
+		Other: "The link has already been submitted: {{.Title}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "SubmitDuplicateAnyway",
+		Other: "Submit anyway",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DuplicateOfTip",
+		Other: "This is a duplicate of {{.Title}}, please join the discussion there.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "OriginalArticleId",
+		Other: "Original article ID",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "MarkDuplicateTip",
+		Other: "Mark as duplicate of an older article, leave the ID blank to unmark",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DuplicateOriginalTip",
+		Other: "The original article should be an older undeleted article",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Poll",
+		Other: "Poll",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollOption",
+		Other: "Poll option",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollClosedAt",
+		Other: "Poll closing time",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollOptionsTip",
+		Other: "One option per line, {{.Min}} to {{.Max}} options, each option not exceeding {{.Num}} characters",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollOptionCountLimit",
+		Other: "A poll should have {{.Min}} to {{.Max}} options",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollClosedAtTip",
+		Other: "Poll closing time should be later than now",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollMultiple",
+		Other: "Multiple choice",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollSingle",
+		Other: "Single choice",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollAnonymous",
+		Other: "Anonymous voters",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollPublic",
+		Other: "Public voters",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollPublicVoters",
+		Other: "Show voters to everyone",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollVoterNum",
+		One:   "{{.Count}} voter",
+		Other: "{{.Count}} voters",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollClosesAt",
+		Other: "Closes at {{.Time}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollClosed",
+		Other: "Poll closed",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollAlreadyVoted",
+		Other: "You have already voted in this poll",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollSingleChoiceTip",
+		Other: "Only one option can be chosen",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollVoteSuccess",
+		Other: "Voted successfully",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollYourChoice",
+		Other: "Your choice",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PollLoginTip",
+		Other: "Login to vote, results are shown after voting",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "Draft",
+		One:   "Draft",
+		Other: "Drafts",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftTarget",
+		Other: "Draft target",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftNewArticle",
+		Other: "Untitled article",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftSavedAt",
+		Other: "saved {{.Time}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftRestoredTip",
+		Other: "Your unsubmitted draft saved {{.Time}} is restored",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftSaved",
+		Other: "Draft saved",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DraftDeleted",
+		Other: "Draft discarded",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginSession",
+		One:   "Logged-in Device",
+		Other: "Logged-in Devices",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ManageLoginSessions",
+		Other: "Manage devices logged in to the account",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginSessionTip",
+		Other: "Devices logged in to your account, revoke the ones you don't recognize. All devices are logged out after the password is reset.",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginDevice",
+		Other: "Device",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginCountry",
+		Other: "Region",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginAt",
+		Other: "Logged in",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LastActiveAt",
+		Other: "Last active",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "CurrentLoginSession",
+		Other: "current",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginSessionRevoked",
+		Other: "The device is logged out",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "OtherLoginSessionsRevoked",
+		Other: "Other devices are logged out",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorAuth",
+		Other: "Two-factor authentication",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ManageTwoFactorAuth",
+		Other: "Manage two-factor authentication",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorEnabled",
+		Other: "Two-factor authentication is enabled",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorNotEnabled",
+		Other: "Two-factor authentication is not enabled",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorDisabled",
+		Other: "Two-factor authentication is disabled",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorCode",
+		Other: "Authentication code or recovery code",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorCodeInvalid",
+		Other: "The code is invalid or has been used",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorLoginTip",
+		Other: "Enter the 6-digit code from your authenticator app, or one of your recovery codes",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorLoginExpired",
+		Other: "The login has expired or failed too many times, please login again",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorEnrollTip",
+		Other: "Scan the QR code with an authenticator app, or enter the secret manually, then enter the 6-digit code it shows to enable",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorSecret",
+		Other: "Secret",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TwoFactorRequiredTip",
+		Other: "Two-factor authentication is required for your role",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "RecoveryCodesCreatedTip",
+		Other: "Save these recovery codes somewhere safe, each of them can be used once to login when your authenticator app is not available, they will not be shown again",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "RecoveryCodesLeftTip",
+		One:   "{{.Count}} recovery code left",
+		Other: "{{.Count}} recovery codes left",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "RecoveryCodeUsedTip",
+		Other: "A recovery code is used to login, you can regenerate the recovery codes in the settings",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID: "VerificationLinkIdentityMailTpl",
+		Other: `<html>
+<body>
+<p>You are linking a login method to your account on {{.DomainName}}, here's the verfication code:</p>
+<p><large><b>{{.Code}}</b></large></p>
+<p>Valid for {{.Minutes}} minutes, ignore this email if it's not you.</p>
+<hr>
+<p style="color:#666">{{.DomainName}}</p>
+</body>
+</html>`,
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "VerificationLinkIdentityMailTitle",
+		Other: "Verification code for linking login method",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethod",
+		One:   "Login Method",
+		Other: "Login Methods",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ManageLoginMethods",
+		Other: "Manage login methods",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodTip",
+		Other: "Link other accounts to login with them, the last login method can not be removed",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodEnabled",
+		Other: "Enabled",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodNotLinked",
+		Other: "Not linked",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "SetPassword",
+		Other: "Set password",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodLinked",
+		Other: "{{.Name}} is linked",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodUnlinked",
+		Other: "{{.Name}} is unlinked",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LoginMethodLinkedBefore",
+		Other: "This {{.Name}} account is linked to another user, or another {{.Name}} account is linked already",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LastLoginMethodTip",
+		Other: "The last login method can not be removed, set a password or link another account first",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LinkLoginMethod",
+		Other: "Link Login Method",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LinkLoginMethodTip",
+		Other: "An account with the email {{.Email}} is registered already, enter the code sent to the email to link {{.Name}} to it, the code is valid for {{.Duration}} minutes",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "LinkLoginMethodExpired",
+		Other: "Linking is expired, please login again",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "PersonalData",
+		Other: "Personal Data",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ExportAccountDataTip",
+		Other: "Download a zip archive of your profile, articles, replies, votes, saves, reactions, messages and activities in JSON format",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DeleteAccount",
+		Other: "Delete Account",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DeleteAccountTip",
+		Other: "Your account will be deleted {{.Days}} days after the request, you can cancel it before that. Articles and replies are kept under an anonymous author, and other data of the account is deleted",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "DeleteAccountConfirmTip",
+		Other: "Enter your username to confirm:",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AccountDeletionRequested",
+		Other: "Account deletion is requested, your account will be deleted on {{.Time}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AccountDeletionPendingTip",
+		Other: "Your account will be deleted on {{.Time}}, cancel the deletion in account settings if you want to keep it",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AccountDeletionCanceled",
+		Other: "Account deletion is canceled",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ChangeUsername",
+		Other: "Change Username",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "ChangeUsernameTip",
+		Other: "Username can be changed once every {{.CooldownDays}} days. The old username redirects to the new one for {{.RedirectDays}} days, and can't be taken by others until then",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UsernameChangeAvailableAt",
+		Other: "Username can be changed again after {{.Time}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "NewUsername",
+		Other: "New Username",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UsernameChanged",
+		Other: "Username is changed to {{.Name}}",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UsernameHistory",
+		Other: "Username History",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "UsernameRedirectUntil",
+		Other: "redirects until {{.Time}}",
+	})
 }
@@ -37,4 +37,14 @@ func (ic *I18nCustom) AddErrorConfigs() {
 		ID:    "PasswordConfirmError",
 		Other: "The passwords entered do not match",
 	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "NotExist",
+		Other: "The {{.FieldNames}} does not exist",
+	})
+
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "TagCountLimit",
+		Other: "Can not select more than {{.Num}} tags",
+	})
 }
@@ -80,15 +80,21 @@ func (ic *I18nCustom) MustLocalize(id string, templateData any, pluralcount any)
 		return ""
 	}
 
+	// Configs are shared between requests, so copy it before setting data
+	localConfig := *config
+	localConfig.Funcs = template.FuncMap{
+		"local": ic.LocalTpl,
+	}
+
 	if templateData != "" && templateData != nil {
-		config.TemplateData = templateData
+		localConfig.TemplateData = templateData
 	}
 
 	if pluralcount != "" && pluralcount != nil {
-		config.PluralCount = pluralcount
+		localConfig.PluralCount = pluralcount
 	}
 
-	return ic.Localizer.MustLocalize(config)
+	return ic.Localizer.MustLocalize(&localConfig)
 }
 
 var timeAgoZHHant = &timeago.Config{
@@ -133,32 +139,34 @@ var timeAgoJP = &timeago.Config{
 	DefaultLayout: "2006-01-02",
 }
 
-func (ic *I18nCustom) SwitchLang(lang string) {
-	// fmt.Println("switch lang: ", lang)
-	// fmt.Println("simplified chinese: ", language.SimplifiedChinese.String())
-	// fmt.Println("traditional chinese: ", language.TraditionalChinese.String())
-	// fmt.Println("japanese: ", language.Japanese.String())
-
+// WithLang returns a localizer of the language, which shares the bundle and
+// configs with ic, so that every request can localize in its own language
+func (ic *I18nCustom) WithLang(lang string) *I18nCustom {
 	var langTag language.Tag
+	var timeAgo *timeago.Config
 	switch lang {
 	case "zh-Hans":
-		ic.TimeAgo = &timeago.Chinese
+		timeAgo = &timeago.Chinese
 		langTag = language.SimplifiedChinese
 	case "zh-Hant":
-		ic.TimeAgo = timeAgoZHHant
+		timeAgo = timeAgoZHHant
 		langTag = language.TraditionalChinese
 	case "ja":
-		ic.TimeAgo = timeAgoJP
+		timeAgo = timeAgoJP
 		langTag = language.Japanese
 	default:
-		ic.TimeAgo = &timeago.English
+		lang = defaultLang
+		timeAgo = &timeago.English
 		langTag = language.English
 	}
 
-	ic.Localizer = i18n.NewLocalizer(ic.Bundle, langTag.String())
-	ic.CurrLang = lang
-
-	// fmt.Println("login str:", MustLocalize("Login", "", 0))
+	return &I18nCustom{
+		CurrLang:  lang,
+		Bundle:    ic.Bundle,
+		Localizer: i18n.NewLocalizer(ic.Bundle, langTag.String()),
+		Configs:   ic.Configs,
+		TimeAgo:   timeAgo,
+	}
 }
 
 func (ic *I18nCustom) LocalTpl(id string, data ...any) string {
@@ -0,0 +1,381 @@
+// Fetch OpenGraph, oEmbed and title metadata of links submitted by users.
+// Requests to private and reserved addresses are rejected when connecting, so
+// that redirects and DNS records pointing to internal services are also
+// blocked.
+
+package linkpreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	DefaultTimeout = 5 * time.Second
+	MaxBodySize    = 1 << 20
+	MaxRedirects   = 3
+
+	MaxTitleLen       = 255
+	MaxDescriptionLen = 500
+	MaxSiteNameLen    = 100
+	MaxImageURLLen    = 1000
+)
+
+const userAgent = "Mozilla/5.0 (compatible; LinkPreview/1.0)"
+
+var (
+	ErrForbiddenAddr = errors.New("linkpreview: address is not allowed")
+	ErrInvalidURL    = errors.New("linkpreview: invalid url")
+)
+
+type Preview struct {
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+type Fetcher struct {
+	client *http.Client
+	// Checks addresses before connecting, only replaced in tests
+	allowIP func(net.IP) bool
+}
+
+func NewFetcher() *Fetcher {
+	f := &Fetcher{
+		allowIP: IsPublicIP,
+	}
+
+	dialer := &net.Dialer{
+		Timeout: DefaultTimeout,
+		Control: f.control,
+	}
+
+	f.client = &http.Client{
+		Timeout: DefaultTimeout,
+		Transport: &http.Transport{
+			// Proxies from environment are not used, the addresses of them
+			// would bypass the check
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   DefaultTimeout,
+			ResponseHeaderTimeout: DefaultTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= MaxRedirects {
+				return fmt.Errorf("linkpreview: stopped after %d redirects", MaxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+// Called with the resolved address of every connection
+func (f *Fetcher) control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !f.allowIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddr, host)
+	}
+	return nil
+}
+
+var reservedNets []*net.IPNet
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"100.64.0.0/10",
+		"192.0.0.0/24",
+		"192.0.2.0/24",
+		"198.18.0.0/15",
+		"198.51.100.0/24",
+		"203.0.113.0/24",
+		"240.0.0.0/4",
+		"64:ff9b::/96",
+		"2001:db8::/32",
+	} {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		reservedNets = append(reservedNets, ipNet)
+	}
+}
+
+// IsPublicIP reports whether the address is reachable on the public internet,
+// loopback, private, link local and other reserved addresses are not
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+
+	for _, ipNet := range reservedNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// Fetch the page of the url and read metadata from its head, oEmbed data is
+// used when the page has no OpenGraph title or image
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+
+	err = checkURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.get(ctx, u.String(), "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("linkpreview: unsupported content type %q", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, MaxBodySize), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := parseHead(body)
+	preview := meta.preview(resp.Request.URL)
+
+	if (preview.Title == "" || preview.ImageURL == "") && meta.oEmbedURL != "" {
+		oEmbedURL, err := resp.Request.URL.Parse(meta.oEmbedURL)
+		if err == nil && checkURL(oEmbedURL) == nil {
+			oEmbed, err := f.fetchOEmbed(ctx, oEmbedURL.String())
+			if err == nil {
+				oEmbed.fill(preview, resp.Request.URL)
+			}
+		}
+	}
+
+	if preview.Title == "" {
+		preview.Title = meta.title
+	}
+
+	preview.truncate()
+
+	return preview, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("linkpreview: unexpected status %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+type oEmbedData struct {
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (f *Fetcher) fetchOEmbed(ctx context.Context, rawURL string) (*oEmbedData, error) {
+	resp, err := f.get(ctx, rawURL, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data oEmbedData
+	err = json.NewDecoder(io.LimitReader(resp.Body, MaxBodySize)).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (o *oEmbedData) fill(p *Preview, base *url.URL) {
+	if p.Title == "" {
+		p.Title = strings.TrimSpace(o.Title)
+	}
+
+	if p.Description == "" {
+		p.Description = strings.TrimSpace(o.AuthorName)
+	}
+
+	if p.SiteName == "" {
+		p.SiteName = strings.TrimSpace(o.ProviderName)
+	}
+
+	if p.ImageURL == "" {
+		p.ImageURL = imageURL(base, o.ThumbnailURL)
+	}
+}
+
+type headMeta struct {
+	title     string
+	props     map[string]string
+	oEmbedURL string
+}
+
+// Read the title, meta and oEmbed link tags until the end of head
+func parseHead(r io.Reader) *headMeta {
+	meta := &headMeta{
+		props: make(map[string]string),
+	}
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return meta
+		case html.TextToken:
+			if inTitle {
+				meta.title += string(z.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			token := z.Token()
+			switch token.Data {
+			case "title":
+				inTitle = tt == html.StartTagToken && meta.title == ""
+			case "meta":
+				key := strings.ToLower(attr(token, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(token, "name"))
+				}
+				if _, ok := meta.props[key]; key != "" && !ok {
+					meta.props[key] = attr(token, "content")
+				}
+			case "link":
+				if strings.EqualFold(attr(token, "rel"), "alternate") &&
+					strings.EqualFold(attr(token, "type"), "application/json+oembed") &&
+					meta.oEmbedURL == "" {
+					meta.oEmbedURL = attr(token, "href")
+				}
+			case "head":
+				if tt == html.EndTagToken {
+					return meta
+				}
+			case "body":
+				return meta
+			}
+		}
+	}
+}
+
+func attr(token html.Token, key string) string {
+	for _, item := range token.Attr {
+		if item.Key == key {
+			return strings.TrimSpace(item.Val)
+		}
+	}
+	return ""
+}
+
+func (m *headMeta) preview(base *url.URL) *Preview {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if val := strings.TrimSpace(m.props[key]); val != "" {
+				return val
+			}
+		}
+		return ""
+	}
+
+	m.title = strings.Join(strings.Fields(m.title), " ")
+
+	return &Preview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		ImageURL:    imageURL(base, first("og:image", "og:image:url", "twitter:image")),
+		SiteName:    first("og:site_name"),
+	}
+}
+
+// Only https images are kept, others are blocked by the content security
+// policy of pages
+func imageURL(base *url.URL, rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := base.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return ""
+	}
+
+	str := u.String()
+	if len(str) > MaxImageURLLen {
+		return ""
+	}
+	return str
+}
+
+func (p *Preview) truncate() {
+	p.Title = truncate(p.Title, MaxTitleLen)
+	p.Description = truncate(p.Description, MaxDescriptionLen)
+	p.SiteName = truncate(p.SiteName, MaxSiteNameLen)
+}
+
+func truncate(str string, n int) string {
+	str = strings.Join(strings.Fields(str), " ")
+	if utf8.RuneCountInString(str) <= n {
+		return str
+	}
+	return string([]rune(str)[:n])
+}
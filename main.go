@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
 	"net"
@@ -16,8 +17,11 @@ import (
 	"github.com/oodzchen/dproject/config"
 	i18nc "github.com/oodzchen/dproject/i18n"
 	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/oauth"
 	"github.com/oodzchen/dproject/service"
+	"github.com/oodzchen/dproject/storage"
 	"github.com/oodzchen/dproject/store"
+	"github.com/oodzchen/dproject/store/cache"
 	"github.com/oodzchen/dproject/store/pgstore"
 	"github.com/oodzchen/dproject/utils"
 	"github.com/oschwald/geoip2-golang"
@@ -39,6 +43,14 @@ func main() {
 
 	appCfg := config.Config
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(appCfg, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if appCfg.Debug {
 		runtime.GOMAXPROCS(1)
 	}
@@ -69,6 +81,22 @@ func main() {
 		log.Fatal(err)
 	}
 
+	oauthData, err := config.ParseOAuthData("./config/oauth.yml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, item := range oauthData.Providers {
+		if !model.AuthType(item.AuthType).IsOAuth() {
+			log.Fatalf("auth type %q of oauth provider %s is not supported", item.AuthType, item.Name)
+		}
+	}
+
+	oauthRegistry, err := oauth.NewRegistry(oauthData, appCfg.GetServerURL(), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	geoDB, err := geoip2.Open("./geoip/Country.mmdb")
 	if err != nil {
 		log.Fatal(err)
@@ -91,6 +119,16 @@ func main() {
 	fmt.Println("connected database successfully")
 	defer pg.CloseDB()
 
+	migrator, err := pgstore.NewMigrator()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = migrator.CheckPending()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	redisAddr := net.JoinHostPort(appCfg.Redis.Host, appCfg.Redis.Port)
 	fmt.Printf("connecting redis at %s ...\n", redisAddr)
 	redisDB := redis.NewClient(&redis.Options{
@@ -114,17 +152,20 @@ func main() {
 
 	fmt.Println("pg module init successfully")
 
-	// dataStore, err := store.New(pg, redisDB)
-	// if err != nil {
-	// 	log.Fatal(err)
-	// }
-	// cacheableArticle := &cache.ArticleCache{
-	// 	Article: pg.Article,
-	// 	Rdb:     redisDB,
-	// }
-	// cacheableArticle.SetAfterUpdateWeights(cacheableArticle.RefreshListCache)
+	var articleStore store.ArticleStore = pg.Article
+	var categoryStore store.CategoryStore = pg.Category
+	if appCfg.CacheEnabled {
+		dataCache := cache.New(redisDB, time.Duration(appCfg.CacheTTLSeconds)*time.Second)
+		articleStore = cache.NewArticleCache(pg.Article, dataCache)
+		categoryStore = cache.NewCategoryCache(pg.Category, dataCache)
+
+		expvar.Publish("cache", expvar.Func(func() any {
+			return dataCache.Stats()
+		}))
+		fmt.Println("data cache enabled")
+	}
 
-	dataStore := store.New(pg.Article, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, pg.Category)
+	dataStore := store.New(articleStore, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, categoryStore, pg.ApiToken, pg.Tag, pg.Report, pg.Attachment, pg.LinkPreview, pg.Poll, pg.Draft)
 
 	permissionSrv := &service.Permission{
 		Store:          dataStore,
@@ -171,9 +212,28 @@ func main() {
 	port := appCfg.AppPort
 	addr := fmt.Sprintf("%s:%d", localHost, port)
 
-	sanitizePolicy := bluemonday.UGCPolicy()
+	sanitizePolicy := utils.AllowMarkdown(bluemonday.UGCPolicy())
 	// sanitizePolicy.AllowElements("b")
 
+	var fileStorage storage.Storage
+	switch appCfg.Storage.Type {
+	case "s3":
+		fileStorage = storage.NewS3(
+			appCfg.Storage.S3Endpoint,
+			appCfg.Storage.S3Region,
+			appCfg.Storage.S3Bucket,
+			appCfg.Storage.S3AccessKey,
+			appCfg.Storage.S3SecretKey,
+		)
+	case "local":
+		fileStorage, err = storage.NewLocal(appCfg.Storage.LocalDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown storage type: %s", appCfg.Storage.Type)
+	}
+
 	mail := service.NewMail(
 		appCfg.SMTP.User,
 		appCfg.SMTP.Password,
@@ -186,14 +246,20 @@ func main() {
 	server := &http.Server{
 		Addr: addr,
 		Handler: (Service(&ServiceConfig{
-			sessSecret:     appCfg.SessionSecret,
-			store:          dataStore,
-			permisisonSrv:  permissionSrv,
-			sanitizePolicy: sanitizePolicy,
-			i18nCustom:     i18nCustom,
-			rdb:            redisDB,
-			mail:           mail,
-			geoDB:          geoDB,
+			sessSecret:       appCfg.SessionSecret,
+			store:            dataStore,
+			permisisonSrv:    permissionSrv,
+			sanitizePolicy:   sanitizePolicy,
+			i18nCustom:       i18nCustom,
+			rdb:              redisDB,
+			mail:             mail,
+			geoDB:            geoDB,
+			storage:          fileStorage,
+			draftExpire:      time.Duration(appCfg.DraftExpireDays) * 24 * time.Hour,
+			oauth:            oauthRegistry,
+			accountDeletion:  time.Duration(appCfg.AccountDeletionDays) * 24 * time.Hour,
+			usernameCooldown: time.Duration(appCfg.UsernameCooldownDays) * 24 * time.Hour,
+			usernameRedirect: time.Duration(appCfg.UsernameRedirectDays) * 24 * time.Hour,
 		})),
 	}
 
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/gorilla/sessions"
 	i18nc "github.com/oodzchen/dproject/i18n"
 	"github.com/oodzchen/dproject/model"
 	"github.com/oodzchen/dproject/service"
@@ -24,6 +23,7 @@ import (
 type Renderer interface {
 	ServerErrorp(msg string, err error, w http.ResponseWriter, r *http.Request)
 	Forbidden(err error, w http.ResponseWriter, r *http.Request)
+	Unauthorized(err error, w http.ResponseWriter, r *http.Request)
 	GetLoginedUserData(r *http.Request) *model.User
 }
 
@@ -33,7 +33,7 @@ func logSessError(sessName string, err error) {
 	}
 }
 
-func FetchUserData(store *store.Store, sessStore *sessions.CookieStore, permissionSrv *service.Permission, renderer any) func(http.Handler) http.Handler {
+func FetchUserData(store *store.Store, sessStore *service.SessionStore, permissionSrv *service.Permission, renderer any) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			sess, err := sessStore.Get(r, "one")
@@ -62,6 +62,11 @@ func FetchUserData(store *store.Store, sessStore *sessions.CookieStore, permissi
 					return
 				}
 				userData = user
+
+				err = sessStore.Touch(r, w, sess)
+				if err != nil {
+					fmt.Println("refresh session device error:", err)
+				}
 				// permissionSrv.SetLoginedUser(user)
 			} else {
 				userData = nil
@@ -74,7 +79,54 @@ func FetchUserData(store *store.Store, sessStore *sessions.CookieStore, permissi
 	}
 }
 
-func AuthCheck(sessStore *sessions.CookieStore) func(http.Handler) http.Handler {
+// Authenticate request with API token in Authorization header,
+// the token owner will be set into "user_data" with permissions limited by the token.
+// Requests without Authorization header are passed through.
+func TokenAuth(store *store.Store, apiTokenSrv *service.ApiToken, renderer Renderer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+			if authHeader == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, found := strings.Cut(authHeader, " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") {
+				renderer.Unauthorized(errors.New("authorization scheme not supported"), w, r)
+				return
+			}
+
+			apiToken, err := apiTokenSrv.Verify(strings.TrimSpace(token))
+			if err != nil {
+				if errors.Is(err, service.ErrApiTokenInvalid) {
+					renderer.Unauthorized(err, w, r)
+				} else {
+					renderer.ServerErrorp("", err, w, r)
+				}
+				return
+			}
+
+			user, err := store.User.Item(apiToken.UserId)
+			if err != nil {
+				renderer.Unauthorized(err, w, r)
+				return
+			}
+
+			if user.Deleted {
+				renderer.Unauthorized(errors.New("user is deleted"), w, r)
+				return
+			}
+
+			user.ScopeToken(apiToken.Permissions)
+
+			ctx := context.WithValue(r.Context(), "user_data", user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func AuthCheck(sessStore *service.SessionStore) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// fmt.Printf("r.URL.Path: %s\n", r.URL.Path)
@@ -96,6 +148,36 @@ func AuthCheck(sessStore *sessions.CookieStore) func(http.Handler) http.Handler
 	}
 }
 
+// Paths still reachable before the required two-factor authentication is
+// enabled
+var twoFactorExemptPaths = []string{
+	"/settings/account/2fa",
+	"/logout",
+}
+
+// Users of the roles requiring two-factor authentication are redirected to
+// enable it first
+func Require2FA(permissionSrv *service.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := r.Context().Value("user_data").(*model.User)
+			if user == nil || user.TotpEnabled || !permissionSrv.RoleData.Require2FA(user.RoleFrontId) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, path := range twoFactorExemptPaths {
+				if strings.HasPrefix(r.URL.Path, path) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		})
+	}
+}
+
 func toForbiddenPage(renderer Renderer, w http.ResponseWriter, r *http.Request) {
 	renderer.Forbidden(nil, w, r)
 }
@@ -178,6 +260,40 @@ var (
 		u.TargetId = id
 		return nil
 	}
+
+	ULogNewCategoryFrontId = func(u *service.UserLogData, w http.ResponseWriter, r *http.Request) error {
+		frontId, ok := r.Context().Value("category_front_id").(string)
+		if !ok {
+			return errors.New("get category front id failed")
+		}
+		u.TargetId = frontId
+		return nil
+	}
+
+	ULogRecoveryCodeUsed = func(u *service.UserLogData, w http.ResponseWriter, r *http.Request) error {
+		if used, ok := r.Context().Value("recovery_code_used").(bool); ok && used {
+			u.Action = model.AcActionUseRecoveryCode
+		}
+		return nil
+	}
+
+	ULogOldUsername = func(u *service.UserLogData, w http.ResponseWriter, r *http.Request) error {
+		oldName, ok := r.Context().Value("old_username").(string)
+		if !ok {
+			return errors.New("get old username failed")
+		}
+		u.TargetId = oldName
+		return nil
+	}
+
+	ULogURLCategoryFrontId = func(u *service.UserLogData, w http.ResponseWriter, r *http.Request) error {
+		frontId := chi.URLParam(r, "categoryFrontId")
+		if frontId == "" {
+			return errors.New("category front id is empty")
+		}
+		u.TargetId = frontId
+		return nil
+	}
 )
 
 func getLoginedUserData(r *http.Request) (*model.User, error) {
@@ -225,15 +341,15 @@ func UserLogger(uLogger *service.UserLogger, actType model.AcType, action model.
 			go func() {
 				err := uLogger.Log(user, func(r *http.Request) (*service.UserLogData, error) {
 					postData := make(map[string]any)
-					if r.Method == "POST" {
-						if r.PostForm["tk"][0] == "" {
+					if r.Method == "POST" && !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+						if r.PostForm.Get("tk") == "" {
 							// fmt.Println("csrf token is required")
 							return nil, errors.New("csrf token is required")
 						}
 					}
 
 					for k, v := range r.PostForm {
-						if k == "tk" || k == "password" || k == "confirm-password" {
+						if k == "tk" || k == "password" || k == "confirm-password" || k == "two_factor_code" {
 							continue
 						}
 						for idx, str := range v {
@@ -256,7 +372,7 @@ func UserLogger(uLogger *service.UserLogger, actType model.AcType, action model.
 	}
 }
 
-func getLoginUserId(sessStore *sessions.CookieStore, w http.ResponseWriter, r *http.Request) (int, error) {
+func getLoginUserId(sessStore *service.SessionStore, w http.ResponseWriter, r *http.Request) (int, error) {
 	sess, err := sessStore.Get(r, "one")
 	logSessError("one", errors.WithStack(err))
 
@@ -267,18 +383,20 @@ func getLoginUserId(sessStore *sessions.CookieStore, w http.ResponseWriter, r *h
 	return 0, errors.WithStack(errors.New("no user id in session"))
 }
 
-func isLogin(sessStore *sessions.CookieStore, w http.ResponseWriter, r *http.Request) bool {
+func isLogin(sessStore *service.SessionStore, w http.ResponseWriter, r *http.Request) bool {
 	_, err := getLoginUserId(sessStore, w, r)
 	return err == nil
 }
 
-func CreateUISettingsMiddleware(sessStore *sessions.CookieStore, sm *service.SettingsManager, ic *i18nc.I18nCustom) func(http.Handler) http.Handler {
+func CreateUISettingsMiddleware(sessStore *service.SessionStore, sm *service.SettingsManager, ic *i18nc.I18nCustom) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			localSess, err := sessStore.Get(r, "local")
 			logSessError("local", errors.WithStack(err))
 
-			uiSettings := model.DefaultUiSettings
+			// Copy the default settings, which is shared by all requests
+			defaultSettings := *model.DefaultUiSettings
+			uiSettings := &defaultSettings
 			uiSettings.Lang = getAcceptLang(r)
 
 			settingsId := localSess.Values["ui-settings-id"]
@@ -292,10 +410,10 @@ func CreateUISettingsMiddleware(sessStore *sessions.CookieStore, sm *service.Set
 				}
 			}
 
-			ic.SwitchLang(uiSettings.Lang.String())
-			model.UpdateErrI18n()
-
+			// Every request carries its own localizer, the shared one must not be
+			// switched to the language of a single request
 			ctx := context.WithValue(r.Context(), "ui_settings", uiSettings)
+			ctx = context.WithValue(ctx, "i18n_custom", ic.WithLang(uiSettings.Lang.String()))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
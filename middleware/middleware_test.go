@@ -2,9 +2,13 @@ package mdw
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	i18nc "github.com/oodzchen/dproject/i18n"
 	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/service"
 )
 
 func TestParseStrLang(t *testing.T) {
@@ -54,3 +58,49 @@ func TestParseStrLang(t *testing.T) {
 		})
 	}
 }
+
+func TestUISettingsLocalizer(t *testing.T) {
+	ic := i18nc.New([]string{})
+	// Requests without cookies never reach redis
+	sessStore := service.NewSessionStore(nil, []byte("test-session-key"))
+	handler := CreateUISettingsMiddleware(sessStore, nil, ic)
+
+	tests := []struct {
+		acceptLang string
+		want       string
+	}{
+		{acceptLang: "ja,en;q=0.9", want: "ja"},
+		{acceptLang: "zh-TW,zh;q=0.9", want: "zh-Hant"},
+		{acceptLang: "zh-CN,zh;q=0.9", want: "zh-Hans"},
+		{acceptLang: "", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("accept language: %s", tt.acceptLang), func(t *testing.T) {
+			var got *i18nc.I18nCustom
+			h := handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = r.Context().Value("i18n_custom").(*i18nc.I18nCustom)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", tt.acceptLang)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got == nil {
+				t.Fatal("no localizer in request context")
+			}
+
+			if got.CurrLang != tt.want {
+				t.Errorf("want %s, but got %s", tt.want, got.CurrLang)
+			}
+
+			if ic.CurrLang != "en" {
+				t.Errorf("shared localizer should not be switched, but got %s", ic.CurrLang)
+			}
+
+			if model.DefaultUiSettings.Lang != model.LangEn {
+				t.Errorf("default ui settings should not be changed, but got %s", model.DefaultUiSettings.Lang)
+			}
+		})
+	}
+}
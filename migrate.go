@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/oodzchen/dproject/config"
+	"github.com/oodzchen/dproject/store/pgstore"
+)
+
+const migrateUsage = `Usage: dproject migrate <command>
+
+Commands:
+  up        Apply all pending migrations
+  down      Roll back the last applied migration
+  status    Show applied and pending migrations
+  to N      Migrate up or down to version N, 0 to roll back all
+  force N   Mark migrations up to version N as applied without running them
+`
+
+func runMigrate(appCfg *config.AppConfig, args []string) error {
+	migrateCmd := flag.NewFlagSet("migrate", flag.ExitOnError)
+	migrateCmd.Usage = func() {
+		fmt.Fprint(migrateCmd.Output(), migrateUsage)
+	}
+	migrateCmd.Parse(args)
+
+	if migrateCmd.NArg() == 0 {
+		migrateCmd.Usage()
+		return errors.New("migrate command is required")
+	}
+
+	pg := pgstore.New(&pgstore.DBConfig{
+		DSN: appCfg.DB.GetDSN(),
+	})
+
+	err := pg.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer pg.CloseDB()
+
+	migrator, err := pgstore.NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	cmd := migrateCmd.Arg(0)
+	switch cmd {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "status":
+		err = printMigrationStatus(migrator)
+	case "to", "force":
+		var version int
+		version, err = strconv.Atoi(migrateCmd.Arg(1))
+		if err != nil || version < 0 {
+			migrateCmd.Usage()
+			return fmt.Errorf("invalid version: %q", migrateCmd.Arg(1))
+		}
+
+		if cmd == "to" {
+			err = migrator.To(version)
+		} else {
+			err = migrator.Force(version)
+		}
+	default:
+		migrateCmd.Usage()
+		return fmt.Errorf("unknown migrate command: %s", cmd)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if cmd != "status" {
+		version, err := migrator.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database schema version: %d\n", version)
+	}
+
+	return nil
+}
+
+func printMigrationStatus(migrator *pgstore.Migrator) error {
+	list, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, item := range list {
+		status, appliedAt := "pending", ""
+		if item.Applied {
+			status = "applied"
+			appliedAt = item.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", item.Version, item.Name, status, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,194 @@
+package mocktool
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+// MockOIDC is a local OpenID Connect provider for testing logins, the
+// authorize endpoint issues codes for the user of the claims without the
+// consent page
+type MockOIDC struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+	// Claims returned by the userinfo endpoint
+	Claims map[string]any
+	// Emails returned by the emails endpoint, same as the GitHub api
+	Emails []map[string]any
+
+	mu     sync.Mutex
+	codes  map[string]*mockAuthCode
+	tokens map[string]bool
+}
+
+type mockAuthCode struct {
+	redirectURI   string
+	codeChallenge string
+}
+
+func NewMockOIDC(clientID, clientSecret string, claims map[string]any) *MockOIDC {
+	m := &MockOIDC{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Claims:       claims,
+		codes:        make(map[string]*mockAuthCode),
+		tokens:       make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/userinfo", m.userInfo)
+	mux.HandleFunc("/emails", m.emails)
+	m.Server = httptest.NewServer(mux)
+
+	return m
+}
+
+// Issuer of the provider, the endpoints are discovered from it
+func (m *MockOIDC) Issuer() string {
+	return m.Server.URL
+}
+
+func (m *MockOIDC) Close() {
+	m.Server.Close()
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (m *MockOIDC) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 m.Issuer(),
+		"authorization_endpoint": m.Issuer() + "/authorize",
+		"token_endpoint":         m.Issuer() + "/token",
+		"userinfo_endpoint":      m.Issuer() + "/userinfo",
+	})
+}
+
+func (m *MockOIDC) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != m.ClientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	if method := query.Get("code_challenge_method"); method != "" && method != "S256" {
+		http.Error(w, "unsupported code challenge method", http.StatusBadRequest)
+		return
+	}
+
+	code := randomHex()
+	m.mu.Lock()
+	m.codes[code] = &mockAuthCode{
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	m.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (m *MockOIDC) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.ParseForm()
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != m.ClientID || clientSecret != m.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	authCode, ok := m.codes[code]
+	// Codes can be used only once
+	delete(m.codes, code)
+	m.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok || authCode.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	if authCode.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != authCode.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+	}
+
+	accessToken := randomHex()
+	m.mu.Lock()
+	m.tokens[accessToken] = true
+	m.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (m *MockOIDC) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokens[header[len(prefix):]]
+}
+
+func (m *MockOIDC) userInfo(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, m.Claims)
+}
+
+func (m *MockOIDC) emails(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, m.Emails)
+}
@@ -30,6 +30,35 @@ ENUM(
    fade_out_article, // Fade out article
    ban_user, // Ban user
    unban_user, // Unban user
+   create_api_token, // Create API token
+   revoke_api_token, // Revoke API token
+   create_tag, // Create tag
+   edit_tag, // Edit tag
+   delete_tag, // Delete tag
+   propose_category, // Propose category
+   approve_category, // Approve category
+   reject_category, // Reject category
+   add_category_moderator, // Add category moderator
+   remove_category_moderator, // Remove category moderator
+   block_user, // Block user
+   unblock_user, // Unblock user
+   report_article, // Report article
+   resolve_report, // Resolve report
+   update_notify_settings, // Update notification settings
+   mark_duplicate, // Mark article as duplicate
+   vote_poll, // Vote poll
+   revoke_session, // Revoke login session
+   revoke_other_sessions, // Revoke other login sessions
+   enable_2fa, // Enable two-factor authentication
+   disable_2fa, // Disable two-factor authentication
+   use_recovery_code, // Login with two-factor recovery code
+   regenerate_recovery_codes, // Regenerate two-factor recovery codes
+   link_login_method, // Link login method
+   unlink_login_method, // Unlink login method
+   export_account_data, // Export account data
+   request_account_deletion, // Request account deletion
+   cancel_account_deletion, // Cancel account deletion
+   change_username, // Change username
 )
 */
 type AcAction string
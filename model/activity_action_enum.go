@@ -90,6 +90,93 @@ const (
 	// AcActionUnbanUser is a AcAction of type unban_user.
 	// Unban user
 	AcActionUnbanUser AcAction = "unban_user"
+	// AcActionCreateApiToken is a AcAction of type create_api_token.
+	// Create API token
+	AcActionCreateApiToken AcAction = "create_api_token"
+	// AcActionRevokeApiToken is a AcAction of type revoke_api_token.
+	// Revoke API token
+	AcActionRevokeApiToken AcAction = "revoke_api_token"
+	// AcActionCreateTag is a AcAction of type create_tag.
+	// Create tag
+	AcActionCreateTag AcAction = "create_tag"
+	// AcActionEditTag is a AcAction of type edit_tag.
+	// Edit tag
+	AcActionEditTag AcAction = "edit_tag"
+	// AcActionDeleteTag is a AcAction of type delete_tag.
+	// Delete tag
+	AcActionDeleteTag AcAction = "delete_tag"
+	// AcActionProposeCategory is a AcAction of type propose_category.
+	// Propose category
+	AcActionProposeCategory AcAction = "propose_category"
+	// AcActionApproveCategory is a AcAction of type approve_category.
+	// Approve category
+	AcActionApproveCategory AcAction = "approve_category"
+	// AcActionRejectCategory is a AcAction of type reject_category.
+	// Reject category
+	AcActionRejectCategory AcAction = "reject_category"
+	// AcActionAddCategoryModerator is a AcAction of type add_category_moderator.
+	// Add category moderator
+	AcActionAddCategoryModerator AcAction = "add_category_moderator"
+	// AcActionRemoveCategoryModerator is a AcAction of type remove_category_moderator.
+	// Remove category moderator
+	AcActionRemoveCategoryModerator AcAction = "remove_category_moderator"
+	// AcActionBlockUser is a AcAction of type block_user.
+	// Block user
+	AcActionBlockUser AcAction = "block_user"
+	// AcActionUnblockUser is a AcAction of type unblock_user.
+	// Unblock user
+	AcActionUnblockUser AcAction = "unblock_user"
+	// AcActionReportArticle is a AcAction of type report_article.
+	// Report article
+	AcActionReportArticle AcAction = "report_article"
+	// AcActionResolveReport is a AcAction of type resolve_report.
+	// Resolve report
+	AcActionResolveReport AcAction = "resolve_report"
+	// AcActionUpdateNotifySettings is a AcAction of type update_notify_settings.
+	// Update notification settings
+	AcActionUpdateNotifySettings AcAction = "update_notify_settings"
+	// AcActionMarkDuplicate is a AcAction of type mark_duplicate.
+	// Mark article as duplicate
+	AcActionMarkDuplicate AcAction = "mark_duplicate"
+	// AcActionVotePoll is a AcAction of type vote_poll.
+	// Vote poll
+	AcActionVotePoll AcAction = "vote_poll"
+	// AcActionRevokeSession is a AcAction of type revoke_session.
+	// Revoke login session
+	AcActionRevokeSession AcAction = "revoke_session"
+	// AcActionRevokeOtherSessions is a AcAction of type revoke_other_sessions.
+	// Revoke other login sessions
+	AcActionRevokeOtherSessions AcAction = "revoke_other_sessions"
+	// AcActionEnable2Fa is a AcAction of type enable_2fa.
+	// Enable two-factor authentication
+	AcActionEnable2Fa AcAction = "enable_2fa"
+	// AcActionDisable2Fa is a AcAction of type disable_2fa.
+	// Disable two-factor authentication
+	AcActionDisable2Fa AcAction = "disable_2fa"
+	// AcActionUseRecoveryCode is a AcAction of type use_recovery_code.
+	// Login with two-factor recovery code
+	AcActionUseRecoveryCode AcAction = "use_recovery_code"
+	// AcActionRegenerateRecoveryCodes is a AcAction of type regenerate_recovery_codes.
+	// Regenerate two-factor recovery codes
+	AcActionRegenerateRecoveryCodes AcAction = "regenerate_recovery_codes"
+	// AcActionLinkLoginMethod is a AcAction of type link_login_method.
+	// Link login method
+	AcActionLinkLoginMethod AcAction = "link_login_method"
+	// AcActionUnlinkLoginMethod is a AcAction of type unlink_login_method.
+	// Unlink login method
+	AcActionUnlinkLoginMethod AcAction = "unlink_login_method"
+	// AcActionExportAccountData is a AcAction of type export_account_data.
+	// Export account data
+	AcActionExportAccountData AcAction = "export_account_data"
+	// AcActionRequestAccountDeletion is a AcAction of type request_account_deletion.
+	// Request account deletion
+	AcActionRequestAccountDeletion AcAction = "request_account_deletion"
+	// AcActionCancelAccountDeletion is a AcAction of type cancel_account_deletion.
+	// Cancel account deletion
+	AcActionCancelAccountDeletion AcAction = "cancel_account_deletion"
+	// AcActionChangeUsername is a AcAction of type change_username.
+	// Change username
+	AcActionChangeUsername AcAction = "change_username"
 )
 
 var ErrInvalidAcAction = fmt.Errorf("not a valid AcAction, try [%s]", strings.Join(_AcActionNames, ", "))
@@ -120,6 +207,35 @@ var _AcActionNames = []string{
 	string(AcActionFadeOutArticle),
 	string(AcActionBanUser),
 	string(AcActionUnbanUser),
+	string(AcActionCreateApiToken),
+	string(AcActionRevokeApiToken),
+	string(AcActionCreateTag),
+	string(AcActionEditTag),
+	string(AcActionDeleteTag),
+	string(AcActionProposeCategory),
+	string(AcActionApproveCategory),
+	string(AcActionRejectCategory),
+	string(AcActionAddCategoryModerator),
+	string(AcActionRemoveCategoryModerator),
+	string(AcActionBlockUser),
+	string(AcActionUnblockUser),
+	string(AcActionReportArticle),
+	string(AcActionResolveReport),
+	string(AcActionUpdateNotifySettings),
+	string(AcActionMarkDuplicate),
+	string(AcActionVotePoll),
+	string(AcActionRevokeSession),
+	string(AcActionRevokeOtherSessions),
+	string(AcActionEnable2Fa),
+	string(AcActionDisable2Fa),
+	string(AcActionUseRecoveryCode),
+	string(AcActionRegenerateRecoveryCodes),
+	string(AcActionLinkLoginMethod),
+	string(AcActionUnlinkLoginMethod),
+	string(AcActionExportAccountData),
+	string(AcActionRequestAccountDeletion),
+	string(AcActionCancelAccountDeletion),
+	string(AcActionChangeUsername),
 }
 
 // AcActionNames returns a list of possible string values of AcAction.
@@ -157,6 +273,35 @@ func AcActionValues() []AcAction {
 		AcActionFadeOutArticle,
 		AcActionBanUser,
 		AcActionUnbanUser,
+		AcActionCreateApiToken,
+		AcActionRevokeApiToken,
+		AcActionCreateTag,
+		AcActionEditTag,
+		AcActionDeleteTag,
+		AcActionProposeCategory,
+		AcActionApproveCategory,
+		AcActionRejectCategory,
+		AcActionAddCategoryModerator,
+		AcActionRemoveCategoryModerator,
+		AcActionBlockUser,
+		AcActionUnblockUser,
+		AcActionReportArticle,
+		AcActionResolveReport,
+		AcActionUpdateNotifySettings,
+		AcActionMarkDuplicate,
+		AcActionVotePoll,
+		AcActionRevokeSession,
+		AcActionRevokeOtherSessions,
+		AcActionEnable2Fa,
+		AcActionDisable2Fa,
+		AcActionUseRecoveryCode,
+		AcActionRegenerateRecoveryCodes,
+		AcActionLinkLoginMethod,
+		AcActionUnlinkLoginMethod,
+		AcActionExportAccountData,
+		AcActionRequestAccountDeletion,
+		AcActionCancelAccountDeletion,
+		AcActionChangeUsername,
 	}
 }
 
@@ -173,31 +318,60 @@ func (x AcAction) IsValid() bool {
 }
 
 var _AcActionValue = map[string]AcAction{
-	"register":            AcActionRegister,
-	"register_verify":     AcActionRegisterVerify,
-	"login":               AcActionLogin,
-	"logout":              AcActionLogout,
-	"update_intro":        AcActionUpdateIntro,
-	"create_article":      AcActionCreateArticle,
-	"reply_article":       AcActionReplyArticle,
-	"edit_article":        AcActionEditArticle,
-	"delete_article":      AcActionDeleteArticle,
-	"save_article":        AcActionSaveArticle,
-	"vote_article":        AcActionVoteArticle,
-	"react_article":       AcActionReactArticle,
-	"set_role":            AcActionSetRole,
-	"add_role":            AcActionAddRole,
-	"edit_role":           AcActionEditRole,
-	"subscribe_article":   AcActionSubscribeArticle,
-	"retrieve_password":   AcActionRetrievePassword,
-	"reset_password":      AcActionResetPassword,
-	"toggle_hide_history": AcActionToggleHideHistory,
-	"recover":             AcActionRecover,
-	"block_regions":       AcActionBlockRegions,
-	"lock_article":        AcActionLockArticle,
-	"fade_out_article":    AcActionFadeOutArticle,
-	"ban_user":            AcActionBanUser,
-	"unban_user":          AcActionUnbanUser,
+	"register":                  AcActionRegister,
+	"register_verify":           AcActionRegisterVerify,
+	"login":                     AcActionLogin,
+	"logout":                    AcActionLogout,
+	"update_intro":              AcActionUpdateIntro,
+	"create_article":            AcActionCreateArticle,
+	"reply_article":             AcActionReplyArticle,
+	"edit_article":              AcActionEditArticle,
+	"delete_article":            AcActionDeleteArticle,
+	"save_article":              AcActionSaveArticle,
+	"vote_article":              AcActionVoteArticle,
+	"react_article":             AcActionReactArticle,
+	"set_role":                  AcActionSetRole,
+	"add_role":                  AcActionAddRole,
+	"edit_role":                 AcActionEditRole,
+	"subscribe_article":         AcActionSubscribeArticle,
+	"retrieve_password":         AcActionRetrievePassword,
+	"reset_password":            AcActionResetPassword,
+	"toggle_hide_history":       AcActionToggleHideHistory,
+	"recover":                   AcActionRecover,
+	"block_regions":             AcActionBlockRegions,
+	"lock_article":              AcActionLockArticle,
+	"fade_out_article":          AcActionFadeOutArticle,
+	"ban_user":                  AcActionBanUser,
+	"unban_user":                AcActionUnbanUser,
+	"create_api_token":          AcActionCreateApiToken,
+	"revoke_api_token":          AcActionRevokeApiToken,
+	"create_tag":                AcActionCreateTag,
+	"edit_tag":                  AcActionEditTag,
+	"delete_tag":                AcActionDeleteTag,
+	"propose_category":          AcActionProposeCategory,
+	"approve_category":          AcActionApproveCategory,
+	"reject_category":           AcActionRejectCategory,
+	"add_category_moderator":    AcActionAddCategoryModerator,
+	"remove_category_moderator": AcActionRemoveCategoryModerator,
+	"block_user":                AcActionBlockUser,
+	"unblock_user":              AcActionUnblockUser,
+	"report_article":            AcActionReportArticle,
+	"resolve_report":            AcActionResolveReport,
+	"update_notify_settings":    AcActionUpdateNotifySettings,
+	"mark_duplicate":            AcActionMarkDuplicate,
+	"vote_poll":                 AcActionVotePoll,
+	"revoke_session":            AcActionRevokeSession,
+	"revoke_other_sessions":     AcActionRevokeOtherSessions,
+	"enable_2fa":                AcActionEnable2Fa,
+	"disable_2fa":               AcActionDisable2Fa,
+	"use_recovery_code":         AcActionUseRecoveryCode,
+	"regenerate_recovery_codes": AcActionRegenerateRecoveryCodes,
+	"link_login_method":         AcActionLinkLoginMethod,
+	"unlink_login_method":       AcActionUnlinkLoginMethod,
+	"export_account_data":       AcActionExportAccountData,
+	"request_account_deletion":  AcActionRequestAccountDeletion,
+	"cancel_account_deletion":   AcActionCancelAccountDeletion,
+	"change_username":           AcActionChangeUsername,
 }
 
 // ParseAcAction attempts to convert a string to a AcAction.
@@ -213,31 +387,60 @@ func (x AcAction) I18nID() string {
 }
 
 var _AcActionTextMap = map[AcAction]string{
-	AcActionRegister:          "Register",
-	AcActionRegisterVerify:    "Registration verification",
-	AcActionLogin:             "Login",
-	AcActionLogout:            "Logout",
-	AcActionUpdateIntro:       "Update introduction",
-	AcActionCreateArticle:     "Create article",
-	AcActionReplyArticle:      "Reply to article",
-	AcActionEditArticle:       "Edit article",
-	AcActionDeleteArticle:     "Delete article",
-	AcActionSaveArticle:       "Save article",
-	AcActionVoteArticle:       "Vote article",
-	AcActionReactArticle:      "React to article",
-	AcActionSetRole:           "Set role",
-	AcActionAddRole:           "Add role",
-	AcActionEditRole:          "Edit role",
-	AcActionSubscribeArticle:  "Subscribe article",
-	AcActionRetrievePassword:  "Retrieve password",
-	AcActionResetPassword:     "Reset password",
-	AcActionToggleHideHistory: "Toggle hide history",
-	AcActionRecover:           "Recover article",
-	AcActionBlockRegions:      "Block regions",
-	AcActionLockArticle:       "Lock article",
-	AcActionFadeOutArticle:    "Fade out article",
-	AcActionBanUser:           "Ban user",
-	AcActionUnbanUser:         "Unban user",
+	AcActionRegister:                "Register",
+	AcActionRegisterVerify:          "Registration verification",
+	AcActionLogin:                   "Login",
+	AcActionLogout:                  "Logout",
+	AcActionUpdateIntro:             "Update introduction",
+	AcActionCreateArticle:           "Create article",
+	AcActionReplyArticle:            "Reply to article",
+	AcActionEditArticle:             "Edit article",
+	AcActionDeleteArticle:           "Delete article",
+	AcActionSaveArticle:             "Save article",
+	AcActionVoteArticle:             "Vote article",
+	AcActionReactArticle:            "React to article",
+	AcActionSetRole:                 "Set role",
+	AcActionAddRole:                 "Add role",
+	AcActionEditRole:                "Edit role",
+	AcActionSubscribeArticle:        "Subscribe article",
+	AcActionRetrievePassword:        "Retrieve password",
+	AcActionResetPassword:           "Reset password",
+	AcActionToggleHideHistory:       "Toggle hide history",
+	AcActionRecover:                 "Recover article",
+	AcActionBlockRegions:            "Block regions",
+	AcActionLockArticle:             "Lock article",
+	AcActionFadeOutArticle:          "Fade out article",
+	AcActionBanUser:                 "Ban user",
+	AcActionUnbanUser:               "Unban user",
+	AcActionCreateApiToken:          "Create API token",
+	AcActionRevokeApiToken:          "Revoke API token",
+	AcActionCreateTag:               "Create tag",
+	AcActionEditTag:                 "Edit tag",
+	AcActionDeleteTag:               "Delete tag",
+	AcActionProposeCategory:         "Propose category",
+	AcActionApproveCategory:         "Approve category",
+	AcActionRejectCategory:          "Reject category",
+	AcActionAddCategoryModerator:    "Add category moderator",
+	AcActionRemoveCategoryModerator: "Remove category moderator",
+	AcActionBlockUser:               "Block user",
+	AcActionUnblockUser:             "Unblock user",
+	AcActionReportArticle:           "Report article",
+	AcActionResolveReport:           "Resolve report",
+	AcActionUpdateNotifySettings:    "Update notification settings",
+	AcActionMarkDuplicate:           "Mark article as duplicate",
+	AcActionVotePoll:                "Vote poll",
+	AcActionRevokeSession:           "Revoke login session",
+	AcActionRevokeOtherSessions:     "Revoke other login sessions",
+	AcActionEnable2Fa:               "Enable two-factor authentication",
+	AcActionDisable2Fa:              "Disable two-factor authentication",
+	AcActionUseRecoveryCode:         "Login with two-factor recovery code",
+	AcActionRegenerateRecoveryCodes: "Regenerate two-factor recovery codes",
+	AcActionLinkLoginMethod:         "Link login method",
+	AcActionUnlinkLoginMethod:       "Unlink login method",
+	AcActionExportAccountData:       "Export account data",
+	AcActionRequestAccountDeletion:  "Request account deletion",
+	AcActionCancelAccountDeletion:   "Cancel account deletion",
+	AcActionChangeUsername:          "Change username",
 }
 
 func (x AcAction) Text(upCaseHead bool, i18nCustom *i18nc.I18nCustom) string {
@@ -359,4 +562,120 @@ func AcActionAddI18nConfigs(ic *i18nc.I18nCustom) {
 		ID:    "AcAction_unban_user",
 		Other: "Unban user",
 	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_create_api_token",
+		Other: "Create API token",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_revoke_api_token",
+		Other: "Revoke API token",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_create_tag",
+		Other: "Create tag",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_edit_tag",
+		Other: "Edit tag",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_delete_tag",
+		Other: "Delete tag",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_propose_category",
+		Other: "Propose category",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_approve_category",
+		Other: "Approve category",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_reject_category",
+		Other: "Reject category",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_add_category_moderator",
+		Other: "Add category moderator",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_remove_category_moderator",
+		Other: "Remove category moderator",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_block_user",
+		Other: "Block user",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_unblock_user",
+		Other: "Unblock user",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_report_article",
+		Other: "Report article",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_resolve_report",
+		Other: "Resolve report",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_update_notify_settings",
+		Other: "Update notification settings",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_mark_duplicate",
+		Other: "Mark article as duplicate",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_vote_poll",
+		Other: "Vote poll",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_revoke_session",
+		Other: "Revoke login session",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_revoke_other_sessions",
+		Other: "Revoke other login sessions",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_enable_2fa",
+		Other: "Enable two-factor authentication",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_disable_2fa",
+		Other: "Disable two-factor authentication",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_use_recovery_code",
+		Other: "Login with two-factor recovery code",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_regenerate_recovery_codes",
+		Other: "Regenerate two-factor recovery codes",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_link_login_method",
+		Other: "Link login method",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_unlink_login_method",
+		Other: "Unlink login method",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_export_account_data",
+		Other: "Export account data",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_request_account_deletion",
+		Other: "Request account deletion",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_cancel_account_deletion",
+		Other: "Cancel account deletion",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcAction_change_username",
+		Other: "Change username",
+	})
 }
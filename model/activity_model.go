@@ -9,6 +9,7 @@ package model
    user, // User
    article, // Article
    role, // Role
+   category, // Category
    )
 */
 type AcModel string
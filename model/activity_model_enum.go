@@ -27,6 +27,9 @@ const (
 	// AcModelRole is a AcModel of type role.
 	// Role
 	AcModelRole AcModel = "role"
+	// AcModelCategory is a AcModel of type category.
+	// Category
+	AcModelCategory AcModel = "category"
 )
 
 var ErrInvalidAcModel = fmt.Errorf("not a valid AcModel, try [%s]", strings.Join(_AcModelNames, ", "))
@@ -36,6 +39,7 @@ var _AcModelNames = []string{
 	string(AcModelUser),
 	string(AcModelArticle),
 	string(AcModelRole),
+	string(AcModelCategory),
 }
 
 // AcModelNames returns a list of possible string values of AcModel.
@@ -52,6 +56,7 @@ func AcModelValues() []AcModel {
 		AcModelUser,
 		AcModelArticle,
 		AcModelRole,
+		AcModelCategory,
 	}
 }
 
@@ -68,10 +73,11 @@ func (x AcModel) IsValid() bool {
 }
 
 var _AcModelValue = map[string]AcModel{
-	"empty":   AcModelEmpty,
-	"user":    AcModelUser,
-	"article": AcModelArticle,
-	"role":    AcModelRole,
+	"empty":    AcModelEmpty,
+	"user":     AcModelUser,
+	"article":  AcModelArticle,
+	"role":     AcModelRole,
+	"category": AcModelCategory,
 }
 
 // ParseAcModel attempts to convert a string to a AcModel.
@@ -87,10 +93,11 @@ func (x AcModel) I18nID() string {
 }
 
 var _AcModelTextMap = map[AcModel]string{
-	AcModelEmpty:   "Empty",
-	AcModelUser:    "User",
-	AcModelArticle: "Article",
-	AcModelRole:    "Role",
+	AcModelEmpty:    "Empty",
+	AcModelUser:     "User",
+	AcModelArticle:  "Article",
+	AcModelRole:     "Role",
+	AcModelCategory: "Category",
 }
 
 func (x AcModel) Text(upCaseHead bool, i18nCustom *i18nc.I18nCustom) string {
@@ -128,4 +135,8 @@ func AcModelAddI18nConfigs(ic *i18nc.I18nCustom) {
 		ID:    "AcModel_role",
 		Other: "Role",
 	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AcModel_category",
+		Other: "Category",
+	})
 }
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ApiTokenPrefix         = "dp_"
+	MaxApiTokenNameLen     = 50
+	MaxApiTokenCount   int = 20
+)
+
+type ApiToken struct {
+	Id     int
+	UserId int
+	Name   string
+	// First characters of the token, only for display
+	Prefix string
+	// Permission front ids the token limited to, such as article.create
+	Permissions    []string
+	CreatedAt      time.Time
+	NullLastUsedAt pgtype.Timestamp
+	LastUsedAt     time.Time
+}
+
+func (t *ApiToken) FormatNullVals() {
+	if t.NullLastUsedAt.Valid {
+		t.LastUsedAt = t.NullLastUsedAt.Time
+	}
+}
+
+func (t *ApiToken) Sanitize() {
+	t.Name = html.EscapeString(t.Name)
+}
+
+func (t *ApiToken) TrimSpace() {
+	t.Name = strings.TrimSpace(t.Name)
+}
+
+func apiTokenValidErr(err error) error {
+	return errors.Join(AppErrApiTokenValidFailed, err)
+}
+
+func (t *ApiToken) Valid() error {
+	if t.UserId == 0 {
+		return apiTokenValidErr(errors.New("require field: user id"))
+	}
+
+	if t.Name == "" {
+		return apiTokenValidErr(NewLocalError("Required", "FieldNames", NewLocalText("ApiTokenName")))
+	}
+
+	if utf8.RuneCountInString(t.Name) > MaxApiTokenNameLen {
+		return apiTokenValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ApiTokenName"), "Num", MaxApiTokenNameLen))
+	}
+
+	if len(t.Permissions) == 0 {
+		return apiTokenValidErr(NewLocalError("Required", "FieldNames", NewLocalText("Permission", "Count", 2)))
+	}
+
+	for _, id := range t.Permissions {
+		if len(strings.Split(id, ".")) != 2 {
+			return apiTokenValidErr(fmt.Errorf("invalid permission: %s", id))
+		}
+	}
+
+	return nil
+}
+
+func (t *ApiToken) ValidCount(existCount int) error {
+	if existCount >= MaxApiTokenCount {
+		return apiTokenValidErr(NewLocalError("ApiTokenCountLimit", "Num", MaxApiTokenCount))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+type apiTokenData struct {
+	UserId      int
+	Name        string
+	Permissions []string
+}
+
+func TestApiTokenValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *apiTokenData
+		valid bool
+	}{
+		{
+			desc:  "All valid",
+			in:    &apiTokenData{UserId: 1, Name: "Bot", Permissions: []string{"article.create", "article.vote_up"}},
+			valid: true,
+		},
+		{
+			desc:  "User id is required",
+			in:    &apiTokenData{UserId: 0, Name: "Bot", Permissions: []string{"article.create"}},
+			valid: false,
+		},
+		{
+			desc:  "Name is required",
+			in:    &apiTokenData{UserId: 1, Name: "", Permissions: []string{"article.create"}},
+			valid: false,
+		},
+		{
+			desc:  "Name length",
+			in:    &apiTokenData{UserId: 1, Name: strings.Repeat("a", MaxApiTokenNameLen+1), Permissions: []string{"article.create"}},
+			valid: false,
+		},
+		{
+			desc:  "Permissions are required",
+			in:    &apiTokenData{UserId: 1, Name: "Bot", Permissions: nil},
+			valid: false,
+		},
+		{
+			desc:  "Permission format",
+			in:    &apiTokenData{UserId: 1, Name: "Bot", Permissions: []string{"article_create"}},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token := &ApiToken{
+				UserId:      tt.in.UserId,
+				Name:        tt.in.Name,
+				Permissions: tt.in.Permissions,
+			}
+			err := token.Valid()
+			got := err == nil
+			want := tt.valid
+
+			if got != want {
+				t.Errorf("api token: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, want, got, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"html"
 	"math"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -28,6 +29,15 @@ const (
 	VoteTypeDown VoteType = "down"
 )
 
+// Format of article content, articles posted before markdown is supported are
+// kept in plain text
+type ContentFormat string
+
+const (
+	ContentFormatPlain    ContentFormat = "plain"
+	ContentFormatMarkdown ContentFormat = "markdown"
+)
+
 var validVoteType = map[VoteType]bool{
 	VoteTypeUp:   true,
 	VoteTypeDown: true,
@@ -223,6 +233,7 @@ type Article struct {
 	AuthorName                string
 	AuthorId                  int
 	Content                   string
+	ContentFormat             ContentFormat
 	Summary                   string
 	CreatedAt                 time.Time
 	UpdatedAt                 time.Time
@@ -236,7 +247,6 @@ type Article struct {
 	ReplyRootArticleId        int
 	NullReplyRootArticleTitle pgtype.Text
 	ReplyRootArticleTitle     string
-	DisplayTitle              string // only for display
 	TotalReplyCount           int
 	ChildrenCount             int
 	VoteUp                    int
@@ -259,6 +269,18 @@ type Article struct {
 	BlockedRegionsISOCode     []string
 	Blocked                   bool
 	FadeOut                   bool
+	SearchRank                float64 // rank in search result
+	HighlightTitle            string  // only for display, title with search keywords highlighted
+	HighlightContent          string  // only for display, content snippet with search keywords highlighted
+	Attachments               []*Attachment
+	LinkPreview               *LinkPreview
+	Poll                      *Poll
+	DuplicateOfId             int
+	DuplicateOfTitle          string
+	Tags                      []*Tag
+	TagFrontIds               []string // only for submitting
+	Mentions                  []string // usernames of the mentioned users
+	AuthorBlocked             bool     // only for display, author is blocked by current user
 }
 
 type ArticleReact struct {
@@ -435,17 +457,16 @@ func (a *Article) FormatReactCounts() {
 	}
 }
 
-func (a *Article) UpdateDisplayTitle() {
-	var displayTitle string
+// Title of the article, or the title of the root article for replies, in the
+// language of ic
+func (a *Article) DisplayTitle(ic *i18nc.I18nCustom) string {
 	if a.ReplyDepth == 0 {
-		displayTitle = a.Title
+		return a.Title
 	} else if a.ReplyDepth == 1 {
-		displayTitle = fmt.Sprintf("%s: %s", translator.LocalTpl("Re"), a.ReplyRootArticleTitle)
+		return fmt.Sprintf("%s: %s", ic.LocalTpl("Re"), a.ReplyRootArticleTitle)
 	} else {
-		displayTitle = fmt.Sprintf("%s × %d: %s", translator.LocalTpl("Re"), a.ReplyDepth, a.ReplyRootArticleTitle)
+		return fmt.Sprintf("%s × %d: %s", ic.LocalTpl("Re"), a.ReplyDepth, a.ReplyRootArticleTitle)
 	}
-
-	a.DisplayTitle = displayTitle
 }
 
 func (a *Article) FormatDeleted() {
@@ -462,9 +483,9 @@ func (a *Article) GenSummary(strLen int) {
 	}
 }
 
-func (a *Article) FormatTimeStr() {
-	a.CreatedAtStr = translator.TimeAgo.Format(a.CreatedAt)
-	a.UpdatedAtStr = translator.TimeAgo.Format(a.UpdatedAt)
+func (a *Article) FormatTimeStr(ic *i18nc.I18nCustom) {
+	a.CreatedAtStr = ic.TimeAgo.Format(a.CreatedAt)
+	a.UpdatedAtStr = ic.TimeAgo.Format(a.UpdatedAt)
 }
 
 func (a *Article) Sanitize(p *bluemonday.Policy) {
@@ -475,8 +496,8 @@ func (a *Article) Sanitize(p *bluemonday.Policy) {
 	a.Content = html.EscapeString(a.Content)
 }
 
-func articleValidErr(str string) error {
-	return errors.Join(AppErrArticleValidFailed, errors.New(", "+str))
+func articleValidErr(err error) error {
+	return errors.Join(AppErrArticleValidFailed, err)
 }
 
 func (a *Article) TrimSpace() {
@@ -486,6 +507,10 @@ func (a *Article) TrimSpace() {
 
 var reArticleURLStr = `^(https?|ftp)://[^\s/$.?#].[^\s]*$`
 
+func ValidArticleURL(link string) bool {
+	return regexp.MustCompile(reArticleURLStr).MatchString(link)
+}
+
 func (a *Article) Valid(isUpdate bool) error {
 	isReply := a.ReplyDepth > 0 || a.ReplyToId != 0
 	authorId := a.AuthorId
@@ -494,36 +519,34 @@ func (a *Article) Valid(isUpdate bool) error {
 	link := strings.TrimSpace(a.Link)
 
 	if !isUpdate && authorId == 0 {
-		return articleValidErr(translator.LocalTpl("Required", "FieldNames", translator.LocalTpl("Author")))
+		return articleValidErr(NewLocalError("Required", "FieldNames", NewLocalText("Author")))
 	}
 
 	if !isReply {
 		if title == "" {
-			return articleValidErr(translator.LocalTpl("Required", "FieldNames", translator.LocalTpl("ArticleTitle")))
+			return articleValidErr(NewLocalError("Required", "FieldNames", NewLocalText("ArticleTitle")))
 		}
 
 		if utf8.RuneCountInString(title) > MAX_ARTICLE_TITLE_LEN {
-			return articleValidErr(translator.LocalTpl("NotExceed", "FieldNames", translator.LocalTpl("ArticleTitle"), "Num", MAX_ARTICLE_TITLE_LEN))
+			return articleValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ArticleTitle"), "Num", MAX_ARTICLE_TITLE_LEN))
 		}
 
-		if link != "" {
-			if !regexp.MustCompile(reArticleURLStr).Match([]byte(link)) {
-				return articleValidErr(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("URL")))
-			}
+		if link != "" && !ValidArticleURL(link) {
+			return articleValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("URL")))
 		}
 
 		if a.CategoryFrontId == "" {
-			return articleValidErr(translator.LocalTpl("Required", "FieldNames", translator.LocalTpl("Category")))
+			return articleValidErr(NewLocalError("Required", "FieldNames", NewLocalText("Category")))
 		}
 
 	} else {
 		if content == "" {
-			return articleValidErr(translator.LocalTpl("Required", "FieldNames", translator.LocalTpl("ArticleContent")))
+			return articleValidErr(NewLocalError("Required", "FieldNames", NewLocalText("ArticleContent")))
 		}
 	}
 
 	if utf8.RuneCountInString(content) > MAX_ARTICLE_CONTENT_LEN {
-		return articleValidErr(translator.LocalTpl("NotExceed", "FieldNames", translator.LocalTpl("ArticleContent"), "Num", MAX_ARTICLE_CONTENT_LEN))
+		return articleValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ArticleContent"), "Num", MAX_ARTICLE_CONTENT_LEN))
 	}
 	return nil
 }
@@ -583,6 +606,10 @@ func (a *Article) UpdateBlockedState(requestRegionISOCode string) {
 	a.Blocked = false
 }
 
+func (a *Article) UpdateAuthorBlockedState(blockedUserIds []int) {
+	a.AuthorBlocked = slices.Contains(blockedUserIds, a.AuthorId)
+}
+
 // First commit  Mon Feb 13 00:11:53 2023 +0800
 var projectStartDate = time.Date(2023, time.Month(2), 13, 0, 11, 53, 0, time.Local)
 
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxAttachmentSize         int64 = 10 << 20
+	MaxArticleAttachmentCount       = 4
+	MaxAttachmentNameLen            = 255
+	// Max width and height of thumbnails
+	AttachmentThumbSize = 360
+)
+
+// Types of files allowed to upload with their extensions, the type is
+// detected from file content instead of the name
+var AttachmentMimeTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+}
+
+type Attachment struct {
+	Id         int
+	ArticleId  int
+	UserId     int
+	FileName   string
+	MimeType   string
+	Size       int64
+	StorageKey string
+	ThumbKey   string
+	Width      int
+	Height     int
+	CreatedAt  time.Time
+	Data       []byte // only for uploading
+	Thumb      []byte // only for uploading
+}
+
+// DetectMimeType detects the type of file content, parameters such as charset
+// are removed
+func DetectMimeType(data []byte) string {
+	mimeType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return strings.TrimSpace(mimeType)
+}
+
+func AttachmentTypeExts() []string {
+	var list []string
+	for _, ext := range AttachmentMimeTypes {
+		list = append(list, ext)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Limits of attachments which are shown in forms
+type AttachmentLimits struct {
+	Count  int
+	Size   string
+	Types  string
+	Accept string
+}
+
+func GetAttachmentLimits() *AttachmentLimits {
+	var mimeTypes []string
+	for mimeType := range AttachmentMimeTypes {
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+	sort.Strings(mimeTypes)
+
+	return &AttachmentLimits{
+		Count:  MaxArticleAttachmentCount,
+		Size:   (&Attachment{Size: MaxAttachmentSize}).SizeStr(),
+		Types:  strings.Join(AttachmentTypeExts(), ", "),
+		Accept: strings.Join(mimeTypes, ","),
+	}
+}
+
+func attachmentValidErr(err error) error {
+	return errors.Join(AppErrAttachmentValidFailed, err)
+}
+
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MimeType, "image/")
+}
+
+func (a *Attachment) SizeStr() string {
+	switch {
+	case a.Size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(a.Size)/(1<<20))
+	case a.Size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(a.Size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", a.Size)
+	}
+}
+
+func (a *Attachment) TrimSpace() {
+	a.FileName = strings.TrimSpace(filepath.Base(filepath.Clean("/" + a.FileName)))
+}
+
+func (a *Attachment) Sanitize() {
+	a.FileName = html.EscapeString(a.FileName)
+}
+
+func (a *Attachment) Valid() error {
+	if a.FileName == "" || a.FileName == "/" {
+		return attachmentValidErr(NewLocalError("Required", "FieldNames", NewLocalText("FileName")))
+	}
+
+	if utf8.RuneCountInString(a.FileName) > MaxAttachmentNameLen {
+		return attachmentValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("FileName"), "Num", MaxAttachmentNameLen))
+	}
+
+	if a.Size <= 0 {
+		return attachmentValidErr(NewLocalError("Required", "FieldNames", NewLocalText("File")))
+	}
+
+	if a.Size > MaxAttachmentSize {
+		return attachmentValidErr(NewLocalError("AttachmentSizeLimit", "Size", GetAttachmentLimits().Size))
+	}
+
+	if _, ok := AttachmentMimeTypes[a.MimeType]; !ok {
+		return attachmentValidErr(NewLocalError("AttachmentTypeLimit", "Types", GetAttachmentLimits().Types))
+	}
+
+	return nil
+}
+
+// ValidAttachmentCount checks the count of attachments of one article
+func ValidAttachmentCount(count int) error {
+	if count > MaxArticleAttachmentCount {
+		return attachmentValidErr(NewLocalError("AttachmentCountLimit", "Num", MaxArticleAttachmentCount))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttachmentValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Attachment
+		valid bool
+	}{
+		{"image", &Attachment{FileName: "a.png", MimeType: "image/png", Size: 100}, true},
+		{"text", &Attachment{FileName: "a.txt", MimeType: "text/plain", Size: 100}, true},
+		{"max size", &Attachment{FileName: "a.png", MimeType: "image/png", Size: MaxAttachmentSize}, true},
+		{"name is required", &Attachment{FileName: "", MimeType: "image/png", Size: 100}, false},
+		{"name length", &Attachment{FileName: strings.Repeat("a", MaxAttachmentNameLen+1), MimeType: "image/png", Size: 100}, false},
+		{"empty file", &Attachment{FileName: "a.png", MimeType: "image/png", Size: 0}, false},
+		{"exceed max size", &Attachment{FileName: "a.png", MimeType: "image/png", Size: MaxAttachmentSize + 1}, false},
+		{"type not allowed", &Attachment{FileName: "a.html", MimeType: "text/html", Size: 100}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("attachment: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestAttachmentTrimSpace(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{" a.png ", "a.png"},
+		{"../../etc/passwd", "passwd"},
+		{"/tmp/a.png", "a.png"},
+	}
+
+	for _, tt := range tests {
+		a := &Attachment{FileName: tt.in}
+		a.TrimSpace()
+		if a.FileName != tt.want {
+			t.Errorf("want %q, got %q", tt.want, a.FileName)
+		}
+	}
+}
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   []byte
+		want string
+	}{
+		{"png", []byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png"},
+		{"pdf", []byte("%PDF-1.4"), "application/pdf"},
+		{"text without charset", []byte("hello"), "text/plain"},
+		{"html", []byte("<html><body>hi</body></html>"), "text/html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := DetectMimeType(tt.in); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidAttachmentCount(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    int
+		valid bool
+	}{
+		{"no attachments", 0, true},
+		{"max count", MaxArticleAttachmentCount, true},
+		{"exceed max count", MaxArticleAttachmentCount + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidAttachmentCount(tt.in)
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("count: %d \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
@@ -2,21 +2,48 @@ package model
 
 import (
 	"errors"
-	"fmt"
+	"html"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	i18nc "github.com/oodzchen/dproject/i18n"
 )
 
 type CategoryState string
 
 const (
-	CategoryStateAll        CategoryState = "all"
-	CategoryStateApproved                 = "approved"
-	CategoryStateUnapproved               = "unapproved"
+	CategoryStateAll      CategoryState = "all"
+	CategoryStateApproved               = "approved"
+	// Proposed categories waiting for review
+	CategoryStateUnapproved = "unapproved"
+	CategoryStateRejected   = "rejected"
+)
+
+const (
+	MaxCategoryFrontIdLen      = 50
+	MaxCategoryNameLen         = 50
+	MaxCategoryDescribeLen     = 500
+	MaxCategoryApprovalComment = 500
 )
 
+var reCategoryFrontId = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+// Permissions that moderators of a category have on the articles under it,
+// other permissions are still granted by the role of user
+var CategoryModeratorPermissions = []string{
+	"article.lock",
+	"article.pin",
+	"article.fade_out",
+	"article.block_regions",
+	"article.delete_others",
+	"article.resolve_report",
+}
+
 type CategoryUserState struct {
 	Subscribed bool
+	Ignored    bool
 }
 
 type Category struct {
@@ -25,30 +52,93 @@ type Category struct {
 	Name              string
 	Describe          string
 	AuthorId          string
+	AuthorName        string
 	CreatedAt         time.Time
 	Approved          bool
 	ApprovalComment   string
+	ReviewedAt        *time.Time
 	UserState         *CategoryUserState
 	TotalArticleCount int
+	Moderators        []*CategoryModerator
+}
+
+type CategoryModerator struct {
+	CategoryFrontId string
+	UserId          int
+	UserName        string
+	CreatedAt       time.Time
+}
+
+func categoryValidErr(err error) error {
+	return errors.Join(AppErrCategoryValidFailed, err)
+}
+
+// Pending reports whether the category is proposed and not reviewed yet
+func (p *Category) Pending() bool {
+	return !p.Approved && p.ReviewedAt == nil
 }
 
-func categoryValidErr(str string) error {
-	return errors.Join(AppErrCategoryValidFailed, errors.New(", "+str))
+// Rejected reports whether the category is reviewed and not approved
+func (p *Category) Rejected() bool {
+	return !p.Approved && p.ReviewedAt != nil
 }
 
 func (p *Category) TrimSpace() {
+	p.FrontId = strings.ToLower(strings.TrimSpace(p.FrontId))
 	p.Name = strings.TrimSpace(p.Name)
+	p.Describe = strings.TrimSpace(p.Describe)
+}
+
+func (p *Category) Sanitize() {
+	p.Name = html.EscapeString(p.Name)
+	p.Describe = html.EscapeString(p.Describe)
 }
 
 func (p *Category) Valid() error {
-	lackField := ""
+	if p.FrontId == "" {
+		return categoryValidErr(NewLocalError("Required", "FieldNames", NewLocalText("CategoryFrontId")))
+	}
+
+	if utf8.RuneCountInString(p.FrontId) > MaxCategoryFrontIdLen {
+		return categoryValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("CategoryFrontId"), "Num", MaxCategoryFrontIdLen))
+	}
+
+	// "new" is used by the route of category proposing page
+	if !reCategoryFrontId.MatchString(p.FrontId) || p.FrontId == "new" {
+		return categoryValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("CategoryFrontId")))
+	}
 
 	if p.Name == "" {
-		lackField = "name"
+		return categoryValidErr(NewLocalError("Required", "FieldNames", NewLocalText("CategoryName")))
+	}
+
+	if utf8.RuneCountInString(p.Name) > MaxCategoryNameLen {
+		return categoryValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("CategoryName"), "Num", MaxCategoryNameLen))
+	}
+
+	if utf8.RuneCountInString(p.Describe) > MaxCategoryDescribeLen {
+		return categoryValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("Description"), "Num", MaxCategoryDescribeLen))
 	}
 
-	if lackField != "" {
-		return categoryValidErr(fmt.Sprintf("require field: %s", lackField))
+	return nil
+}
+
+// ValidApprovalComment checks the comment that moderators leave when
+// approving or rejecting a proposed category
+func ValidApprovalComment(comment string) error {
+	if utf8.RuneCountInString(comment) > MaxCategoryApprovalComment {
+		return categoryValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ApprovalComment"), "Num", MaxCategoryApprovalComment))
+	}
+
+	return nil
+}
+
+// ValidCategory checks that the article is posted to an approved category
+func (a *Article) ValidCategory(category *Category) error {
+	if category == nil || !category.Approved {
+		return articleValidErr(NewLocalError("NotExist", "FieldNames", LocalFunc(func(ic *i18nc.I18nCustom) string {
+			return NewLocalText("Category", "Count", 1).Localize(ic) + " " + html.EscapeString(a.CategoryFrontId)
+		})))
 	}
 
 	return nil
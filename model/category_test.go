@@ -0,0 +1,118 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCategoryValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Category
+		valid bool
+	}{
+		{
+			desc:  "All valid",
+			in:    &Category{FrontId: "golang", Name: "Go", Describe: "The Go programming language"},
+			valid: true,
+		},
+		{
+			desc:  "Front id is required",
+			in:    &Category{FrontId: "", Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Front id format",
+			in:    &Category{FrontId: "go lang", Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Front id is reserved",
+			in:    &Category{FrontId: "new", Name: "New"},
+			valid: false,
+		},
+		{
+			desc:  "Front id length",
+			in:    &Category{FrontId: strings.Repeat("a", MaxCategoryFrontIdLen+1), Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Name is required",
+			in:    &Category{FrontId: "golang", Name: ""},
+			valid: false,
+		},
+		{
+			desc:  "Name length",
+			in:    &Category{FrontId: "golang", Name: strings.Repeat("a", MaxCategoryNameLen+1)},
+			valid: false,
+		},
+		{
+			desc:  "Describe length",
+			in:    &Category{FrontId: "golang", Name: "Go", Describe: strings.Repeat("a", MaxCategoryDescribeLen+1)},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("category: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestCategoryReviewState(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		desc         string
+		in           *Category
+		wantPending  bool
+		wantRejected bool
+	}{
+		{"proposed", &Category{}, true, false},
+		{"approved", &Category{Approved: true, ReviewedAt: &now}, false, false},
+		{"rejected", &Category{ReviewedAt: &now}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.in.Pending(); got != tt.wantPending {
+				t.Errorf("pending should be %t, but got %t", tt.wantPending, got)
+			}
+
+			if got := tt.in.Rejected(); got != tt.wantRejected {
+				t.Errorf("rejected should be %t, but got %t", tt.wantRejected, got)
+			}
+		})
+	}
+}
+
+func TestArticleValidCategory(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Category
+		valid bool
+	}{
+		{"approved", &Category{FrontId: "golang", Approved: true}, true},
+		{"unapproved", &Category{FrontId: "golang"}, false},
+		{"not exist", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			article := &Article{CategoryFrontId: "golang"}
+			err := article.ValidCategory(tt.in)
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("validate result should be %t, but got %t, error: %v", tt.valid, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const MaxDraftLinkLen = 255
+
+type DraftTargetType string
+
+const (
+	DraftTargetNew   DraftTargetType = "new"
+	DraftTargetReply                 = "reply"
+	DraftTargetEdit                  = "edit"
+)
+
+func ValidDraftTargetType(targetType string) bool {
+	switch DraftTargetType(targetType) {
+	case DraftTargetNew, DraftTargetReply, DraftTargetEdit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Draft of a new article, a reply to the target article, or an edit of the
+// target article, one draft is kept for each target of a user
+type Draft struct {
+	Id              int
+	UserId          int
+	TargetType      DraftTargetType
+	TargetId        int
+	Title           string
+	Link            string
+	Content         string
+	CategoryFrontId string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	TargetTitle     string // only for display, title of the target article
+	Summary         string // only for display
+}
+
+func draftValidErr(err error) error {
+	return errors.Join(AppErrDraftValidFailed, err)
+}
+
+func (d *Draft) TrimSpace() {
+	d.Title = strings.TrimSpace(d.Title)
+	d.Link = strings.TrimSpace(d.Link)
+	d.CategoryFrontId = strings.TrimSpace(d.CategoryFrontId)
+}
+
+// Sanitize the same way as articles, so that restored drafts are displayed
+// in forms as submitted ones
+func (d *Draft) Sanitize(p *bluemonday.Policy) {
+	d.Title = p.Sanitize(d.Title)
+	d.Link = p.Sanitize(d.Link)
+	d.CategoryFrontId = html.EscapeString(d.CategoryFrontId)
+	d.Content = html.EscapeString(d.Content)
+}
+
+// Drafts are saved while editing, so only the target and lengths are checked
+func (d *Draft) Valid() error {
+	if !ValidDraftTargetType(string(d.TargetType)) {
+		return draftValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("DraftTarget")))
+	}
+
+	// Drafts of new articles have no target article
+	if (d.TargetType == DraftTargetNew) != (d.TargetId == 0) || d.TargetId < 0 {
+		return draftValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("DraftTarget")))
+	}
+
+	if utf8.RuneCountInString(d.Title) > MAX_ARTICLE_TITLE_LEN {
+		return draftValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ArticleTitle"), "Num", MAX_ARTICLE_TITLE_LEN))
+	}
+
+	if utf8.RuneCountInString(d.Link) > MaxDraftLinkLen {
+		return draftValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("URL"), "Num", MaxDraftLinkLen))
+	}
+
+	if utf8.RuneCountInString(d.Content) > MAX_ARTICLE_CONTENT_LEN {
+		return draftValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ArticleContent"), "Num", MAX_ARTICLE_CONTENT_LEN))
+	}
+
+	return nil
+}
+
+func (d *Draft) IsEmpty() bool {
+	return strings.TrimSpace(d.Title) == "" && strings.TrimSpace(d.Link) == "" && strings.TrimSpace(d.Content) == ""
+}
+
+// Path of the form page where the draft is restored
+func (d *Draft) FormPath() string {
+	switch d.TargetType {
+	case DraftTargetReply:
+		return fmt.Sprintf("/articles/%d/reply", d.TargetId)
+	case DraftTargetEdit:
+		return fmt.Sprintf("/articles/%d/edit", d.TargetId)
+	default:
+		return "/articles/new"
+	}
+}
+
+func (d *Draft) GenSummary(strLen int) {
+	if utf8.RuneCountInString(d.Content) > strLen {
+		d.Summary = string([]rune(d.Content)[:strLen])
+	} else {
+		d.Summary = d.Content
+	}
+}
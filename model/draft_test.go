@@ -0,0 +1,96 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDraftValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Draft
+		valid bool
+	}{
+		{
+			desc:  "New article",
+			in:    &Draft{TargetType: DraftTargetNew, Title: "Title", Content: "Content"},
+			valid: true,
+		},
+		{
+			desc:  "Reply",
+			in:    &Draft{TargetType: DraftTargetReply, TargetId: 1, Content: "Content"},
+			valid: true,
+		},
+		{
+			desc:  "Edit",
+			in:    &Draft{TargetType: DraftTargetEdit, TargetId: 1, Title: "Title"},
+			valid: true,
+		},
+		{
+			desc:  "Unknown target type",
+			in:    &Draft{TargetType: "other", TargetId: 1},
+			valid: false,
+		},
+		{
+			desc:  "New article with target",
+			in:    &Draft{TargetType: DraftTargetNew, TargetId: 1},
+			valid: false,
+		},
+		{
+			desc:  "Reply without target",
+			in:    &Draft{TargetType: DraftTargetReply},
+			valid: false,
+		},
+		{
+			desc:  "Negative target",
+			in:    &Draft{TargetType: DraftTargetEdit, TargetId: -1},
+			valid: false,
+		},
+		{
+			desc:  "Title length",
+			in:    &Draft{TargetType: DraftTargetNew, Title: strings.Repeat("a", MAX_ARTICLE_TITLE_LEN+1)},
+			valid: false,
+		},
+		{
+			desc:  "Link length",
+			in:    &Draft{TargetType: DraftTargetNew, Link: "https://example.com/" + strings.Repeat("a", MaxDraftLinkLen)},
+			valid: false,
+		},
+		{
+			desc:  "Content length",
+			in:    &Draft{TargetType: DraftTargetReply, TargetId: 1, Content: strings.Repeat("a", MAX_ARTICLE_CONTENT_LEN+1)},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("draft: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestDraftFormPath(t *testing.T) {
+	tests := []struct {
+		in   *Draft
+		want string
+	}{
+		{&Draft{TargetType: DraftTargetNew}, "/articles/new"},
+		{&Draft{TargetType: DraftTargetReply, TargetId: 3}, "/articles/3/reply"},
+		{&Draft{TargetType: DraftTargetEdit, TargetId: 5}, "/articles/5/edit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.in.TargetType), func(t *testing.T) {
+			got := tt.in.FormPath()
+			if got != tt.want {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,13 @@
 
 package model
 
+import (
+	"fmt"
+	"strings"
+
+	i18nc "github.com/oodzchen/dproject/i18n"
+)
+
 // App Error
 /*
    ENUM(
@@ -17,6 +24,19 @@ package model
 
    UserNotExist, // user dose not exist
    ArticleNotExist, // article dose not exist
+
+   ApiTokenValidFailed, // API token data validation failed
+   SearchValidFailed, // search query validation failed
+   TagValidFailed, // tag data validation failed
+   ReportValidFailed, // report data validation failed
+   AttachmentValidFailed, // attachment validation failed
+   AttachmentQuotaExceeded, // attachment storage quota exceeded
+   PollValidFailed, // poll data validation failed
+   DraftValidFailed, // draft data validation failed
+   IdentityAlreadyLinked, // login method is already linked
+   LastLoginMethod, // the last login method can not be removed
+   UsernameUnavailable, // username is taken or reserved
+   UsernameChangeTooOften, // username is changed too often
    )
 */
 type AppErrCode int
@@ -25,8 +45,9 @@ type AppError struct {
 	ErrCode AppErrCode
 }
 
+// Error returns the text in English, use LocalizeError for displaying
 func (x AppError) Error() string {
-	return x.ErrCode.Text(false, translator)
+	return x.ErrCode.Text(false, nil)
 }
 
 func NewAppError(code AppErrCode) error {
@@ -34,3 +55,102 @@ func NewAppError(code AppErrCode) error {
 		ErrCode: code,
 	}
 }
+
+// Text localized later in the language of request, for the messages made in
+// places where the localizer of request is not available, like validations
+type LocalText struct {
+	Id string
+	// Pairs of key and value passed to LocalTpl, values of *LocalText and
+	// LocalFunc are localized first
+	Data []any
+}
+
+// Text made by the localizer of request, ic is nil when the text is logged
+type LocalFunc func(ic *i18nc.I18nCustom) string
+
+func NewLocalText(id string, data ...any) *LocalText {
+	return &LocalText{
+		Id:   id,
+		Data: data,
+	}
+}
+
+func (lt *LocalText) Localize(ic *i18nc.I18nCustom) string {
+	if ic == nil {
+		return lt.String()
+	}
+
+	data := make([]any, len(lt.Data))
+	for idx, item := range lt.Data {
+		switch v := item.(type) {
+		case *LocalText:
+			data[idx] = v.Localize(ic)
+		case LocalFunc:
+			data[idx] = v(ic)
+		default:
+			data[idx] = item
+		}
+	}
+
+	return ic.LocalTpl(lt.Id, data...)
+}
+
+// String returns the message id with data, for logging
+func (lt *LocalText) String() string {
+	if len(lt.Data) == 0 {
+		return lt.Id
+	}
+
+	var data []string
+	for idx := 0; idx+1 < len(lt.Data); idx += 2 {
+		val := lt.Data[idx+1]
+		switch v := val.(type) {
+		case *LocalText:
+			val = v.String()
+		case LocalFunc:
+			val = v(nil)
+		}
+		data = append(data, fmt.Sprintf("%v=%v", lt.Data[idx], val))
+	}
+	return fmt.Sprintf("%s(%s)", lt.Id, strings.Join(data, ", "))
+}
+
+// Error with the message localized in the language of request by LocalizeError
+type LocalError struct {
+	Text *LocalText
+}
+
+func (e *LocalError) Error() string {
+	return e.Text.String()
+}
+
+func NewLocalError(id string, data ...any) error {
+	return &LocalError{
+		Text: NewLocalText(id, data...),
+	}
+}
+
+// LocalizeError returns the message of err in the language of ic, errors
+// joined by errors.Join are localized separately
+func LocalizeError(err error, ic *i18nc.I18nCustom) string {
+	if err == nil {
+		return ""
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		var texts []string
+		for _, item := range e.Unwrap() {
+			if text := LocalizeError(item, ic); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, ", ")
+	case *LocalError:
+		return e.Text.Localize(ic)
+	case *AppError:
+		return e.ErrCode.Text(false, ic)
+	}
+
+	return err.Error()
+}
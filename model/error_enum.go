@@ -45,11 +45,47 @@ const (
 	// AppErrCodeArticleNotExist is a AppErrCode of type ArticleNotExist.
 	// article dose not exist
 	AppErrCodeArticleNotExist
+	// AppErrCodeApiTokenValidFailed is a AppErrCode of type ApiTokenValidFailed.
+	// API token data validation failed
+	AppErrCodeApiTokenValidFailed
+	// AppErrCodeSearchValidFailed is a AppErrCode of type SearchValidFailed.
+	// search query validation failed
+	AppErrCodeSearchValidFailed
+	// AppErrCodeTagValidFailed is a AppErrCode of type TagValidFailed.
+	// tag data validation failed
+	AppErrCodeTagValidFailed
+	// AppErrCodeReportValidFailed is a AppErrCode of type ReportValidFailed.
+	// report data validation failed
+	AppErrCodeReportValidFailed
+	// AppErrCodeAttachmentValidFailed is a AppErrCode of type AttachmentValidFailed.
+	// attachment validation failed
+	AppErrCodeAttachmentValidFailed
+	// AppErrCodeAttachmentQuotaExceeded is a AppErrCode of type AttachmentQuotaExceeded.
+	// attachment storage quota exceeded
+	AppErrCodeAttachmentQuotaExceeded
+	// AppErrCodePollValidFailed is a AppErrCode of type PollValidFailed.
+	// poll data validation failed
+	AppErrCodePollValidFailed
+	// AppErrCodeDraftValidFailed is a AppErrCode of type DraftValidFailed.
+	// draft data validation failed
+	AppErrCodeDraftValidFailed
+	// AppErrCodeIdentityAlreadyLinked is a AppErrCode of type IdentityAlreadyLinked.
+	// login method is already linked
+	AppErrCodeIdentityAlreadyLinked
+	// AppErrCodeLastLoginMethod is a AppErrCode of type LastLoginMethod.
+	// the last login method can not be removed
+	AppErrCodeLastLoginMethod
+	// AppErrCodeUsernameUnavailable is a AppErrCode of type UsernameUnavailable.
+	// username is taken or reserved
+	AppErrCodeUsernameUnavailable
+	// AppErrCodeUsernameChangeTooOften is a AppErrCode of type UsernameChangeTooOften.
+	// username is changed too often
+	AppErrCodeUsernameChangeTooOften
 )
 
 var ErrInvalidAppErrCode = fmt.Errorf("not a valid AppErrCode, try [%s]", strings.Join(_AppErrCodeNames, ", "))
 
-const _AppErrCodeName = "AlreadyRegisteredNotRegisteredUserValidFailedArticleValidFailedPermissionValidFailedRoleValidFailedActivityValidFailedCategoryValidFailedUserNotExistArticleNotExist"
+const _AppErrCodeName = "AlreadyRegisteredNotRegisteredUserValidFailedArticleValidFailedPermissionValidFailedRoleValidFailedActivityValidFailedCategoryValidFailedUserNotExistArticleNotExistApiTokenValidFailedSearchValidFailedTagValidFailedReportValidFailedAttachmentValidFailedAttachmentQuotaExceededPollValidFailedDraftValidFailedIdentityAlreadyLinkedLastLoginMethodUsernameUnavailableUsernameChangeTooOften"
 
 var _AppErrCodeNames = []string{
 	_AppErrCodeName[0:17],
@@ -62,6 +98,18 @@ var _AppErrCodeNames = []string{
 	_AppErrCodeName[118:137],
 	_AppErrCodeName[137:149],
 	_AppErrCodeName[149:164],
+	_AppErrCodeName[164:183],
+	_AppErrCodeName[183:200],
+	_AppErrCodeName[200:214],
+	_AppErrCodeName[214:231],
+	_AppErrCodeName[231:252],
+	_AppErrCodeName[252:275],
+	_AppErrCodeName[275:290],
+	_AppErrCodeName[290:306],
+	_AppErrCodeName[306:327],
+	_AppErrCodeName[327:342],
+	_AppErrCodeName[342:361],
+	_AppErrCodeName[361:383],
 }
 
 // AppErrCodeNames returns a list of possible string values of AppErrCode.
@@ -84,20 +132,44 @@ func AppErrCodeValues() []AppErrCode {
 		AppErrCodeCategoryValidFailed,
 		AppErrCodeUserNotExist,
 		AppErrCodeArticleNotExist,
+		AppErrCodeApiTokenValidFailed,
+		AppErrCodeSearchValidFailed,
+		AppErrCodeTagValidFailed,
+		AppErrCodeReportValidFailed,
+		AppErrCodeAttachmentValidFailed,
+		AppErrCodeAttachmentQuotaExceeded,
+		AppErrCodePollValidFailed,
+		AppErrCodeDraftValidFailed,
+		AppErrCodeIdentityAlreadyLinked,
+		AppErrCodeLastLoginMethod,
+		AppErrCodeUsernameUnavailable,
+		AppErrCodeUsernameChangeTooOften,
 	}
 }
 
 var _AppErrCodeMap = map[AppErrCode]string{
-	AppErrCodeAlreadyRegistered:     _AppErrCodeName[0:17],
-	AppErrCodeNotRegistered:         _AppErrCodeName[17:30],
-	AppErrCodeUserValidFailed:       _AppErrCodeName[30:45],
-	AppErrCodeArticleValidFailed:    _AppErrCodeName[45:63],
-	AppErrCodePermissionValidFailed: _AppErrCodeName[63:84],
-	AppErrCodeRoleValidFailed:       _AppErrCodeName[84:99],
-	AppErrCodeActivityValidFailed:   _AppErrCodeName[99:118],
-	AppErrCodeCategoryValidFailed:   _AppErrCodeName[118:137],
-	AppErrCodeUserNotExist:          _AppErrCodeName[137:149],
-	AppErrCodeArticleNotExist:       _AppErrCodeName[149:164],
+	AppErrCodeAlreadyRegistered:       _AppErrCodeName[0:17],
+	AppErrCodeNotRegistered:           _AppErrCodeName[17:30],
+	AppErrCodeUserValidFailed:         _AppErrCodeName[30:45],
+	AppErrCodeArticleValidFailed:      _AppErrCodeName[45:63],
+	AppErrCodePermissionValidFailed:   _AppErrCodeName[63:84],
+	AppErrCodeRoleValidFailed:         _AppErrCodeName[84:99],
+	AppErrCodeActivityValidFailed:     _AppErrCodeName[99:118],
+	AppErrCodeCategoryValidFailed:     _AppErrCodeName[118:137],
+	AppErrCodeUserNotExist:            _AppErrCodeName[137:149],
+	AppErrCodeArticleNotExist:         _AppErrCodeName[149:164],
+	AppErrCodeApiTokenValidFailed:     _AppErrCodeName[164:183],
+	AppErrCodeSearchValidFailed:       _AppErrCodeName[183:200],
+	AppErrCodeTagValidFailed:          _AppErrCodeName[200:214],
+	AppErrCodeReportValidFailed:       _AppErrCodeName[214:231],
+	AppErrCodeAttachmentValidFailed:   _AppErrCodeName[231:252],
+	AppErrCodeAttachmentQuotaExceeded: _AppErrCodeName[252:275],
+	AppErrCodePollValidFailed:         _AppErrCodeName[275:290],
+	AppErrCodeDraftValidFailed:        _AppErrCodeName[290:306],
+	AppErrCodeIdentityAlreadyLinked:   _AppErrCodeName[306:327],
+	AppErrCodeLastLoginMethod:         _AppErrCodeName[327:342],
+	AppErrCodeUsernameUnavailable:     _AppErrCodeName[342:361],
+	AppErrCodeUsernameChangeTooOften:  _AppErrCodeName[361:383],
 }
 
 // String implements the Stringer interface.
@@ -126,6 +198,18 @@ var _AppErrCodeValue = map[string]AppErrCode{
 	_AppErrCodeName[118:137]: AppErrCodeCategoryValidFailed,
 	_AppErrCodeName[137:149]: AppErrCodeUserNotExist,
 	_AppErrCodeName[149:164]: AppErrCodeArticleNotExist,
+	_AppErrCodeName[164:183]: AppErrCodeApiTokenValidFailed,
+	_AppErrCodeName[183:200]: AppErrCodeSearchValidFailed,
+	_AppErrCodeName[200:214]: AppErrCodeTagValidFailed,
+	_AppErrCodeName[214:231]: AppErrCodeReportValidFailed,
+	_AppErrCodeName[231:252]: AppErrCodeAttachmentValidFailed,
+	_AppErrCodeName[252:275]: AppErrCodeAttachmentQuotaExceeded,
+	_AppErrCodeName[275:290]: AppErrCodePollValidFailed,
+	_AppErrCodeName[290:306]: AppErrCodeDraftValidFailed,
+	_AppErrCodeName[306:327]: AppErrCodeIdentityAlreadyLinked,
+	_AppErrCodeName[327:342]: AppErrCodeLastLoginMethod,
+	_AppErrCodeName[342:361]: AppErrCodeUsernameUnavailable,
+	_AppErrCodeName[361:383]: AppErrCodeUsernameChangeTooOften,
 }
 
 // ParseAppErrCode attempts to convert a string to a AppErrCode.
@@ -137,16 +221,28 @@ func ParseAppErrCode(name string) (AppErrCode, error) {
 }
 
 var (
-	AppErrAlreadyRegistered     = NewAppError(AppErrCodeAlreadyRegistered)
-	AppErrNotRegistered         = NewAppError(AppErrCodeNotRegistered)
-	AppErrUserValidFailed       = NewAppError(AppErrCodeUserValidFailed)
-	AppErrArticleValidFailed    = NewAppError(AppErrCodeArticleValidFailed)
-	AppErrPermissionValidFailed = NewAppError(AppErrCodePermissionValidFailed)
-	AppErrRoleValidFailed       = NewAppError(AppErrCodeRoleValidFailed)
-	AppErrActivityValidFailed   = NewAppError(AppErrCodeActivityValidFailed)
-	AppErrCategoryValidFailed   = NewAppError(AppErrCodeCategoryValidFailed)
-	AppErrUserNotExist          = NewAppError(AppErrCodeUserNotExist)
-	AppErrArticleNotExist       = NewAppError(AppErrCodeArticleNotExist)
+	AppErrAlreadyRegistered       = NewAppError(AppErrCodeAlreadyRegistered)
+	AppErrNotRegistered           = NewAppError(AppErrCodeNotRegistered)
+	AppErrUserValidFailed         = NewAppError(AppErrCodeUserValidFailed)
+	AppErrArticleValidFailed      = NewAppError(AppErrCodeArticleValidFailed)
+	AppErrPermissionValidFailed   = NewAppError(AppErrCodePermissionValidFailed)
+	AppErrRoleValidFailed         = NewAppError(AppErrCodeRoleValidFailed)
+	AppErrActivityValidFailed     = NewAppError(AppErrCodeActivityValidFailed)
+	AppErrCategoryValidFailed     = NewAppError(AppErrCodeCategoryValidFailed)
+	AppErrUserNotExist            = NewAppError(AppErrCodeUserNotExist)
+	AppErrArticleNotExist         = NewAppError(AppErrCodeArticleNotExist)
+	AppErrApiTokenValidFailed     = NewAppError(AppErrCodeApiTokenValidFailed)
+	AppErrSearchValidFailed       = NewAppError(AppErrCodeSearchValidFailed)
+	AppErrTagValidFailed          = NewAppError(AppErrCodeTagValidFailed)
+	AppErrReportValidFailed       = NewAppError(AppErrCodeReportValidFailed)
+	AppErrAttachmentValidFailed   = NewAppError(AppErrCodeAttachmentValidFailed)
+	AppErrAttachmentQuotaExceeded = NewAppError(AppErrCodeAttachmentQuotaExceeded)
+	AppErrPollValidFailed         = NewAppError(AppErrCodePollValidFailed)
+	AppErrDraftValidFailed        = NewAppError(AppErrCodeDraftValidFailed)
+	AppErrIdentityAlreadyLinked   = NewAppError(AppErrCodeIdentityAlreadyLinked)
+	AppErrLastLoginMethod         = NewAppError(AppErrCodeLastLoginMethod)
+	AppErrUsernameUnavailable     = NewAppError(AppErrCodeUsernameUnavailable)
+	AppErrUsernameChangeTooOften  = NewAppError(AppErrCodeUsernameChangeTooOften)
 )
 
 func (x AppErrCode) I18nID() string {
@@ -154,16 +250,28 @@ func (x AppErrCode) I18nID() string {
 }
 
 var _AppErrCodeTextMap = map[AppErrCode]string{
-	AppErrCodeAlreadyRegistered:     "already registered",
-	AppErrCodeNotRegistered:         "not registered",
-	AppErrCodeUserValidFailed:       "user data validation failed",
-	AppErrCodeArticleValidFailed:    "article data validation failed",
-	AppErrCodePermissionValidFailed: "permission data validation failed",
-	AppErrCodeRoleValidFailed:       "role data validation failed",
-	AppErrCodeActivityValidFailed:   "activity data validation failed",
-	AppErrCodeCategoryValidFailed:   "category data validation failed",
-	AppErrCodeUserNotExist:          "user dose not exist",
-	AppErrCodeArticleNotExist:       "article dose not exist",
+	AppErrCodeAlreadyRegistered:       "already registered",
+	AppErrCodeNotRegistered:           "not registered",
+	AppErrCodeUserValidFailed:         "user data validation failed",
+	AppErrCodeArticleValidFailed:      "article data validation failed",
+	AppErrCodePermissionValidFailed:   "permission data validation failed",
+	AppErrCodeRoleValidFailed:         "role data validation failed",
+	AppErrCodeActivityValidFailed:     "activity data validation failed",
+	AppErrCodeCategoryValidFailed:     "category data validation failed",
+	AppErrCodeUserNotExist:            "user dose not exist",
+	AppErrCodeArticleNotExist:         "article dose not exist",
+	AppErrCodeApiTokenValidFailed:     "API token data validation failed",
+	AppErrCodeSearchValidFailed:       "search query validation failed",
+	AppErrCodeTagValidFailed:          "tag data validation failed",
+	AppErrCodeReportValidFailed:       "report data validation failed",
+	AppErrCodeAttachmentValidFailed:   "attachment validation failed",
+	AppErrCodeAttachmentQuotaExceeded: "attachment storage quota exceeded",
+	AppErrCodePollValidFailed:         "poll data validation failed",
+	AppErrCodeDraftValidFailed:        "draft data validation failed",
+	AppErrCodeIdentityAlreadyLinked:   "login method is already linked",
+	AppErrCodeLastLoginMethod:         "the last login method can not be removed",
+	AppErrCodeUsernameUnavailable:     "username is taken or reserved",
+	AppErrCodeUsernameChangeTooOften:  "username is changed too often",
 }
 
 func (x AppErrCode) Text(upCaseHead bool, i18nCustom *i18nc.I18nCustom) string {
@@ -225,4 +333,52 @@ func AppErrCodeAddI18nConfigs(ic *i18nc.I18nCustom) {
 		ID:    "AppErrCode_ArticleNotExist",
 		Other: "article dose not exist",
 	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_ApiTokenValidFailed",
+		Other: "API token data validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_SearchValidFailed",
+		Other: "search query validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_TagValidFailed",
+		Other: "tag data validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_ReportValidFailed",
+		Other: "report data validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_AttachmentValidFailed",
+		Other: "attachment validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_AttachmentQuotaExceeded",
+		Other: "attachment storage quota exceeded",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_PollValidFailed",
+		Other: "poll data validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_DraftValidFailed",
+		Other: "draft data validation failed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_IdentityAlreadyLinked",
+		Other: "login method is already linked",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_LastLoginMethod",
+		Other: "the last login method can not be removed",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_UsernameUnavailable",
+		Other: "username is taken or reserved",
+	})
+	ic.AddLocalizeConfig(&i18n.Message{
+		ID:    "AppErrCode_UsernameChangeTooOften",
+		Other: "username is changed too often",
+	})
 }
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	i18nc "github.com/oodzchen/dproject/i18n"
+)
+
+func TestLocalizeError(t *testing.T) {
+	ic := i18nc.New([]string{"../i18n/active.ja.toml"})
+	SetupI18n(ic)
+
+	article := &Article{
+		AuthorId:        1,
+		CategoryFrontId: "general",
+		Content:         "content",
+	}
+	err := article.Valid(false)
+	if !errors.Is(err, AppErrArticleValidFailed) {
+		t.Fatalf("want article validation error, got %v", err)
+	}
+
+	for _, lang := range []string{"en", "ja"} {
+		lic := ic.WithLang(lang)
+		want := AppErrArticleValidFailed.(*AppError).ErrCode.Text(false, lic) + ", " + lic.LocalTpl("Required", "FieldNames", lic.LocalTpl("ArticleTitle"))
+
+		if got := LocalizeError(err, lic); got != want {
+			t.Errorf("%s: want %q, got %q", lang, want, got)
+		}
+	}
+
+	if LocalizeError(err, ic.WithLang("en")) == LocalizeError(err, ic.WithLang("ja")) {
+		t.Error("translation of ja is not used")
+	}
+
+	plainErr := errors.New("plain error")
+	if got := LocalizeError(plainErr, ic); got != plainErr.Error() {
+		t.Errorf("want %q, got %q", plainErr.Error(), got)
+	}
+}
+
+func TestArticleDisplayTitle(t *testing.T) {
+	ic := i18nc.New([]string{"../i18n/active.ja.toml"})
+
+	article := &Article{
+		Title:                 "Reply",
+		ReplyDepth:            2,
+		ReplyRootArticleTitle: "Root",
+	}
+
+	for _, lang := range []string{"en", "ja"} {
+		lic := ic.WithLang(lang)
+		want := lic.LocalTpl("Re") + " × 2: Root"
+		if got := article.DisplayTitle(lic); got != want {
+			t.Errorf("%s: want %q, got %q", lang, want, got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"html"
+	"time"
+	"unicode/utf8"
+)
+
+// Previews are fetched again after the time
+const LinkPreviewLifeTime = 7 * 24 * time.Hour
+
+type LinkPreview struct {
+	Id          int
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+	FetchedAt   time.Time
+}
+
+func (lp *LinkPreview) Sanitize() {
+	lp.Title = html.EscapeString(lp.Title)
+	lp.Description = html.EscapeString(lp.Description)
+	lp.ImageURL = html.EscapeString(lp.ImageURL)
+	lp.SiteName = html.EscapeString(lp.SiteName)
+}
+
+func (lp *LinkPreview) Expired() bool {
+	return time.Since(lp.FetchedAt) > LinkPreviewLifeTime
+}
+
+// Previews without any metadata are not displayed
+func (lp *LinkPreview) IsEmpty() bool {
+	return lp.Title == "" && lp.Description == "" && lp.ImageURL == ""
+}
+
+// ArticleTitle returns the preview title for the article which has no title,
+// it's escaped as other article titles and cut to the max title length
+func (lp *LinkPreview) ArticleTitle() string {
+	runes := []rune(html.UnescapeString(lp.Title))
+	for len(runes) > 0 {
+		title := html.EscapeString(string(runes))
+		if utf8.RuneCountInString(title) <= MAX_ARTICLE_TITLE_LEN {
+			return title
+		}
+		runes = runes[:len(runes)-1]
+	}
+	return ""
+}
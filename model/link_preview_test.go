@@ -0,0 +1,40 @@
+package model
+
+import (
+	"html"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLinkPreviewArticleTitle(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain", "Hello world", "Hello world"},
+		{"escaped", "Tom &amp; Jerry", "Tom &amp; Jerry"},
+		{"cut long title", strings.Repeat("a", MAX_ARTICLE_TITLE_LEN+10), strings.Repeat("a", MAX_ARTICLE_TITLE_LEN)},
+		{"not cut in entity", strings.Repeat("a", MAX_ARTICLE_TITLE_LEN-3) + "&amp;", strings.Repeat("a", MAX_ARTICLE_TITLE_LEN-3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			lp := &LinkPreview{Title: tt.in}
+			got := lp.ArticleTitle()
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+
+			if utf8.RuneCountInString(got) > MAX_ARTICLE_TITLE_LEN {
+				t.Errorf("title length %d exceeds max length", utf8.RuneCountInString(got))
+			}
+
+			if html.EscapeString(html.UnescapeString(got)) != got {
+				t.Errorf("title %q is not escaped properly", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Max count of the users mentioned in one article, the rest are ignored
+const MaxArticleMentionCount = 10
+
+// Username after "@", which is not a part of a word, an email or a URL path
+var reMention = regexp.MustCompile(`(^|[^a-zA-Z0-9._@/-])@([a-zA-Z0-9][a-zA-Z0-9._-]*[a-zA-Z0-9])`)
+
+// ParseMentions returns the unique usernames mentioned in the content
+func ParseMentions(content string) []string {
+	var list []string
+	for _, match := range reMention.FindAllStringSubmatch(content, -1) {
+		username := match[2]
+		if len(username) > MaxUsernameLen || slices.Contains(list, username) {
+			continue
+		}
+
+		list = append(list, username)
+		if len(list) >= MaxArticleMentionCount {
+			break
+		}
+	}
+	return list
+}
+
+// FindMentions returns the byte ranges of the mentions of the given usernames
+// in the text, each range covers "@" and the username
+func FindMentions(text string, usernames []string) [][]int {
+	if len(usernames) == 0 || !strings.Contains(text, "@") {
+		return nil
+	}
+
+	var list [][]int
+	for _, match := range reMention.FindAllStringSubmatchIndex(text, -1) {
+		if slices.Contains(usernames, text[match[4]:match[5]]) {
+			list = append(list, []int{match[4] - 1, match[5]})
+		}
+	}
+	return list
+}
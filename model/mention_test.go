@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	var manyMentions []string
+	var wantMany []string
+	for i := 0; i < MaxArticleMentionCount+2; i++ {
+		name := fmt.Sprintf("user%d", i)
+		manyMentions = append(manyMentions, "@"+name)
+		if i < MaxArticleMentionCount {
+			wantMany = append(wantMany, name)
+		}
+	}
+
+	tests := []struct {
+		desc string
+		in   string
+		want []string
+	}{
+		{"no mentions", "hello world", nil},
+		{"single", "hi @alice", []string{"alice"}},
+		{"start of content", "@alice hi", []string{"alice"}},
+		{"with separators", "@bob.smith and @carol_x-y", []string{"bob.smith", "carol_x-y"}},
+		{"trailing punctuation", "thanks @alice.", []string{"alice"}},
+		{"duplicated", "@alice @bob @alice", []string{"alice", "bob"}},
+		{"email is not mention", "mail me at me@example.com", nil},
+		{"url path is not mention", "https://example.com/@alice", nil},
+		{"too long", "@" + strings.Repeat("a", MaxUsernameLen+1), nil},
+		{"max count", strings.Join(manyMentions, " "), wantMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := ParseMentions(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindMentions(t *testing.T) {
+	tests := []struct {
+		desc      string
+		in        string
+		usernames []string
+		want      [][]int
+	}{
+		{"mentioned user", "hi @alice", []string{"alice"}, [][]int{{3, 9}}},
+		{"start of text", "@alice hi", []string{"alice"}, [][]int{{0, 6}}},
+		{"unknown user", "hi @alice and @bob", []string{"bob"}, [][]int{{14, 18}}},
+		{"no usernames", "hi @alice", nil, nil},
+		{"email is not mention", "me@alice.com", []string{"alice"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := FindMentions(tt.in, tt.usernames)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
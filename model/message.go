@@ -8,6 +8,15 @@ const (
 	MessageTypeReply    MessageType = "reply"
 	MessageTypeCategory             = "category"
 	MessageTypeSystem               = "system"
+	MessageTypeMention              = "mention"
+)
+
+// MessageAction tells what happened in a system message
+type MessageAction string
+
+const (
+	MessageActionCategoryApproved MessageAction = "category_approved"
+	MessageActionCategoryRejected               = "category_rejected"
 )
 
 type Message struct {
@@ -23,4 +32,5 @@ type Message struct {
 	IsRead           bool
 	CreatedAt        *time.Time
 	Type             MessageType
+	Action           MessageAction
 }
@@ -4,12 +4,8 @@ import (
 	i18nc "github.com/oodzchen/dproject/i18n"
 )
 
-var translator *i18nc.I18nCustom
-
 func init() {
 	InitConfidences()
-	ic := i18nc.New([]string{})
-	SetupI18n(ic)
 }
 
 type OptionItem struct {
@@ -36,12 +32,10 @@ func ConvertEnumToOPtions(values []StringEnum, upCaseHead bool, enumName string,
 	return options
 }
 
+// Add the i18n messages of enums to ic
 func SetupI18n(ic *i18nc.I18nCustom) {
-	translator = ic
-	AcActionAddI18nConfigs(translator)
-	AcTypeAddI18nConfigs(translator)
-	AcModelAddI18nConfigs(translator)
-	AppErrCodeAddI18nConfigs(translator)
-
-	UpdateErrI18n()
+	AcActionAddI18nConfigs(ic)
+	AcTypeAddI18nConfigs(ic)
+	AcModelAddI18nConfigs(ic)
+	AppErrCodeAddI18nConfigs(ic)
 }
@@ -59,6 +59,7 @@ type PageData struct {
 	LoginedUser           *User
 	JSONStr               string
 	CSRFField             string
+	CSRFToken             string
 	UISettings            *UISettings
 	RoutePath             string
 	RouteQuery            url.Values
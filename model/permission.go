@@ -53,8 +53,8 @@ type PermissionListItem struct {
 // 	}
 // }
 
-func permissionValidErr(str string) error {
-	return errors.Join(AppErrPermissionValidFailed, errors.New(", "+str))
+func permissionValidErr(err error) error {
+	return errors.Join(AppErrPermissionValidFailed, err)
 }
 
 const PermissionFrontIdMaxLen int = 50
@@ -82,27 +82,27 @@ func (p *Permission) Valid() error {
 	}
 
 	if lackField != "" {
-		return permissionValidErr(fmt.Sprintf("require field: %s", lackField))
+		return permissionValidErr(fmt.Errorf("require field: %s", lackField))
 	}
 
 	// if ok := ValidPermissionModule(string(p.Module)); !ok {
-	// 	return permissionValidErr("module not exist")
+	// 	return permissionValidErr(errors.New("module not exist"))
 	// }
 
 	if utf8.RuneCountInString(p.FrontId) > PermissionFrontIdMaxLen {
-		return permissionValidErr(fmt.Sprintf("front id length limit in %d characters", PermissionFrontIdMaxLen))
+		return permissionValidErr(fmt.Errorf("front id length limit in %d characters", PermissionFrontIdMaxLen))
 	}
 
 	if !rePermissionFrontId.Match([]byte(p.FrontId)) {
-		return permissionValidErr("front id format error")
+		return permissionValidErr(errors.New("front id format error"))
 	}
 
 	if utf8.RuneCountInString(p.Name) > PermissionNameMaxLen {
-		return permissionValidErr(fmt.Sprintf("name length limit in %d characters", PermissionNameMaxLen))
+		return permissionValidErr(fmt.Errorf("name length limit in %d characters", PermissionNameMaxLen))
 	}
 
 	if !rePermissionName.Match([]byte(p.Name)) {
-		return permissionValidErr("name format error")
+		return permissionValidErr(errors.New("name format error"))
 	}
 
 	return nil
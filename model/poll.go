@@ -0,0 +1,147 @@
+package model
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	MinPollOptionCount = 2
+	MaxPollOptionCount = 10
+	MaxPollOptionLen   = 100
+)
+
+type PollOption struct {
+	Id        int
+	PollId    int
+	Content   string
+	Position  int
+	VoteCount int
+	// Current user voted this option
+	Voted bool
+	// Usernames of voters, only for public polls
+	Voters []string
+}
+
+type Poll struct {
+	Id        int
+	ArticleId int
+	Multiple  bool
+	Anonymous bool
+	// Zero for never closed
+	ClosedAt     time.Time
+	NullClosedAt pgtype.Timestamp
+	CreatedAt    time.Time
+	Options      []*PollOption
+	// Count of users who voted
+	VoterCount int
+	// Current user has voted
+	Voted bool
+}
+
+func pollValidErr(err error) error {
+	return errors.Join(AppErrPollValidFailed, err)
+}
+
+func (p *Poll) FormatNullValues() {
+	if p.ClosedAt.IsZero() && p.NullClosedAt.Valid {
+		p.ClosedAt = p.NullClosedAt.Time
+	}
+}
+
+// Trim spaces of options, empty and duplicated options are removed
+func (p *Poll) TrimSpace() {
+	var list []*PollOption
+	seen := make(map[string]bool)
+	for _, item := range p.Options {
+		item.Content = strings.TrimSpace(item.Content)
+		if item.Content == "" || seen[item.Content] {
+			continue
+		}
+		seen[item.Content] = true
+		item.Position = len(list)
+		list = append(list, item)
+	}
+	p.Options = list
+}
+
+func (p *Poll) Sanitize() {
+	for _, item := range p.Options {
+		item.Content = html.EscapeString(item.Content)
+	}
+}
+
+func (p *Poll) Valid() error {
+	if len(p.Options) < MinPollOptionCount || len(p.Options) > MaxPollOptionCount {
+		return pollValidErr(NewLocalError("PollOptionCountLimit", "Min", MinPollOptionCount, "Max", MaxPollOptionCount))
+	}
+
+	for _, item := range p.Options {
+		if utf8.RuneCountInString(item.Content) > MaxPollOptionLen {
+			return pollValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("PollOption"), "Num", MaxPollOptionLen))
+		}
+	}
+
+	if !p.ClosedAt.IsZero() && !p.ClosedAt.After(time.Now()) {
+		return pollValidErr(NewLocalError("PollClosedAtTip"))
+	}
+
+	return nil
+}
+
+func (p *Poll) Closed() bool {
+	return !p.ClosedAt.IsZero() && !p.ClosedAt.After(time.Now())
+}
+
+// Results are shown after the current user voted or the poll is closed
+func (p *Poll) ShowResults() bool {
+	return p.Voted || p.Closed()
+}
+
+// Percentage of voters who chose the option
+func (p *Poll) Percent(option *PollOption) int {
+	if p.VoterCount == 0 {
+		return 0
+	}
+	return option.VoteCount * 100 / p.VoterCount
+}
+
+// ValidVote checks the options chosen by the current user, the poll should be
+// open and not voted by the user before
+func (p *Poll) ValidVote(optionIds []int) error {
+	if p.Closed() {
+		return pollValidErr(NewLocalError("PollClosed"))
+	}
+
+	if p.Voted {
+		return pollValidErr(NewLocalError("PollAlreadyVoted"))
+	}
+
+	if len(optionIds) == 0 {
+		return pollValidErr(NewLocalError("Required", "FieldNames", NewLocalText("PollOption")))
+	}
+
+	if !p.Multiple && len(optionIds) > 1 {
+		return pollValidErr(NewLocalError("PollSingleChoiceTip"))
+	}
+
+	existMap := make(map[int]bool)
+	for _, item := range p.Options {
+		existMap[item.Id] = true
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range optionIds {
+		if !existMap[id] || seen[id] {
+			return pollValidErr(NewLocalError("NotExist", "FieldNames", NewLocalText("PollOption")))
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
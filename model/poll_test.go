@@ -0,0 +1,156 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func pollOptions(contents ...string) []*PollOption {
+	var list []*PollOption
+	for i, item := range contents {
+		list = append(list, &PollOption{Id: i + 1, Content: item})
+	}
+	return list
+}
+
+func TestPollTrimSpace(t *testing.T) {
+	poll := &Poll{Options: pollOptions(" Yes ", "", "No", "Yes", "  ")}
+	poll.TrimSpace()
+
+	var got []string
+	for i, item := range poll.Options {
+		if item.Position != i {
+			t.Errorf("option %q should be at position %d, but got %d", item.Content, i, item.Position)
+		}
+		got = append(got, item.Content)
+	}
+
+	if strings.Join(got, ",") != "Yes,No" {
+		t.Errorf("unexpected options %v", got)
+	}
+}
+
+func TestPollValid(t *testing.T) {
+	var tooMany []string
+	for i := 0; i <= MaxPollOptionCount; i++ {
+		tooMany = append(tooMany, strings.Repeat("a", i+1))
+	}
+
+	tests := []struct {
+		desc  string
+		in    *Poll
+		valid bool
+	}{
+		{
+			desc:  "All valid",
+			in:    &Poll{Options: pollOptions("Yes", "No")},
+			valid: true,
+		},
+		{
+			desc:  "Closing time in the future",
+			in:    &Poll{Options: pollOptions("Yes", "No"), ClosedAt: time.Now().Add(time.Hour)},
+			valid: true,
+		},
+		{
+			desc:  "Too few options",
+			in:    &Poll{Options: pollOptions("Yes")},
+			valid: false,
+		},
+		{
+			desc:  "Too many options",
+			in:    &Poll{Options: pollOptions(tooMany...)},
+			valid: false,
+		},
+		{
+			desc:  "Option length",
+			in:    &Poll{Options: pollOptions("Yes", strings.Repeat("a", MaxPollOptionLen+1))},
+			valid: false,
+		},
+		{
+			desc:  "Closing time in the past",
+			in:    &Poll{Options: pollOptions("Yes", "No"), ClosedAt: time.Now().Add(-time.Hour)},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("poll: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestPollValidVote(t *testing.T) {
+	tests := []struct {
+		desc      string
+		in        *Poll
+		optionIds []int
+		valid     bool
+	}{
+		{
+			desc:      "Single choice",
+			in:        &Poll{Options: pollOptions("Yes", "No")},
+			optionIds: []int{1},
+			valid:     true,
+		},
+		{
+			desc:      "Multiple choice",
+			in:        &Poll{Multiple: true, Options: pollOptions("A", "B", "C")},
+			optionIds: []int{1, 3},
+			valid:     true,
+		},
+		{
+			desc:      "Several options of single choice",
+			in:        &Poll{Options: pollOptions("Yes", "No")},
+			optionIds: []int{1, 2},
+			valid:     false,
+		},
+		{
+			desc:      "No option",
+			in:        &Poll{Options: pollOptions("Yes", "No")},
+			optionIds: nil,
+			valid:     false,
+		},
+		{
+			desc:      "Option of other polls",
+			in:        &Poll{Options: pollOptions("Yes", "No")},
+			optionIds: []int{5},
+			valid:     false,
+		},
+		{
+			desc:      "Duplicated options",
+			in:        &Poll{Multiple: true, Options: pollOptions("Yes", "No")},
+			optionIds: []int{1, 1},
+			valid:     false,
+		},
+		{
+			desc:      "Already voted",
+			in:        &Poll{Voted: true, Options: pollOptions("Yes", "No")},
+			optionIds: []int{1},
+			valid:     false,
+		},
+		{
+			desc:      "Closed",
+			in:        &Poll{ClosedAt: time.Now().Add(-time.Minute), Options: pollOptions("Yes", "No")},
+			optionIds: []int{1},
+			valid:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.ValidVote(tt.optionIds)
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("vote %v validate result should be %t, but got %t, error: %v", tt.optionIds, tt.valid, got, err)
+			}
+		})
+	}
+}
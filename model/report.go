@@ -0,0 +1,122 @@
+package model
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxReportCommentLen = 500
+
+type ReportReason string
+
+const (
+	ReportReasonSpam     ReportReason = "spam"
+	ReportReasonAbuse                 = "abuse"
+	ReportReasonOffTopic              = "off_topic"
+	ReportReasonOther                 = "other"
+)
+
+var ReportReasons = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonAbuse,
+	ReportReasonOffTopic,
+	ReportReasonOther,
+}
+
+func ValidReportReason(reason string) bool {
+	for _, item := range ReportReasons {
+		if string(item) == reason {
+			return true
+		}
+	}
+	return false
+}
+
+type ReportResolution string
+
+const (
+	ReportResolutionDismiss ReportResolution = "dismiss"
+	ReportResolutionDelete                   = "delete"
+	ReportResolutionLock                     = "lock"
+	ReportResolutionFadeOut                  = "fade_out"
+	ReportResolutionBan                      = "ban"
+)
+
+var ReportResolutions = []ReportResolution{
+	ReportResolutionDismiss,
+	ReportResolutionDelete,
+	ReportResolutionLock,
+	ReportResolutionFadeOut,
+	ReportResolutionBan,
+}
+
+func ValidReportResolution(resolution string) bool {
+	for _, item := range ReportResolutions {
+		if string(item) == resolution {
+			return true
+		}
+	}
+	return false
+}
+
+type Report struct {
+	Id           int
+	ArticleId    int
+	ReporterId   int
+	ReporterName string
+	Reason       ReportReason
+	Comment      string
+	CreatedAt    time.Time
+}
+
+func reportValidErr(err error) error {
+	return errors.Join(AppErrReportValidFailed, err)
+}
+
+func (r *Report) TrimSpace() {
+	r.Comment = strings.TrimSpace(r.Comment)
+}
+
+func (r *Report) Sanitize() {
+	r.Comment = html.EscapeString(r.Comment)
+}
+
+func (r *Report) Valid() error {
+	if r.ArticleId == 0 || r.ReporterId == 0 {
+		return reportValidErr(errors.New("require field: article id and reporter id"))
+	}
+
+	if !ValidReportReason(string(r.Reason)) {
+		return reportValidErr(NewLocalError("Required", "FieldNames", NewLocalText("ReportReason")))
+	}
+
+	// Comment tells what the other reason is
+	if r.Reason == ReportReasonOther && r.Comment == "" {
+		return reportValidErr(NewLocalError("Required", "FieldNames", NewLocalText("ReportComment")))
+	}
+
+	if utf8.RuneCountInString(r.Comment) > MaxReportCommentLen {
+		return reportValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("ReportComment"), "Num", MaxReportCommentLen))
+	}
+
+	return nil
+}
+
+// ArticleReport is the reports of an article aggregated, reports resolved at
+// the same time are grouped together
+type ArticleReport struct {
+	Article        *Article
+	Reports        []*Report
+	ReasonCounts   map[ReportReason]int
+	LastReportedAt time.Time
+	Resolution     ReportResolution
+	ResolverName   string
+	ResolvedAt     *time.Time
+}
+
+func (ar *ArticleReport) ReportCount() int {
+	return len(ar.Reports)
+}
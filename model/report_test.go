@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Report
+		valid bool
+	}{
+		{
+			desc:  "All valid",
+			in:    &Report{ArticleId: 1, ReporterId: 2, Reason: ReportReasonSpam},
+			valid: true,
+		},
+		{
+			desc:  "Other reason with comment",
+			in:    &Report{ArticleId: 1, ReporterId: 2, Reason: ReportReasonOther, Comment: "Copied from another site"},
+			valid: true,
+		},
+		{
+			desc:  "Article id is required",
+			in:    &Report{ReporterId: 2, Reason: ReportReasonAbuse},
+			valid: false,
+		},
+		{
+			desc:  "Reporter id is required",
+			in:    &Report{ArticleId: 1, Reason: ReportReasonAbuse},
+			valid: false,
+		},
+		{
+			desc:  "Unknown reason",
+			in:    &Report{ArticleId: 1, ReporterId: 2, Reason: "boring"},
+			valid: false,
+		},
+		{
+			desc:  "Other reason without comment",
+			in:    &Report{ArticleId: 1, ReporterId: 2, Reason: ReportReasonOther},
+			valid: false,
+		},
+		{
+			desc:  "Comment length",
+			in:    &Report{ArticleId: 1, ReporterId: 2, Reason: ReportReasonOffTopic, Comment: strings.Repeat("a", MaxReportCommentLen+1)},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("report: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestValidReportResolution(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"dismiss", true},
+		{"delete", true},
+		{"lock", true},
+		{"fade_out", true},
+		{"ban", true},
+		{"", false},
+		{"pin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := ValidReportResolution(tt.in); got != tt.want {
+				t.Errorf("resolution %q should be %t, but got %t", tt.in, tt.want, got)
+			}
+		})
+	}
+}
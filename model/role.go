@@ -29,8 +29,8 @@ type Role struct {
 	FormattedPermissions []*PermissionListItem
 }
 
-func roleValidErr(str string) error {
-	return errors.Join(AppErrRoleValidFailed, errors.New(", "+str))
+func roleValidErr(err error) error {
+	return errors.Join(AppErrRoleValidFailed, err)
 }
 
 const RoleFrontIdMaxLen int = 50
@@ -53,27 +53,27 @@ func (r *Role) Valid(isUpdate bool) error {
 	}
 
 	if lackField != "" {
-		return roleValidErr(fmt.Sprintf("require field: %s", lackField))
+		return roleValidErr(fmt.Errorf("require field: %s", lackField))
 	}
 
 	if !isUpdate {
 		if utf8.RuneCountInString(r.FrontId) > PermissionFrontIdMaxLen {
-			return roleValidErr(fmt.Sprintf("front id length limit in %d characters", PermissionFrontIdMaxLen))
+			return roleValidErr(fmt.Errorf("front id length limit in %d characters", PermissionFrontIdMaxLen))
 		}
 
 		reFrontId := regexp.MustCompile(`^[\w\d_]{1,` + strconv.Itoa(PermissionFrontIdMaxLen) + `}$`)
 		if !reFrontId.Match([]byte(r.FrontId)) {
-			return roleValidErr("front id format error")
+			return roleValidErr(errors.New("front id format error"))
 		}
 	}
 
 	if utf8.RuneCountInString(r.Name) > PermissionNameMaxLen {
-		return roleValidErr(fmt.Sprintf("name length limit in %d characters", PermissionNameMaxLen))
+		return roleValidErr(fmt.Errorf("name length limit in %d characters", PermissionNameMaxLen))
 	}
 
 	reName := regexp.MustCompile(`^[\w\d\s]{1,` + strconv.Itoa(PermissionNameMaxLen) + `}$`)
 	if !reName.Match([]byte(r.Name)) {
-		return roleValidErr("name format error")
+		return roleValidErr(errors.New("name format error"))
 	}
 
 	return nil
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type SearchType string
+
+const (
+	SearchTypeAll     SearchType = "all"
+	SearchTypeArticle            = "article"
+	SearchTypeReply              = "reply"
+)
+
+var searchTypeMap = map[SearchType]bool{
+	SearchTypeAll:     true,
+	SearchTypeArticle: true,
+	SearchTypeReply:   true,
+}
+
+func ValidSearchType(searchType string) bool {
+	return searchTypeMap[SearchType(searchType)]
+}
+
+const (
+	MaxSearchKeywordsLen = 100
+	HighlightStartSel    = "<mark>"
+	HighlightStopSel     = "</mark>"
+)
+
+// Text search configuration of PostgreSQL for the language, there is no
+// built-in parser for Chinese and Japanese, so they use the simple one
+func (x Lang) TsConfig() string {
+	if x == LangEn {
+		return "english"
+	}
+	return "simple"
+}
+
+// Whether words are not separated by spaces in the language
+func (x Lang) IsCJK() bool {
+	return x == LangZhHans || x == LangZhHant || x == LangJa
+}
+
+type SearchQuery struct {
+	Keywords        string
+	Lang            Lang
+	CategoryFrontId string
+	AuthorName      string
+	StartDate       time.Time
+	EndDate         time.Time
+	Type            SearchType
+}
+
+func searchValidErr(err error) error {
+	return errors.Join(AppErrSearchValidFailed, err)
+}
+
+func (q *SearchQuery) TrimSpace() {
+	q.Keywords = strings.TrimSpace(q.Keywords)
+	q.CategoryFrontId = strings.TrimSpace(q.CategoryFrontId)
+	q.AuthorName = strings.TrimSpace(q.AuthorName)
+}
+
+func (q *SearchQuery) Valid() error {
+	if q.Keywords == "" {
+		return searchValidErr(NewLocalError("Required", "FieldNames", NewLocalText("Keyword", "Count", 2)))
+	}
+
+	if utf8.RuneCountInString(q.Keywords) > MaxSearchKeywordsLen {
+		return searchValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("Keyword", "Count", 2), "Num", MaxSearchKeywordsLen))
+	}
+
+	if !ValidSearchType(string(q.Type)) {
+		return searchValidErr(errors.New("invalid search type"))
+	}
+
+	if !q.StartDate.IsZero() && !q.EndDate.IsZero() && q.EndDate.Before(q.StartDate) {
+		return searchValidErr(NewLocalError("DateRangeInvalid"))
+	}
+
+	return nil
+}
+
+// Plain keywords without the operators of web search syntax
+func (q *SearchQuery) KeywordList() []string {
+	var list []string
+	for _, word := range strings.Fields(q.Keywords) {
+		word = strings.TrimLeft(word, "-")
+		word = strings.Trim(word, `"`)
+		if word == "" || strings.EqualFold(word, "or") {
+			continue
+		}
+		list = append(list, word)
+	}
+	return list
+}
+
+// HighlightKeywords cuts a snippet with at most strLen characters around the
+// first matched keyword from escaped content, and wraps every matched keyword
+// in the snippet with highlight tags. Return empty string if nothing matched.
+func HighlightKeywords(content string, keywords []string, strLen int) string {
+	text := []rune(html.UnescapeString(content))
+	lowerText := toLowerRunes(text)
+
+	var lowerKeywords [][]rune
+	for _, kw := range keywords {
+		if kw != "" {
+			lowerKeywords = append(lowerKeywords, toLowerRunes([]rune(kw)))
+		}
+	}
+
+	matchAt := func(i int) int {
+		for _, kw := range lowerKeywords {
+			if i+len(kw) <= len(lowerText) && string(lowerText[i:i+len(kw)]) == string(kw) {
+				return len(kw)
+			}
+		}
+		return 0
+	}
+
+	first := -1
+	for i := range lowerText {
+		if matchAt(i) > 0 {
+			first = i
+			break
+		}
+	}
+
+	if first < 0 {
+		return ""
+	}
+
+	start := first - strLen/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + strLen
+	if end > len(text) {
+		end = len(text)
+		start = max(end-strLen, 0)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("... ")
+	}
+
+	plainStart := start
+	for i := start; i < end; {
+		n := matchAt(i)
+		if n == 0 {
+			i++
+			continue
+		}
+		if i+n > end {
+			break
+		}
+
+		sb.WriteString(html.EscapeString(string(text[plainStart:i])))
+		sb.WriteString(HighlightStartSel)
+		sb.WriteString(html.EscapeString(string(text[i : i+n])))
+		sb.WriteString(HighlightStopSel)
+		i += n
+		plainStart = i
+	}
+	sb.WriteString(html.EscapeString(string(text[plainStart:end])))
+
+	if end < len(text) {
+		sb.WriteString(" ...")
+	}
+
+	return sb.String()
+}
+
+func toLowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
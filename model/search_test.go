@@ -0,0 +1,83 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchQueryKeywordList(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   string
+		want []string
+	}{
+		{"plain words", "golang  generics", []string{"golang", "generics"}},
+		{"web search operators", `"hello" or -world`, []string{"hello", "world"}},
+		{"only operators", `- "" OR`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			q := &SearchQuery{Keywords: tt.in}
+			got := q.KeywordList()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHighlightKeywords(t *testing.T) {
+	tests := []struct {
+		desc     string
+		content  string
+		keywords []string
+		strLen   int
+		want     string
+	}{
+		{
+			"no match",
+			"hello world",
+			[]string{"golang"},
+			20,
+			"",
+		},
+		{
+			"case insensitive",
+			"Hello world, hello",
+			[]string{"hello"},
+			20,
+			"<mark>Hello</mark> world, <mark>hello</mark>",
+		},
+		{
+			"CJK text",
+			"我喜欢数据库和搜索",
+			[]string{"数据库"},
+			20,
+			"我喜欢<mark>数据库</mark>和搜索",
+		},
+		{
+			"cut around the first match",
+			"0123456789abcdef",
+			[]string{"ab"},
+			8,
+			"... 89<mark>ab</mark>cdef",
+		},
+		{
+			"escaped content",
+			"a &lt;b&gt; &amp; c",
+			[]string{"c"},
+			20,
+			"a &lt;b&gt; &amp; <mark>c</mark>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := HighlightKeywords(tt.content, tt.keywords, tt.strLen)
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
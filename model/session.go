@@ -0,0 +1,91 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Device of a logined session, recorded in the session values
+type SessionDevice struct {
+	UserId    int
+	UserAgent string
+	IP        string
+	Country   string // ISO code of the country detected from IP
+	CreatedAt time.Time
+	ActiveAt  time.Time
+	PublicId  string // only for display, id to revoke the session
+	Current   bool   // only for display, session of current request
+}
+
+var uaBrowsers = []struct {
+	keyword string
+	name    string
+}{
+	// Keywords of derived browsers go before the ones they derive from
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var uaSystems = []struct {
+	keyword string
+	name    string
+}{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Name of the browser and the operating system from user agent, the raw user
+// agent is shown instead if neither is known
+func (sd *SessionDevice) Name() string {
+	var browser, system string
+	for _, item := range uaBrowsers {
+		if strings.Contains(sd.UserAgent, item.keyword) {
+			browser = item.name
+			break
+		}
+	}
+
+	for _, item := range uaSystems {
+		if strings.Contains(sd.UserAgent, item.keyword) {
+			system = item.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " / " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return sd.UserAgent
+	}
+}
+
+const TwoFactorLoginLifeTime = 5 * time.Minute
+
+// Codes can be tried in the life time of a login of the user, counted across
+// the logins started in the period
+const TwoFactorLoginMaxAttempts = 5
+
+// Login waiting for the second step of two-factor authentication, kept in
+// the session values until the code is verified
+type TwoFactorLogin struct {
+	UserId    int
+	StartedAt time.Time
+}
+
+// Expired login should be started over
+func (tl *TwoFactorLogin) Expired() bool {
+	return time.Since(tl.StartedAt) > TwoFactorLoginLifeTime
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionDeviceName(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Chrome / Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge / Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15", "Safari / macOS"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.0.0 Mobile/15E148 Safari/604.1", "Chrome / iOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox / Linux"},
+		{"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "Chrome / Android"},
+		{"curl/8.4.0", "curl/8.4.0"},
+	}
+
+	for _, tt := range tests {
+		device := &SessionDevice{UserAgent: tt.userAgent}
+		if got := device.Name(); got != tt.want {
+			t.Errorf("user agent %q, want %q, got %q", tt.userAgent, tt.want, got)
+		}
+	}
+}
+
+func TestTwoFactorLoginExpired(t *testing.T) {
+	tests := []struct {
+		desc      string
+		startedAt time.Time
+		want      bool
+	}{
+		{"just started", time.Now(), false},
+		{"timeout", time.Now().Add(-TwoFactorLoginLifeTime - time.Second), true},
+	}
+
+	for _, tt := range tests {
+		tl := &TwoFactorLogin{UserId: 1, StartedAt: tt.startedAt}
+		if got := tl.Expired(); got != tt.want {
+			t.Errorf("%s: want expired %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	i18nc "github.com/oodzchen/dproject/i18n"
+)
+
+const (
+	MaxTagFrontIdLen   = 50
+	MaxTagNameLen      = 50
+	MaxTagDescribeLen  = 500
+	MaxArticleTagCount = 5
+)
+
+var reTagFrontId = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+type Tag struct {
+	Id                int
+	FrontId           string
+	Name              string
+	Describe          string
+	AuthorId          int
+	CreatedAt         time.Time
+	TotalArticleCount int
+}
+
+func tagValidErr(err error) error {
+	return errors.Join(AppErrTagValidFailed, err)
+}
+
+func (t *Tag) TrimSpace() {
+	t.FrontId = strings.ToLower(strings.TrimSpace(t.FrontId))
+	t.Name = strings.TrimSpace(t.Name)
+	t.Describe = strings.TrimSpace(t.Describe)
+}
+
+func (t *Tag) Sanitize() {
+	t.Name = html.EscapeString(t.Name)
+	t.Describe = html.EscapeString(t.Describe)
+}
+
+func (t *Tag) Valid() error {
+	if t.FrontId == "" {
+		return tagValidErr(NewLocalError("Required", "FieldNames", NewLocalText("TagFrontId")))
+	}
+
+	if utf8.RuneCountInString(t.FrontId) > MaxTagFrontIdLen {
+		return tagValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("TagFrontId"), "Num", MaxTagFrontIdLen))
+	}
+
+	// "new" is used by the route of tag creating page
+	if !reTagFrontId.MatchString(t.FrontId) || t.FrontId == "new" {
+		return tagValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("TagFrontId")))
+	}
+
+	if t.Name == "" {
+		return tagValidErr(NewLocalError("Required", "FieldNames", NewLocalText("TagName")))
+	}
+
+	if utf8.RuneCountInString(t.Name) > MaxTagNameLen {
+		return tagValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("TagName"), "Num", MaxTagNameLen))
+	}
+
+	if utf8.RuneCountInString(t.Describe) > MaxTagDescribeLen {
+		return tagValidErr(NewLocalError("NotExceed", "FieldNames", NewLocalText("Description"), "Num", MaxTagDescribeLen))
+	}
+
+	return nil
+}
+
+// Trim spaces and remove empty and duplicated ones from the tag front ids
+func CleanTagFrontIds(frontIds []string) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, item := range frontIds {
+		for _, id := range strings.Split(item, ",") {
+			id = strings.ToLower(strings.TrimSpace(id))
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// ValidTags checks the count of article tags, and every tag must be one of
+// the existing tags
+func (a *Article) ValidTags(existTags []*Tag) error {
+	if len(a.TagFrontIds) > MaxArticleTagCount {
+		return articleValidErr(NewLocalError("TagCountLimit", "Num", MaxArticleTagCount))
+	}
+
+	existMap := make(map[string]bool)
+	for _, tag := range existTags {
+		existMap[tag.FrontId] = true
+	}
+
+	for _, frontId := range a.TagFrontIds {
+		if !existMap[frontId] {
+			return articleValidErr(NewLocalError("NotExist", "FieldNames", LocalFunc(func(ic *i18nc.I18nCustom) string {
+				return NewLocalText("Tag", "Count", 1).Localize(ic) + " " + html.EscapeString(frontId)
+			})))
+		}
+	}
+
+	return nil
+}
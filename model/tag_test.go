@@ -0,0 +1,134 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *Tag
+		valid bool
+	}{
+		{
+			desc:  "All valid",
+			in:    &Tag{FrontId: "golang", Name: "Go", Describe: "The Go programming language"},
+			valid: true,
+		},
+		{
+			desc:  "Front id with separators",
+			in:    &Tag{FrontId: "c-plus_plus2", Name: "C++"},
+			valid: true,
+		},
+		{
+			desc:  "Front id is required",
+			in:    &Tag{FrontId: "", Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Front id format",
+			in:    &Tag{FrontId: "go lang", Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Front id starts with separator",
+			in:    &Tag{FrontId: "-go", Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Front id is reserved",
+			in:    &Tag{FrontId: "new", Name: "New"},
+			valid: false,
+		},
+		{
+			desc:  "Front id length",
+			in:    &Tag{FrontId: strings.Repeat("a", MaxTagFrontIdLen+1), Name: "Go"},
+			valid: false,
+		},
+		{
+			desc:  "Name is required",
+			in:    &Tag{FrontId: "golang", Name: ""},
+			valid: false,
+		},
+		{
+			desc:  "Name length",
+			in:    &Tag{FrontId: "golang", Name: strings.Repeat("a", MaxTagNameLen+1)},
+			valid: false,
+		},
+		{
+			desc:  "Describe length",
+			in:    &Tag{FrontId: "golang", Name: "Go", Describe: strings.Repeat("a", MaxTagDescribeLen+1)},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("tag: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestCleanTagFrontIds(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   []string
+		want []string
+	}{
+		{"empty", nil, nil},
+		{"trim and lower", []string{" Golang ", "linux"}, []string{"golang", "linux"}},
+		{"remove duplicated", []string{"golang", "GOLANG", ""}, []string{"golang"}},
+		{"comma separated", []string{"golang,linux", "emacs"}, []string{"golang", "linux", "emacs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := CleanTagFrontIds(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestArticleValidTags(t *testing.T) {
+	existTags := []*Tag{
+		{FrontId: "golang"},
+		{FrontId: "linux"},
+		{FrontId: "emacs"},
+		{FrontId: "clang"},
+		{FrontId: "cpp"},
+		{FrontId: "ai"},
+	}
+
+	tests := []struct {
+		desc  string
+		in    []string
+		valid bool
+	}{
+		{"no tags", nil, true},
+		{"existing tags", []string{"golang", "linux"}, true},
+		{"max count", []string{"golang", "linux", "emacs", "clang", "cpp"}, true},
+		{"exceed max count", []string{"golang", "linux", "emacs", "clang", "cpp", "ai"}, false},
+		{"tag does not exist", []string{"golang", "rust"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			article := &Article{TagFrontIds: tt.in}
+			err := article.ValidTags(existTags)
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("tags: %v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
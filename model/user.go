@@ -4,11 +4,13 @@ import (
 	"errors"
 	"html"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/microcosm-cc/bluemonday"
+	i18nc "github.com/oodzchen/dproject/i18n"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -29,6 +31,8 @@ const (
 	AuthTypeGoogle    AuthType = "google"
 	AuthTypeGithub    AuthType = "github"
 	AuthTypeMicrosoft AuthType = "microsoft"
+	// Generic OpenID Connect providers
+	AuthTypeOIDC AuthType = "oidc"
 )
 
 var AuthTypeNames = map[AuthType]string{
@@ -36,6 +40,7 @@ var AuthTypeNames = map[AuthType]string{
 	AuthTypeGoogle:    "Google",
 	AuthTypeGithub:    "GitHub",
 	AuthTypeMicrosoft: "Microsoft",
+	AuthTypeOIDC:      "OIDC",
 }
 
 func GetAuthTypeList() []AuthType {
@@ -44,6 +49,17 @@ func GetAuthTypeList() []AuthType {
 		AuthTypeGoogle,
 		AuthTypeGithub,
 		AuthTypeMicrosoft,
+		AuthTypeOIDC,
+	}
+}
+
+// Auth types of the users registered with OAuth providers
+func (at AuthType) IsOAuth() bool {
+	switch at {
+	case AuthTypeGoogle, AuthTypeGithub, AuthTypeMicrosoft, AuthTypeOIDC:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -94,6 +110,22 @@ type User struct {
 	BannedEndAt       time.Time
 	BannedDayNum      int
 	BannedCount       int
+	// Front ids of the categories moderated by the user
+	ModeratedCategories []string
+	// Ids of the users blocked by the user
+	BlockedUserIds []int
+	// Receive messages when mentioned by others
+	MentionNotify bool
+	// Second login step with TOTP codes is enabled
+	TotpEnabled bool
+	// Password is set, users registered with OAuth have no password
+	HasPassword bool
+	// Zero if the deletion is not requested
+	NullDeletionRequestedAt pgtype.Timestamp
+	DeletionRequestedAt     time.Time
+	// Set when current request is authenticated by API token
+	TokenScoped      bool
+	TokenPermissions []string
 }
 
 // func (u *User) FormatTimeStr() {
@@ -105,12 +137,50 @@ func (u *User) FormatNullVals() {
 		u.Introduction = u.NullIntroduction.String
 	}
 
+	if u.NullDeletionRequestedAt.Valid {
+		u.DeletionRequestedAt = u.NullDeletionRequestedAt.Time
+	}
+
 	if u.NullBannedStartAt.Valid {
 		u.BannedStartAt = u.NullBannedStartAt.Time
 		u.BannedEndAt = u.BannedStartAt.Add(time.Duration(u.BannedDayNum) * 24 * time.Hour)
 	}
 }
 
+// Limit the user's permissions to the front ids that API token granted
+func (u *User) ScopeToken(permissionIds []string) {
+	u.TokenScoped = true
+	u.TokenPermissions = permissionIds
+}
+
+// TokenPermit reports whether the API token of the request is granted the
+// permission, requests without token are not limited
+func (u *User) TokenPermit(permissionId string) bool {
+	return !u.TokenScoped || slices.Contains(u.TokenPermissions, permissionId)
+}
+
+// PermitInCategory reports whether the user has the permission on articles
+// under the category as a moderator of it
+func (u *User) PermitInCategory(categoryFrontId, permissionId string) bool {
+	if u.Banned || categoryFrontId == "" {
+		return false
+	}
+
+	if !slices.Contains(CategoryModeratorPermissions, permissionId) {
+		return false
+	}
+
+	if !u.TokenPermit(permissionId) {
+		return false
+	}
+
+	return slices.Contains(u.ModeratedCategories, categoryFrontId)
+}
+
+func (u *User) IsBlocking(userId int) bool {
+	return slices.Contains(u.BlockedUserIds, userId)
+}
+
 func (u *User) UpdateBannedState() {
 	if (!u.BannedEndAt.IsZero() && u.BannedEndAt.Compare(time.Now()) > 0) || u.RoleFrontId == "banned_user" {
 		u.Banned = true
@@ -124,8 +194,8 @@ func (u *User) Sanitize(p *bluemonday.Policy) {
 	u.Introduction = html.EscapeString(u.Introduction)
 }
 
-func userValidErr(str string) error {
-	return errors.Join(AppErrUserValidFailed, errors.New(", "+str))
+func userValidErr(err error) error {
+	return errors.Join(AppErrUserValidFailed, err)
 }
 
 func (u *User) TrimSpace() {
@@ -133,49 +203,54 @@ func (u *User) TrimSpace() {
 	u.Name = strings.TrimSpace(u.Name)
 }
 
-var ErrEmailValidFailed error
-
-func UpdateErrI18n() {
-	ErrEmailValidFailed = errors.New(translator.LocalTpl(
+// Tells the email is invalid or already used, without telling which one
+var ErrEmailValidFailed = &LocalError{
+	Text: NewLocalText(
 		"Or",
 		"A",
-		translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Email")),
+		NewLocalText("FormatError", "FieldNames", NewLocalText("Email")),
 		"B",
-		translator.LocalTpl(
+		NewLocalText(
 			"AlreadyExists",
 			"FieldNames",
-			translator.LocalTpl(
+			NewLocalText(
 				"Or",
 				"A",
-				translator.LocalTpl("Username"),
+				NewLocalText("Username"),
 				"B",
-				translator.LocalTpl("Email")),
+				NewLocalText("Email")),
 		),
-	))
+	),
+}
+
+// EmailValidFailedText returns the message of ErrEmailValidFailed in the
+// language of ic
+func EmailValidFailedText(ic *i18nc.I18nCustom) string {
+	return ErrEmailValidFailed.Text.Localize(ic)
 }
 
 func (u *User) Valid(withPassword bool) error {
-	lackField := ""
+	var lackField *LocalText
 
 	if u.Email == "" {
-		lackField = translator.LocalTpl("Email")
+		lackField = NewLocalText("Email")
 	}
 	if u.Name == "" {
-		lackField = translator.LocalTpl("Username")
+		lackField = NewLocalText("Username")
 	}
 
 	if withPassword {
 		if u.Password == "" {
-			lackField = translator.LocalTpl("Password")
+			lackField = NewLocalText("Password")
 		}
 	}
 
-	if lackField != "" {
-		return userValidErr(translator.LocalTpl("Required", "FieldNames", lackField))
+	if lackField != nil {
+		return userValidErr(NewLocalError("Required", "FieldNames", lackField))
 	}
 
 	if err := ValidateEmail(u.Email); err != nil {
-		return userValidErr(ErrEmailValidFailed.Error())
+		return userValidErr(ErrEmailValidFailed)
 	}
 
 	if err := ValidUsername(u.Name); err != nil {
@@ -185,7 +260,7 @@ func (u *User) Valid(withPassword bool) error {
 	if withPassword {
 		err := ValidPassword(u.Password)
 		if err != nil {
-			return errors.Join(AppErrUserValidFailed, errors.New(", "), err)
+			return userValidErr(err)
 		}
 	}
 
@@ -202,7 +277,7 @@ func ValidPassword(pwd string) error {
 
 	if !rePassword.Match(pwdBytes) || !reLetter.Match(pwdBytes) || !reNum.Match(pwdBytes) || !reNotaion.Match(pwdBytes) {
 		// return userValidErr("password format error")
-		return errors.New(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Password")))
+		return NewLocalError("FormatError", "FieldNames", NewLocalText("Password"))
 	}
 
 	return nil
@@ -231,23 +306,23 @@ func DoEncryptPassword(pwd string) (string, error) {
 // Validate email string
 func ValidateEmail(email string) error {
 	if len(email) > MaxEmailLen {
-		return userValidErr(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Email")))
+		return userValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("Email")))
 	}
 	re := regexp.MustCompile(ReEmailStr)
 	if !re.Match([]byte(email)) {
-		return userValidErr(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Email")))
+		return userValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("Email")))
 	}
 	return nil
 }
 
 func ValidUsername(username string) error {
 	if len(username) > MaxUsernameLen {
-		return userValidErr(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Username")))
+		return userValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("Username")))
 	}
 
 	reUsername := regexp.MustCompile(ReUsernameStr)
 	if !reUsername.Match([]byte(username)) {
-		return userValidErr(translator.LocalTpl("FormatError", "FieldNames", translator.LocalTpl("Username")))
+		return userValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("Username")))
 	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+type UserBlockType string
+
+const (
+	// Content of the blocked user is hidden and the replies make no messages
+	UserBlockTypeBlock UserBlockType = "block"
+	// Replies of the muted user make no messages
+	UserBlockTypeMute = "mute"
+)
+
+func ValidUserBlockType(t string) bool {
+	return t == string(UserBlockTypeBlock) || t == string(UserBlockTypeMute)
+}
+
+type UserBlock struct {
+	UserId         int
+	TargetUserId   int
+	TargetUserName string
+	Type           UserBlockType
+	CreatedAt      time.Time
+}
+
+func (b *UserBlock) Valid() error {
+	if b.UserId == 0 || b.TargetUserId == 0 {
+		return userValidErr(errors.New("require field: user id"))
+	}
+
+	if b.UserId == b.TargetUserId {
+		return userValidErr(NewLocalError("CannotBlockSelf"))
+	}
+
+	if !ValidUserBlockType(string(b.Type)) {
+		return userValidErr(NewLocalError("FormatError", "FieldNames", NewLocalText("UserBlockType")))
+	}
+
+	return nil
+}
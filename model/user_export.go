@@ -0,0 +1,94 @@
+package model
+
+import "time"
+
+// Username of the placeholder author, content of the deleted users is
+// transferred to it, which is created in the init migration
+const PlaceholderUsername = "anonymous"
+
+// Personal data of the user, each part is saved as a JSON file in the
+// exported archive
+type UserExport struct {
+	Profile    *UserExportProfile
+	Articles   []*UserExportArticle
+	Replies    []*UserExportArticle
+	Votes      []*UserExportVote
+	Saves      []*UserExportSave
+	Reactions  []*UserExportReaction
+	Messages   []*UserExportMessage
+	Activities []*UserExportActivity
+}
+
+type UserExportIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserExportProfile struct {
+	Id            int                   `json:"id"`
+	Username      string                `json:"username"`
+	Email         string                `json:"email"`
+	Introduction  string                `json:"introduction"`
+	AuthFrom      AuthType              `json:"auth_from"`
+	RoleName      string                `json:"role_name"`
+	Reputation    int                   `json:"reputation"`
+	MentionNotify bool                  `json:"mention_notify"`
+	RegisteredAt  time.Time             `json:"registered_at"`
+	Identities    []*UserExportIdentity `json:"identities"`
+}
+
+type UserExportArticle struct {
+	Id              int       `json:"id"`
+	Title           string    `json:"title,omitempty"`
+	URL             string    `json:"url,omitempty"`
+	Content         string    `json:"content"`
+	CategoryFrontId string    `json:"category,omitempty"`
+	ReplyToId       int       `json:"reply_to,omitempty"`
+	RootArticleId   int       `json:"root_article_id,omitempty"`
+	Deleted         bool      `json:"deleted"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type UserExportVote struct {
+	ArticleId int       `json:"article_id"`
+	Type      VoteType  `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserExportSave struct {
+	ArticleId int       `json:"article_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserExportReaction struct {
+	ArticleId int       `json:"article_id"`
+	Emoji     string    `json:"emoji"`
+	FrontId   string    `json:"front_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Messages received by the user
+type UserExportMessage struct {
+	Id              int       `json:"id"`
+	Type            string    `json:"type"`
+	Action          string    `json:"action,omitempty"`
+	SenderName      string    `json:"sender"`
+	SourceArticleId int       `json:"source_article_id,omitempty"`
+	ContentId       int       `json:"content_id,omitempty"`
+	Content         string    `json:"content,omitempty"`
+	IsRead          bool      `json:"is_read"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type UserExportActivity struct {
+	Type        string    `json:"type"`
+	Action      string    `json:"action"`
+	TargetModel string    `json:"target_model,omitempty"`
+	TargetId    string    `json:"target_id,omitempty"`
+	IpAddr      string    `json:"ip_address"`
+	DeviceInfo  string    `json:"device_info"`
+	Details     string    `json:"details,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Login method from an OAuth provider linked to the user
+type UserIdentity struct {
+	Id     int
+	UserId int
+	// Name of the provider in config/oauth.yml
+	Provider string
+	// Unique id of the user in the provider
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// The last login method can't be removed, users without password need at
+// least one identity left
+func CanUnlinkIdentity(hasPassword bool, identityCount int) bool {
+	return hasPassword || identityCount > 1
+}
+
+const IdentityLinkLifeTime = 10 * time.Minute
+
+// Codes can be tried in the life time of linking to the user
+const IdentityLinkMaxAttempts = 5
+
+// Identity waiting to be linked to the registered user with the same email,
+// kept in the session values until the code sent to the email is verified
+type IdentityLink struct {
+	UserId    int
+	Provider  string
+	Subject   string
+	Email     string
+	StartedAt time.Time
+}
+
+// Expired linking should be started over
+func (il *IdentityLink) Expired() bool {
+	return time.Since(il.StartedAt) > IdentityLinkLifeTime
+}
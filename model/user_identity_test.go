@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanUnlinkIdentity(t *testing.T) {
+	tests := []struct {
+		desc          string
+		hasPassword   bool
+		identityCount int
+		want          bool
+	}{
+		{"password and identity", true, 1, true},
+		{"password only", true, 0, true},
+		{"last identity", false, 1, false},
+		{"two identities", false, 2, true},
+	}
+
+	for _, tt := range tests {
+		if got := CanUnlinkIdentity(tt.hasPassword, tt.identityCount); got != tt.want {
+			t.Errorf("%s: want %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
+
+func TestIdentityLinkExpired(t *testing.T) {
+	tests := []struct {
+		desc      string
+		startedAt time.Time
+		want      bool
+	}{
+		{"just started", time.Now(), false},
+		{"timeout", time.Now().Add(-IdentityLinkLifeTime - time.Second), true},
+	}
+
+	for _, tt := range tests {
+		il := &IdentityLink{UserId: 1, Provider: "github", Subject: "1", StartedAt: tt.startedAt}
+		if got := il.Expired(); got != tt.want {
+			t.Errorf("%s: want expired %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
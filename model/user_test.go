@@ -172,3 +172,101 @@ func TestValidateEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestUserPermitInCategory(t *testing.T) {
+	tests := []struct {
+		desc       string
+		user       *User
+		category   string
+		permission string
+		want       bool
+	}{
+		{
+			"moderator of the category",
+			&User{ModeratedCategories: []string{"golang", "linux"}},
+			"golang", "article.lock", true,
+		},
+		{
+			"not moderator of the category",
+			&User{ModeratedCategories: []string{"linux"}},
+			"golang", "article.lock", false,
+		},
+		{
+			"empty category",
+			&User{ModeratedCategories: []string{"golang"}},
+			"", "article.lock", false,
+		},
+		{
+			"permission out of category scope",
+			&User{ModeratedCategories: []string{"golang"}},
+			"golang", "user.ban", false,
+		},
+		{
+			"banned user",
+			&User{ModeratedCategories: []string{"golang"}, Banned: true},
+			"golang", "article.pin", false,
+		},
+		{
+			"token without the permission",
+			&User{ModeratedCategories: []string{"golang"}, TokenScoped: true, TokenPermissions: []string{"article.pin"}},
+			"golang", "article.delete_others", false,
+		},
+		{
+			"token with the permission",
+			&User{ModeratedCategories: []string{"golang"}, TokenScoped: true, TokenPermissions: []string{"article.pin"}},
+			"golang", "article.pin", true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := tt.user.PermitInCategory(tt.category, tt.permission)
+			if got != tt.want {
+				t.Errorf("permit %s in %s should be %t, but got %t", tt.permission, tt.category, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUserBlockValid(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    *UserBlock
+		valid bool
+	}{
+		{"block", &UserBlock{UserId: 1, TargetUserId: 2, Type: UserBlockTypeBlock}, true},
+		{"mute", &UserBlock{UserId: 1, TargetUserId: 2, Type: UserBlockTypeMute}, true},
+		{"block self", &UserBlock{UserId: 1, TargetUserId: 1, Type: UserBlockTypeBlock}, false},
+		{"empty target", &UserBlock{UserId: 1, Type: UserBlockTypeBlock}, false},
+		{"wrong type", &UserBlock{UserId: 1, TargetUserId: 2, Type: "ignore"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.in.Valid()
+			got := err == nil
+
+			if got != tt.valid {
+				t.Errorf("user block: %+v \nvalidate result should be %t, but got %t, error: %v", tt.in, tt.valid, got, err)
+			}
+		})
+	}
+}
+
+func TestUserTokenPermit(t *testing.T) {
+	tests := []struct {
+		desc string
+		user *User
+		want bool
+	}{
+		{"session", &User{}, true},
+		{"token granted", &User{TokenScoped: true, TokenPermissions: []string{"category.subscribe"}}, true},
+		{"token not granted", &User{TokenScoped: true, TokenPermissions: []string{"article.save"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.user.TokenPermit("category.subscribe"); got != tt.want {
+			t.Errorf("%s: want %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
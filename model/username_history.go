@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Username used by the user before, kept for redirecting and preventing
+// others from taking it until the redirect expires
+type UsernameHistory struct {
+	Id               int
+	UserId           int
+	OldName          string
+	NewName          string
+	CreatedAt        time.Time
+	RedirectExpireAt time.Time
+}
+
+func (uh *UsernameHistory) Redirecting() bool {
+	return time.Now().Before(uh.RedirectExpireAt)
+}
+
+// Time when the username can be changed again, zero if it's never changed
+func UsernameChangeAvailableAt(lastChangedAt time.Time, cooldown time.Duration) time.Time {
+	if lastChangedAt.IsZero() {
+		return time.Time{}
+	}
+	return lastChangedAt.Add(cooldown)
+}
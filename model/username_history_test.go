@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsernameChangeAvailableAt(t *testing.T) {
+	changedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc          string
+		lastChangedAt time.Time
+		cooldown      time.Duration
+		want          time.Time
+	}{
+		{"never changed", time.Time{}, 24 * time.Hour, time.Time{}},
+		{"changed before", changedAt, 24 * time.Hour, changedAt.Add(24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		if got := UsernameChangeAvailableAt(tt.lastChangedAt, tt.cooldown); !got.Equal(tt.want) {
+			t.Errorf("%s: want %v, got %v", tt.desc, tt.want, got)
+		}
+	}
+}
+
+func TestUsernameHistoryRedirecting(t *testing.T) {
+	tests := []struct {
+		desc             string
+		redirectExpireAt time.Time
+		want             bool
+	}{
+		{"not expired", time.Now().Add(time.Hour), true},
+		{"expired", time.Now().Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		uh := &UsernameHistory{OldName: "alice", NewName: "bob", RedirectExpireAt: tt.redirectExpireAt}
+		if got := uh.Redirecting(); got != tt.want {
+			t.Errorf("%s: want redirecting %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Login with OAuth 2.0 and OpenID Connect providers configured in
+// config/oauth.yml. Each provider is configured with its endpoints, scopes
+// and the claims of user info, endpoints of OpenID Connect providers can be
+// discovered from the issuer.
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oodzchen/dproject/config"
+	"golang.org/x/oauth2"
+)
+
+const (
+	DefaultTimeout = 5 * time.Second
+	MaxBodySize    = 1 << 20
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var (
+	ErrProviderNotFound = errors.New("oauth: provider not found")
+	ErrNoSubject        = errors.New("oauth: subject not found in user info")
+)
+
+type UserInfo struct {
+	// Unique id of the user in the provider
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	Name() string
+	DisplayName() string
+	AuthType() string
+	// URL of the consent page, the verifier is used as PKCE code verifier if
+	// the provider enables PKCE
+	AuthCodeURL(ctx context.Context, state, verifier string) (string, error)
+	// Exchange the code for the access token, then fetch the user info
+	Exchange(ctx context.Context, code, verifier string) (*UserInfo, error)
+}
+
+// Random code verifier for PKCE
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+type provider struct {
+	cfg         *config.OAuthProvider
+	redirectURL string
+	client      *http.Client
+
+	mu          sync.Mutex
+	oauth2Cfg   *oauth2.Config
+	userInfoURL string
+}
+
+// Endpoints of OpenID Connect providers are discovered on the first use, the
+// discovery is retried on the next use if it failed
+func NewProvider(cfg *config.OAuthProvider, redirectURL string, client *http.Client) (Provider, error) {
+	err := cfg.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return &provider{
+		cfg:         cfg,
+		redirectURL: redirectURL,
+		client:      client,
+	}, nil
+}
+
+func (p *provider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *provider) DisplayName() string {
+	if p.cfg.DisplayName != "" {
+		return p.cfg.DisplayName
+	}
+	return p.cfg.Name
+}
+
+func (p *provider) AuthType() string {
+	return p.cfg.AuthType
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+func (p *provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + discoveryPath
+	var doc discoveryDocument
+	err := p.getJSON(ctx, discoveryURL, "", &doc)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: discover %s: %w", p.cfg.Name, err)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("oauth: discover %s: endpoints are missing", p.cfg.Name)
+	}
+
+	return &doc, nil
+}
+
+// OAuth2 config with the endpoints resolved, endpoints in the config take
+// precedence over the discovered ones
+func (p *provider) config(ctx context.Context) (*oauth2.Config, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2Cfg != nil {
+		return p.oauth2Cfg, p.userInfoURL, nil
+	}
+
+	authURL, tokenURL, userInfoURL := p.cfg.AuthURL, p.cfg.TokenURL, p.cfg.UserInfoURL
+	if p.cfg.Type == config.OAuthProviderTypeOIDC && (authURL == "" || tokenURL == "" || userInfoURL == "") {
+		doc, err := p.discover(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if authURL == "" {
+			authURL = doc.AuthorizationEndpoint
+		}
+		if tokenURL == "" {
+			tokenURL = doc.TokenEndpoint
+		}
+		if userInfoURL == "" {
+			userInfoURL = doc.UserInfoEndpoint
+		}
+	}
+
+	p.oauth2Cfg = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+		RedirectURL: p.redirectURL,
+		Scopes:      p.cfg.Scopes,
+	}
+	p.userInfoURL = userInfoURL
+
+	return p.oauth2Cfg, p.userInfoURL, nil
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	oauth2Cfg, _, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if p.cfg.PKCE {
+		opts = append(opts, oauth2.S256ChallengeOption(verifier))
+	}
+
+	return oauth2Cfg.AuthCodeURL(state, opts...), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code, verifier string) (*UserInfo, error) {
+	oauth2Cfg, userInfoURL, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if p.cfg.PKCE {
+		opts = append(opts, oauth2.VerifierOption(verifier))
+	}
+
+	token, err := oauth2Cfg.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: exchange token of %s: %w", p.cfg.Name, err)
+	}
+
+	claims := make(map[string]any)
+	err = p.getJSON(ctx, userInfoURL, token.AccessToken, &claims)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: get user info of %s: %w", p.cfg.Name, err)
+	}
+
+	info := &UserInfo{
+		Subject: claimString(claims, p.cfg.Claims.Subject),
+		Email:   claimString(claims, p.cfg.Claims.Email),
+		Name:    claimString(claims, p.cfg.Claims.Name),
+	}
+	if info.Subject == "" {
+		return nil, ErrNoSubject
+	}
+
+	if info.Email != "" {
+		info.EmailVerified = p.cfg.TrustEmail || claimBool(claims, p.cfg.Claims.EmailVerified)
+	}
+
+	if !info.EmailVerified && p.cfg.EmailsURL != "" {
+		err = p.fillVerifiedEmail(ctx, token.AccessToken, info)
+		if err != nil {
+			return nil, fmt.Errorf("oauth: get emails of %s: %w", p.cfg.Name, err)
+		}
+	}
+
+	return info, nil
+}
+
+type emailItem struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// Use the primary verified email, or the first verified one
+func (p *provider) fillVerifiedEmail(ctx context.Context, accessToken string, info *UserInfo) error {
+	var list []*emailItem
+	err := p.getJSON(ctx, p.cfg.EmailsURL, accessToken, &list)
+	if err != nil {
+		return err
+	}
+
+	var chosen *emailItem
+	for _, item := range list {
+		if !item.Verified || item.Email == "" {
+			continue
+		}
+		if chosen == nil || item.Primary {
+			chosen = item
+		}
+		if item.Primary {
+			break
+		}
+	}
+
+	if chosen != nil {
+		info.Email = chosen.Email
+		info.EmailVerified = true
+	}
+	return nil
+}
+
+func (p *provider) getJSON(ctx context.Context, url, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	dec := json.NewDecoder(io.LimitReader(resp.Body, MaxBodySize))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// Value of the claim in the path such as "address.email", numbers are
+// formatted as they are in the response
+func claimValue(claims map[string]any, path string) any {
+	if path == "" {
+		return nil
+	}
+
+	var val any = claims
+	for _, key := range strings.Split(path, ".") {
+		m, ok := val.(map[string]any)
+		if !ok {
+			return nil
+		}
+		val = m[key]
+	}
+	return val
+}
+
+func claimString(claims map[string]any, path string) string {
+	switch v := claimValue(claims, path).(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// Some providers return booleans as strings
+func claimBool(claims map[string]any, path string) bool {
+	switch v := claimValue(claims, path).(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// Registry of the enabled providers, in the order of the config
+type Registry struct {
+	list   []Provider
+	byName map[string]Provider
+}
+
+// Callback URL of each provider is {serverURL}/auth/{name}/callback
+func NewRegistry(data *config.OAuthData, serverURL string, client *http.Client) (*Registry, error) {
+	reg := &Registry{
+		byName: make(map[string]Provider),
+	}
+
+	for _, item := range data.Providers {
+		p, err := NewProvider(item, CallbackURL(serverURL, item.Name), client)
+		if err != nil {
+			return nil, err
+		}
+		reg.list = append(reg.list, p)
+		reg.byName[item.Name] = p
+	}
+
+	return reg, nil
+}
+
+func CallbackURL(serverURL, name string) string {
+	return fmt.Sprintf("%s/auth/%s/callback", strings.TrimSuffix(serverURL, "/"), name)
+}
+
+func (reg *Registry) Get(name string) (Provider, error) {
+	if reg != nil {
+		if p, ok := reg.byName[name]; ok {
+			return p, nil
+		}
+	}
+	return nil, ErrProviderNotFound
+}
+
+func (reg *Registry) List() []Provider {
+	if reg == nil {
+		return nil
+	}
+	return reg.list
+}
@@ -0,0 +1,295 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/oodzchen/dproject/config"
+	"github.com/oodzchen/dproject/mocktool"
+)
+
+const testServerURL = "http://localhost:3000"
+
+// Visit the consent page of the mock provider, returns the code and state
+// in the callback url
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("want status %d from authorize endpoint, got %d", http.StatusFound, resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := location.Scheme+"://"+location.Host+location.Path, CallbackURL(testServerURL, "mock"); got != want {
+		t.Errorf("want callback %q, got %q", want, got)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newMockProvider(t *testing.T, mock *mocktool.MockOIDC, modify func(cfg *config.OAuthProvider)) Provider {
+	t.Helper()
+
+	cfg := &config.OAuthProvider{
+		Name:         "mock",
+		Type:         config.OAuthProviderTypeOIDC,
+		AuthType:     "oidc",
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+		Issuer:       mock.Issuer(),
+		Scopes:       []string{"openid", "email"},
+		PKCE:         true,
+		Claims: config.OAuthClaims{
+			Subject:       "sub",
+			Email:         "email",
+			EmailVerified: "email_verified",
+			Name:          "preferred_username",
+		},
+	}
+	if modify != nil {
+		modify(cfg)
+	}
+
+	reg, err := NewRegistry(&config.OAuthData{Providers: []*config.OAuthProvider{cfg}}, testServerURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := reg.Get("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestOIDCLogin(t *testing.T) {
+	mock := mocktool.NewMockOIDC("client", "secret", map[string]any{
+		"sub":                "u-1",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+	})
+	defer mock.Close()
+
+	p := newMockProvider(t, mock, nil)
+	ctx := context.Background()
+
+	verifier := NewVerifier()
+	authURL, err := p.AuthCodeURL(ctx, "state-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := authorize(t, authURL)
+	if state != "state-1" {
+		t.Errorf("want state %q, got %q", "state-1", state)
+	}
+
+	info, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := UserInfo{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Name: "alice"}
+	if *info != want {
+		t.Errorf("want user info %#v, got %#v", want, *info)
+	}
+
+	// Codes are used only once
+	_, err = p.Exchange(ctx, code, verifier)
+	if err == nil {
+		t.Error("want error when the code is reused")
+	}
+}
+
+func TestOIDCLoginWrongVerifier(t *testing.T) {
+	mock := mocktool.NewMockOIDC("client", "secret", map[string]any{"sub": "u-1"})
+	defer mock.Close()
+
+	p := newMockProvider(t, mock, nil)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", NewVerifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := authorize(t, authURL)
+	_, err = p.Exchange(ctx, code, NewVerifier())
+	if err == nil {
+		t.Error("want error when the code verifier is not the one of the challenge")
+	}
+}
+
+func TestOAuth2LoginWithEmails(t *testing.T) {
+	mock := mocktool.NewMockOIDC("client", "secret", map[string]any{
+		"id":    12345,
+		"login": "bob",
+		"email": nil,
+	})
+	mock.Emails = []map[string]any{
+		{"email": "old@example.com", "primary": false, "verified": true},
+		{"email": "unverified@example.com", "primary": false, "verified": false},
+		{"email": "bob@example.com", "primary": true, "verified": true},
+	}
+	defer mock.Close()
+
+	// Configured as GitHub, with the endpoints and without discovery
+	p := newMockProvider(t, mock, func(cfg *config.OAuthProvider) {
+		cfg.Type = config.OAuthProviderTypeOAuth2
+		cfg.Issuer = ""
+		cfg.AuthURL = mock.Issuer() + "/authorize"
+		cfg.TokenURL = mock.Issuer() + "/token"
+		cfg.UserInfoURL = mock.Issuer() + "/userinfo"
+		cfg.EmailsURL = mock.Issuer() + "/emails"
+		cfg.PKCE = false
+		cfg.Claims = config.OAuthClaims{Subject: "id", Email: "email", Name: "login"}
+	})
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := authorize(t, authURL)
+	info, err := p.Exchange(ctx, code, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := UserInfo{Subject: "12345", Email: "bob@example.com", EmailVerified: true, Name: "bob"}
+	if *info != want {
+		t.Errorf("want user info %#v, got %#v", want, *info)
+	}
+}
+
+func TestEmailVerified(t *testing.T) {
+	tests := []struct {
+		desc       string
+		claims     map[string]any
+		trustEmail bool
+		want       bool
+	}{
+		{"verified", map[string]any{"sub": "1", "email": "a@example.com", "email_verified": true}, false, true},
+		{"verified in string", map[string]any{"sub": "1", "email": "a@example.com", "email_verified": "true"}, false, true},
+		{"not verified", map[string]any{"sub": "1", "email": "a@example.com", "email_verified": false}, false, false},
+		{"no claim", map[string]any{"sub": "1", "email": "a@example.com"}, false, false},
+		{"trusted", map[string]any{"sub": "1", "email": "a@example.com"}, true, true},
+		{"trusted without email", map[string]any{"sub": "1"}, true, false},
+	}
+
+	for _, tt := range tests {
+		mock := mocktool.NewMockOIDC("client", "secret", tt.claims)
+		p := newMockProvider(t, mock, func(cfg *config.OAuthProvider) {
+			cfg.TrustEmail = tt.trustEmail
+		})
+
+		verifier := NewVerifier()
+		authURL, err := p.AuthCodeURL(context.Background(), "state", verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, _ := authorize(t, authURL)
+
+		info, err := p.Exchange(context.Background(), code, verifier)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.desc, err)
+		}
+		if info.EmailVerified != tt.want {
+			t.Errorf("%s: want email verified %t, got %t", tt.desc, tt.want, info.EmailVerified)
+		}
+		mock.Close()
+	}
+}
+
+func TestNoSubject(t *testing.T) {
+	mock := mocktool.NewMockOIDC("client", "secret", map[string]any{"email": "a@example.com"})
+	defer mock.Close()
+
+	p := newMockProvider(t, mock, nil)
+	verifier := NewVerifier()
+	authURL, err := p.AuthCodeURL(context.Background(), "state", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := authorize(t, authURL)
+
+	_, err = p.Exchange(context.Background(), code, verifier)
+	if !errors.Is(err, ErrNoSubject) {
+		t.Errorf("want error %v, got %v", ErrNoSubject, err)
+	}
+}
+
+func TestDiscoveryFailed(t *testing.T) {
+	mock := mocktool.NewMockOIDC("client", "secret", nil)
+	p := newMockProvider(t, mock, nil)
+	mock.Close()
+
+	_, err := p.AuthCodeURL(context.Background(), "state", NewVerifier())
+	if err == nil {
+		t.Error("want error when the issuer is unreachable")
+	}
+}
+
+func TestClaimValue(t *testing.T) {
+	claims := map[string]any{
+		"sub": "abc",
+		"address": map[string]any{
+			"email": "a@example.com",
+		},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"sub", "abc"},
+		{"address.email", "a@example.com"},
+		{"address.missing", ""},
+		{"sub.email", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := claimString(claims, tt.path); got != tt.want {
+			t.Errorf("path %q, want %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg, err := NewRegistry(&config.OAuthData{}, testServerURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reg.Get("google")
+	if !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("want error %v, got %v", ErrProviderNotFound, err)
+	}
+
+	var nilReg *Registry
+	if len(nilReg.List()) != 0 {
+		t.Error("want no provider in nil registry")
+	}
+}
@@ -107,7 +107,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	dataStore := store.New(pg.Article, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, pg.Category)
+	dataStore := store.New(pg.Article, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, pg.Category, pg.ApiToken, pg.Tag, pg.Report, pg.Attachment, pg.LinkPreview, pg.Poll, pg.Draft)
 
 	var wg sync.WaitGroup
 	policy := bluemonday.UGCPolicy()
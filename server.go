@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"text/template"
 	"time"
 
@@ -14,13 +17,15 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httprate"
 	"github.com/gorilla/csrf"
-	"github.com/gorilla/sessions"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/oodzchen/dproject/config"
 	i18nc "github.com/oodzchen/dproject/i18n"
+	"github.com/oodzchen/dproject/linkpreview"
 	mdw "github.com/oodzchen/dproject/middleware"
 	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/oauth"
 	"github.com/oodzchen/dproject/service"
+	"github.com/oodzchen/dproject/storage"
 	"github.com/oodzchen/dproject/store"
 	"github.com/oodzchen/dproject/utils"
 	"github.com/oodzchen/dproject/web"
@@ -29,16 +34,25 @@ import (
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
+// Header of CSRF token, which is checked before the form body
+const csrfHeader = "X-CSRF-Token"
+
 type ServiceConfig struct {
-	sessSecret     string
-	csrfSecret     string
-	store          *store.Store
-	permisisonSrv  *service.Permission
-	sanitizePolicy *bluemonday.Policy
-	i18nCustom     *i18nc.I18nCustom
-	rdb            *redis.Client
-	mail           *service.Mail
-	geoDB          *geoip2.Reader
+	sessSecret       string
+	csrfSecret       string
+	store            *store.Store
+	permisisonSrv    *service.Permission
+	sanitizePolicy   *bluemonday.Policy
+	i18nCustom       *i18nc.I18nCustom
+	rdb              *redis.Client
+	mail             *service.Mail
+	geoDB            *geoip2.Reader
+	storage          storage.Storage
+	draftExpire      time.Duration
+	oauth            *oauth.Registry
+	accountDeletion  time.Duration
+	usernameCooldown time.Duration
+	usernameRedirect time.Duration
 }
 
 // func FileServer(r chi.Router, path string, root http.FileSystem) {
@@ -69,8 +83,27 @@ func Service(c *ServiceConfig) http.Handler {
 		"permit": func(module, action string) bool {
 			return c.permisisonSrv.Permit(nil, module, action)
 		},
+		"permitCategory": func(categoryFrontId, module, action string) bool {
+			return c.permisisonSrv.PermitCategory(nil, categoryFrontId, module, action)
+		},
 		"local":   c.i18nCustom.LocalTpl,
 		"timeAgo": c.i18nCustom.TimeAgo.Format,
+		"displayTitle": func(article *model.Article) string {
+			return article.DisplayTitle(c.i18nCustom)
+		},
+		"markdown": func(content string) string {
+			return utils.RenderMarkdown(content, c.sanitizePolicy)
+		},
+		"markdownMentions": func(content string, usernames []string) string {
+			return utils.RenderMarkdownMentions(content, c.sanitizePolicy, func(text string) [][]int {
+				return model.FindMentions(text, usernames)
+			})
+		},
+		"replaceLinkMentions": func(content string, usernames []string) string {
+			return c.sanitizePolicy.Sanitize(utils.ReplaceLinkMentions(content, func(text string) [][]int {
+				return model.FindMentions(text, usernames)
+			}))
+		},
 	}
 
 	baseTmpl := template.New("base").Funcs(TmplFuncs).Funcs(tmplFuncs).Funcs(sprig.FuncMap())
@@ -81,15 +114,18 @@ func Service(c *ServiceConfig) http.Handler {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.AllowContentEncoding("default", "gzip"))
-	r.Use(middleware.AllowContentType("application/x-www-form-urlencoded"))
-	r.Use(middleware.Compress(5, "text/html", "text/css", "text/plain", "text/javascript"))
+	r.Use(middleware.AllowContentType("application/x-www-form-urlencoded", "multipart/form-data", "application/json"))
+	r.Use(middleware.Compress(5, "text/html", "text/css", "text/plain", "text/javascript", "application/json"))
 	r.Use(middleware.GetHead)
 	// r.Use(middleware.RedirectSlashes)
 	r.Use(mdw.CreateGeoDetect(c.geoDB))
 
 	gob.Register(model.Lang(""))
+	gob.Register(model.SessionDevice{})
+	gob.Register(model.TwoFactorLogin{})
+	gob.Register(model.IdentityLink{})
 
-	sessStore := sessions.NewCookieStore([]byte(c.sessSecret))
+	sessStore := service.NewSessionStore(c.rdb, []byte(c.sessSecret))
 	sessStore.Options.HttpOnly = true
 	sessStore.Options.Secure = !utils.IsDebug()
 	sessStore.Options.SameSite = http.SameSiteLaxMode
@@ -102,14 +138,21 @@ func Service(c *ServiceConfig) http.Handler {
 		Rdb:      c.rdb,
 		LifeTime: service.DefaultSettingsLifeTime,
 	}
+	linkPreviewSrv := &service.LinkPreview{
+		Store:   c.store,
+		Fetcher: linkpreview.NewFetcher(),
+	}
 	srv := &service.Service{
 		Article: &service.Article{
 			Store:         c.store,
 			SantizePolicy: c.sanitizePolicy,
+			LinkPreview:   linkPreviewSrv,
 		},
 		User: &service.User{
-			Store:         c.store,
-			SantizePolicy: c.sanitizePolicy,
+			Store:            c.store,
+			SantizePolicy:    c.sanitizePolicy,
+			UsernameCooldown: c.usernameCooldown,
+			UsernameRedirect: c.usernameRedirect,
 		},
 		Permission: c.permisisonSrv,
 		UserLogger: userLogger,
@@ -119,8 +162,35 @@ func Service(c *ServiceConfig) http.Handler {
 		},
 		Mail:            c.mail,
 		SettingsManager: settingsManager,
+		ApiToken: &service.ApiToken{
+			Store: c.store,
+		},
+		Attachment: &service.Attachment{
+			Store:    c.store,
+			Storage:  c.storage,
+			RoleData: c.permisisonSrv.RoleData,
+		},
+		LinkPreview: linkPreviewSrv,
+		Draft: &service.Draft{
+			Store:         c.store,
+			SantizePolicy: c.sanitizePolicy,
+			Expire:        c.draftExpire,
+		},
+		TwoFactor: &service.TwoFactor{
+			Store:  c.store,
+			Rdb:    c.rdb,
+			Issuer: config.Config.BrandName,
+		},
+		OAuth: c.oauth,
+		Account: &service.Account{
+			Store:       c.store,
+			SessStore:   sessStore,
+			GracePeriod: c.accountDeletion,
+		},
 	}
 
+	go srv.Account.RunPurge(context.Background(), service.DefaultPurgeInterval)
+
 	dmp := diffmatchpatch.New()
 
 	renderer := web.NewRenderer(
@@ -136,6 +206,7 @@ func Service(c *ServiceConfig) http.Handler {
 	)
 
 	r.Use(mdw.FetchUserData(c.store, sessStore, c.permisisonSrv, renderer))
+	r.Use(mdw.Require2FA(c.permisisonSrv))
 	r.Use(mdw.CreateUISettingsMiddleware(sessStore, settingsManager, c.i18nCustom))
 
 	articleResource := web.NewArticleResource(renderer)
@@ -143,6 +214,11 @@ func Service(c *ServiceConfig) http.Handler {
 	mainResource := web.NewMainResource(renderer, articleResource)
 	manageResource := web.NewManageResource(renderer, userResource)
 	rssResource := web.NewRSSResource(renderer, articleResource)
+	searchResource := web.NewSearchResource(renderer)
+	tagResource := web.NewTagResource(renderer, articleResource)
+	apiResource := web.NewApiResource(renderer, articleResource)
+	attachmentResource := web.NewAttachmentResource(renderer)
+	draftResource := web.NewDraftResource(renderer)
 
 	rateLimit := 100
 	if utils.IsDebug() {
@@ -190,6 +266,11 @@ func Service(c *ServiceConfig) http.Handler {
 	r.Mount("/users", userResource.Routes())
 	r.Mount("/manage", manageResource.Routes())
 	r.Mount("/feed", rssResource.Routes())
+	r.Mount("/search", searchResource.Routes())
+	r.Mount("/tags", tagResource.Routes())
+	r.Mount("/api/v1", apiResource.Routes())
+	r.Mount("/attachments", attachmentResource.Routes())
+	r.Mount("/drafts", draftResource.Routes())
 
 	// chi.Walk(r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
 	// 	////
@@ -199,11 +280,35 @@ func Service(c *ServiceConfig) http.Handler {
 
 	CSRF := csrf.Protect([]byte(c.csrfSecret),
 		csrf.FieldName("tk"),
+		csrf.RequestHeader(csrfHeader),
 		csrf.CookieName("sc"),
 		csrf.HttpOnly(true),
 		csrf.Secure(!utils.IsDebug()),
 		csrf.Path("/"),
 		// csrf.ErrorHandler(r),
 	)
-	return CSRF(r)
+	csrfHandler := CSRF(r)
+
+	// State changing API requests must have JSON content type unless they are
+	// authenticated by API token, which can't be sent by cross-site forms, so
+	// skip the CSRF token check for them
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/api/") {
+			req = csrf.UnsafeSkipCheck(req)
+		}
+
+		// Token of multipart forms is sent in the query, otherwise the body
+		// would be parsed without limit by the CSRF check, before the
+		// permission is checked. The body is parsed by the handler of the route.
+		if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data") && req.Header.Get(csrfHeader) == "" {
+			token := req.URL.Query().Get("tk")
+			if token == "" {
+				articleResource.Forbidden(errors.New("csrf token is required in query"), w, req)
+				return
+			}
+			req.Header.Set(csrfHeader, token)
+		}
+
+		csrfHandler.ServeHTTP(w, req)
+	})
 }
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+const DefaultDeletionGracePeriod = 14 * 24 * time.Hour
+
+// Interval of checking the accounts to be deleted
+const DefaultPurgeInterval = time.Hour
+
+// Account handles personal data export and account deletion, deletion
+// requested can be canceled in the grace period, and the account is deleted
+// by the purge job after that
+type Account struct {
+	Store     *store.Store
+	SessStore *SessionStore
+	// Accounts are deleted after the period since deletion requested
+	GracePeriod time.Duration
+}
+
+func (a *Account) gracePeriod() time.Duration {
+	if a.GracePeriod <= 0 {
+		return DefaultDeletionGracePeriod
+	}
+	return a.GracePeriod
+}
+
+func (a *Account) GracePeriodDays() int {
+	return int(a.gracePeriod() / (24 * time.Hour))
+}
+
+// Time when the account requested deletion at will be deleted
+func (a *Account) DeletionTime(requestedAt time.Time) time.Time {
+	return requestedAt.Add(a.gracePeriod())
+}
+
+func (a *Account) RequestDeletion(userId int) error {
+	return a.Store.User.RequestDeletion(userId)
+}
+
+func (a *Account) CancelDeletion(userId int) error {
+	return a.Store.User.CancelDeletion(userId)
+}
+
+// Delete the accounts whose grace period is over, and log out all of their
+// sessions. Failing to delete one account doesn't stop the others, the errors
+// are joined.
+func (a *Account) PurgeDeleted() (int, error) {
+	ids, err := a.Store.User.ListDeletionDue(time.Now().Add(-a.gracePeriod()))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	var errs []error
+	for _, id := range ids {
+		err := a.Store.User.DeleteHard(id)
+		if err != nil {
+			fmt.Printf("delete account %d error: %v\n", id, err)
+			errs = append(errs, fmt.Errorf("delete account %d: %w", id, err))
+			continue
+		}
+		count++
+
+		if a.SessStore != nil {
+			err = a.SessStore.RevokeAll(id, "")
+			if err != nil {
+				fmt.Println("revoke sessions of deleted user error:", err)
+			}
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
+
+// Run PurgeDeleted periodically until the context is done
+func (a *Account) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := a.PurgeDeleted()
+			if err != nil {
+				fmt.Println("purge deleted accounts error:", err)
+			}
+			if count > 0 {
+				fmt.Printf("purged %d deleted accounts\n", count)
+			}
+		}
+	}
+}
+
+// Write the personal data of the user as a zip archive
+func (a *Account) Export(userId int, w io.Writer) error {
+	data, err := a.Store.User.Export(userId)
+	if err != nil {
+		return err
+	}
+
+	return writeExportArchive(data, w)
+}
+
+func writeExportArchive(data *model.UserExport, w io.Writer) error {
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", data.Profile},
+		{"articles.json", nonNilSlice(data.Articles)},
+		{"replies.json", nonNilSlice(data.Replies)},
+		{"votes.json", nonNilSlice(data.Votes)},
+		{"saves.json", nonNilSlice(data.Saves)},
+		{"reactions.json", nonNilSlice(data.Reactions)},
+		{"messages.json", nonNilSlice(data.Messages)},
+		{"activities.json", nonNilSlice(data.Activities)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		_, err = fw.Write(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Empty list is encoded as [] rather than null
+func nonNilSlice[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+func TestWriteExportArchive(t *testing.T) {
+	data := &model.UserExport{
+		Profile: &model.UserExportProfile{
+			Id:       2,
+			Username: "alice",
+			Email:    "alice@example.com",
+		},
+		Articles: []*model.UserExportArticle{
+			{Id: 10, Title: "Hello", Content: "world", CategoryFrontId: "general"},
+		},
+		Replies: []*model.UserExportArticle{
+			{Id: 11, Content: "reply", ReplyToId: 10, RootArticleId: 10},
+		},
+		Votes: []*model.UserExportVote{
+			{ArticleId: 12, Type: model.VoteTypeUp},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := writeExportArchive(data, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = content
+	}
+
+	tests := []struct {
+		name  string
+		count int
+	}{
+		{"articles.json", 1},
+		{"replies.json", 1},
+		{"votes.json", 1},
+		{"saves.json", 0},
+		{"reactions.json", 0},
+		{"messages.json", 0},
+		{"activities.json", 0},
+	}
+
+	for _, tt := range tests {
+		content, ok := files[tt.name]
+		if !ok {
+			t.Errorf("%s not found in archive", tt.name)
+			continue
+		}
+
+		var list []map[string]any
+		err := json.Unmarshal(content, &list)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if list == nil || len(list) != tt.count {
+			t.Errorf("%s: want %d items, got %s", tt.name, tt.count, content)
+		}
+	}
+
+	var profile map[string]any
+	err = json.Unmarshal(files["profile.json"], &profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile["username"] != "alice" || profile["email"] != "alice@example.com" {
+		t.Errorf("unexpected profile %s", files["profile.json"])
+	}
+}
+
+func TestAccountDeletionTime(t *testing.T) {
+	requestedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc        string
+		gracePeriod time.Duration
+		want        time.Time
+	}{
+		{"default", 0, requestedAt.Add(DefaultDeletionGracePeriod)},
+		{"configured", 24 * time.Hour, requestedAt.Add(24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		a := &Account{GracePeriod: tt.gracePeriod}
+		if got := a.DeletionTime(requestedAt); !got.Equal(tt.want) {
+			t.Errorf("%s: want %v, got %v", tt.desc, tt.want, got)
+		}
+	}
+}
+
+type purgeUserStore struct {
+	store.UserStore
+	dueIds    []int
+	failIds   map[int]bool
+	deleteIds []int
+}
+
+func (s *purgeUserStore) ListDeletionDue(requestedBefore time.Time) ([]int, error) {
+	return s.dueIds, nil
+}
+
+func (s *purgeUserStore) DeleteHard(id int) error {
+	if s.failIds[id] {
+		return errors.New("delete failed")
+	}
+	s.deleteIds = append(s.deleteIds, id)
+	return nil
+}
+
+func TestPurgeDeletedContinuesOnError(t *testing.T) {
+	userStore := &purgeUserStore{
+		dueIds:  []int{1, 2, 3, 4},
+		failIds: map[int]bool{2: true, 3: true},
+	}
+	a := &Account{Store: &store.Store{User: userStore}}
+
+	count, err := a.PurgeDeleted()
+	if count != 2 {
+		t.Errorf("want 2 accounts deleted, got %d", count)
+	}
+	if !slices.Equal(userStore.deleteIds, []int{1, 4}) {
+		t.Errorf("want accounts 1 and 4 deleted, got %v", userStore.deleteIds)
+	}
+	if err == nil {
+		t.Fatal("want error of the failed accounts")
+	}
+	for _, str := range []string{"account 2", "account 3"} {
+		if !strings.Contains(err.Error(), str) {
+			t.Errorf("want %q in error %q", str, err.Error())
+		}
+	}
+}
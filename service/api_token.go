@@ -0,0 +1,106 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+const apiTokenByteLen = 32
+const apiTokenDisplayLen = 8
+
+var ErrApiTokenInvalid = errors.New("api token is invalid")
+
+type ApiToken struct {
+	Store *store.Store
+}
+
+func genApiToken() (string, error) {
+	b := make([]byte, apiTokenByteLen)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return model.ApiTokenPrefix + hex.EncodeToString(b), nil
+}
+
+func hashApiToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create token for user, the raw token is only returned here,
+// only the hash of it will be stored
+func (at *ApiToken) Create(userId int, name string, permissions []string) (string, error) {
+	apiToken := &model.ApiToken{
+		UserId:      userId,
+		Name:        name,
+		Permissions: permissions,
+	}
+
+	apiToken.TrimSpace()
+	apiToken.Sanitize()
+
+	err := apiToken.Valid()
+	if err != nil {
+		return "", err
+	}
+
+	count, err := at.Store.ApiToken.Count(userId)
+	if err != nil {
+		return "", err
+	}
+
+	err = apiToken.ValidCount(count)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := genApiToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = at.Store.ApiToken.Create(
+		userId,
+		apiToken.Name,
+		hashApiToken(token),
+		token[:len(model.ApiTokenPrefix)+apiTokenDisplayLen],
+		apiToken.Permissions,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (at *ApiToken) Verify(token string) (*model.ApiToken, error) {
+	if !strings.HasPrefix(token, model.ApiTokenPrefix) || len(token) != len(model.ApiTokenPrefix)+apiTokenByteLen*2 {
+		return nil, ErrApiTokenInvalid
+	}
+
+	apiToken, err := at.Store.ApiToken.ItemWithHash(hashApiToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrApiTokenInvalid
+		}
+		return nil, err
+	}
+
+	go func() {
+		err := at.Store.ApiToken.UpdateLastUsed(apiToken.Id)
+		if err != nil {
+			fmt.Println("update api token last used time error:", err)
+		}
+	}()
+
+	return apiToken, nil
+}
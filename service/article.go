@@ -1,22 +1,27 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/oodzchen/dproject/model"
 	"github.com/oodzchen/dproject/store"
+	"github.com/oodzchen/dproject/utils"
 )
 
 type Article struct {
 	Store         *store.Store
 	SantizePolicy *bluemonday.Policy
 	WG            *sync.WaitGroup
+	LinkPreview   *LinkPreview
 }
 
-func (a *Article) Create(title, url, content string, authorId, replyToId int, categoryFrontId string, pinnedExpireAt time.Time, locked bool) (int, error) {
+func (a *Article) Create(title, url, content string, authorId, replyToId int, categoryFrontId string, tagFrontIds []string, pinnedExpireAt time.Time, locked bool) (int, error) {
 	article := &model.Article{
 		Title:           title,
 		AuthorId:        authorId,
@@ -24,6 +29,7 @@ func (a *Article) Create(title, url, content string, authorId, replyToId int, ca
 		Content:         content,
 		ReplyToId:       replyToId,
 		CategoryFrontId: categoryFrontId,
+		TagFrontIds:     model.CleanTagFrontIds(tagFrontIds),
 	}
 
 	article.TrimSpace()
@@ -36,16 +42,45 @@ func (a *Article) Create(title, url, content string, authorId, replyToId int, ca
 		return 0, err
 	}
 
+	if replyToId == 0 {
+		err = a.CheckCategory(article.CategoryFrontId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = a.validTags(article)
+	if err != nil {
+		return 0, err
+	}
+
 	id, err := a.Store.Article.Create(article.Title, article.Link, article.Content, article.AuthorId, article.ReplyToId, article.CategoryFrontId, pinnedExpireAt, locked)
 	if err != nil {
 		return 0, err
 	}
 
+	if len(article.TagFrontIds) > 0 {
+		err = a.Store.Article.Tag(id, article.TagFrontIds)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = a.SaveNormalizedLink(id, article.Link)
+	if err != nil {
+		return 0, err
+	}
+
 	err = a.Store.Article.ToggleSubscribe(id, authorId)
 	if err != nil {
 		return 0, err
 	}
 
+	mentionedUserIds, err := a.saveMentions(id, authorId, article.Content)
+	if err != nil {
+		return 0, err
+	}
+
 	go func() {
 		if a.WG != nil {
 			defer a.WG.Done()
@@ -53,17 +88,62 @@ func (a *Article) Create(title, url, content string, authorId, replyToId int, ca
 
 		// fmt.Println("store:", a.Store, a.Store.Category)
 		// fmt.Println("args:", categoryFrontId, authorId, id)
-		err = a.Store.Category.Notify(categoryFrontId, authorId, id)
+		err := a.Store.Category.Notify(categoryFrontId, authorId, id)
 		if err != nil {
 			// ar.ServerErrorp("", err, w, r)
 			fmt.Println("notify to category subscribers error: ", err)
-			return
 		}
+
+		// Notified after subscribers, so that they don't get mention messages again
+		err = a.Store.Article.NotifyMention(authorId, id, mentionedUserIds)
+		if err != nil {
+			fmt.Println("notify to mentioned users error: ", err)
+		}
+
+		a.RefreshLinkPreview(article.Link)
 	}()
 
 	return id, nil
 }
 
+// SaveNormalizedLink saves the normalized article link which is sanitized, so
+// that later submissions of the same link can be found
+func (a *Article) SaveNormalizedLink(id int, link string) error {
+	return a.Store.Article.UpdateNormalizedURL(id, utils.NormalizeURL(html.UnescapeString(link)))
+}
+
+// DuplicateLink returns the earliest article with the same normalized link,
+// or nil if there is none
+func (a *Article) DuplicateLink(link string, excludeId int) (*model.Article, error) {
+	normalizedURL := utils.NormalizeURL(html.UnescapeString(link))
+	if normalizedURL == "" {
+		return nil, nil
+	}
+
+	id, err := a.Store.Article.DuplicateLink(normalizedURL, excludeId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return a.Store.Article.Item(id, 0)
+}
+
+// RefreshLinkPreview fetches preview of the article link if it's not fetched
+// or expired, errors are only logged
+func (a *Article) RefreshLinkPreview(link string) {
+	if link == "" || a.LinkPreview == nil {
+		return
+	}
+
+	_, err := a.LinkPreview.Fetch(link)
+	if err != nil {
+		fmt.Println("fetch link preview error: ", err)
+	}
+}
+
 func (a *Article) Reply(target int, content string, authorId int, pinnedExpireAt time.Time, locked bool) (int, error) {
 	article := &model.Article{
 		AuthorId:  authorId,
@@ -98,18 +178,102 @@ func (a *Article) Reply(target int, content string, authorId int, pinnedExpireAt
 		}
 	}
 
+	mentionedUserIds, err := a.saveMentions(id, authorId, article.Content)
+	if err != nil {
+		return 0, err
+	}
+
 	go func() {
 		if a.WG != nil {
 			defer a.WG.Done()
 		}
 
-		err = a.Store.Article.Notify(authorId, target, id)
+		err := a.Store.Article.Notify(authorId, target, id)
 		if err != nil {
 			// ar.ServerErrorp("", err, w, r)
 			fmt.Println("notify to article subscribers error: ", err)
-			return
+		}
+
+		// Notified after subscribers, so that they don't get mention messages again
+		err = a.Store.Article.NotifyMention(authorId, id, mentionedUserIds)
+		if err != nil {
+			fmt.Println("notify to mentioned users error: ", err)
 		}
 	}()
 
 	return id, nil
 }
+
+// Tag replaces tags of the article after checking them
+func (a *Article) Tag(id int, tagFrontIds []string) error {
+	article := &model.Article{
+		Id:          id,
+		TagFrontIds: model.CleanTagFrontIds(tagFrontIds),
+	}
+
+	err := a.validTags(article)
+	if err != nil {
+		return err
+	}
+
+	return a.Store.Article.Tag(id, article.TagFrontIds)
+}
+
+// Mention updates the users mentioned in the edited content of the article,
+// only the newly mentioned ones are notified
+func (a *Article) Mention(id, authorId int, content string) error {
+	userIds, err := a.saveMentions(id, authorId, content)
+	if err != nil {
+		return err
+	}
+
+	return a.Store.Article.NotifyMention(authorId, id, userIds)
+}
+
+// saveMentions saves the existing users mentioned in the content, returns ids
+// of the newly mentioned ones
+func (a *Article) saveMentions(id, authorId int, content string) ([]int, error) {
+	var userIds []int
+	for _, username := range model.ParseMentions(content) {
+		user, err := a.Store.User.ItemWithUsername(username)
+		if err != nil {
+			if errors.Is(err, model.AppErrUserNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		if user.Id != authorId {
+			userIds = append(userIds, user.Id)
+		}
+	}
+
+	return a.Store.Article.Mention(id, userIds)
+}
+
+// CheckCategory makes sure that articles are only posted to approved categories
+func (a *Article) CheckCategory(categoryFrontId string) error {
+	article := &model.Article{
+		CategoryFrontId: categoryFrontId,
+	}
+
+	category, err := a.Store.Category.Item(categoryFrontId, 0)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	return article.ValidCategory(category)
+}
+
+func (a *Article) validTags(article *model.Article) error {
+	if len(article.TagFrontIds) == 0 {
+		return nil
+	}
+
+	tags, err := a.Store.Tag.List()
+	if err != nil {
+		return err
+	}
+
+	return article.ValidTags(tags)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oodzchen/dproject/config"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/storage"
+	"github.com/oodzchen/dproject/store"
+	"github.com/oodzchen/dproject/utils"
+)
+
+type Attachment struct {
+	Store    *store.Store
+	Storage  storage.Storage
+	RoleData *config.RoleData
+}
+
+// Prepare reads and validates the uploaded files, images are cleaned and
+// thumbnailed, existCount is the count of attachments the article already has
+func (a *Attachment) Prepare(user *model.User, files []*multipart.FileHeader, existCount int) ([]*model.Attachment, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	err := model.ValidAttachmentCount(existCount + len(files))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*model.Attachment
+	var totalSize int64
+	for _, fh := range files {
+		item, err := a.prepareFile(fh)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+		totalSize += item.Size
+	}
+
+	if !user.Super {
+		usedSize, err := a.Store.Attachment.UsedSize(user.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if usedSize+totalSize > a.RoleData.UploadQuota(user.RoleFrontId) {
+			return nil, model.AppErrAttachmentQuotaExceeded
+		}
+	}
+
+	return list, nil
+}
+
+func (a *Attachment) prepareFile(fh *multipart.FileHeader) (*model.Attachment, error) {
+	item := &model.Attachment{
+		FileName: fh.Filename,
+		Size:     fh.Size,
+	}
+	item.TrimSpace()
+
+	// Large files are rejected before reading, the validation always fails
+	// because of the size
+	if item.Size > model.MaxAttachmentSize {
+		return nil, item.Valid()
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, model.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	item.Size = int64(len(data))
+	item.MimeType = model.DetectMimeType(data)
+
+	err = item.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	item.Data = data
+	if item.IsImage() {
+		cleaned, img, err := utils.CleanImage(data, item.MimeType)
+		if err != nil {
+			return nil, errors.Join(model.AppErrAttachmentValidFailed, fmt.Errorf(", %s: %w", item.FileName, err))
+		}
+
+		thumb, err := utils.Thumbnail(img, model.AttachmentThumbSize, item.MimeType)
+		if err != nil {
+			return nil, err
+		}
+
+		bounds := img.Bounds()
+		item.Data = cleaned
+		item.Thumb = thumb
+		item.Size = int64(len(cleaned))
+		item.Width = bounds.Dx()
+		item.Height = bounds.Dy()
+	}
+
+	item.Sanitize()
+
+	return item, nil
+}
+
+// Save puts the prepared files to the storage and creates the records
+func (a *Attachment) Save(articleId, userId int, list []*model.Attachment) error {
+	ctx := context.Background()
+	dir := path.Join("attachments", time.Now().Format("2006/01"))
+
+	for _, item := range list {
+		name := uuid.NewString()
+		item.ArticleId = articleId
+		item.UserId = userId
+		item.StorageKey = path.Join(dir, name+model.AttachmentMimeTypes[item.MimeType])
+
+		err := a.Storage.Put(ctx, item.StorageKey, item.Data, item.MimeType)
+		if err != nil {
+			return err
+		}
+
+		if item.Thumb != nil {
+			// Thumbnails of jpeg images are jpeg, others are png
+			thumbType := "image/png"
+			if item.MimeType == "image/jpeg" {
+				thumbType = item.MimeType
+			}
+			item.ThumbKey = path.Join(dir, name+"_thumb"+model.AttachmentMimeTypes[thumbType])
+
+			err = a.Storage.Put(ctx, item.ThumbKey, item.Thumb, thumbType)
+			if err != nil {
+				return err
+			}
+		}
+
+		id, err := a.Store.Attachment.Create(item)
+		if err != nil {
+			return err
+		}
+		item.Id = id
+	}
+
+	return nil
+}
+
+// Delete removes attachments of the article, files in the storage are
+// removed after the records are deleted
+func (a *Attachment) Delete(articleId int, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	list, err := a.Store.Attachment.Delete(articleId, ids)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, item := range list {
+		err := a.Storage.Delete(ctx, item.StorageKey)
+		if err != nil {
+			return err
+		}
+
+		if item.ThumbKey != "" {
+			err = a.Storage.Delete(ctx, item.ThumbKey)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
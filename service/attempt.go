@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+func genAttemptsKey(name string, id int) string {
+	return fmt.Sprintf("attempts:%s:%d", name, id)
+}
+
+// Count an attempt of the action on the target, ErrTooManyAttempts is returned
+// when it's tried more than max times in the period since the first attempt.
+// The count is increased atomically by redis, so concurrent attempts are
+// counted separately.
+func CountAttempt(rdb *redis.Client, action string, targetId, max int, period time.Duration) error {
+	ctx := context.Background()
+	key := genAttemptsKey(action, targetId)
+
+	count, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	if count == 1 {
+		err = rdb.Expire(ctx, key, period).Err()
+		if err != nil {
+			rdb.Del(ctx, key)
+			return err
+		}
+	}
+
+	if count > int64(max) {
+		return ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+// Clear the attempts of the action on the target, after it's succeeded
+func ResetAttempts(rdb *redis.Client, action string, targetId int) error {
+	return rdb.Del(context.Background(), genAttemptsKey(action, targetId)).Err()
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+const DefaultDraftExpire = 30 * 24 * time.Hour
+
+type Draft struct {
+	Store         *store.Store
+	SantizePolicy *bluemonday.Policy
+	// Drafts not updated in the period are expired
+	Expire time.Duration
+}
+
+func (d *Draft) expiredBefore() time.Time {
+	expire := d.Expire
+	if expire <= 0 {
+		expire = DefaultDraftExpire
+	}
+	return time.Now().Add(-expire)
+}
+
+// Save the draft of the target, the draft is removed if it's empty
+func (d *Draft) Save(item *model.Draft) error {
+	item.TrimSpace()
+
+	err := item.Valid()
+	if err != nil {
+		return err
+	}
+
+	if item.IsEmpty() {
+		return d.Delete(item.UserId, item.TargetType, item.TargetId)
+	}
+
+	item.Sanitize(d.SantizePolicy)
+
+	_, err = d.Store.Draft.Save(item, d.expiredBefore())
+	return err
+}
+
+// Item of the unexpired draft, nil if there is none
+func (d *Draft) Item(userId int, targetType model.DraftTargetType, targetId int) (*model.Draft, error) {
+	item, err := d.Store.Draft.Item(userId, targetType, targetId, d.expiredBefore())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (d *Draft) List(userId int) ([]*model.Draft, error) {
+	return d.Store.Draft.List(userId, d.expiredBefore())
+}
+
+func (d *Draft) Delete(userId int, targetType model.DraftTargetType, targetId int) error {
+	return d.Store.Draft.Delete(userId, targetType, targetId)
+}
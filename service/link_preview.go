@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oodzchen/dproject/linkpreview"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+// Time limit of fetching a page and its oEmbed data
+const linkPreviewFetchTimeout = 2 * linkpreview.DefaultTimeout
+
+type LinkPreview struct {
+	Store   *store.Store
+	Fetcher *linkpreview.Fetcher
+}
+
+// Fetch preview of the article link, which is sanitized as stored in
+// articles, the saved preview is used until it's expired
+func (lp *LinkPreview) Fetch(link string) (*model.LinkPreview, error) {
+	item, err := lp.Store.LinkPreview.Item(link)
+	if err == nil && !item.Expired() {
+		return item, nil
+	}
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewFetchTimeout)
+	defer cancel()
+
+	preview, err := lp.Fetcher.Fetch(ctx, html.UnescapeString(link))
+	if err != nil {
+		return nil, err
+	}
+
+	item = &model.LinkPreview{
+		URL:         link,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+		FetchedAt:   time.Now(),
+	}
+	item.Sanitize()
+
+	id, err := lp.Store.LinkPreview.Save(item)
+	if err != nil {
+		return nil, err
+	}
+	item.Id = id
+
+	return item, nil
+}
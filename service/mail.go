@@ -54,11 +54,13 @@ type VerifCodeType string
 const (
 	VerifCodeRegister      VerifCodeType = "register"
 	VerifCodeResetPassword               = "reset_password"
+	VerifCodeLinkIdentity                = "link_identity"
 )
 
 var VerifCodeTypeMap = map[VerifCodeType]bool{
 	VerifCodeRegister:      true,
 	VerifCodeResetPassword: true,
+	VerifCodeLinkIdentity:  true,
 }
 
 func NewMail(userEmail, password, smtpServer, smtpServerPort, senderAddress string, i18nCustom *i18nc.I18nCustom) *Mail {
@@ -67,7 +69,9 @@ func NewMail(userEmail, password, smtpServer, smtpServerPort, senderAddress stri
 	return &Mail{userEmail, senderAddress, auth, smtpServer, smtpServerPort, i18nCustom}
 }
 
-func (m *Mail) SendVerificationCode(email, code string, codeType VerifCodeType) error {
+// SendVerificationCode sends the code in the language of the recipient
+func (m *Mail) SendVerificationCode(email, code string, codeType VerifCodeType, lang string) error {
+	ic := m.i18nCustom.WithLang(lang)
 	addr := net.JoinHostPort(m.SMTPServer, m.SMTPServerPort)
 
 	var buf bytes.Buffer
@@ -83,6 +87,9 @@ func (m *Mail) SendVerificationCode(email, code string, codeType VerifCodeType)
 	case VerifCodeResetPassword:
 		mailTitleTplId = "VerificationResetPassMailTitle"
 		verifMailTplId = "VerificationResetPassMailTpl"
+	case VerifCodeLinkIdentity:
+		mailTitleTplId = "VerificationLinkIdentityMailTitle"
+		verifMailTplId = "VerificationLinkIdentityMailTpl"
 	}
 
 	if mailTitleTplId == "" {
@@ -92,7 +99,7 @@ func (m *Mail) SendVerificationCode(email, code string, codeType VerifCodeType)
 	err := headTpl.Execute(writer, &MailHead{
 		To:      email,
 		From:    m.SenderMail,
-		Subject: m.i18nCustom.LocalTpl(mailTitleTplId),
+		Subject: ic.LocalTpl(mailTitleTplId),
 	})
 	if err != nil {
 		return err
@@ -102,7 +109,7 @@ func (m *Mail) SendVerificationCode(email, code string, codeType VerifCodeType)
 		return errors.New(fmt.Sprintf("no mail template associate with the code type: %s", string(codeType)))
 	}
 
-	verificationCodeTpl := m.i18nCustom.LocalTpl(verifMailTplId, "DomainName", "dizkaz.com", "Code", code, "Minutes", 5)
+	verificationCodeTpl := ic.LocalTpl(verifMailTplId, "DomainName", "dizkaz.com", "Code", code, "Minutes", 5)
 
 	bodyTpl := template.Must(template.New("body").Parse(verificationCodeTpl))
 
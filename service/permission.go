@@ -165,6 +165,10 @@ func (pm *Permission) GetEnabledIdList(u *model.User) []string {
 			permittedIdList = append(permittedIdList, item.FrontId)
 		}
 		enabledList = pm.PermissionData.GetEnabledFrontIdList(permittedIdList, u.Super)
+
+		if u.TokenScoped {
+			enabledList = filterTokenPermissions(enabledList, u.TokenPermissions)
+		}
 	} else {
 		enabledList = pm.PermissionData.GetDefaultEnabledFrontIdList()
 	}
@@ -172,6 +176,22 @@ func (pm *Permission) GetEnabledIdList(u *model.User) []string {
 	return enabledList
 }
 
+func filterTokenPermissions(enabledList, tokenPermissions []string) []string {
+	tokenPermissionMap := make(map[string]bool)
+	for _, id := range tokenPermissions {
+		tokenPermissionMap[id] = true
+	}
+
+	var list []string
+	for _, id := range enabledList {
+		if tokenPermissionMap[id] {
+			list = append(list, id)
+		}
+	}
+
+	return list
+}
+
 func (pm *Permission) Permit(u *model.User, module string, action string) bool {
 	if pm.PermissionData == nil {
 		return false
@@ -187,3 +207,17 @@ func (pm *Permission) Permit(u *model.User, module string, action string) bool {
 
 	return false
 }
+
+// PermitCategory checks the permission of user on articles under the category,
+// moderators of the category are permitted with the scoped permissions
+func (pm *Permission) PermitCategory(u *model.User, categoryFrontId, module, action string) bool {
+	if pm.Permit(u, module, action) {
+		return true
+	}
+
+	if u == nil {
+		return false
+	}
+
+	return u.PermitInCategory(categoryFrontId, fmt.Sprintf("%s.%s", module, action))
+}
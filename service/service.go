@@ -1,5 +1,7 @@
 package service
 
+import "github.com/oodzchen/dproject/oauth"
+
 type Service struct {
 	Article         *Article
 	User            *User
@@ -8,4 +10,11 @@ type Service struct {
 	Verifier        *Verifier
 	Mail            *Mail
 	SettingsManager *SettingsManager
+	ApiToken        *ApiToken
+	Attachment      *Attachment
+	LinkPreview     *LinkPreview
+	Draft           *Draft
+	TwoFactor       *TwoFactor
+	OAuth           *oauth.Registry
+	Account         *Account
 }
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultSessionMaxAge = 86400 * 30
+
+// Device info of sessions is refreshed at most once in the period
+const DefaultSessionActiveInterval = 10 * time.Minute
+
+// Key of the session values holding the device of logined sessions
+const sessKeyDevice = "_device"
+
+const maxUserAgentLen = 255
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore keeps session values in redis and only the session id in
+// cookie, sessions of each user are indexed so that they can be listed and
+// revoked
+type SessionStore struct {
+	Rdb            *redis.Client
+	Codecs         []securecookie.Codec
+	Options        *sessions.Options
+	ActiveInterval time.Duration
+}
+
+func NewSessionStore(rdb *redis.Client, keyPairs ...[]byte) *SessionStore {
+	ss := &SessionStore{
+		Rdb:    rdb,
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:   "/",
+			MaxAge: DefaultSessionMaxAge,
+		},
+		ActiveInterval: DefaultSessionActiveInterval,
+	}
+	ss.MaxAge(DefaultSessionMaxAge)
+	return ss
+}
+
+// Set max age of sessions and cookies
+func (ss *SessionStore) MaxAge(age int) {
+	ss.Options.MaxAge = age
+	for _, codec := range ss.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+func genSessionKey(id string) string {
+	return "session:" + id
+}
+
+func genUserSessionsKey(userId int) string {
+	return fmt.Sprintf("user_sessions:%d", userId)
+}
+
+// Id shown to the user, the real session id is kept away from pages
+func SessionPublicId(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (ss *SessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(ss, name)
+}
+
+// New session of the name, values are loaded if the session in cookie exists,
+// cookies failed to decode are treated as absent, such as the ones issued by
+// cookie stores before
+func (ss *SessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(ss, name)
+	opts := *ss.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	var id string
+	err = securecookie.DecodeMulti(name, c.Value, &id, ss.Codecs...)
+	if err != nil {
+		return session, nil
+	}
+
+	values, err := ss.load(id)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// Expired or revoked, the id is never reused
+			return session, nil
+		}
+		return session, err
+	}
+
+	session.ID = id
+	session.Values = values
+	session.IsNew = false
+	return session, nil
+}
+
+// Save the session, sessions with negative max age are deleted
+func (ss *SessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		err := ss.delete(session.ID, sessionUserId(session.Values))
+		if err != nil {
+			return err
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	ctx := context.Background()
+	if ss.recordDevice(r, session) && session.ID != "" {
+		// Renew the id on login, so that ids known before are useless
+		err := ss.Rdb.Del(ctx, genSessionKey(session.ID)).Err()
+		if err != nil {
+			return err
+		}
+		session.ID = ""
+	}
+
+	if session.ID != "" {
+		exists, err := ss.Rdb.Exists(ctx, genSessionKey(session.ID)).Result()
+		if err != nil {
+			return err
+		}
+
+		// Revoked during the request, it's saved as a new session without
+		// the logined user
+		if exists == 0 {
+			delete(session.Values, "user_id")
+			delete(session.Values, "user_name")
+			delete(session.Values, sessKeyDevice)
+			session.ID = ""
+		}
+	}
+
+	if session.ID == "" {
+		key := securecookie.GenerateRandomKey(32)
+		if key == nil {
+			return errors.New("generate session id failed")
+		}
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(key), "=")
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(session.Values)
+	if err != nil {
+		return err
+	}
+
+	// Cookies without max age are kept by the browser until closed, which is
+	// unknown to the server
+	maxAge := session.Options.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultSessionMaxAge
+	}
+	lifeTime := time.Duration(maxAge) * time.Second
+	_, err = ss.Rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, genSessionKey(session.ID), buf.Bytes(), lifeTime)
+		if userId := sessionUserId(session.Values); userId > 0 {
+			pipe.SAdd(ctx, genUserSessionsKey(userId), session.ID)
+			pipe.Expire(ctx, genUserSessionsKey(userId), lifeTime)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, ss.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// Refresh device info of the logined session, saved at most once in the
+// active interval
+func (ss *SessionStore) Touch(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if sessionUserId(session.Values) == 0 {
+		return nil
+	}
+
+	device, ok := session.Values[sessKeyDevice].(model.SessionDevice)
+	if ok && time.Since(device.ActiveAt) < ss.ActiveInterval {
+		return nil
+	}
+
+	return session.Save(r, w)
+}
+
+func sessionUserId(values map[any]any) int {
+	if userId, ok := values["user_id"].(int); ok && userId > 0 {
+		return userId
+	}
+	return 0
+}
+
+// Record device of the request for logined sessions, returns true if the
+// user is newly logined
+func (ss *SessionStore) recordDevice(r *http.Request, session *sessions.Session) bool {
+	userId := sessionUserId(session.Values)
+	if userId == 0 {
+		delete(session.Values, sessKeyDevice)
+		return false
+	}
+
+	now := time.Now()
+	device, ok := session.Values[sessKeyDevice].(model.SessionDevice)
+	logined := !ok || device.UserId != userId
+	if logined {
+		device = model.SessionDevice{
+			UserId:    userId,
+			CreatedAt: now,
+		}
+	}
+
+	// Both come from request headers, escaped as other user inputs
+	userAgent := r.UserAgent()
+	if utf8.RuneCountInString(userAgent) > maxUserAgentLen {
+		userAgent = string([]rune(userAgent)[:maxUserAgentLen])
+	}
+	device.UserAgent = html.EscapeString(userAgent)
+	device.IP = html.EscapeString(utils.GetRealIP(r))
+	if code, ok := r.Context().Value("region_country_iso_code").(string); ok {
+		device.Country = code
+	}
+	device.ActiveAt = now
+
+	session.Values[sessKeyDevice] = device
+	return logined
+}
+
+func (ss *SessionStore) load(id string) (map[any]any, error) {
+	data, err := ss.Rdb.Get(context.Background(), genSessionKey(id)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[any]any)
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&values)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (ss *SessionStore) delete(id string, userId int) error {
+	if id == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	_, err := ss.Rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, genSessionKey(id))
+		if userId > 0 {
+			pipe.SRem(ctx, genUserSessionsKey(userId), id)
+		}
+		return nil
+	})
+	return err
+}
+
+// Ids of unexpired sessions of the user with their devices
+func (ss *SessionStore) userSessions(userId int) (map[string]model.SessionDevice, error) {
+	ids, err := ss.Rdb.SMembers(context.Background(), genUserSessionsKey(userId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make(map[string]model.SessionDevice)
+	var staleIds []any
+	for _, id := range ids {
+		values, err := ss.load(id)
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				staleIds = append(staleIds, id)
+				continue
+			}
+			return nil, err
+		}
+
+		device, ok := values[sessKeyDevice].(model.SessionDevice)
+		if !ok || device.UserId != userId {
+			staleIds = append(staleIds, id)
+			continue
+		}
+		devices[id] = device
+	}
+
+	if len(staleIds) > 0 {
+		err = ss.Rdb.SRem(context.Background(), genUserSessionsKey(userId), staleIds...).Err()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return devices, nil
+}
+
+// Logined sessions of the user, the latest active first
+func (ss *SessionStore) List(userId int) ([]*model.SessionDevice, error) {
+	devices, err := ss.userSessions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*model.SessionDevice
+	for id, device := range devices {
+		item := device
+		item.PublicId = SessionPublicId(id)
+		list = append(list, &item)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ActiveAt.After(list[j].ActiveAt)
+	})
+
+	return list, nil
+}
+
+// Revoke the session of the user with the public id
+func (ss *SessionStore) Revoke(userId int, publicId string) error {
+	devices, err := ss.userSessions(userId)
+	if err != nil {
+		return err
+	}
+
+	for id := range devices {
+		if SessionPublicId(id) == publicId {
+			return ss.delete(id, userId)
+		}
+	}
+
+	return ErrSessionNotFound
+}
+
+// Revoke all sessions of the user except the one of exceptId, empty to
+// revoke all
+func (ss *SessionStore) RevokeAll(userId int, exceptId string) error {
+	ids, err := ss.Rdb.SMembers(context.Background(), genUserSessionsKey(userId)).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if id == exceptId {
+			continue
+		}
+
+		err := ss.delete(id, userId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
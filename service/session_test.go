@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/oodzchen/dproject/model"
+)
+
+func TestSessionRecordDevice(t *testing.T) {
+	ss := NewSessionStore(nil, []byte("test-session-key"))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", `<script>alert(1)</script> Firefox/121.0`)
+	req.Header.Set("X-Real-IP", "1.2.3.4")
+	req = req.WithContext(context.WithValue(req.Context(), "region_country_iso_code", "JP"))
+
+	session := sessions.NewSession(ss, "one")
+	if ss.recordDevice(req, session) {
+		t.Error("anonymous session should not be logined")
+	}
+	if _, ok := session.Values[sessKeyDevice]; ok {
+		t.Error("anonymous session should have no device")
+	}
+
+	session.Values["user_id"] = 1
+	if !ss.recordDevice(req, session) {
+		t.Error("want newly logined")
+	}
+
+	device, ok := session.Values[sessKeyDevice].(model.SessionDevice)
+	if !ok {
+		t.Fatal("no device recorded")
+	}
+	if device.UserId != 1 || device.IP != "1.2.3.4" || device.Country != "JP" {
+		t.Errorf("unexpected device %#v", device)
+	}
+	if device.UserAgent != "&lt;script&gt;alert(1)&lt;/script&gt; Firefox/121.0" {
+		t.Errorf("user agent should be escaped, got %q", device.UserAgent)
+	}
+
+	if ss.recordDevice(req, session) {
+		t.Error("want the same login")
+	}
+
+	session.Values["user_id"] = 2
+	if !ss.recordDevice(req, session) {
+		t.Error("want newly logined for another user")
+	}
+
+	delete(session.Values, "user_id")
+	ss.recordDevice(req, session)
+	if _, ok := session.Values[sessKeyDevice]; ok {
+		t.Error("device should be removed after logout")
+	}
+}
+
+func TestSessionPublicId(t *testing.T) {
+	id := SessionPublicId("abc")
+	if len(id) != 16 || id == "abc" {
+		t.Errorf("unexpected public id %q", id)
+	}
+	if id != SessionPublicId("abc") || id == SessionPublicId("abd") {
+		t.Error("public ids should be stable and distinct")
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oodzchen/dproject/store"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/redis/go-redis/v9"
+)
+
+const RecoveryCodeNum = 10
+
+// Bytes of a recovery code, encoded as 16 characters
+const recoveryCodeByteLen = 10
+
+const totpQRCodeSize = 200
+
+// Accepted codes are kept for the skew periods before and after, so that
+// they can't be replayed
+const totpUsedLifeTime = 3 * 30 * time.Second
+
+var ErrTwoFactorCodeInvalid = errors.New("two-factor code is invalid")
+
+var totpCodeRegexp = regexp.MustCompile(`^\d{6}$`)
+
+type TwoFactor struct {
+	Store  *store.Store
+	Rdb    *redis.Client
+	Issuer string
+}
+
+// Generate a new TOTP key for the account, not saved until enabled
+func (tf *TwoFactor) GenerateKey(accountName string) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      tf.Issuer,
+		AccountName: accountName,
+	})
+}
+
+// PNG of the QR code of the provisioning URI
+func TotpQRCode(provisioningUri string) ([]byte, error) {
+	key, err := otp.NewKeyFromURL(provisioningUri)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Generate recovery codes grouped by 4 characters, such as abcd-efgh-ijkl-mnop
+func GenRecoveryCodes() ([]string, error) {
+	var codes []string
+	for i := 0; i < RecoveryCodeNum; i++ {
+		b := make([]byte, recoveryCodeByteLen)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		var groups []string
+		for j := 0; j < len(raw); j += 4 {
+			groups = append(groups, raw[j:j+4])
+		}
+		codes = append(codes, strings.Join(groups, "-"))
+	}
+
+	return codes, nil
+}
+
+// Codes are accepted regardless of cases, spaces and dashes
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeTwoFactorCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashRecoveryCodes(codes []string) []string {
+	var hashes []string
+	for _, code := range codes {
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return hashes
+}
+
+func genTotpUsedKey(userId int, code string) string {
+	return fmt.Sprintf("totp_used:%d:%s", userId, code)
+}
+
+// Validate the TOTP code, each code can be used only once
+func (tf *TwoFactor) validateTotp(userId int, secret, code string) error {
+	if !totpCodeRegexp.MatchString(code) || !totp.Validate(code, secret) {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	ok, err := tf.Rdb.SetNX(context.Background(), genTotpUsedKey(userId, code), 1, totpUsedLifeTime).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	return nil
+}
+
+// Enable TOTP after the code of the new secret is confirmed, the recovery
+// codes are only returned here, only the hashes of them will be stored
+func (tf *TwoFactor) Enable(userId int, secret, code string) ([]string, error) {
+	err := tf.validateTotp(userId, secret, normalizeTwoFactorCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := GenRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tf.Store.User.EnableTotp(userId, secret, hashRecoveryCodes(codes))
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (tf *TwoFactor) Disable(userId int) error {
+	return tf.Store.User.DisableTotp(userId)
+}
+
+// Verify the TOTP code or a recovery code of the user, returns true if a
+// recovery code is used
+func (tf *TwoFactor) Verify(userId int, code string) (bool, error) {
+	code = normalizeTwoFactorCode(code)
+	if code == "" {
+		return false, ErrTwoFactorCodeInvalid
+	}
+
+	if totpCodeRegexp.MatchString(code) {
+		secret, err := tf.Store.User.GetTotpSecret(userId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, ErrTwoFactorCodeInvalid
+			}
+			return false, err
+		}
+
+		return false, tf.validateTotp(userId, secret, code)
+	}
+
+	err := tf.Store.User.UseRecoveryCode(userId, hashRecoveryCode(code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrTwoFactorCodeInvalid
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Replace the recovery codes of the user with new ones
+func (tf *TwoFactor) RegenerateRecoveryCodes(userId int) ([]string, error) {
+	codes, err := GenRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tf.Store.User.ReplaceRecoveryCodes(userId, hashRecoveryCodes(codes))
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (tf *TwoFactor) CountRecoveryCodes(userId int) (int, error) {
+	return tf.Store.User.CountRecoveryCodes(userId)
+}
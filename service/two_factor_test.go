@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+func TestGenRecoveryCodes(t *testing.T) {
+	codes, err := GenRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != RecoveryCodeNum {
+		t.Fatalf("want %d codes, got %d", RecoveryCodeNum, len(codes))
+	}
+
+	codeRegexp := regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !codeRegexp.MatchString(code) {
+			t.Errorf("code %q is not well formatted", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q is duplicated", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := hashRecoveryCode("abcd-efgh-ijkl-mnop")
+
+	tests := []string{
+		"abcdefghijklmnop",
+		"ABCD-EFGH-IJKL-MNOP",
+		" abcd efgh ijkl mnop ",
+	}
+
+	for _, code := range tests {
+		if got := hashRecoveryCode(code); got != want {
+			t.Errorf("code %q, want hash %s, got %s", code, want, got)
+		}
+	}
+
+	if hashRecoveryCode("abcd-efgh-ijkl-mnoq") == want {
+		t.Error("different codes should have different hashes")
+	}
+}
+
+func TestTwoFactorEnableInvalidCode(t *testing.T) {
+	tf := &TwoFactor{Issuer: "test"}
+	key, err := tf.GenerateKey("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validCode, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrongCode := "000000"
+	if validCode == wrongCode {
+		wrongCode = "111111"
+	}
+
+	// Invalid codes are rejected before touching redis and the store
+	for _, code := range []string{"", "12345", "abcdef", wrongCode} {
+		_, err := tf.Enable(1, key.Secret(), code)
+		if !errors.Is(err, ErrTwoFactorCodeInvalid) {
+			t.Errorf("code %q, want error %v, got %v", code, ErrTwoFactorCodeInvalid, err)
+		}
+	}
+}
+
+func TestTotpQRCode(t *testing.T) {
+	tf := &TwoFactor{Issuer: "test"}
+	key, err := tf.GenerateKey("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := TotpQRCode(key.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(img) < 8 || string(img[1:4]) != "PNG" {
+		t.Errorf("want png image, got %d bytes", len(img))
+	}
+}
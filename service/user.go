@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/oodzchen/dproject/model"
@@ -18,6 +20,7 @@ const (
 	UserListActivity                = "activity"
 	UserListSubscribed              = "subscribed"
 	UserListVoteUp                  = "vote_up"
+	UserListDrafts                  = "drafts"
 )
 
 var AuthRequiedUserTabMap = map[UserListType]bool{
@@ -25,15 +28,76 @@ var AuthRequiedUserTabMap = map[UserListType]bool{
 	UserListSubscribed: true,
 	UserListActivity:   true,
 	UserListVoteUp:     true,
+	UserListDrafts:     true,
 }
 
 func CheckUserTabAuthRequired(tab UserListType) bool {
 	return AuthRequiedUserTabMap[tab]
 }
 
+const DefaultUsernameCooldown = 30 * 24 * time.Hour
+const DefaultUsernameRedirect = 90 * 24 * time.Hour
+
 type User struct {
 	Store         *store.Store
 	SantizePolicy *bluemonday.Policy
+	// Username can be changed once in the period
+	UsernameCooldown time.Duration
+	// Old username redirects to the user and is reserved in the period
+	UsernameRedirect time.Duration
+}
+
+func (u *User) usernameCooldown() time.Duration {
+	if u.UsernameCooldown <= 0 {
+		return DefaultUsernameCooldown
+	}
+	return u.UsernameCooldown
+}
+
+func (u *User) usernameRedirect() time.Duration {
+	if u.UsernameRedirect <= 0 {
+		return DefaultUsernameRedirect
+	}
+	return u.UsernameRedirect
+}
+
+func (u *User) UsernameRedirectDays() int {
+	return int(u.usernameRedirect() / (24 * time.Hour))
+}
+
+func (u *User) UsernameCooldownDays() int {
+	return int(u.usernameCooldown() / (24 * time.Hour))
+}
+
+// Time when the user can change the username again, zero if it can be
+// changed now
+func (u *User) UsernameChangeAvailableAt(userId int) (time.Time, error) {
+	history, err := u.Store.User.ListUsernameHistory(userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(history) == 0 {
+		return time.Time{}, nil
+	}
+
+	availableAt := model.UsernameChangeAvailableAt(history[0].CreatedAt, u.usernameCooldown())
+	if availableAt.Before(time.Now()) {
+		return time.Time{}, nil
+	}
+	return availableAt, nil
+}
+
+// Change the username, returns the old one
+func (u *User) ChangeUsername(userId int, newName string) (string, error) {
+	newName = strings.TrimSpace(newName)
+	err := model.ValidUsername(newName)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return u.Store.User.ChangeUsername(userId, newName, now.Add(-u.usernameCooldown()), now.Add(u.usernameRedirect()))
 }
 
 func (u *User) Register(email string, password string, name string) (int, error) {
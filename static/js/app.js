@@ -341,6 +341,227 @@
     }, 0);
   }
 
+  /*--------------------- markdown preview  ---------------------------------------------------*/
+  let previewTimer = null;
+
+  addEvents(".btn-preview", "click", function (ev) {
+    const previewEl = document.getElementById("content-preview");
+    if (!previewEl) return;
+
+    previewEl.hidden = !previewEl.hidden;
+    if (!previewEl.hidden) {
+      renderPreview(ev.target);
+    }
+  });
+
+  addEvents(".create-form textarea", "input", function (ev) {
+    const previewEl = document.getElementById("content-preview");
+    const btn = document.querySelector(".btn-preview");
+    if (!previewEl || previewEl.hidden || !btn) return;
+
+    clearTimeout(previewTimer);
+    previewTimer = setTimeout(function () {
+      renderPreview(btn);
+    }, 500);
+  });
+
+  async function renderPreview(btn) {
+    const form = btn.closest("form");
+    const previewEl = document.getElementById("content-preview");
+
+    try {
+      // Only the csrf token and content are posted, attachments of the form
+      // are not needed
+      const res = await fetch(btn.getAttribute("data-preview-path"), {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/x-www-form-urlencoded",
+        },
+        body: new URLSearchParams({
+          tk: form.elements.tk.value,
+          content: form.elements.content.value,
+        }),
+      });
+
+      if (!res.ok) {
+        previewEl.textContent = res.statusText;
+        return;
+      }
+
+      previewEl.innerHTML = await res.text();
+    } catch (err) {
+      previewEl.textContent = err.message;
+    }
+  }
+
+  /*--------------------- link title  ---------------------------------------------------*/
+  addEvents("input[data-title-path]", "change", async function (ev) {
+    const form = ev.target.closest("form");
+    const titleEl = form.elements.title;
+    if (!titleEl || titleEl.value.trim() || !ev.target.checkValidity()) return;
+
+    try {
+      const res = await fetch(ev.target.getAttribute("data-title-path"), {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/x-www-form-urlencoded",
+        },
+        body: new URLSearchParams({
+          tk: form.elements.tk.value,
+          url: ev.target.value,
+        }),
+      });
+      if (!res.ok) return;
+
+      // The title may be entered while fetching
+      const title = await res.text();
+      if (title && !titleEl.value.trim()) {
+        titleEl.value = title;
+      }
+    } catch (err) {
+      console.error(err);
+    }
+  });
+
+  /*--------------------- draft autosave  ---------------------------------------------------*/
+  const draftFields = ["title", "url", "content", "category_front_id"];
+
+  document.querySelectorAll("form[data-draft-path]").forEach(function (form) {
+    let draftTimer = null;
+
+    form.addEventListener("input", function (ev) {
+      if (!draftFields.includes(ev.target.name)) return;
+
+      clearTimeout(draftTimer);
+      draftTimer = setTimeout(function () {
+        saveDraft(form);
+      }, 2000);
+    });
+
+    // The draft is deleted after submitting, it should not be saved again
+    form.addEventListener("submit", function () {
+      clearTimeout(draftTimer);
+    });
+  });
+
+  async function saveDraft(form) {
+    const statusEl = form.querySelector(".draft-status");
+    const params = new URLSearchParams({
+      tk: form.elements.tk.value,
+      target_type: form.getAttribute("data-draft-type"),
+      target_id: form.getAttribute("data-draft-target") || "0",
+    });
+
+    draftFields.forEach(function (name) {
+      if (form.elements[name]) {
+        params.set(name, form.elements[name].value);
+      }
+    });
+
+    try {
+      const res = await fetch(form.getAttribute("data-draft-path"), {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/x-www-form-urlencoded",
+        },
+        body: params,
+      });
+
+      if (statusEl) {
+        statusEl.textContent = res.ok
+          ? statusEl.getAttribute("data-saved-text")
+          : res.statusText;
+      }
+    } catch (err) {
+      if (statusEl) {
+        statusEl.textContent = err.message;
+      }
+    }
+  }
+
+  /*--------------------- mention autocomplete  ---------------------------------------------------*/
+  let mentionTimer = null;
+  const reMentionPrefix = /(^|[^a-zA-Z0-9._@\/-])@([a-zA-Z0-9._-]{1,20})$/;
+
+  document.querySelectorAll("textarea[data-mention-path]").forEach(function (textarea) {
+    textarea.addEventListener("input", function () {
+      clearTimeout(mentionTimer);
+      mentionTimer = setTimeout(function () {
+        showMentionList(textarea);
+      }, 300);
+    });
+
+    textarea.addEventListener("keydown", function (ev) {
+      if (ev.key === "Escape") {
+        hideMentionList(textarea);
+      }
+    });
+  });
+
+  function getMentionList(textarea) {
+    const listEl = textarea.nextElementSibling;
+    if (listEl && listEl.classList.contains("mention-list")) {
+      return listEl;
+    }
+    return null;
+  }
+
+  function hideMentionList(textarea) {
+    const listEl = getMentionList(textarea);
+    if (listEl) {
+      listEl.hidden = true;
+    }
+  }
+
+  async function showMentionList(textarea) {
+    const listEl = getMentionList(textarea);
+    if (!listEl) return;
+
+    const beforeCaret = textarea.value.slice(0, textarea.selectionStart);
+    const match = beforeCaret.match(reMentionPrefix);
+    if (!match) {
+      listEl.hidden = true;
+      return;
+    }
+
+    const prefix = match[2];
+    let usernames = [];
+    try {
+      const res = await fetch(
+        textarea.getAttribute("data-mention-path") + "?prefix=" + encodeURIComponent(prefix),
+      );
+      if (res.ok) {
+        usernames = (await res.json()).data || [];
+      }
+    } catch (err) {
+      console.error("fetch usernames error: ", err);
+    }
+
+    listEl.innerHTML = "";
+    usernames.forEach(function (username) {
+      const itemEl = document.createElement("li");
+      const btn = document.createElement("button");
+      btn.type = "button";
+      btn.textContent = "@" + username;
+      btn.onclick = function () {
+        insertMention(textarea, prefix, username);
+        listEl.hidden = true;
+      };
+      itemEl.appendChild(btn);
+      listEl.appendChild(itemEl);
+    });
+    listEl.hidden = usernames.length == 0;
+  }
+
+  function insertMention(textarea, prefix, username) {
+    const caret = textarea.selectionStart;
+    const start = caret - prefix.length;
+    textarea.value =
+      textarea.value.slice(0, start) + username + " " + textarea.value.slice(caret);
+    textarea.selectionStart = textarea.selectionEnd = start + username.length + 1;
+    textarea.focus();
+  }
+
   /*--------------------- role edit page  ---------------------------------------------------*/
   document.querySelectorAll(".btn-all").forEach((el) => toggleBtnAll(el));
 
@@ -379,10 +600,12 @@
   const SITE = "dizkaz.com";
   const searchForm = document.getElementById("search-form");
   searchForm.onsubmit = function search(e) {
-    e.preventDefault();
     const engine = document.getElementById("search-engine").value;
     const keywords = document.getElementById("keywords").value;
 
+    if (engine === "site") return;
+
+    e.preventDefault();
     if (keywords.trim() == "") return;
 
     let searchUrl = "";
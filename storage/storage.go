@@ -0,0 +1,20 @@
+// Storage backends of uploaded files, files are saved and read by keys like
+// "attachments/2024/01/xxx.png"
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotExist = errors.New("storage: object does not exist")
+
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Returns ErrNotExist if there is no object of the key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Deleting an object that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+}
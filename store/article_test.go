@@ -54,7 +54,7 @@ func TestArticleVote(t *testing.T) {
 		log.Fatal(err)
 	}
 
-	store := New(pg.Article, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, pg.Category)
+	store := New(pg.Article, pg.User, pg.Role, pg.Permission, pg.Activity, pg.Message, pg.Category, pg.ApiToken, pg.Tag, pg.Report, pg.Attachment, pg.LinkPreview, pg.Poll, pg.Draft)
 
 	uId, err := registerNewUser(store, appCfg)
 	mt.LogFailed(err)
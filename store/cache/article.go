@@ -0,0 +1,361 @@
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+// Only the first pages are cached, which are visited the most
+const maxCachedPageNum = 3
+
+type ListType string
+
+const (
+	ListTypeHome     ListType = "home"
+	ListTypeCategory          = "category"
+	ListTypeTree              = "tree"
+	ListTypeReplies           = "replies"
+)
+
+type CachedList struct {
+	Total int
+	List  []*model.Article
+}
+
+// ArticleCache caches the article lists of anonymous users, the category
+// lists, and the reply trees of articles
+type ArticleCache struct {
+	store.ArticleStore
+	Cache *Cache
+}
+
+func NewArticleCache(article store.ArticleStore, c *Cache) *ArticleCache {
+	return &ArticleCache{
+		ArticleStore: article,
+		Cache:        c,
+	}
+}
+
+// Lists with user related or rarely used filters are not cached
+func listCacheable(page, pageSize int, tagFrontId string, ignoreUserId int, deleted, includeReplies bool, keywords string) bool {
+	return page > 0 && page <= maxCachedPageNum &&
+		pageSize > 0 &&
+		tagFrontId == "" &&
+		ignoreUserId == 0 &&
+		!deleted &&
+		!includeReplies &&
+		keywords == ""
+}
+
+func listIndexKey(categoryFrontId string) string {
+	if categoryFrontId == "" {
+		return genIndexKey(KindArticleList, string(ListTypeHome))
+	}
+	return genIndexKey(KindArticleList, ListTypeCategory+":"+categoryFrontId)
+}
+
+func treeIndexKey(rootArticleId int) string {
+	return genIndexKey(KindArticleTree, strconv.Itoa(rootArticleId))
+}
+
+var categoryIndexKey = genIndexKey(KindCategory, "all")
+
+func (ac *ArticleCache) List(
+	page,
+	pageSize int,
+	sortType model.ArticleSortType,
+	categoryFrontId, tagFrontId string,
+	ignoreUserId int,
+	pinned, deleted, includeReplies bool,
+	keywords string,
+) ([]*model.Article, int, error) {
+	if !listCacheable(page, pageSize, tagFrontId, ignoreUserId, deleted, includeReplies, keywords) {
+		return ac.ArticleStore.List(page, pageSize, sortType, categoryFrontId, tagFrontId, ignoreUserId, pinned, deleted, includeReplies, keywords)
+	}
+
+	listType := ListTypeHome
+	if categoryFrontId != "" {
+		listType = ListTypeCategory
+	}
+
+	key := genKey(KindArticleList, string(listType), map[string]string{
+		"sortType":        string(sortType),
+		"page":            strconv.Itoa(page),
+		"pageSize":        strconv.Itoa(pageSize),
+		"categoryFrontId": categoryFrontId,
+		"pinned":          strconv.FormatBool(pinned),
+	})
+
+	var cached CachedList
+	if ac.Cache.get(KindArticleList, key, &cached) {
+		return cached.List, cached.Total, nil
+	}
+
+	list, total, err := ac.ArticleStore.List(page, pageSize, sortType, categoryFrontId, tagFrontId, ignoreUserId, pinned, deleted, includeReplies, keywords)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ac.Cache.set(key, &CachedList{Total: total, List: list}, listIndexKey(categoryFrontId))
+
+	return list, total, nil
+}
+
+func (ac *ArticleCache) ReplyTree(page, pageSize, articleId int, sortType model.ArticleSortType, pinned bool) ([]*model.Article, error) {
+	return ac.replies(ListTypeTree, page, pageSize, articleId, sortType, pinned)
+}
+
+func (ac *ArticleCache) ReplyList(page, pageSize, articleId int, sortType model.ArticleSortType, pinned bool) ([]*model.Article, error) {
+	return ac.replies(ListTypeReplies, page, pageSize, articleId, sortType, pinned)
+}
+
+func (ac *ArticleCache) replies(listType ListType, page, pageSize, articleId int, sortType model.ArticleSortType, pinned bool) ([]*model.Article, error) {
+	fetch := ac.ArticleStore.ReplyTree
+	if listType == ListTypeReplies {
+		fetch = ac.ArticleStore.ReplyList
+	}
+
+	key := genKey(KindArticleTree, string(listType), map[string]string{
+		"id":       strconv.Itoa(articleId),
+		"sortType": string(sortType),
+		"page":     strconv.Itoa(page),
+		"pageSize": strconv.Itoa(pageSize),
+		"pinned":   strconv.FormatBool(pinned),
+	})
+
+	var cached CachedList
+	if ac.Cache.get(KindArticleTree, key, &cached) {
+		return cached.List, nil
+	}
+
+	list, err := fetch(page, pageSize, articleId, sortType, pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies of any article in the tree are invalidated with the root
+	// article, which is only known from the replies, so empty lists are
+	// left uncached
+	if len(list) > 0 {
+		ac.Cache.set(key, &CachedList{Total: len(list), List: list}, treeIndexKey(list[0].ReplyRootArticleId))
+	}
+
+	return list, nil
+}
+
+type articleScope struct {
+	rootArticleId   int
+	categoryFrontId string
+}
+
+// Root article and category of the article, for the invalidation
+func (ac *ArticleCache) scope(id int) (*articleScope, error) {
+	article, err := ac.ArticleStore.Item(id, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rootArticleId := article.ReplyRootArticleId
+	if article.ReplyToId == 0 {
+		rootArticleId = article.Id
+	}
+
+	return &articleScope{
+		rootArticleId:   rootArticleId,
+		categoryFrontId: article.CategoryFrontId,
+	}, nil
+}
+
+// Invalidate the reply tree and the lists containing the article, category
+// lists are invalidated too if the article counts of categories are changed
+func (ac *ArticleCache) invalidate(scope *articleScope, categoryCounts bool) {
+	if scope == nil {
+		return
+	}
+
+	indexKeys := []string{
+		treeIndexKey(scope.rootArticleId),
+		listIndexKey(""),
+		listIndexKey(scope.categoryFrontId),
+	}
+	if categoryCounts {
+		indexKeys = append(indexKeys, categoryIndexKey)
+	}
+
+	ac.Cache.invalidate(indexKeys...)
+}
+
+func (ac *ArticleCache) invalidateArticle(id int, categoryCounts bool) {
+	scope, err := ac.scope(id)
+	if err != nil {
+		logErr("get article cache scope error:", err)
+		return
+	}
+	ac.invalidate(scope, categoryCounts)
+}
+
+// Scope before the write, for writes changing or hiding the article
+func (ac *ArticleCache) scopeBefore(id int) *articleScope {
+	scope, err := ac.scope(id)
+	if err != nil {
+		logErr("get article cache scope error:", err)
+		return nil
+	}
+	return scope
+}
+
+func (ac *ArticleCache) Create(title, url, content string, authorId, replyToId int, categoryFrontId string, pinnedExpireAt time.Time, locked bool) (int, error) {
+	id, err := ac.ArticleStore.Create(title, url, content, authorId, replyToId, categoryFrontId, pinnedExpireAt, locked)
+	if err != nil {
+		return 0, err
+	}
+
+	ac.invalidateArticle(id, replyToId == 0)
+	return id, nil
+}
+
+func (ac *ArticleCache) UpdateRootArticle(id int, title, content, link, categoryFrontId string, pinnedExpireAt time.Time, locked bool) (int, error) {
+	prev := ac.scopeBefore(id)
+
+	updatedId, err := ac.ArticleStore.UpdateRootArticle(id, title, content, link, categoryFrontId, pinnedExpireAt, locked)
+	if err != nil {
+		return 0, err
+	}
+
+	categoryChanged := prev != nil && prev.categoryFrontId != categoryFrontId
+	ac.invalidate(prev, categoryChanged)
+	if categoryChanged {
+		ac.Cache.invalidate(listIndexKey(categoryFrontId))
+	}
+	return updatedId, nil
+}
+
+func (ac *ArticleCache) UpdateReply(id int, content string, pinnedExpireAt time.Time, locked bool) (int, error) {
+	updatedId, err := ac.ArticleStore.UpdateReply(id, content, pinnedExpireAt, locked)
+	if err != nil {
+		return 0, err
+	}
+
+	ac.invalidateArticle(id, false)
+	return updatedId, nil
+}
+
+func (ac *ArticleCache) Delete(id int) (int, error) {
+	prev := ac.scopeBefore(id)
+
+	deletedId, err := ac.ArticleStore.Delete(id)
+	if err != nil {
+		return 0, err
+	}
+
+	ac.invalidate(prev, prev != nil && prev.rootArticleId == id)
+	return deletedId, nil
+}
+
+func (ac *ArticleCache) Recover(articleId int) error {
+	err := ac.ArticleStore.Recover(articleId)
+	if err != nil {
+		return err
+	}
+
+	scope, err := ac.scope(articleId)
+	if err != nil {
+		logErr("get article cache scope error:", err)
+		return nil
+	}
+	ac.invalidate(scope, scope.rootArticleId == articleId)
+	return nil
+}
+
+func (ac *ArticleCache) ToggleVote(id, loginedUserId int, voteType string) (int, error) {
+	res, err := ac.ArticleStore.ToggleVote(id, loginedUserId, voteType)
+	if err != nil {
+		return res, err
+	}
+
+	ac.invalidateArticle(id, false)
+	return res, nil
+}
+
+func (ac *ArticleCache) ToggleReact(id, loginedUserId, reactId int) (int, string, error) {
+	res, prevReactId, err := ac.ArticleStore.ToggleReact(id, loginedUserId, reactId)
+	if err != nil {
+		return res, prevReactId, err
+	}
+
+	ac.invalidateArticle(id, false)
+	return res, prevReactId, nil
+}
+
+func (ac *ArticleCache) Tag(id int, tagFrontIds []string) error {
+	err := ac.ArticleStore.Tag(id, tagFrontIds)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(id, false)
+	return nil
+}
+
+func (ac *ArticleCache) MarkDuplicate(id, originalId int) error {
+	err := ac.ArticleStore.MarkDuplicate(id, originalId)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(id, false)
+	return nil
+}
+
+func (ac *ArticleCache) ToggleLock(articleId int) error {
+	err := ac.ArticleStore.ToggleLock(articleId)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(articleId, false)
+	return nil
+}
+
+func (ac *ArticleCache) Pin(articleId int, expireAt time.Time) error {
+	err := ac.ArticleStore.Pin(articleId, expireAt)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(articleId, false)
+	return nil
+}
+
+func (ac *ArticleCache) Unpin(articleId int) error {
+	err := ac.ArticleStore.Unpin(articleId)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(articleId, false)
+	return nil
+}
+
+func (ac *ArticleCache) SetBlockRegions(articleId int, regions []string) error {
+	err := ac.ArticleStore.SetBlockRegions(articleId, regions)
+	if err != nil {
+		return err
+	}
+
+	ac.invalidateArticle(articleId, false)
+	return nil
+}
+
+func (ac *ArticleCache) ToggleFadeOut(articleId int) (int, error) {
+	res, err := ac.ArticleStore.ToggleFadeOut(articleId)
+	if err != nil {
+		return res, err
+	}
+
+	ac.invalidateArticle(articleId, false)
+	return res, nil
+}
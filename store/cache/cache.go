@@ -1,93 +1,76 @@
+// Caching decorators of the stores, results are kept in redis and indexed by
+// the data they depend on, so that writes only invalidate the affected ones.
+
 package cache
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/oodzchen/dproject/model"
-	"github.com/oodzchen/dproject/store/pgstore"
 	"github.com/redis/go-redis/v9"
 )
 
-type ArticleCache struct {
-	*pgstore.Article
-	Rdb *redis.Client
+// Cached results are also refreshed after the period, for data changed
+// without writing through the stores, such as expired pins and usernames
+const DefaultTTL = 5 * time.Minute
+
+type Kind string
+
+const (
+	KindArticleList Kind = "article_list"
+	KindArticleTree      = "article_tree"
+	KindCategory         = "category"
+)
+
+type counter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
-type CachedList struct {
-	Total int
-	List  []*model.Article
+type Stats struct {
+	Hits   uint64
+	Misses uint64
 }
 
-type ListType string
+type Cache struct {
+	Rdb      *redis.Client
+	TTL      time.Duration
+	counters map[Kind]*counter
+}
 
-const (
-	ListTypeHome ListType = "home"
-	ListTypeTree          = "tree"
-)
+func New(rdb *redis.Client, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
 
-// func (ac *ArticleCache) List(page, pageSize int, sortType model.ArticleSortType, categoryFrontId string) ([]*model.Article, int, error) {
-// params := map[string]string{
-// 	"sortType":        string(sortType),
-// 	"page":            strconv.Itoa(page),
-// 	"pageSize":        strconv.Itoa(pageSize),
-// 	"categoryFrontId": categoryFrontId,
-// }
-// cachedList,
-// 	total, err := ac.getList(ListTypeHome, params)
-// if err != nil {
-// 	if errors.Is(err, redis.Nil) {
-// 		list, total, err := ac.Article.List(page, pageSize, sortType, categoryFrontId)
-// 		if err != nil {
-// 			return nil, 0, err
-// 		}
-// 		go func() {
-// 			err := ac.setList(ListTypeHome, params, list, total)
-// 			if err != nil {
-// 				fmt.Println("redis cache article list error:", err)
-// 			}
-// 		}()
-
-// 		return list, total, nil
-// 	}
-// 	return nil, 0, err
-// }
-
-// return cachedList, total, nil
-// 	return ac.Article.List(page, pageSize, sortType, categoryFrontId)
-// }
-
-// func (ac *ArticleCache) ItemTree(id int, sortType model.ArticleSortType) ([]*model.Article, error) {
-// 	params := map[string]string{
-// 		"sortType": string(sortType),
-// 		"id":       strconv.Itoa(id),
-// 	}
-// 	cachedList, _, err := ac.getList(ListTypeTree, params)
-// 	// fmt.Println("cached tree list len:", len(cachedList))
-// 	if err != nil {
-// 		if errors.Is(err, redis.Nil) {
-// 			list, err := ac.Article.ItemTree(id, sortType)
-// 			if err != nil {
-// 				return nil, err
-// 			}
-// 			go func() {
-// 				err := ac.setList(ListTypeTree, params, list, len(list))
-// 				if err != nil {
-// 					fmt.Println("redis cache article tree list error:", err)
-// 				}
-// 			}()
-
-// 			return list, nil
-// 		}
-// 		return nil, err
-// 	}
-
-// 	return cachedList, nil
-// }
+	return &Cache{
+		Rdb: rdb,
+		TTL: ttl,
+		counters: map[Kind]*counter{
+			KindArticleList: {},
+			KindArticleTree: {},
+			KindCategory:    {},
+		},
+	}
+}
+
+// Hit and miss counts of each kind since the app started
+func (c *Cache) Stats() map[Kind]Stats {
+	stats := make(map[Kind]Stats)
+	for kind, item := range c.counters {
+		stats[kind] = Stats{
+			Hits:   item.hits.Load(),
+			Misses: item.misses.Load(),
+		}
+	}
+	return stats
+}
 
 func paramsToStr(params map[string]string) string {
 	var paramsArr []string
@@ -97,7 +80,6 @@ func paramsToStr(params map[string]string) string {
 	}
 
 	sort.Strings(keys)
-	// fmt.Println("sorted keys:", keys)
 
 	for _, key := range keys {
 		paramsArr = append(paramsArr, fmt.Sprintf("%s=%s", key, params[key]))
@@ -106,112 +88,70 @@ func paramsToStr(params map[string]string) string {
 	return strings.Join(paramsArr, "&")
 }
 
-// Set list data to redis
-func (ac *ArticleCache) setList(listType ListType, params map[string]string, list []*model.Article, total int) error {
-	jsonStr, err := json.Marshal(&CachedList{
-		List:  list,
-		Total: total,
-	})
+func genKey(kind Kind, name string, params map[string]string) string {
+	return fmt.Sprintf("%s:%s?%s", string(kind), name, paramsToStr(params))
+}
 
-	if err != nil {
-		return err
+// Index of the keys depending on the same data
+func genIndexKey(kind Kind, name string) string {
+	return fmt.Sprintf("cache_index:%s:%s", string(kind), name)
+}
+
+// Get the cached value into v, returns false if it's missing
+func (c *Cache) get(kind Kind, key string, v any) bool {
+	str, err := c.Rdb.Get(context.Background(), key).Result()
+	if err == nil {
+		err = json.Unmarshal([]byte(str), v)
 	}
 
-	err = ac.Rdb.Set(
-		context.Background(),
-		fmt.Sprintf("article_list:%s?%s", string(listType), paramsToStr(params)),
-		string(jsonStr),
-		30*24*time.Hour,
-	).Err()
 	if err != nil {
-		return err
+		if !errors.Is(err, redis.Nil) {
+			logErr("redis get cache error:", err)
+		}
+		c.counters[kind].misses.Add(1)
+		return false
 	}
 
-	return nil
+	c.counters[kind].hits.Add(1)
+	return true
 }
 
-// Get list data from redis
-func (ac *ArticleCache) getList(listType ListType, params map[string]string) ([]*model.Article, int, error) {
-	str, err := ac.Rdb.Get(
-		context.Background(),
-		fmt.Sprintf("article_list:%s?%s", string(listType), paramsToStr(params)),
-	).Result()
+// Set the value and add the key to the indexes, the value is encoded before
+// return, so callers are free to modify it afterwards
+func (c *Cache) set(key string, v any, indexKeys ...string) {
+	jsonStr, err := json.Marshal(v)
 	if err != nil {
-		return nil, 0, err
+		logErr("encode cache error:", err)
+		return
 	}
 
-	var listData CachedList
-	err = json.Unmarshal([]byte(str), &listData)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// fmt.Printf("cached list: %#v\n", listData)
-
-	return listData.List, listData.Total, nil
+	ctx := context.Background()
+	_, err = c.Rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, string(jsonStr), c.TTL)
+		for _, indexKey := range indexKeys {
+			pipe.SAdd(ctx, indexKey, key)
+			// Members are expired not later than the index
+			pipe.Expire(ctx, indexKey, c.TTL)
+		}
+		return nil
+	})
+	logErr("redis set cache error:", err)
 }
 
-const maxCachedPageNum = 1
-
-// func (ac *ArticleCache) RefreshListCache() error {
-// 	total, err := ac.Article.Count()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	// fmt.Println("total on cache:", total)
-
-// 	totalPage := int(math.Ceil(float64(total) / float64(pgstore.DefaultPageSize)))
-// 	maxPage := maxCachedPageNum
-// 	if totalPage < maxCachedPageNum {
-// 		maxPage = totalPage
-// 	}
-
-// 	sortTypes := []model.ArticleSortType{model.ListSortBest, model.ListSortLatest, model.ListSortHot}
-// 	for _, sortType := range sortTypes {
-// 		// fmt.Println("cache list sort type:", string(sortType))
-// 		for i := 1; i <= maxPage; i++ {
-// 			// fmt.Println("cache list page:", i)
-// 			list, total, err := ac.Article.List(i, pgstore.DefaultPageSize, sortType, "")
-// 			// fmt.Println("total from db:", total)
-// 			if err != nil {
-// 				return err
-// 			}
-
-// 			params := map[string]string{
-// 				"sortType":        string(sortType),
-// 				"page":            strconv.Itoa(i),
-// 				"pageSize":        strconv.Itoa(pgstore.DefaultPageSize),
-// 				"categoryFrontId": "",
-// 			}
-
-// 			err = ac.setList(ListTypeHome, params, list, total)
-// 			if err != nil {
-// 				return err
-// 			}
-// 		}
-// 	}
-
-// 	return nil
-// }
-
-// func (ac *ArticleCache) RefreshArticleTreeCache(id int) error {
-// 	sortTypes := []model.ArticleSortType{model.ReplySortBest, model.ListSortLatest}
-// 	for _, sortType := range sortTypes {
-// 		list, err := ac.Article.ItemTree(id, sortType)
-// 		if err != nil {
-// 			return err
-// 		}
-
-// 		params := map[string]string{
-// 			"sortType": string(sortType),
-// 			"id":       strconv.Itoa(id),
-// 		}
-
-// 		ac.setList(ListTypeTree, params, list, len(list))
-// 	}
-
-// 	return nil
-// }
+// Remove keys in the indexes along with the indexes
+func (c *Cache) invalidate(indexKeys ...string) {
+	ctx := context.Background()
+	for _, indexKey := range indexKeys {
+		keys, err := c.Rdb.SMembers(ctx, indexKey).Result()
+		if err != nil {
+			logErr("redis get cache index error:", err)
+			continue
+		}
+
+		err = c.Rdb.Del(ctx, append(keys, indexKey)...).Err()
+		logErr("redis invalidate cache error:", err)
+	}
+}
 
 func logErr(msg string, err error) {
 	if err != nil {
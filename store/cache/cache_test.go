@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGenKey(t *testing.T) {
+	tests := []struct {
+		kind   Kind
+		name   string
+		params map[string]string
+		want   string
+	}{
+		{KindArticleList, "home", map[string]string{"sortType": "hot", "page": "1", "categoryFrontId": ""}, "article_list:home?categoryFrontId=&page=1&sortType=hot"},
+		{KindArticleTree, "tree", map[string]string{"id": "12"}, "article_tree:tree?id=12"},
+		{KindCategory, "moderators", nil, "category:moderators?"},
+	}
+
+	for _, tt := range tests {
+		if got := genKey(tt.kind, tt.name, tt.params); got != tt.want {
+			t.Errorf("want key %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestListCacheable(t *testing.T) {
+	tests := []struct {
+		desc           string
+		page           int
+		pageSize       int
+		tagFrontId     string
+		ignoreUserId   int
+		deleted        bool
+		includeReplies bool
+		keywords       string
+		want           bool
+	}{
+		{"first page", 1, 50, "", 0, false, false, "", true},
+		{"last cached page", maxCachedPageNum, 50, "", 0, false, false, "", true},
+		{"page not cached", maxCachedPageNum + 1, 50, "", 0, false, false, "", false},
+		{"all data", 1, -1, "", 0, false, false, "", false},
+		{"tag list", 1, 50, "go", 0, false, false, "", false},
+		{"ignored categories of user", 1, 50, "", 1, false, false, "", false},
+		{"deleted list", 1, 50, "", 0, true, false, "", false},
+		{"with replies", 1, 50, "", 0, false, true, "", false},
+		{"keywords", 1, 50, "", 0, false, false, "abc", false},
+	}
+
+	for _, tt := range tests {
+		got := listCacheable(tt.page, tt.pageSize, tt.tagFrontId, tt.ignoreUserId, tt.deleted, tt.includeReplies, tt.keywords)
+		if got != tt.want {
+			t.Errorf("%s: want cacheable %t, got %t", tt.desc, tt.want, got)
+		}
+	}
+}
+
+func TestIndexKey(t *testing.T) {
+	if got, want := listIndexKey(""), "cache_index:article_list:home"; got != want {
+		t.Errorf("want home index %q, got %q", want, got)
+	}
+	if got, want := listIndexKey("go"), "cache_index:article_list:category:go"; got != want {
+		t.Errorf("want category index %q, got %q", want, got)
+	}
+	if got, want := treeIndexKey(12), "cache_index:article_tree:12"; got != want {
+		t.Errorf("want tree index %q, got %q", want, got)
+	}
+}
+
+func TestCachedListEncoding(t *testing.T) {
+	article := &model.Article{
+		Id:                 1,
+		Title:              "Title",
+		CreatedAt:          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ReplyRootArticleId: 0,
+		ReactCounts:        model.ArticleReactCounts{"like": 2},
+		Tags:               []*model.Tag{{FrontId: "go"}},
+		CategoryFrontId:    "general",
+	}
+
+	data, err := json.Marshal(&CachedList{Total: 10, List: []*model.Article{article}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cached CachedList
+	err = json.Unmarshal(data, &cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cached.Total != 10 || len(cached.List) != 1 {
+		t.Fatalf("want total 10 and 1 item, got %d and %d items", cached.Total, len(cached.List))
+	}
+
+	got := cached.List[0]
+	if got.Title != article.Title || !got.CreatedAt.Equal(article.CreatedAt) || got.ReactCounts["like"] != 2 || got.Tags[0].FrontId != "go" || got.CategoryFrontId != "general" {
+		t.Errorf("want article %#v, got %#v", article, got)
+	}
+}
+
+type fakeArticleStore struct {
+	store.ArticleStore
+	listCalled int
+}
+
+func (f *fakeArticleStore) List(page, pageSize int, sortType model.ArticleSortType, categoryFrontId, tagFrontId string, ignoreUserId int, pinned, deleted, includeReplies bool, keywords string) ([]*model.Article, int, error) {
+	f.listCalled++
+	return []*model.Article{{Id: 1}}, 1, nil
+}
+
+func TestArticleCacheUnavailable(t *testing.T) {
+	// Nothing is listening on the port, the store is used as if the data is
+	// not cached
+	rdb := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		MaxRetries:  -1,
+		DialTimeout: 100 * time.Millisecond,
+	})
+	defer rdb.Close()
+
+	articleStore := &fakeArticleStore{}
+	c := New(rdb, time.Minute)
+	ac := NewArticleCache(articleStore, c)
+
+	list, total, err := ac.List(1, 50, model.ListSortHot, "", "", 0, false, false, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 || len(list) != 1 || articleStore.listCalled != 1 {
+		t.Errorf("want list from the store, got total %d, %d items, store called %d times", total, len(list), articleStore.listCalled)
+	}
+
+	stats := c.Stats()[KindArticleList]
+	if stats.Hits != 0 || stats.Misses != 1 {
+		t.Errorf("want 0 hit and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
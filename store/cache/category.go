@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/store"
+)
+
+// CategoryCache caches the category lists, the moderators, and the
+// categories without user states, categories are few so all of them are
+// invalidated on any change
+type CategoryCache struct {
+	store.CategoryStore
+	Cache *Cache
+}
+
+func NewCategoryCache(category store.CategoryStore, c *Cache) *CategoryCache {
+	return &CategoryCache{
+		CategoryStore: category,
+		Cache:         c,
+	}
+}
+
+func (cc *CategoryCache) List(state model.CategoryState) ([]*model.Category, error) {
+	key := genKey(KindCategory, "list", map[string]string{
+		"state": string(state),
+	})
+
+	var list []*model.Category
+	if cc.Cache.get(KindCategory, key, &list) {
+		return list, nil
+	}
+
+	list, err := cc.CategoryStore.List(state)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.Cache.set(key, list, categoryIndexKey)
+	return list, nil
+}
+
+func (cc *CategoryCache) Item(frontId string, loginedUserId int) (*model.Category, error) {
+	if loginedUserId != 0 {
+		return cc.CategoryStore.Item(frontId, loginedUserId)
+	}
+
+	key := genKey(KindCategory, "item", map[string]string{
+		"frontId": frontId,
+	})
+
+	var item model.Category
+	if cc.Cache.get(KindCategory, key, &item) {
+		return &item, nil
+	}
+
+	category, err := cc.CategoryStore.Item(frontId, loginedUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.Cache.set(key, category, categoryIndexKey)
+	return category, nil
+}
+
+func (cc *CategoryCache) ListModerators() ([]*model.CategoryModerator, error) {
+	key := genKey(KindCategory, "moderators", nil)
+
+	var list []*model.CategoryModerator
+	if cc.Cache.get(KindCategory, key, &list) {
+		return list, nil
+	}
+
+	list, err := cc.CategoryStore.ListModerators()
+	if err != nil {
+		return nil, err
+	}
+
+	cc.Cache.set(key, list, categoryIndexKey)
+	return list, nil
+}
+
+func (cc *CategoryCache) Create(frontId, name, describe string, authorId int) (int, error) {
+	id, err := cc.CategoryStore.Create(frontId, name, describe, authorId)
+	if err != nil {
+		return 0, err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return id, nil
+}
+
+func (cc *CategoryCache) Update(frontId, name, describe string) (int, error) {
+	id, err := cc.CategoryStore.Update(frontId, name, describe)
+	if err != nil {
+		return 0, err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return id, nil
+}
+
+func (cc *CategoryCache) Approval(frontId string, pass bool, comment string, reviewerId int) error {
+	err := cc.CategoryStore.Approval(frontId, pass, comment, reviewerId)
+	if err != nil {
+		return err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return nil
+}
+
+func (cc *CategoryCache) Delete(frontId string) error {
+	err := cc.CategoryStore.Delete(frontId)
+	if err != nil {
+		return err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return nil
+}
+
+func (cc *CategoryCache) AddModerator(frontId string, userId int) error {
+	err := cc.CategoryStore.AddModerator(frontId, userId)
+	if err != nil {
+		return err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return nil
+}
+
+func (cc *CategoryCache) RemoveModerator(frontId string, userId int) error {
+	err := cc.CategoryStore.RemoveModerator(frontId, userId)
+	if err != nil {
+		return err
+	}
+
+	cc.Cache.invalidate(categoryIndexKey)
+	return nil
+}
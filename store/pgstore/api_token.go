@@ -0,0 +1,128 @@
+package pgstore
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type ApiToken struct {
+	dbPool *pgxpool.Pool
+}
+
+func (t *ApiToken) List(userId int) ([]*model.ApiToken, error) {
+	sqlStr := `SELECT id, user_id, name, token_prefix, permissions, created_at, last_used_at
+FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC`
+
+	rows, err := t.dbPool.Query(context.Background(), sqlStr, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.ApiToken
+	for rows.Next() {
+		var item model.ApiToken
+		err := rows.Scan(
+			&item.Id,
+			&item.UserId,
+			&item.Name,
+			&item.Prefix,
+			&item.Permissions,
+			&item.CreatedAt,
+			&item.NullLastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.FormatNullVals()
+		list = append(list, &item)
+	}
+
+	return list, nil
+}
+
+func (t *ApiToken) Create(userId int, name, tokenHash, prefix string, permissions []string) (int, error) {
+	var id int
+	err := t.dbPool.QueryRow(
+		context.Background(),
+		`INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, permissions) VALUES ($1, $2, $3, $4, $5) RETURNING (id)`,
+		userId,
+		name,
+		tokenHash,
+		prefix,
+		permissions,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (t *ApiToken) ItemWithHash(tokenHash string) (*model.ApiToken, error) {
+	sqlStr := `SELECT id, user_id, name, token_prefix, permissions, created_at, last_used_at
+FROM api_tokens
+WHERE token_hash = $1`
+
+	var item model.ApiToken
+	err := t.dbPool.QueryRow(context.Background(), sqlStr, tokenHash).Scan(
+		&item.Id,
+		&item.UserId,
+		&item.Name,
+		&item.Prefix,
+		&item.Permissions,
+		&item.CreatedAt,
+		&item.NullLastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	item.FormatNullVals()
+
+	return &item, nil
+}
+
+func (t *ApiToken) Count(userId int) (int, error) {
+	var total int
+	err := t.dbPool.QueryRow(context.Background(), `SELECT COUNT(*) FROM api_tokens WHERE user_id = $1`, userId).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (t *ApiToken) Delete(id, userId int) (int, error) {
+	var deletedId int
+	err := t.dbPool.QueryRow(
+		context.Background(),
+		`DELETE FROM api_tokens WHERE id = $1 AND user_id = $2 RETURNING (id)`,
+		id,
+		userId,
+	).Scan(&deletedId)
+	if err != nil {
+		return 0, err
+	}
+	return deletedId, nil
+}
+
+func (t *ApiToken) DeleteAll(userId int) error {
+	_, err := t.dbPool.Exec(context.Background(), `DELETE FROM api_tokens WHERE user_id = $1`, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ApiToken) UpdateLastUsed(id int) error {
+	_, err := t.dbPool.Exec(context.Background(), `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
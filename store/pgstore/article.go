@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,7 +25,8 @@ type Article struct {
 func (a *Article) List(
 	page, pageSize int,
 	sortType model.ArticleSortType,
-	categoryFrontId string,
+	categoryFrontId, tagFrontId string,
+	ignoreUserId int,
 	pinned, deleted, includeReplies bool,
 	keywords string,
 ) ([]*model.Article, int, error) {
@@ -73,6 +76,16 @@ WITH postIds AS (
 		sqlStr += fmt.Sprintf(" LEFT JOIN categories c ON c.front_id = $%d ", len(args))
 	}
 
+	if strings.TrimSpace(tagFrontId) != "" {
+		args = append(args, tagFrontId)
+		conditions = append(conditions, tagFilterSql(len(args)))
+	}
+
+	if ignoreUserId > 0 {
+		args = append(args, ignoreUserId)
+		conditions = append(conditions, categoryIgnoreSql(len(args)))
+	}
+
 	if deleted {
 		conditions = append(conditions, `p.deleted = true`)
 	} else {
@@ -105,7 +118,7 @@ WITH postIds AS (
     OFFSET $1
     LIMIT $2
 )
-SELECT tp.id, tp.title, COALESCE(tp.url, ''), u.username as author_name, tp.author_id, tp.content, tp.created_at, tp.updated_at, tp.depth, tp.list_weight, tp.reply_weight, tp.participate_count, p2.title as root_article_title, COUNT(p3.id) AS total_reply_count, tp.locked, tp.pinned_expire_at, COALESCE(tp.blocked_regions, ''), tp.fade_out,
+SELECT tp.id, tp.title, COALESCE(tp.url, ''), u.username as author_name, tp.author_id, tp.content, tp.created_at, tp.updated_at, tp.depth, tp.list_weight, tp.reply_weight, tp.participate_count, p2.title as root_article_title, COUNT(p3.id) AS total_reply_count, tp.locked, tp.pinned_expire_at, COALESCE(tp.blocked_regions, ''), tp.fade_out, tp.content_format,
 
 (
 SELECT COUNT(post_id) FROM post_votes
@@ -172,6 +185,7 @@ GROUP BY tp.id, u.username, p2.title, pi.total, c.id` + orderSqlStrTail
 			&item.NullPinnedExpireAt,
 			&blockedRegions,
 			&item.FadeOut,
+			&item.ContentFormat,
 			&item.VoteUp,
 			&item.VoteDown,
 			&total,
@@ -198,14 +212,348 @@ GROUP BY tp.id, u.username, p2.title, pi.total, c.id` + orderSqlStrTail
 	for _, item := range list {
 		item.CalcScore()
 		item.FormatNullValues()
-		item.UpdateDisplayTitle()
 		item.GenSummary(200)
 		item.UpdatePinnedState()
 	}
 
+	err = a.fillTags(list)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	return list, total, nil
 }
 
+// Condition of posts which have the tag, n is the index of tag front id in args
+func tagFilterSql(n int) string {
+	return fmt.Sprintf(`EXISTS (
+  SELECT 1 FROM post_tags pt
+  JOIN tags t ON t.id = pt.tag_id
+  WHERE pt.post_id = p.id AND t.front_id = $%d AND t.deleted = false
+)`, n)
+}
+
+// Condition of posts which are not in the categories ignored by the user, n is
+// the index of user id in args
+func categoryIgnoreSql(n int) string {
+	return fmt.Sprintf(`NOT EXISTS (
+  SELECT 1 FROM category_ignores ci
+  WHERE ci.category_id = p.category_id AND ci.user_id = $%d
+)`, n)
+}
+
+func (a *Article) fillTags(list []*model.Article) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var ids []int
+	listMap := make(map[int]*model.Article)
+	for _, item := range list {
+		ids = append(ids, item.Id)
+		listMap[item.Id] = item
+	}
+
+	rows, err := a.dbPool.Query(context.Background(), `SELECT pt.post_id, t.id, t.front_id, t.name
+FROM post_tags pt
+JOIN tags t ON t.id = pt.tag_id
+WHERE pt.post_id = ANY($1) AND t.deleted = false
+ORDER BY pt.id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postId int
+		var tag model.Tag
+		err := rows.Scan(&postId, &tag.Id, &tag.FrontId, &tag.Name)
+		if err != nil {
+			return err
+		}
+
+		if item, ok := listMap[postId]; ok {
+			item.Tags = append(item.Tags, &tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (a *Article) fillMentions(list []*model.Article) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var ids []int
+	listMap := make(map[int]*model.Article)
+	for _, item := range list {
+		ids = append(ids, item.Id)
+		listMap[item.Id] = item
+	}
+
+	rows, err := a.dbPool.Query(context.Background(), `SELECT pm.post_id, u.username
+FROM post_mentions pm
+JOIN users u ON u.id = pm.user_id
+WHERE pm.post_id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postId int
+		var username string
+		err := rows.Scan(&postId, &username)
+		if err != nil {
+			return err
+		}
+
+		if item, ok := listMap[postId]; ok {
+			item.Mentions = append(item.Mentions, username)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (a *Article) fillAttachments(list []*model.Article) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var ids []int
+	listMap := make(map[int]*model.Article)
+	for _, item := range list {
+		ids = append(ids, item.Id)
+		listMap[item.Id] = item
+	}
+
+	attachments, err := (&Attachment{a.dbPool}).List(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if item, ok := listMap[attachment.ArticleId]; ok {
+			item.Attachments = append(item.Attachments, attachment)
+		}
+	}
+
+	return nil
+}
+
+// Fill the fetched preview of the article link, nothing is filled if it has
+// not been fetched yet
+func (a *Article) fillLinkPreview(article *model.Article) error {
+	if article.Link == "" {
+		return nil
+	}
+
+	preview, err := (&LinkPreview{a.dbPool}).Item(article.Link)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	article.LinkPreview = preview
+	return nil
+}
+
+// Fill the poll of the article, nothing is filled if the article is not a poll
+func (a *Article) fillPoll(article *model.Article, loginedUserId int) error {
+	poll, err := (&Poll{a.dbPool}).Item(article.Id, loginedUserId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	article.Poll = poll
+	return nil
+}
+
+const (
+	searchTitleHeadlineOpts   = `HighlightAll=true, StartSel=<mark>, StopSel=</mark>`
+	searchContentHeadlineOpts = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "`
+)
+
+func (a *Article) Search(query *model.SearchQuery, page, pageSize int) ([]*model.Article, int, error) {
+	if page < 1 {
+		page = DefaultPage
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	args := []any{pageSize * (page - 1), pageSize, query.Lang.TsConfig(), query.Keywords}
+	conditions := []string{`p.deleted = false`}
+	matchCondition := `p.search_vector @@ q.query`
+	rankExpr := `ts_rank(p.search_vector, q.query)`
+
+	// Texts without spaces are parsed into long words which seldom match the query,
+	// so fall back to substring matching for these languages
+	if query.Lang.IsCJK() {
+		var likeConditions []string
+		for _, kw := range query.KeywordList() {
+			args = append(args, "%"+escapeLikePattern(html.EscapeString(kw))+"%")
+			likeConditions = append(likeConditions, fmt.Sprintf("(p.title ILIKE $%d OR p.content ILIKE $%d)", len(args), len(args)))
+			rankExpr += fmt.Sprintf(" + (CASE WHEN p.title ILIKE $%d THEN 0.2 ELSE 0 END) + (CASE WHEN p.content ILIKE $%d THEN 0.1 ELSE 0 END)", len(args), len(args))
+		}
+
+		if len(likeConditions) > 0 {
+			matchCondition = fmt.Sprintf("(%s OR (%s))", matchCondition, strings.Join(likeConditions, " AND "))
+		}
+	}
+	conditions = append(conditions, matchCondition)
+
+	sqlStr := `
+WITH postIds AS (
+    SELECT p.id, ` + rankExpr + ` AS rank, COUNT(p.id) OVER() AS total
+    FROM posts p
+    CROSS JOIN websearch_to_tsquery($3::regconfig, $4) q`
+
+	if query.CategoryFrontId != "" {
+		args = append(args, query.CategoryFrontId)
+		sqlStr += fmt.Sprintf(" JOIN categories c ON c.id = p.category_id AND c.front_id = $%d", len(args))
+	}
+
+	if query.AuthorName != "" {
+		args = append(args, query.AuthorName)
+		sqlStr += fmt.Sprintf(" JOIN users u ON u.id = p.author_id AND u.username = $%d", len(args))
+	}
+
+	switch query.Type {
+	case model.SearchTypeArticle:
+		conditions = append(conditions, `p.reply_to = 0`)
+	case model.SearchTypeReply:
+		conditions = append(conditions, `p.reply_to <> 0`)
+	}
+
+	if !query.StartDate.IsZero() {
+		args = append(args, query.StartDate)
+		conditions = append(conditions, fmt.Sprintf("p.created_at >= $%d", len(args)))
+	}
+
+	if !query.EndDate.IsZero() {
+		args = append(args, query.EndDate.AddDate(0, 0, 1))
+		conditions = append(conditions, fmt.Sprintf("p.created_at < $%d", len(args)))
+	}
+
+	sqlStr += ` WHERE ` + strings.Join(conditions, " AND ") + `
+    ORDER BY rank DESC, p.created_at DESC
+    OFFSET $1
+    LIMIT $2
+)
+SELECT tp.id, tp.title, COALESCE(tp.url, ''), u.username as author_name, tp.author_id, tp.content, tp.created_at, tp.updated_at, tp.depth, tp.reply_to, tp.root_article_id, p2.title as root_article_title, tp.locked, COALESCE(tp.blocked_regions, ''), tp.fade_out,
+(
+SELECT COUNT(post_id) FROM post_votes
+WHERE post_id = tp.id AND type = 'up'
+) AS vote_up,
+(
+SELECT COUNT(post_id) FROM post_votes
+WHERE post_id = tp.id AND type = 'down'
+) AS vote_down,
+pi.total AS total,
+pi.rank AS rank,
+c.id AS category_id,
+c.front_id AS category_front_id,
+c.name AS category_name,
+ts_headline($3::regconfig, COALESCE(tp.title, ''), q.query, '` + searchTitleHeadlineOpts + `') AS title_headline,
+ts_headline($3::regconfig, COALESCE(tp.content, ''), q.query, '` + searchContentHeadlineOpts + `') AS content_headline
+FROM posts tp
+JOIN postIds pi ON tp.id = pi.id
+CROSS JOIN websearch_to_tsquery($3::regconfig, $4) q
+LEFT JOIN posts p2 ON tp.root_article_id = p2.id
+LEFT JOIN users u ON u.id = tp.author_id
+LEFT JOIN categories c ON c.id = tp.category_id
+ORDER BY pi.rank DESC, tp.created_at DESC`
+
+	rows, err := a.dbPool.Query(context.Background(), sqlStr, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	keywords := query.KeywordList()
+
+	var total int
+	var list []*model.Article
+	for rows.Next() {
+		var category model.Category
+		item := model.Article{
+			Category: &category,
+		}
+		var blockedRegions, titleHeadline, contentHeadline string
+
+		err := rows.Scan(
+			&item.Id,
+			&item.NullTitle,
+			&item.Link,
+			&item.AuthorName,
+			&item.AuthorId,
+			&item.Content,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.ReplyDepth,
+			&item.ReplyToId,
+			&item.ReplyRootArticleId,
+			&item.NullReplyRootArticleTitle,
+			&item.Locked,
+			&blockedRegions,
+			&item.FadeOut,
+			&item.VoteUp,
+			&item.VoteDown,
+			&total,
+			&item.SearchRank,
+
+			&category.Id,
+			&category.FrontId,
+			&category.Name,
+			&titleHeadline,
+			&contentHeadline,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		item.CategoryFrontId = category.FrontId
+
+		if blockedRegions != "" {
+			item.BlockedRegionsISOCode = strings.Split(blockedRegions, ",")
+		}
+
+		item.CalcScore()
+		item.FormatNullValues()
+		item.GenSummary(200)
+
+		if strings.Contains(titleHeadline, model.HighlightStartSel) {
+			item.HighlightTitle = titleHeadline
+		} else if query.Lang.IsCJK() {
+			item.HighlightTitle = model.HighlightKeywords(item.Title, keywords, utf8.RuneCountInString(item.Title))
+		}
+
+		if strings.Contains(contentHeadline, model.HighlightStartSel) {
+			item.HighlightContent = contentHeadline
+		} else if query.Lang.IsCJK() {
+			item.HighlightContent = model.HighlightKeywords(item.Content, keywords, 200)
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, total, nil
+}
+
+func escapeLikePattern(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(str)
+}
+
 func (a *Article) ListUserState(ids []int, userId int) ([]*model.Article, error) {
 	sqlStr := `
 SELECT p.id,
@@ -239,17 +587,27 @@ WHERE p.id = ANY($2)
 	return list, nil
 }
 
-func (a *Article) Count(frontId string, includePinned bool) (int, error) {
+func (a *Article) Count(categoryFrontId, tagFrontId string, ignoreUserId int, includePinned bool) (int, error) {
 	var count int
 	var args []any
 	sqlStr := `SELECT COUNT(*) FROM posts p WHERE p.reply_to = 0 AND p.deleted = false`
-	if frontId != "" {
-		args = append(args, frontId)
+	if categoryFrontId != "" {
+		args = append(args, categoryFrontId)
 		sqlStr = `SELECT COUNT(p.*) FROM posts p
 LEFT JOIN categories c ON c.front_id = $1
 WHERE p.reply_to = 0 AND p.deleted = false AND p.category_id = c.id`
 	}
 
+	if tagFrontId != "" {
+		args = append(args, tagFrontId)
+		sqlStr += ` AND ` + tagFilterSql(len(args))
+	}
+
+	if ignoreUserId > 0 {
+		args = append(args, ignoreUserId)
+		sqlStr += ` AND ` + categoryIgnoreSql(len(args))
+	}
+
 	if !includePinned {
 		sqlStr += ` AND (p.pinned_expire_at IS NULL OR p.pinned_expire_at <= NOW())`
 	}
@@ -521,7 +879,8 @@ func (a *Article) UpdateReply(id int, content string, pinnedExpireAt time.Time,
 
 func (a *Article) Item(id, userId int) (*model.Article, error) {
 	sqlStr := `
-SELECT p.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p2.title as root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out,
+SELECT p.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p2.title as root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out, p.content_format,
+COALESCE(p.duplicate_of, 0), COALESCE(p4.title, ''),
 
 COUNT(DISTINCT p3.id) AS children_count,
 COUNT(DISTINCT pv1.id) AS vote_up_count,
@@ -568,8 +927,9 @@ LEFT JOIN reacts r ON r.id = pr.react_id
 LEFT JOIN post_reacts pr2 ON pr2.post_id = p.id AND pr2.user_id = $2
 LEFT JOIN reacts r2 ON r2.id = pr2.react_id
 LEFT JOIN categories c ON c.id = p.category_id
+LEFT JOIN posts p4 ON p4.id = p.duplicate_of
 WHERE p.id = $1
-GROUP BY p.id, p.title, p.url, u.username, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p2.title, pv.type, pr.id, r.id,  c.id, r2.id;`
+GROUP BY p.id, p.title, p.url, u.username, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p2.title, p4.title, pv.type, pr.id, r.id,  c.id, r2.id;`
 
 	var article *model.Article
 	rows, err := a.dbPool.Query(context.Background(), sqlStr, id, userId)
@@ -606,6 +966,9 @@ GROUP BY p.id, p.title, p.url, u.username, p.author_id, p.content, p.created_at,
 			&item.NullPinnedExpireAt,
 			&blockedRegions,
 			&item.FadeOut,
+			&item.ContentFormat,
+			&item.DuplicateOfId,
+			&item.DuplicateOfTitle,
 
 			&item.ChildrenCount,
 			&item.VoteUp,
@@ -662,8 +1025,34 @@ GROUP BY p.id, p.title, p.url, u.username, p.author_id, p.content, p.created_at,
 		article.CalcScore()
 		article.CheckShowScore(userId)
 		article.UpdatePinnedState()
-		article.UpdateDisplayTitle()
 		article.GenSummary(100)
+
+		if article.ReplyToId == 0 {
+			err = a.fillTags([]*model.Article{article})
+			if err != nil {
+				return nil, err
+			}
+
+			err = a.fillLinkPreview(article)
+			if err != nil {
+				return nil, err
+			}
+
+			err = a.fillPoll(article, userId)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = a.fillMentions([]*model.Article{article})
+		if err != nil {
+			return nil, err
+		}
+
+		err = a.fillAttachments([]*model.Article{article})
+		if err != nil {
+			return nil, err
+		}
 		return article, nil
 	}
 }
@@ -715,7 +1104,7 @@ WITH RECURSIVE articleTree AS (
     SELECT p.*, ROW_NUMBER() OVER (PARTITION BY p.reply_to ` + orderSqlStrTail + `) AS rn
     FROM articleTree p
 )
-SELECT ar.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p.reply_weight, p2.title AS root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out,
+SELECT ar.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p.reply_weight, p2.title AS root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out, p.content_format,
 COUNT(DISTINCT p3.id) AS children_count,
 COUNT(DISTINCT pv1.id) AS vote_up_count,
 COUNT(DISTINCT pv2.id) AS vote_down_count,
@@ -788,6 +1177,7 @@ GROUP BY ar.id, p.id, p.title, p.url, u.username, p.author_id, p.content, p.crea
 			&item.NullPinnedExpireAt,
 			&blockedRegions,
 			&item.FadeOut,
+			&item.ContentFormat,
 			&item.ChildrenCount,
 
 			&item.VoteUp,
@@ -845,10 +1235,19 @@ GROUP BY ar.id, p.id, p.title, p.url, u.username, p.author_id, p.content, p.crea
 		item.FormatReactCounts()
 		item.CalcScore()
 		item.UpdatePinnedState()
-		item.UpdateDisplayTitle()
 		item.GenSummary(100)
 	}
 
+	err = a.fillMentions(list)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.fillAttachments(list)
+	if err != nil {
+		return nil, err
+	}
+
 	return list, nil
 }
 
@@ -899,7 +1298,7 @@ WITH RECURSIVE articleTree AS (
     OFFSET $2
     LIMIT $3
 )
-SELECT ar.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p.reply_weight, p2.title AS root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out,
+SELECT ar.id, p.title, COALESCE(p.url, ''), u.username AS author_name, p.author_id, p.content, p.created_at, p.updated_at, p.deleted, p.reply_to, p.depth, p.root_article_id, p.reply_weight, p2.title AS root_article_title, p.locked, p.pinned_expire_at, COALESCE(p.blocked_regions, ''), p.fade_out, p.content_format,
 COUNT(DISTINCT p3.id) AS children_count,
 COUNT(DISTINCT pv1.id) AS vote_up_count,
 COUNT(DISTINCT pv2.id) AS vote_down_count,
@@ -986,6 +1385,7 @@ GROUP BY ar.id, p.id, p.title, p.url, u.username, p.author_id, p.content, p.crea
 			&item.NullPinnedExpireAt,
 			&blockedRegions,
 			&item.FadeOut,
+			&item.ContentFormat,
 			&item.ChildrenCount,
 
 			&item.VoteUp,
@@ -1060,10 +1460,19 @@ GROUP BY ar.id, p.id, p.title, p.url, u.username, p.author_id, p.content, p.crea
 		item.FormatReactCounts()
 		item.CalcScore()
 		item.UpdatePinnedState()
-		item.UpdateDisplayTitle()
 		item.GenSummary(100)
 	}
 
+	err = a.fillMentions(list)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.fillAttachments(list)
+	if err != nil {
+		return nil, err
+	}
+
 	return list, nil
 }
 
@@ -1506,7 +1915,10 @@ WITH RECURSIVE parentPosts AS (
 )
 INSERT INTO messages (sender_id, reciever_id, source_article_id, content_id, type)
 SELECT $1, ps.user_id, pp.id, $3, 'reply' FROM parentPosts pp
-INNER JOIN post_subs ps ON ps.post_id = pp.id AND ps.user_id != $1;
+INNER JOIN post_subs ps ON ps.post_id = pp.id AND ps.user_id != $1
+WHERE NOT EXISTS (
+  SELECT 1 FROM user_blocks ub WHERE ub.user_id = ps.user_id AND ub.target_user_id = $1
+);
 `
 	_, err := a.dbPool.Exec(context.Background(), sqlStr, senderUserId, sourceArticleId, contentArticleId)
 
@@ -1517,6 +1929,66 @@ INNER JOIN post_subs ps ON ps.post_id = pp.id AND ps.user_id != $1;
 	return nil
 }
 
+// Mention replaces the mentioned users of the article, returns the ids of the
+// users who are newly mentioned
+func (a *Article) Mention(id int, userIds []int) ([]int, error) {
+	if userIds == nil {
+		userIds = []int{}
+	}
+
+	var newUserIds []int
+	err := pgx.BeginFunc(context.Background(), a.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `DELETE FROM post_mentions WHERE post_id = $1 AND NOT (user_id = ANY($2))`, id, userIds)
+		if err != nil {
+			return err
+		}
+
+		if len(userIds) == 0 {
+			return nil
+		}
+
+		rows, err := tx.Query(context.Background(), `INSERT INTO post_mentions (post_id, user_id)
+SELECT $1, unnest($2::int[])
+ON CONFLICT (post_id, user_id) DO NOTHING
+RETURNING user_id`, id, userIds)
+		if err != nil {
+			return err
+		}
+
+		newUserIds, err = pgx.CollectRows(rows, pgx.RowTo[int])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserIds, nil
+}
+
+// NotifyMention sends messages to the mentioned users, except the ones who
+// turned mention notifications off, blocked the sender, or already got a
+// message of the same content, e.g. reply notifications
+func (a *Article) NotifyMention(senderUserId, contentArticleId int, userIds []int) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	_, err := a.dbPool.Exec(context.Background(), `INSERT INTO messages (sender_id, reciever_id, source_article_id, content_id, type)
+SELECT $1, u.id, $2, $2, 'mention' FROM users u
+WHERE u.id = ANY($3) AND u.id != $1 AND u.mention_notify = true
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks ub WHERE ub.user_id = u.id AND ub.target_user_id = $1
+)
+AND NOT EXISTS (
+  SELECT 1 FROM messages m WHERE m.content_id = $2 AND m.reciever_id = u.id
+)`,
+		senderUserId,
+		contentArticleId,
+		userIds,
+	)
+	return err
+}
+
 func (a *Article) subscribeCheck(id, userId int) (error, bool) {
 	var count int
 	err := a.dbPool.QueryRow(
@@ -1606,8 +2078,23 @@ func (a *Article) SetAfterUpdateWeights(fn func() error) {
 	afterUpdateWeights = fn
 }
 
-func (a *Article) Tag(id int, tagFrontId string) error {
-	return nil
+func (a *Article) Tag(id int, tagFrontIds []string) error {
+	return pgx.BeginFunc(context.Background(), a.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `DELETE FROM post_tags WHERE post_id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(tagFrontIds) == 0 {
+			return nil
+		}
+
+		_, err = tx.Exec(context.Background(), `INSERT INTO post_tags (post_id, tag_id)
+SELECT $1, t.id FROM tags t
+WHERE t.front_id = ANY($2) AND t.deleted = false
+ON CONFLICT (post_id, tag_id) DO NOTHING`, id, tagFrontIds)
+		return err
+	})
 }
 
 func (a *Article) AddHistory(
@@ -1827,3 +2314,34 @@ func (a *Article) SetBlockRegions(articleId int, regions []string) error {
 	}
 	return nil
 }
+
+func (a *Article) UpdateNormalizedURL(id int, normalizedURL string) error {
+	_, err := a.dbPool.Exec(context.Background(), `UPDATE posts SET normalized_url = NULLIF($1, '') WHERE id = $2`,
+		normalizedURL,
+		id,
+	)
+	return err
+}
+
+func (a *Article) DuplicateLink(normalizedURL string, excludeId int) (int, error) {
+	var id int
+	err := a.dbPool.QueryRow(context.Background(), `SELECT id FROM posts
+WHERE normalized_url = $1 AND id <> $2 AND reply_to = 0 AND deleted = false AND duplicate_of IS NULL
+ORDER BY created_at
+LIMIT 1`,
+		normalizedURL,
+		excludeId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (a *Article) MarkDuplicate(id, originalId int) error {
+	_, err := a.dbPool.Exec(context.Background(), `UPDATE posts SET duplicate_of = NULLIF($1, 0) WHERE id = $2 AND reply_to = 0`,
+		originalId,
+		id,
+	)
+	return err
+}
@@ -0,0 +1,135 @@
+package pgstore
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type Attachment struct {
+	dbPool *pgxpool.Pool
+}
+
+func (at *Attachment) Create(item *model.Attachment) (int, error) {
+	var id int
+	err := at.dbPool.QueryRow(context.Background(), `INSERT INTO attachments (post_id, user_id, file_name, mime_type, size, storage_key, thumb_key, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)
+RETURNING (id)`,
+		item.ArticleId,
+		item.UserId,
+		item.FileName,
+		item.MimeType,
+		item.Size,
+		item.StorageKey,
+		item.ThumbKey,
+		item.Width,
+		item.Height,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+const attachmentFields = `a.id, a.post_id, a.user_id, a.file_name, a.mime_type, a.size, a.storage_key, COALESCE(a.thumb_key, ''), a.width, a.height, a.created_at`
+
+func scanAttachment(row interface{ Scan(dest ...any) error }, item *model.Attachment) error {
+	return row.Scan(
+		&item.Id,
+		&item.ArticleId,
+		&item.UserId,
+		&item.FileName,
+		&item.MimeType,
+		&item.Size,
+		&item.StorageKey,
+		&item.ThumbKey,
+		&item.Width,
+		&item.Height,
+		&item.CreatedAt,
+	)
+}
+
+// Item of attachment which is not deleted, and the article of it is not deleted
+func (at *Attachment) Item(id int) (*model.Attachment, error) {
+	var item model.Attachment
+	err := scanAttachment(at.dbPool.QueryRow(context.Background(), `SELECT `+attachmentFields+`
+FROM attachments a
+JOIN posts p ON p.id = a.post_id
+WHERE a.id = $1 AND a.deleted = false AND p.deleted = false`,
+		id,
+	), &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (at *Attachment) List(articleIds []int) ([]*model.Attachment, error) {
+	rows, err := at.dbPool.Query(context.Background(), `SELECT `+attachmentFields+`
+FROM attachments a
+WHERE a.post_id = ANY($1) AND a.deleted = false
+ORDER BY a.id`,
+		articleIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.Attachment
+	for rows.Next() {
+		var item model.Attachment
+		err := scanAttachment(rows, &item)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, rows.Err()
+}
+
+// Delete attachments of the article, returns the deleted ones to remove files
+// from storage
+func (at *Attachment) Delete(articleId int, ids []int) ([]*model.Attachment, error) {
+	rows, err := at.dbPool.Query(context.Background(), `UPDATE attachments a SET deleted = true
+WHERE a.post_id = $1 AND a.id = ANY($2) AND a.deleted = false
+RETURNING `+attachmentFields,
+		articleId,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.Attachment
+	for rows.Next() {
+		var item model.Attachment
+		err := scanAttachment(rows, &item)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, rows.Err()
+}
+
+// Total size of the undeleted attachments uploaded by the user
+func (at *Attachment) UsedSize(userId int) (int64, error) {
+	var size int64
+	err := at.dbPool.QueryRow(context.Background(), `SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $1 AND deleted = false`,
+		userId,
+	).Scan(&size)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
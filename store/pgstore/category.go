@@ -2,7 +2,6 @@ package pgstore
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/oodzchen/dproject/model"
@@ -13,18 +12,21 @@ type Category struct {
 }
 
 func (p *Category) List(state model.CategoryState) ([]*model.Category, error) {
-	sqlStr := `SELECT c.id, c.front_id, c.name, COALESCE(c.describe, ''), c.author_id, c.approved, COALESCE(c.approval_comment, ''), c.created_at, COUNT(DISTINCT p.id) AS total_post_count
+	sqlStr := `SELECT c.id, c.front_id, c.name, COALESCE(c.describe, ''), c.author_id, u.username, c.approved, COALESCE(c.approval_comment, ''), c.reviewed_at, c.created_at, COUNT(DISTINCT p.id) AS total_post_count
 FROM categories c
+LEFT JOIN users u ON u.id = c.author_id
 LEFT JOIN posts p ON p.category_id = c.id AND p.deleted = false AND p.reply_to = 0
-GROUP BY c.id`
+WHERE c.deleted = false`
 	switch state {
 	case model.CategoryStateApproved:
-		sqlStr += " WHERE approved = true "
+		sqlStr += " AND c.approved = true"
 	case model.CategoryStateUnapproved:
-		sqlStr += " WHERE approved = false "
+		sqlStr += " AND c.approved = false AND c.reviewed_at IS NULL"
+	case model.CategoryStateRejected:
+		sqlStr += " AND c.approved = false AND c.reviewed_at IS NOT NULL"
 	}
 
-	sqlStr += ` ORDER BY created_at DESC`
+	sqlStr += ` GROUP BY c.id, u.username ORDER BY c.created_at DESC`
 
 	rows, err := p.dbPool.Query(
 		context.Background(),
@@ -46,8 +48,10 @@ GROUP BY c.id`
 			&item.Name,
 			&item.Describe,
 			&item.AuthorId,
+			&item.AuthorName,
 			&item.Approved,
 			&item.ApprovalComment,
+			&item.ReviewedAt,
 			&item.CreatedAt,
 			&item.TotalArticleCount,
 		)
@@ -62,9 +66,10 @@ GROUP BY c.id`
 	return list, nil
 }
 
+// Create an unapproved category, conflicting with an existing one returns no rows
 func (p *Category) Create(frontId, name, describe string, authorId int) (int, error) {
 	var id int
-	err := p.dbPool.QueryRow(context.Background(), "INSERT INTO categories (front_id, name, describe, author_id) VALUES ($1, $2, $3, $4) RETURNING (id)",
+	err := p.dbPool.QueryRow(context.Background(), "INSERT INTO categories (front_id, name, describe, author_id) VALUES ($1, $2, $3, $4) ON CONFLICT (front_id) DO NOTHING RETURNING (id)",
 		frontId,
 		name,
 		describe,
@@ -78,7 +83,7 @@ func (p *Category) Create(frontId, name, describe string, authorId int) (int, er
 
 func (p *Category) Update(frontId, name, describe string) (int, error) {
 	var id int
-	err := p.dbPool.QueryRow(context.Background(), "UPDATE categories SET name = $1, describe = $2 WHERE front_id = $3 RETURNING (id)",
+	err := p.dbPool.QueryRow(context.Background(), "UPDATE categories SET name = $1, describe = $2 WHERE front_id = $3 AND deleted = false RETURNING (id)",
 		name,
 		describe,
 		frontId,
@@ -90,9 +95,6 @@ func (p *Category) Update(frontId, name, describe string) (int, error) {
 }
 
 func (p *Category) Item(frontId string, userId int) (*model.Category, error) {
-	fmt.Println("category front id:", frontId)
-	fmt.Println("user id:", userId)
-
 	var item model.Category
 	var userState model.CategoryUserState
 
@@ -102,16 +104,24 @@ c.front_id,
 c.name,
 COALESCE(c.describe, ''),
 c.author_id,
+u.username,
 c.approved,
 COALESCE(c.approval_comment, ''),
+c.reviewed_at,
 c.created_at,
 (
   SELECT EXISTS (
     SELECT 1 FROM category_subs cs WHERE cs.category_id = c.id AND cs.user_id = $2
   )
-) AS subscribed
+) AS subscribed,
+(
+  SELECT EXISTS (
+    SELECT 1 FROM category_ignores ci WHERE ci.category_id = c.id AND ci.user_id = $2
+  )
+) AS ignored
 FROM categories c
-WHERE c.front_id = $1`
+LEFT JOIN users u ON u.id = c.author_id
+WHERE c.front_id = $1 AND c.deleted = false`
 
 	// fmt.Println("category item sql:", sqlStr)
 
@@ -124,11 +134,14 @@ WHERE c.front_id = $1`
 		&item.Name,
 		&item.Describe,
 		&item.AuthorId,
+		&item.AuthorName,
 		&item.Approved,
 		&item.ApprovalComment,
+		&item.ReviewedAt,
 		&item.CreatedAt,
 
 		&userState.Subscribed,
+		&userState.Ignored,
 	)
 	if err != nil {
 		return nil, err
@@ -138,7 +151,19 @@ WHERE c.front_id = $1`
 	return &item, nil
 }
 
-func (p *Category) Approval(frontId string, pass bool, comment string) error {
+func (p *Category) Approval(frontId string, pass bool, comment string, reviewerId int) error {
+	var id int
+	err := p.dbPool.QueryRow(context.Background(), `UPDATE categories SET approved = $1, approval_comment = $2, reviewer_id = $3, reviewed_at = NOW()
+WHERE front_id = $4 AND deleted = false
+RETURNING (id)`,
+		pass,
+		comment,
+		reviewerId,
+		frontId,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -200,12 +225,85 @@ WHERE category_id = c.id AND user_id = $2`,
 	return nil, count > 0
 }
 
+func (c *Category) Ignore(frontId string, loginedUserId int) error {
+	var ignored bool
+	err := c.dbPool.QueryRow(
+		context.Background(),
+		`SELECT EXISTS (
+  SELECT 1 FROM category_ignores ci
+  JOIN categories c ON c.id = ci.category_id
+  WHERE c.front_id = $1 AND ci.user_id = $2
+)`,
+		frontId,
+		loginedUserId,
+	).Scan(&ignored)
+	if err != nil {
+		return err
+	}
+
+	sqlStr := `INSERT INTO category_ignores (category_id, user_id)
+SELECT c.id, $2 FROM categories c WHERE c.front_id = $1 AND c.deleted = false`
+
+	if ignored {
+		sqlStr = `DELETE FROM category_ignores
+WHERE category_id = (
+  SELECT c.id FROM categories c WHERE c.front_id = $1
+) AND user_id = $2`
+	}
+
+	_, err = c.dbPool.Exec(context.Background(), sqlStr, frontId, loginedUserId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Category) ListIgnored(userId int) ([]*model.Category, error) {
+	rows, err := c.dbPool.Query(context.Background(), `SELECT c.id, c.front_id, c.name, COALESCE(c.describe, ''), c.created_at
+FROM category_ignores ci
+JOIN categories c ON c.id = ci.category_id
+WHERE ci.user_id = $1 AND c.deleted = false
+ORDER BY ci.created_at DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.Category
+	for rows.Next() {
+		item := model.Category{
+			UserState: &model.CategoryUserState{Ignored: true},
+		}
+		err := rows.Scan(
+			&item.Id,
+			&item.FrontId,
+			&item.Name,
+			&item.Describe,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, nil
+}
+
 func (c *Category) Notify(sourceCateogryFrontId string, senderUserId, contentArticleId int) error {
 	sqlStr := `
 INSERT INTO messages (sender_id, reciever_id, source_category_id, content_id, type)
 SELECT $1, cs.user_id, c.id, $3, 'category' FROM category_subs cs
 LEFT JOIN categories c ON c.front_id = $2
 WHERE cs.category_id = c.id AND cs.user_id != $1
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks ub WHERE ub.user_id = cs.user_id AND ub.target_user_id = $1
+)
 `
 	_, err := c.dbPool.Exec(context.Background(), sqlStr, senderUserId, sourceCateogryFrontId, contentArticleId)
 
@@ -215,3 +313,62 @@ WHERE cs.category_id = c.id AND cs.user_id != $1
 
 	return nil
 }
+
+func (c *Category) ListModerators() ([]*model.CategoryModerator, error) {
+	rows, err := c.dbPool.Query(context.Background(), `SELECT c.front_id, u.id, u.username, cm.created_at
+FROM category_moderators cm
+JOIN categories c ON c.id = cm.category_id
+JOIN users u ON u.id = cm.user_id
+WHERE c.deleted = false
+ORDER BY cm.created_at`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.CategoryModerator
+	for rows.Next() {
+		var item model.CategoryModerator
+		err := rows.Scan(
+			&item.CategoryFrontId,
+			&item.UserId,
+			&item.UserName,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, nil
+}
+
+func (c *Category) AddModerator(frontId string, userId int) error {
+	_, err := c.dbPool.Exec(context.Background(), `INSERT INTO category_moderators (category_id, user_id)
+SELECT c.id, $2 FROM categories c WHERE c.front_id = $1 AND c.deleted = false
+ON CONFLICT (category_id, user_id) DO NOTHING`,
+		frontId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Category) RemoveModerator(frontId string, userId int) error {
+	_, err := c.dbPool.Exec(context.Background(), `DELETE FROM category_moderators
+WHERE category_id = (SELECT c.id FROM categories c WHERE c.front_id = $1) AND user_id = $2`,
+		frontId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
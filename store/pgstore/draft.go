@@ -0,0 +1,117 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type Draft struct {
+	dbPool *pgxpool.Pool
+}
+
+// Save the draft of the target, the old one is replaced, expired drafts of
+// the user are removed at the same time
+func (d *Draft) Save(item *model.Draft, expiredBefore time.Time) (int, error) {
+	var id int
+	err := pgx.BeginFunc(context.Background(), d.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `DELETE FROM drafts WHERE user_id = $1 AND updated_at < $2`,
+			item.UserId,
+			expiredBefore,
+		)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(context.Background(), `INSERT INTO drafts (user_id, target_type, target_id, title, url, content, category_front_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, target_type, target_id) DO UPDATE SET title = EXCLUDED.title, url = EXCLUDED.url, content = EXCLUDED.content, category_front_id = EXCLUDED.category_front_id, updated_at = NOW()
+RETURNING (id)`,
+			item.UserId,
+			item.TargetType,
+			item.TargetId,
+			item.Title,
+			item.Link,
+			item.Content,
+			item.CategoryFrontId,
+		).Scan(&id)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+const draftFields = `d.id, d.user_id, d.target_type, d.target_id, d.title, d.url, d.content, d.category_front_id, d.created_at, d.updated_at,
+COALESCE(NULLIF(p.title, ''), p3.title, '') AS target_title`
+
+const draftJoins = `LEFT JOIN posts p ON p.id = d.target_id AND p.deleted = false
+LEFT JOIN posts p3 ON p3.id = p.root_article_id`
+
+func scanDraft(row interface{ Scan(dest ...any) error }, item *model.Draft) error {
+	return row.Scan(
+		&item.Id,
+		&item.UserId,
+		&item.TargetType,
+		&item.TargetId,
+		&item.Title,
+		&item.Link,
+		&item.Content,
+		&item.CategoryFrontId,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&item.TargetTitle,
+	)
+}
+
+// Item of the draft updated after the time
+func (d *Draft) Item(userId int, targetType model.DraftTargetType, targetId int, updatedAfter time.Time) (*model.Draft, error) {
+	var item model.Draft
+	err := scanDraft(d.dbPool.QueryRow(context.Background(), `SELECT `+draftFields+`
+FROM drafts d
+`+draftJoins+`
+WHERE d.user_id = $1 AND d.target_type = $2 AND d.target_id = $3 AND d.updated_at >= $4`,
+		userId,
+		targetType,
+		targetId,
+		updatedAfter,
+	), &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// Drafts of the user updated after the time, the latest first
+func (d *Draft) List(userId int, updatedAfter time.Time) ([]*model.Draft, error) {
+	rows, err := d.dbPool.Query(context.Background(), `SELECT `+draftFields+`
+FROM drafts d
+`+draftJoins+`
+WHERE d.user_id = $1 AND d.updated_at >= $2
+ORDER BY d.updated_at DESC`,
+		userId,
+		updatedAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Draft, error) {
+		var item model.Draft
+		err := scanDraft(row, &item)
+		return &item, err
+	})
+}
+
+func (d *Draft) Delete(userId int, targetType model.DraftTargetType, targetId int) error {
+	_, err := d.dbPool.Exec(context.Background(), `DELETE FROM drafts WHERE user_id = $1 AND target_type = $2 AND target_id = $3`,
+		userId,
+		targetType,
+		targetId,
+	)
+	return err
+}
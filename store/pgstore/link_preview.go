@@ -0,0 +1,52 @@
+package pgstore
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type LinkPreview struct {
+	dbPool *pgxpool.Pool
+}
+
+func (lp *LinkPreview) Item(url string) (*model.LinkPreview, error) {
+	var item model.LinkPreview
+	err := lp.dbPool.QueryRow(context.Background(), `SELECT id, url, title, description, image_url, site_name, fetched_at
+FROM link_previews
+WHERE url = $1`,
+		url,
+	).Scan(
+		&item.Id,
+		&item.URL,
+		&item.Title,
+		&item.Description,
+		&item.ImageURL,
+		&item.SiteName,
+		&item.FetchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// Save preview of the url, the old one is replaced
+func (lp *LinkPreview) Save(item *model.LinkPreview) (int, error) {
+	var id int
+	err := lp.dbPool.QueryRow(context.Background(), `INSERT INTO link_previews (url, title, description, image_url, site_name) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (url) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description, image_url = EXCLUDED.image_url, site_name = EXCLUDED.site_name, fetched_at = NOW()
+RETURNING (id)`,
+		item.URL,
+		item.Title,
+		item.Description,
+		item.ImageURL,
+		item.SiteName,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -14,7 +14,7 @@ type Message struct {
 }
 
 func (m *Message) List(userId int, status string, page, pageSize int) ([]*model.Message, int, error) {
-	sqlStr := `SELECT m.id, m.sender_id, u.username AS sender_name, m.reciever_id, u1.username AS reciever_name, m.created_at, m.is_read, m.type,
+	sqlStr := `SELECT m.id, m.sender_id, u.username AS sender_name, m.reciever_id, u1.username AS reciever_name, m.created_at, m.is_read, m.type, COALESCE(m.action, ''), COALESCE(m.content, ''),
 COALESCE(p.id, 0),
 COALESCE(p.title, ''),
 COALESCE(p.url, ''),
@@ -129,6 +129,8 @@ LEFT JOIN categories c ON c.id = m.source_category_id`
 			&item.CreatedAt,
 			&item.IsRead,
 			&item.Type,
+			&item.Action,
+			&item.Content,
 
 			&sourceArticle.Id,
 			&sourceArticle.Title,
@@ -208,6 +210,31 @@ LEFT JOIN categories c ON c.id = m.source_category_id`
 // 	return id, nil
 // }
 
+// CreateSystem sends a system message, zero source ids are stored as null
+func (m *Message) CreateSystem(senderUserId, recieverUserId int, action model.MessageAction, content string, sourceArticleId, sourceCategoryId int) (int, error) {
+	var id int
+	err := m.dbPool.QueryRow(
+		context.Background(),
+		`INSERT INTO messages
+(sender_id, reciever_id, source_article_id, source_category_id, type, action, content)
+VALUES
+($1, $2, NULLIF($3, 0), NULLIF($4, 0), 'system', $5, $6)
+RETURNING (id)`,
+		senderUserId,
+		recieverUserId,
+		sourceArticleId,
+		sourceCategoryId,
+		action,
+		content,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
 func (m *Message) Read(messageId int) error {
 	_, err := m.dbPool.Exec(context.Background(), `UPDATE messages SET is_read = true WHERE id = $1`, messageId)
 	if err != nil {
@@ -0,0 +1,343 @@
+package pgstore
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// Arbitrary key of the advisory lock which prevents migrating concurrently
+const migrationLockKey = 8_726_351_001
+
+var ErrMigrationPending = errors.New("database schema is behind, run `migrate up` first")
+
+var reMigrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+type MigrationStatus struct {
+	*Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	dbPool     *pgxpool.Pool
+	migrations []*Migration
+}
+
+func NewMigrator() (*Migrator, error) {
+	err := CheckDB(false)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := LoadMigrations(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{pgDB.Pool, migrations}, nil
+}
+
+// LoadMigrations reads migration files named like 000001_init_schema.up.sql
+// from the directory, every version must have both up and down file.
+func LoadMigrations(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationMap := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := reMigrationFile.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+		if version < 1 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := migrationMap[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			migrationMap[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("duplicate migration version: %d", version)
+		}
+
+		if matches[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	var list []*Migration
+	for _, m := range migrationMap {
+		if m.UpSQL == "" || m.DownSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s requires both up and down file", m.Version, m.Name)
+		}
+		list = append(list, m)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
+
+func (mg *Migrator) Latest() int {
+	if len(mg.migrations) == 0 {
+		return 0
+	}
+	return mg.migrations[len(mg.migrations)-1].Version
+}
+
+func (mg *Migrator) ensureTable(ctx context.Context) error {
+	_, err := mg.dbPool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`)
+	return err
+}
+
+func (mg *Migrator) Version() (int, error) {
+	ctx := context.Background()
+	err := mg.ensureTable(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = mg.dbPool.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (mg *Migrator) Status() ([]*MigrationStatus, error) {
+	ctx := context.Background()
+	err := mg.ensureTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := mg.dbPool.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedMap := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		err := rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		appliedMap[version] = appliedAt
+	}
+
+	var list []*MigrationStatus
+	for _, m := range mg.migrations {
+		appliedAt, ok := appliedMap[m.Version]
+		list = append(list, &MigrationStatus{
+			Migration: m,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return list, nil
+}
+
+// CheckPending returns ErrMigrationPending if any migration is not applied
+func (mg *Migrator) CheckPending() error {
+	version, err := mg.Version()
+	if err != nil {
+		return err
+	}
+
+	if version < mg.Latest() {
+		return fmt.Errorf("%w, current version %d, latest version %d", ErrMigrationPending, version, mg.Latest())
+	}
+	return nil
+}
+
+// Up applies all pending migrations
+func (mg *Migrator) Up() error {
+	return mg.To(mg.Latest())
+}
+
+// Down rolls back the last applied migration
+func (mg *Migrator) Down() error {
+	version, err := mg.Version()
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	target := 0
+	for _, m := range mg.migrations {
+		if m.Version < version {
+			target = m.Version
+		}
+	}
+
+	return mg.To(target)
+}
+
+// To migrates up or down to the specific version, 0 to roll back all
+func (mg *Migrator) To(target int) error {
+	if target != 0 && mg.find(target) == nil {
+		return fmt.Errorf("migration version %d does not exist", target)
+	}
+
+	ctx := context.Background()
+	err := mg.ensureTable(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := mg.dbPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey)
+	if err != nil {
+		return err
+	}
+	defer conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	// Read version after locked, in case other process migrated just now
+	var version int
+	err = conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	if target >= version {
+		for _, m := range mg.migrations {
+			if m.Version <= version || m.Version > target {
+				continue
+			}
+
+			fmt.Printf("applying migration %d_%s\n", m.Version, m.Name)
+			err = mg.run(ctx, conn.Conn(), m.UpSQL, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			if err != nil {
+				return fmt.Errorf("apply migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	}
+
+	for i := len(mg.migrations) - 1; i >= 0; i-- {
+		m := mg.migrations[i]
+		if m.Version > version || m.Version <= target {
+			continue
+		}
+
+		fmt.Printf("rolling back migration %d_%s\n", m.Version, m.Name)
+		err = mg.run(ctx, conn.Conn(), m.DownSQL, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+		if err != nil {
+			return fmt.Errorf("roll back migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Force marks versions up to the specific one as applied without running them,
+// used for databases created before the migrations existed
+func (mg *Migrator) Force(target int) error {
+	if target != 0 && mg.find(target) == nil {
+		return fmt.Errorf("migration version %d does not exist", target)
+	}
+
+	ctx := context.Background()
+	err := mg.ensureTable(ctx)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, mg.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `DELETE FROM schema_migrations`)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range mg.migrations {
+			if m.Version > target {
+				break
+			}
+
+			_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (mg *Migrator) run(ctx context.Context, conn *pgx.Conn, migrationSQL, recordSQL string, recordArgs ...any) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		// Migration files contain multiple statements, which are only
+		// allowed by the simple protocol
+		_, err := tx.Exec(ctx, migrationSQL, pgx.QueryExecModeSimpleProtocol)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, recordSQL, recordArgs...)
+		return err
+	})
+}
+
+func (mg *Migrator) find(version int) *Migration {
+	for _, m := range mg.migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package pgstore
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		desc         string
+		files        fstest.MapFS
+		wantVersions []int
+		wantErr      bool
+	}{
+		{
+			"sorted by version",
+			fstest.MapFS{
+				"m/000002_add_b.up.sql":   {Data: []byte("b up")},
+				"m/000002_add_b.down.sql": {Data: []byte("b down")},
+				"m/000001_add_a.up.sql":   {Data: []byte("a up")},
+				"m/000001_add_a.down.sql": {Data: []byte("a down")},
+			},
+			[]int{1, 2},
+			false,
+		},
+		{
+			"down file is required",
+			fstest.MapFS{
+				"m/000001_add_a.up.sql": {Data: []byte("a up")},
+			},
+			nil,
+			true,
+		},
+		{
+			"duplicate version",
+			fstest.MapFS{
+				"m/000001_add_a.up.sql":   {Data: []byte("a up")},
+				"m/000001_add_a.down.sql": {Data: []byte("a down")},
+				"m/000001_add_b.up.sql":   {Data: []byte("b up")},
+				"m/000001_add_b.down.sql": {Data: []byte("b down")},
+			},
+			nil,
+			true,
+		},
+		{
+			"invalid file name",
+			fstest.MapFS{
+				"m/add_a.sql": {Data: []byte("a")},
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			list, err := LoadMigrations(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+
+			if len(list) != len(tt.wantVersions) {
+				t.Fatalf("want %d migrations, got %d", len(tt.wantVersions), len(list))
+			}
+
+			for i, m := range list {
+				if m.Version != tt.wantVersions[i] {
+					t.Errorf("want version %d at %d, got %d", tt.wantVersions[i], i, m.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := LoadMigrations(migrationFS, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range list {
+		if m.Version != i+1 {
+			t.Errorf("migration versions should be continuous, want %d, got %d", i+1, m.Version)
+		}
+	}
+}
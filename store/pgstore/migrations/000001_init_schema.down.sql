@@ -0,0 +1,9 @@
+DROP RULE IF EXISTS post_del_protect ON posts;
+DROP TABLE IF EXISTS post_votes;
+DROP TYPE IF EXISTS vote_type;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS category_ignores;
+DROP TABLE IF EXISTS category_subs;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
+DROP TYPE IF EXISTS auth_type;
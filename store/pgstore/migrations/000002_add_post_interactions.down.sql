@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS messages;
+DROP TYPE IF EXISTS message_type;
+DROP TABLE IF EXISTS post_subs;
+DROP TABLE IF EXISTS post_reacts;
+DROP TABLE IF EXISTS reacts;
+DROP TABLE IF EXISTS post_saves;
+DROP TYPE IF EXISTS save_type;
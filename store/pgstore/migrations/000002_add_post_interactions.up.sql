@@ -0,0 +1,64 @@
+CREATE TYPE save_type AS ENUM ('fav');
+
+CREATE TABLE post_saves (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    post_id INTEGER REFERENCES posts(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    type save_type,
+    UNIQUE(user_id, post_id)
+);
+
+CREATE INDEX idx_post_saves_post_id ON post_saves (post_id);
+
+CREATE TABLE reacts (
+    id SERIAL PRIMARY KEY,
+    emoji VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    front_id VARCHAR(50) NOT NULL,
+    describe VARCHAR(255),
+    UNIQUE(front_id)
+);
+
+CREATE TABLE post_reacts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    post_id INTEGER REFERENCES posts(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    react_id INTEGER REFERENCES reacts(id) NOT NULL,
+    UNIQUE(user_id, post_id),
+    UNIQUE(user_id, post_id, react_id)
+);
+
+CREATE INDEX idx_post_reacts_post_id ON post_reacts (post_id);
+
+CREATE TABLE post_subs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    post_id INTEGER REFERENCES posts(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, post_id)
+);
+
+CREATE INDEX idx_post_subs_post_id ON post_subs (post_id);
+
+CREATE TYPE message_type AS ENUM ('reply', 'category', 'system');
+
+CREATE TABLE messages (
+    id SERIAL PRIMARY KEY,
+    sender_id INTEGER REFERENCES users(id) NOT NULL,
+    reciever_id INTEGER REFERENCES users(id) NOT NULL,
+    source_article_id INTEGER REFERENCES posts(id),
+    source_category_id INTEGER REFERENCES posts(id),
+    content_id INTEGER REFERENCES posts(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_read BOOLEAN NOT NULL DEFAULT false,
+    type message_type NOT NULL
+);
+
+INSERT INTO reacts (emoji, front_id, describe) VALUES
+('♥️', 'thanks', 'Thanks'),
+('😀', 'happy', 'Haha'),
+('😕', 'confused', 'Confuse'),
+('👀', 'eyes', 'Watching'),
+('🎉', 'party', 'Yeah');
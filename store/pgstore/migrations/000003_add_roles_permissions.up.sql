@@ -0,0 +1,45 @@
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    front_id VARCHAR(50) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE(front_id)
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    front_id VARCHAR(50) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    module VARCHAR(50) NOT NULL,
+    UNIQUE(front_id)
+);
+
+CREATE TABLE role_permissions (
+    id SERIAL PRIMARY KEY,
+    role_id INTEGER REFERENCES roles(id) NOT NULL,
+    permission_id INTEGER REFERENCES permissions(id) NOT NULL,
+    UNIQUE(role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    role_id INTEGER REFERENCES roles(id) NOT NULL,
+    UNIQUE(user_id)
+);
+
+CREATE TABLE activities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target_model VARCHAR(255),
+    target_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ip_address VARCHAR(255) NOT NULL,
+    device_info VARCHAR(255),
+    details TEXT
+);
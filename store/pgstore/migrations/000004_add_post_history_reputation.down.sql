@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS reputation_log;
+DROP TYPE IF EXISTS reputaion_change_type;
+ALTER TABLE users DROP COLUMN IF EXISTS reputation;
+DROP TABLE IF EXISTS post_history;
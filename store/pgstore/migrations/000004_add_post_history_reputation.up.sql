@@ -0,0 +1,29 @@
+CREATE TABLE post_history (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER REFERENCES posts(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    operator_id INTEGER REFERENCES users(id) NOT NULL,
+    curr TIMESTAMP NOT NULL,
+    prev TIMESTAMP NOT NULL,
+    version_num INTEGER NOT NULL,
+    title_delta TEXT,
+    url_delta TEXT,
+    content_delta TEXT,
+    category_front_delta TEXT
+);
+
+ALTER TABLE post_history ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE users ADD COLUMN reputation INTEGER NOT NULL DEFAULT 0;
+
+CREATE TYPE reputaion_change_type AS ENUM ('upvoted', 'downvoted', 'thanked', 'laughed', 'banned', 'fade_out', 'other');
+
+CREATE TABLE reputation_log (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    value_diff INTEGER NOT NULL,
+    type reputaion_change_type,
+    is_revert BOOLEAN NOT NULL DEFAULT false,
+    comment TEXT
+);
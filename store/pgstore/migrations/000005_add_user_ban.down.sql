@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS banned_count;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_day_num;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_start_at;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN banned_start_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN banned_day_num INTEGER;
+ALTER TABLE users ADD COLUMN banned_count INTEGER NOT NULL DEFAULT 0;
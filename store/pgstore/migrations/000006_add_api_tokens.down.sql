@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id IN ('user.access_messages', 'category.subscribe'));
+DELETE FROM permissions WHERE front_id IN ('user.access_messages', 'category.subscribe');
+
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,29 @@
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+
+INSERT INTO permissions (front_id, name, module)
+SELECT v.front_id, v.name, v.module FROM (VALUES
+  ('user.access_messages', 'Access Messages of Myself', 'user'),
+  ('category.subscribe', 'Subscribe or Ignore Category', 'category')
+) AS v(front_id, name, module)
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+-- Messages and category subscriptions were available to every user who can
+-- subscribe articles, tokens are scoped to them by these permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id FROM role_permissions rp
+JOIN permissions ep ON ep.id = rp.permission_id AND ep.front_id = 'article.subscribe'
+CROSS JOIN permissions p
+WHERE p.front_id IN ('user.access_messages', 'category.subscribe')
+ON CONFLICT (role_id, permission_id) DO NOTHING;
@@ -0,0 +1,10 @@
+-- Both english and simple lexemes are stored, so queries work with either
+-- configuration, simple is used for languages without a built-in parser
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(content, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(content, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
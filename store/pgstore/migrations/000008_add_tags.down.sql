@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id = 'tag.manage');
+DELETE FROM permissions WHERE front_id = 'tag.manage';
+
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
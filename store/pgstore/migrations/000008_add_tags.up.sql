@@ -0,0 +1,32 @@
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    front_id VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    describe TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    author_id INTEGER REFERENCES users(id) NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE(front_id)
+);
+
+CREATE TABLE post_tags (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE NOT NULL,
+    UNIQUE(post_id, tag_id)
+);
+
+CREATE INDEX idx_post_tags_tag_id ON post_tags (tag_id);
+
+-- Permissions are initialized from config/permissions.yml only when the table
+-- is empty, so grant the new one to existing databases here
+INSERT INTO permissions (front_id, name, module)
+SELECT 'tag.manage', 'Manage Tags', 'tag'
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.front_id IN ('moderator', 'admin') AND p.front_id = 'tag.manage'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
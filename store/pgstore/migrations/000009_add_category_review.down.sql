@@ -0,0 +1,13 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id IN ('category.propose', 'category.approve'));
+DELETE FROM permissions WHERE front_id IN ('category.propose', 'category.approve');
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_source_category_id_fkey;
+ALTER TABLE messages ADD CONSTRAINT messages_source_category_id_fkey FOREIGN KEY (source_category_id) REFERENCES posts(id) NOT VALID;
+
+DELETE FROM messages WHERE content_id IS NULL;
+ALTER TABLE messages DROP COLUMN IF EXISTS action;
+ALTER TABLE messages DROP COLUMN IF EXISTS content;
+ALTER TABLE messages ALTER COLUMN content_id SET NOT NULL;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS reviewer_id;
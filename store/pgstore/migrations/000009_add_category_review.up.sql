@@ -0,0 +1,33 @@
+ALTER TABLE categories ADD COLUMN reviewer_id INTEGER REFERENCES users(id);
+ALTER TABLE categories ADD COLUMN reviewed_at TIMESTAMP;
+
+UPDATE categories SET reviewed_at = created_at WHERE approved = true;
+
+-- System messages have no content article, the text is stored in content
+ALTER TABLE messages ALTER COLUMN content_id DROP NOT NULL;
+ALTER TABLE messages ADD COLUMN content TEXT;
+ALTER TABLE messages ADD COLUMN action VARCHAR(50);
+
+-- source_category_id referenced posts by mistake
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_source_category_id_fkey;
+ALTER TABLE messages ADD CONSTRAINT messages_source_category_id_fkey FOREIGN KEY (source_category_id) REFERENCES categories(id) NOT VALID;
+
+-- Permissions are initialized from config/permissions.yml only when the table
+-- is empty, so grant the new ones to existing databases here
+INSERT INTO permissions (front_id, name, module)
+SELECT v.front_id, v.name, 'category' FROM (VALUES
+  ('category.propose', 'Propose Category'),
+  ('category.approve', 'Approve Category')
+) AS v(front_id, name)
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.front_id IN ('common_user', 'moderator', 'admin') AND p.front_id = 'category.propose'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.front_id IN ('moderator', 'admin') AND p.front_id = 'category.approve'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
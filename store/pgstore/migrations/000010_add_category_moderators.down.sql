@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id IN ('article.fade_out', 'article.block_regions'));
+DELETE FROM permissions WHERE front_id IN ('article.fade_out', 'article.block_regions');
+
+DROP TABLE IF EXISTS category_moderators;
@@ -0,0 +1,26 @@
+CREATE TABLE category_moderators (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(category_id, user_id)
+);
+
+CREATE INDEX idx_category_moderators_user_id ON category_moderators (user_id);
+
+-- Fade out and block regions were checked with article.edit_others, they get
+-- their own permissions so that category moderators can be scoped to them
+INSERT INTO permissions (front_id, name, module)
+SELECT v.front_id, v.name, 'article' FROM (VALUES
+  ('article.fade_out', 'Fade Out Article'),
+  ('article.block_regions', 'Block Regions of Article')
+) AS v(front_id, name)
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id FROM role_permissions rp
+JOIN permissions ep ON ep.id = rp.permission_id AND ep.front_id = 'article.edit_others'
+CROSS JOIN permissions p
+WHERE p.front_id IN ('article.fade_out', 'article.block_regions')
+ON CONFLICT (role_id, permission_id) DO NOTHING;
@@ -0,0 +1,11 @@
+CREATE TABLE user_blocks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    target_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'block',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, target_user_id),
+    CHECK (user_id != target_user_id)
+);
+
+CREATE INDEX idx_user_blocks_target_user_id ON user_blocks (target_user_id);
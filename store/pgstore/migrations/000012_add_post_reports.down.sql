@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id IN ('article.report', 'article.resolve_report'));
+DELETE FROM permissions WHERE front_id IN ('article.report', 'article.resolve_report');
+
+DROP TABLE IF EXISTS post_reports;
@@ -0,0 +1,39 @@
+CREATE TABLE post_reports (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE NOT NULL,
+    reporter_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolver_id INTEGER REFERENCES users(id),
+    resolution VARCHAR(20),
+    resolved_at TIMESTAMP
+);
+
+-- One pending report of a post from each user
+CREATE UNIQUE INDEX idx_post_reports_pending ON post_reports (post_id, reporter_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_post_reports_resolved_at ON post_reports (resolved_at);
+
+INSERT INTO permissions (front_id, name, module)
+SELECT v.front_id, v.name, 'article' FROM (VALUES
+  ('article.report', 'Report Article'),
+  ('article.resolve_report', 'Resolve Reports of Article')
+) AS v(front_id, name)
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+-- Everyone who can reply is able to report, and the roles able to delete
+-- articles of others resolve the reports
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id FROM role_permissions rp
+JOIN permissions ep ON ep.id = rp.permission_id AND ep.front_id = 'article.reply'
+CROSS JOIN permissions p
+WHERE p.front_id = 'article.report'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id FROM role_permissions rp
+JOIN permissions ep ON ep.id = rp.permission_id AND ep.front_id = 'article.delete_others'
+CROSS JOIN permissions p
+WHERE p.front_id = 'article.resolve_report'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
@@ -0,0 +1,5 @@
+-- Existing posts are plain text, they keep rendering as before
+ALTER TABLE posts ADD COLUMN content_format VARCHAR(20) NOT NULL DEFAULT 'plain';
+
+-- Posts created from now on are written in markdown
+ALTER TABLE posts ALTER COLUMN content_format SET DEFAULT 'markdown';
@@ -0,0 +1,10 @@
+ALTER TABLE users DROP COLUMN IF EXISTS mention_notify;
+
+DROP TABLE IF EXISTS post_mentions;
+
+-- Enum values can not be dropped, so the type is recreated without 'mention'
+DELETE FROM messages WHERE type = 'mention';
+ALTER TYPE message_type RENAME TO message_type_old;
+CREATE TYPE message_type AS ENUM ('reply', 'category', 'system');
+ALTER TABLE messages ALTER COLUMN type TYPE message_type USING type::text::message_type;
+DROP TYPE message_type_old;
@@ -0,0 +1,13 @@
+ALTER TYPE message_type ADD VALUE IF NOT EXISTS 'mention';
+
+CREATE TABLE post_mentions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(post_id, user_id)
+);
+
+CREATE INDEX idx_post_mentions_user_id ON post_mentions (user_id);
+
+ALTER TABLE users ADD COLUMN mention_notify BOOLEAN NOT NULL DEFAULT true;
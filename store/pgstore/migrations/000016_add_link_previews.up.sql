@@ -0,0 +1,10 @@
+-- Metadata of the links of posts, shared by posts with the same link
+CREATE TABLE link_previews (
+    id SERIAL PRIMARY KEY,
+    url VARCHAR(255) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    image_url VARCHAR(1000) NOT NULL DEFAULT '',
+    site_name VARCHAR(100) NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
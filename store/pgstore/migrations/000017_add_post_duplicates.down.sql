@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE front_id = 'article.mark_duplicate');
+DELETE FROM permissions WHERE front_id = 'article.mark_duplicate';
+
+DROP INDEX IF EXISTS idx_posts_normalized_url;
+ALTER TABLE posts DROP COLUMN IF EXISTS duplicate_of;
+ALTER TABLE posts DROP COLUMN IF EXISTS normalized_url;
@@ -0,0 +1,30 @@
+-- Links normalized by utils.NormalizeURL to find duplicated submissions
+ALTER TABLE posts ADD COLUMN normalized_url TEXT;
+-- Set by moderators when the post is a duplicate of an older one
+ALTER TABLE posts ADD COLUMN duplicate_of INTEGER REFERENCES posts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_posts_normalized_url ON posts (normalized_url) WHERE normalized_url IS NOT NULL;
+
+-- Approximation for existing links, utm parameters are not removed and other
+-- parameters are not sorted
+UPDATE posts SET normalized_url = regexp_replace(
+  regexp_replace(
+    regexp_replace(replace(url, '&amp;', '&'), '#.*$', ''),
+    '^[a-zA-Z]+://(www\.)?', ''
+  ),
+  '/+(\?|$)', '\1'
+)
+WHERE url IS NOT NULL AND url <> '' AND reply_to = 0;
+
+INSERT INTO permissions (front_id, name, module)
+SELECT 'article.mark_duplicate', 'Mark Article as Duplicate', 'article'
+WHERE EXISTS (SELECT 1 FROM permissions)
+ON CONFLICT (front_id) DO NOTHING;
+
+-- Marking duplicates was part of editing articles of others
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id FROM role_permissions rp
+JOIN permissions ep ON ep.id = rp.permission_id AND ep.front_id = 'article.edit_others'
+CROSS JOIN permissions p
+WHERE p.front_id = 'article.mark_duplicate'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
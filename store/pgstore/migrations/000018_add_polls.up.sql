@@ -0,0 +1,28 @@
+CREATE TABLE polls (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE NOT NULL UNIQUE,
+    multiple BOOLEAN NOT NULL DEFAULT false,
+    anonymous BOOLEAN NOT NULL DEFAULT true,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE poll_options (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER REFERENCES polls(id) ON DELETE CASCADE NOT NULL,
+    content VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_poll_options_poll_id ON poll_options (poll_id);
+
+CREATE TABLE poll_votes (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER REFERENCES polls(id) ON DELETE CASCADE NOT NULL,
+    option_id INTEGER REFERENCES poll_options(id) ON DELETE CASCADE NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (option_id, user_id)
+);
+
+CREATE INDEX idx_poll_votes_poll_id_user_id ON poll_votes (poll_id, user_id);
@@ -0,0 +1,15 @@
+-- Enum values can not be dropped, so the type is recreated without 'oidc',
+-- which is refused if any user is registered with generic providers
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE auth_from = 'oidc') THEN
+        RAISE EXCEPTION 'users registered with oidc providers exist';
+    END IF;
+END $$;
+
+ALTER TABLE users ALTER COLUMN auth_from DROP DEFAULT;
+ALTER TYPE auth_type RENAME TO auth_type_old;
+CREATE TYPE auth_type AS ENUM ('self', 'google', 'github', 'microsoft');
+ALTER TABLE users ALTER COLUMN auth_from TYPE auth_type USING auth_from::text::auth_type;
+ALTER TABLE users ALTER COLUMN auth_from SET DEFAULT 'self';
+DROP TYPE auth_type_old;
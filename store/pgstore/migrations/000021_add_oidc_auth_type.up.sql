@@ -0,0 +1 @@
+ALTER TYPE auth_type ADD VALUE IF NOT EXISTS 'oidc';
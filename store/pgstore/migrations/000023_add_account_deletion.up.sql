@@ -0,0 +1,4 @@
+-- Accounts are deleted after the grace period since the deletion is requested
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_requested_at ON users (deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE username_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    old_name VARCHAR(255) NOT NULL,
+    new_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- Old name redirects to the user and can't be taken by others until then
+    redirect_expire_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_username_history_user_id ON username_history (user_id);
+CREATE INDEX idx_username_history_old_name ON username_history (LOWER(old_name));
@@ -6,13 +6,20 @@ import (
 )
 
 type PGStore struct {
-	Activity   *Activity
-	Article    *Article
-	Message    *Message
-	Permission *Permission
-	Role       *Role
-	User       *User
-	Category   *Category
+	Activity    *Activity
+	Article     *Article
+	Message     *Message
+	Permission  *Permission
+	Role        *Role
+	User        *User
+	Category    *Category
+	ApiToken    *ApiToken
+	Tag         *Tag
+	Report      *Report
+	Attachment  *Attachment
+	LinkPreview *LinkPreview
+	Poll        *Poll
+	Draft       *Draft
 }
 
 type DBConfig struct {
@@ -79,6 +86,13 @@ func (pg *PGStore) InitModules() error {
 	pg.Role = &Role{pgDB.Pool}
 	pg.User = &User{pgDB.Pool}
 	pg.Category = &Category{pgDB.Pool}
+	pg.ApiToken = &ApiToken{pgDB.Pool}
+	pg.Tag = &Tag{pgDB.Pool}
+	pg.Report = &Report{pgDB.Pool}
+	pg.Attachment = &Attachment{pgDB.Pool}
+	pg.LinkPreview = &LinkPreview{pgDB.Pool}
+	pg.Poll = &Poll{pgDB.Pool}
+	pg.Draft = &Draft{pgDB.Pool}
 
 	return nil
 }
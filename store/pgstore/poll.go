@@ -0,0 +1,198 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type Poll struct {
+	dbPool *pgxpool.Pool
+}
+
+func (pl *Poll) Create(articleId int, poll *model.Poll) (int, error) {
+	var id int
+	err := pgx.BeginFunc(context.Background(), pl.dbPool, func(tx pgx.Tx) error {
+		var closedAt any
+		if !poll.ClosedAt.IsZero() {
+			closedAt = poll.ClosedAt
+		}
+
+		err := tx.QueryRow(context.Background(), `INSERT INTO polls (post_id, multiple, anonymous, closed_at) VALUES ($1, $2, $3, $4) RETURNING (id)`,
+			articleId,
+			poll.Multiple,
+			poll.Anonymous,
+			closedAt,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range poll.Options {
+			err = tx.QueryRow(context.Background(), `INSERT INTO poll_options (poll_id, content, position) VALUES ($1, $2, $3) RETURNING (id)`,
+				id,
+				item.Content,
+				item.Position,
+			).Scan(&item.Id)
+			if err != nil {
+				return err
+			}
+			item.PollId = id
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	poll.Id = id
+	poll.ArticleId = articleId
+	return id, nil
+}
+
+// Item of the poll with vote counts and votes of the user, voters are only
+// filled for public polls, returns pgx.ErrNoRows if the article is deleted
+func (pl *Poll) Item(articleId, loginedUserId int) (*model.Poll, error) {
+	var poll model.Poll
+	err := pl.dbPool.QueryRow(context.Background(), `SELECT pl.id, pl.post_id, pl.multiple, pl.anonymous, pl.closed_at, pl.created_at,
+(SELECT COUNT(DISTINCT pv.user_id) FROM poll_votes pv WHERE pv.poll_id = pl.id) AS voter_count,
+EXISTS (SELECT 1 FROM poll_votes pv WHERE pv.poll_id = pl.id AND pv.user_id = $2) AS voted
+FROM polls pl
+JOIN posts p ON p.id = pl.post_id
+WHERE pl.post_id = $1 AND p.deleted = false`,
+		articleId,
+		loginedUserId,
+	).Scan(
+		&poll.Id,
+		&poll.ArticleId,
+		&poll.Multiple,
+		&poll.Anonymous,
+		&poll.NullClosedAt,
+		&poll.CreatedAt,
+		&poll.VoterCount,
+		&poll.Voted,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	poll.FormatNullValues()
+
+	rows, err := pl.dbPool.Query(context.Background(), `SELECT po.id, po.poll_id, po.content, po.position,
+(SELECT COUNT(*) FROM poll_votes pv WHERE pv.option_id = po.id) AS vote_count,
+EXISTS (SELECT 1 FROM poll_votes pv WHERE pv.option_id = po.id AND pv.user_id = $2) AS voted
+FROM poll_options po
+WHERE po.poll_id = $1
+ORDER BY po.position, po.id`,
+		poll.Id,
+		loginedUserId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	poll.Options, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.PollOption, error) {
+		var item model.PollOption
+		err := row.Scan(
+			&item.Id,
+			&item.PollId,
+			&item.Content,
+			&item.Position,
+			&item.VoteCount,
+			&item.Voted,
+		)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !poll.Anonymous {
+		err = pl.fillVoters(&poll)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &poll, nil
+}
+
+func (pl *Poll) fillVoters(poll *model.Poll) error {
+	rows, err := pl.dbPool.Query(context.Background(), `SELECT pv.option_id, u.username
+FROM poll_votes pv
+JOIN users u ON u.id = pv.user_id
+WHERE pv.poll_id = $1
+ORDER BY pv.id`,
+		poll.Id,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	optionMap := make(map[int]*model.PollOption)
+	for _, item := range poll.Options {
+		optionMap[item.Id] = item
+	}
+
+	for rows.Next() {
+		var optionId int
+		var username string
+		err := rows.Scan(&optionId, &username)
+		if err != nil {
+			return err
+		}
+
+		if option, ok := optionMap[optionId]; ok {
+			option.Voters = append(option.Voters, username)
+		}
+	}
+
+	return rows.Err()
+}
+
+// Vote options of the poll, votes of a user can not be changed, returns
+// pgx.ErrNoRows if the user has voted
+func (pl *Poll) Vote(pollId, userId int, optionIds []int) error {
+	return pgx.BeginFunc(context.Background(), pl.dbPool, func(tx pgx.Tx) error {
+		// Lock the poll so concurrent votes of the same user are serialized
+		_, err := tx.Exec(context.Background(), `SELECT id FROM polls WHERE id = $1 FOR UPDATE`, pollId)
+		if err != nil {
+			return err
+		}
+
+		var voted bool
+		err = tx.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM poll_votes WHERE poll_id = $1 AND user_id = $2)`,
+			pollId,
+			userId,
+		).Scan(&voted)
+		if err != nil {
+			return err
+		}
+
+		if voted {
+			return pgx.ErrNoRows
+		}
+
+		tag, err := tx.Exec(context.Background(), `INSERT INTO poll_votes (poll_id, option_id, user_id)
+SELECT $1, po.id, $3 FROM poll_options po
+WHERE po.poll_id = $1 AND po.id = ANY($2)`,
+			pollId,
+			optionIds,
+			userId,
+		)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return errors.New("no option of the poll is voted")
+		}
+
+		return nil
+	})
+}
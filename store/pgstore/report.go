@@ -0,0 +1,202 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type Report struct {
+	dbPool *pgxpool.Pool
+}
+
+func (rp *Report) Create(articleId, reporterId int, reason model.ReportReason, comment string) (int, error) {
+	var id int
+	err := rp.dbPool.QueryRow(context.Background(), `INSERT INTO post_reports (post_id, reporter_id, reason, comment) VALUES ($1, $2, $3, $4)
+ON CONFLICT (post_id, reporter_id) WHERE resolved_at IS NULL
+DO UPDATE SET reason = EXCLUDED.reason, comment = EXCLUDED.comment, created_at = NOW()
+RETURNING (id)`,
+		articleId,
+		reporterId,
+		reason,
+		comment,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Key of reports resolved at the same time of an article
+func reportGroupKey(articleId int, resolvedAt *time.Time) string {
+	if resolvedAt == nil {
+		return fmt.Sprintf("%d", articleId)
+	}
+	return fmt.Sprintf("%d_%d", articleId, resolvedAt.UnixMicro())
+}
+
+func (rp *Report) List(resolved bool, categoryFrontIds []string, page, pageSize int) ([]*model.ArticleReport, int, error) {
+	if page < 1 {
+		page = DefaultPage
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	args := []any{resolved, pageSize * (page - 1), pageSize}
+
+	var categoryCondition string
+	if categoryFrontIds != nil {
+		args = append(args, categoryFrontIds)
+		categoryCondition = fmt.Sprintf(" AND c.front_id = ANY($%d)", len(args))
+	}
+
+	orderStr := ` ORDER BY report_count DESC, last_reported_at DESC`
+	if resolved {
+		orderStr = ` ORDER BY resolved_at DESC`
+	}
+
+	sqlStr := `WITH reportGroups AS (
+  SELECT pr.post_id, pr.resolved_at, COUNT(pr.id) AS report_count, MAX(pr.created_at) AS last_reported_at, COUNT(*) OVER() AS total
+  FROM post_reports pr
+  JOIN posts p ON p.id = pr.post_id
+  LEFT JOIN categories c ON c.id = p.category_id
+  WHERE (pr.resolved_at IS NOT NULL) = $1` + categoryCondition + `
+  GROUP BY pr.post_id, pr.resolved_at` + orderStr + `
+  OFFSET $2
+  LIMIT $3
+)
+SELECT rg.post_id, rg.resolved_at, rg.last_reported_at, rg.total,
+COALESCE(p.title, ''), p.content, p.author_id, COALESCE(u.username, ''), p.created_at, p.deleted, p.locked, p.fade_out, p.depth, p.root_article_id, p2.title,
+COALESCE(c.front_id, ''), COALESCE(c.name, '')
+FROM reportGroups rg
+JOIN posts p ON p.id = rg.post_id
+LEFT JOIN posts p2 ON p2.id = p.root_article_id
+LEFT JOIN users u ON u.id = p.author_id
+LEFT JOIN categories c ON c.id = p.category_id` + orderStr
+
+	rows, err := rp.dbPool.Query(context.Background(), sqlStr, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	var total int
+	var list []*model.ArticleReport
+	var articleIds []int
+	groupMap := make(map[string]*model.ArticleReport)
+	for rows.Next() {
+		article := model.Article{
+			Category: &model.Category{},
+		}
+		item := model.ArticleReport{
+			Article:      &article,
+			ReasonCounts: make(map[model.ReportReason]int),
+		}
+
+		err := rows.Scan(
+			&article.Id,
+			&item.ResolvedAt,
+			&item.LastReportedAt,
+			&total,
+			&article.Title,
+			&article.Content,
+			&article.AuthorId,
+			&article.AuthorName,
+			&article.CreatedAt,
+			&article.Deleted,
+			&article.Locked,
+			&article.FadeOut,
+			&article.ReplyDepth,
+			&article.ReplyRootArticleId,
+			&article.NullReplyRootArticleTitle,
+			&article.Category.FrontId,
+			&article.Category.Name,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		article.CategoryFrontId = article.Category.FrontId
+		article.FormatNullValues()
+		article.GenSummary(200)
+
+		list = append(list, &item)
+		articleIds = append(articleIds, article.Id)
+		groupMap[reportGroupKey(article.Id, item.ResolvedAt)] = &item
+	}
+
+	if len(list) == 0 {
+		return list, total, nil
+	}
+
+	reportRows, err := rp.dbPool.Query(context.Background(), `SELECT pr.id, pr.post_id, pr.reporter_id, u.username, pr.reason, COALESCE(pr.comment, ''), pr.created_at,
+pr.resolved_at, COALESCE(pr.resolution, ''), COALESCE(ru.username, '')
+FROM post_reports pr
+JOIN users u ON u.id = pr.reporter_id
+LEFT JOIN users ru ON ru.id = pr.resolver_id
+WHERE pr.post_id = ANY($1) AND (pr.resolved_at IS NOT NULL) = $2
+ORDER BY pr.created_at DESC`,
+		articleIds,
+		resolved,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer reportRows.Close()
+
+	for reportRows.Next() {
+		var report model.Report
+		var resolvedAt *time.Time
+		var resolution model.ReportResolution
+		var resolverName string
+
+		err := reportRows.Scan(
+			&report.Id,
+			&report.ArticleId,
+			&report.ReporterId,
+			&report.ReporterName,
+			&report.Reason,
+			&report.Comment,
+			&report.CreatedAt,
+			&resolvedAt,
+			&resolution,
+			&resolverName,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Reports of the groups out of current page are skipped
+		item, ok := groupMap[reportGroupKey(report.ArticleId, resolvedAt)]
+		if !ok {
+			continue
+		}
+
+		item.Reports = append(item.Reports, &report)
+		item.ReasonCounts[report.Reason] += 1
+		item.Resolution = resolution
+		item.ResolverName = resolverName
+	}
+
+	return list, total, nil
+}
+
+func (rp *Report) Resolve(articleId, resolverId int, resolution model.ReportResolution) (int, error) {
+	res, err := rp.dbPool.Exec(context.Background(), `UPDATE post_reports SET resolver_id = $1, resolution = $2, resolved_at = NOW()
+WHERE post_id = $3 AND resolved_at IS NULL`,
+		resolverId,
+		resolution,
+		articleId,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,112 @@
+package pgstore
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oodzchen/dproject/model"
+)
+
+type Tag struct {
+	dbPool *pgxpool.Pool
+}
+
+func (t *Tag) List() ([]*model.Tag, error) {
+	sqlStr := `SELECT t.id, t.front_id, t.name, COALESCE(t.describe, ''), t.author_id, t.created_at, COUNT(DISTINCT p.id) AS total_post_count
+FROM tags t
+LEFT JOIN post_tags pt ON pt.tag_id = t.id
+LEFT JOIN posts p ON p.id = pt.post_id AND p.deleted = false
+WHERE t.deleted = false
+GROUP BY t.id
+ORDER BY t.name`
+
+	rows, err := t.dbPool.Query(context.Background(), sqlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.Tag
+	for rows.Next() {
+		var item model.Tag
+		err := rows.Scan(
+			&item.Id,
+			&item.FrontId,
+			&item.Name,
+			&item.Describe,
+			&item.AuthorId,
+			&item.CreatedAt,
+			&item.TotalArticleCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, nil
+}
+
+func (t *Tag) Item(frontId string) (*model.Tag, error) {
+	var item model.Tag
+	err := t.dbPool.QueryRow(context.Background(), `SELECT id, front_id, name, COALESCE(describe, ''), author_id, created_at
+FROM tags
+WHERE front_id = $1 AND deleted = false`,
+		frontId,
+	).Scan(
+		&item.Id,
+		&item.FrontId,
+		&item.Name,
+		&item.Describe,
+		&item.AuthorId,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// Create tag, or restore the deleted one with the same front id
+func (t *Tag) Create(frontId, name, describe string, authorId int) (int, error) {
+	var id int
+	err := t.dbPool.QueryRow(context.Background(), `INSERT INTO tags (front_id, name, describe, author_id) VALUES ($1, $2, $3, $4)
+ON CONFLICT (front_id) DO UPDATE SET name = EXCLUDED.name, describe = EXCLUDED.describe, author_id = EXCLUDED.author_id, deleted = false
+WHERE tags.deleted = true
+RETURNING (id)`,
+		frontId,
+		name,
+		describe,
+		authorId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (t *Tag) Update(frontId, name, describe string) (int, error) {
+	var id int
+	err := t.dbPool.QueryRow(context.Background(), "UPDATE tags SET name = $1, describe = $2 WHERE front_id = $3 AND deleted = false RETURNING (id)",
+		name,
+		describe,
+		frontId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (t *Tag) Delete(frontId string) error {
+	_, err := t.dbPool.Exec(context.Background(), "UPDATE tags SET deleted = true WHERE front_id = $1",
+		frontId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
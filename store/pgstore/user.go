@@ -7,11 +7,16 @@ import (
 	"math"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/oodzchen/dproject/model"
 )
 
+const pgErrUniqueViolation = "23505"
+
 type User struct {
 	dbPool *pgxpool.Pool
 }
@@ -184,6 +189,272 @@ func (u *User) UpdateIntroduction(username, introduction string) error {
 	return nil
 }
 
+func (u *User) UpdateMentionNotify(userId int, mentionNotify bool) error {
+	_, err := u.dbPool.Exec(context.Background(), `UPDATE users SET mention_notify = $1 WHERE id = $2`, mentionNotify, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *User) ListUsernames(prefix string, limit int) ([]string, error) {
+	// Wildcards in usernames are matched literally
+	prefix = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+
+	rows, err := u.dbPool.Query(context.Background(), `SELECT username FROM users
+WHERE username ILIKE $1 || '%' AND deleted = false
+ORDER BY username
+LIMIT $2`,
+		prefix,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (u *User) GetTotpSecret(userId int) (string, error) {
+	var secret string
+	err := u.dbPool.QueryRow(context.Background(), `SELECT totp_secret FROM users
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NOT NULL`, userId).Scan(&secret)
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (u *User) EnableTotp(userId int, secret string, recoveryCodeHashes []string) error {
+	return pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `UPDATE users SET totp_secret = $1, totp_enabled_at = NOW() WHERE id = $2`, secret, userId)
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(tx, userId, recoveryCodeHashes)
+	})
+}
+
+func (u *User) DisableTotp(userId int) error {
+	return pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL WHERE id = $1`, userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(context.Background(), `DELETE FROM user_recovery_codes WHERE user_id = $1`, userId)
+		return err
+	})
+}
+
+func (u *User) UseRecoveryCode(userId int, codeHash string) error {
+	tag, err := u.dbPool.Exec(context.Background(), `UPDATE user_recovery_codes SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (u *User) CountRecoveryCodes(userId int) (int, error) {
+	var count int
+	err := u.dbPool.QueryRow(context.Background(), `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userId).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (u *User) ReplaceRecoveryCodes(userId int, recoveryCodeHashes []string) error {
+	return pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(tx, userId, recoveryCodeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx pgx.Tx, userId int, recoveryCodeHashes []string) error {
+	_, err := tx.Exec(context.Background(), `DELETE FROM user_recovery_codes WHERE user_id = $1`, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec(context.Background(), `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userId, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *User) ItemWithIdentity(provider, subject string) (*model.User, error) {
+	var userId int
+	err := u.dbPool.QueryRow(context.Background(), `SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.AppErrUserNotExist
+		}
+		return nil, err
+	}
+
+	return u.queryItem("id", userId)
+}
+
+func (u *User) ListIdentities(userId int) ([]*model.UserIdentity, error) {
+	rows, err := u.dbPool.Query(context.Background(), `SELECT id, user_id, provider, subject, email, created_at
+FROM user_identities WHERE user_id = $1 ORDER BY created_at`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserIdentity, error) {
+		var item model.UserIdentity
+		err := row.Scan(&item.Id, &item.UserId, &item.Provider, &item.Subject, &item.Email, &item.CreatedAt)
+		return &item, err
+	})
+}
+
+func (u *User) LinkIdentity(userId int, provider, subject, email string) error {
+	tag, err := u.dbPool.Exec(context.Background(), `INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, userId, provider, subject, email)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.AppErrIdentityAlreadyLinked
+	}
+
+	return nil
+}
+
+func (u *User) UnlinkIdentity(userId int, provider string) error {
+	return pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		// Lock the user, so that the last two identities can't be unlinked
+		// at the same time
+		var hasPassword bool
+		err := tx.QueryRow(context.Background(), `SELECT password IS NOT NULL FROM users WHERE id = $1 FOR UPDATE`, userId).Scan(&hasPassword)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		err = tx.QueryRow(context.Background(), `SELECT COUNT(*) FROM user_identities WHERE user_id = $1`, userId).Scan(&count)
+		if err != nil {
+			return err
+		}
+
+		if !model.CanUnlinkIdentity(hasPassword, count) {
+			return model.AppErrLastLoginMethod
+		}
+
+		tag, err := tx.Exec(context.Background(), `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userId, provider)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
+func (u *User) ChangeUsername(userId int, newName string, changedAfter, redirectExpireAt time.Time) (string, error) {
+	var oldName string
+	err := pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(context.Background(), `SELECT username FROM users WHERE id = $1 FOR UPDATE`, userId).Scan(&oldName)
+		if err != nil {
+			return err
+		}
+
+		var changedRecently bool
+		err = tx.QueryRow(context.Background(), `SELECT EXISTS (
+SELECT 1 FROM username_history WHERE user_id = $1 AND created_at > $2
+)`, userId, changedAfter).Scan(&changedRecently)
+		if err != nil {
+			return err
+		}
+		if changedRecently {
+			return model.AppErrUsernameChangeTooOften
+		}
+
+		// Names of the other users, including the old ones still redirecting
+		var unavailable bool
+		err = tx.QueryRow(context.Background(), `SELECT EXISTS (
+SELECT 1 FROM users WHERE LOWER(username) = LOWER($1) AND id <> $2
+UNION ALL
+SELECT 1 FROM username_history WHERE LOWER(old_name) = LOWER($1) AND user_id <> $2 AND redirect_expire_at > NOW()
+)`, newName, userId).Scan(&unavailable)
+		if err != nil {
+			return err
+		}
+		if unavailable {
+			return model.AppErrUsernameUnavailable
+		}
+
+		_, err = tx.Exec(context.Background(), `INSERT INTO username_history (user_id, old_name, new_name, redirect_expire_at) VALUES ($1, $2, $3, $4)`, userId, oldName, newName, redirectExpireAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(context.Background(), `UPDATE users SET username = $1 WHERE id = $2`, newName, userId)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgErrUniqueViolation {
+				return model.AppErrUsernameUnavailable
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return oldName, nil
+}
+
+func (u *User) UsernameRedirect(oldName string) (string, error) {
+	var username string
+	err := u.dbPool.QueryRow(context.Background(), `SELECT u.username
+FROM username_history uh
+JOIN users u ON u.id = uh.user_id
+WHERE LOWER(uh.old_name) = LOWER($1) AND uh.redirect_expire_at > NOW()
+ORDER BY uh.created_at DESC
+LIMIT 1`, oldName).Scan(&username)
+	if err != nil {
+		return "", err
+	}
+
+	return username, nil
+}
+
+func (u *User) ListUsernameHistory(userId int) ([]*model.UsernameHistory, error) {
+	rows, err := u.dbPool.Query(context.Background(), `SELECT id, user_id, old_name, new_name, created_at, redirect_expire_at
+FROM username_history WHERE user_id = $1 ORDER BY created_at DESC`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UsernameHistory, error) {
+		var item model.UsernameHistory
+		err := row.Scan(&item.Id, &item.UserId, &item.OldName, &item.NewName, &item.CreatedAt, &item.RedirectExpireAt)
+		return &item, err
+	})
+}
+
 func (u *User) UpdatePassword(email, password string) (int, error) {
 	// fmt.Println("email: ", email)
 	// fmt.Println("password: ", password)
@@ -213,6 +484,15 @@ func (u *User) queryItem(fieldName string, val any) (*model.User, error) {
 
 	sqlStr := `SELECT u.id, u.username, u.email, u.created_at, u.super_admin, COALESCE(u.introduction, '') as introduction, u.auth_from, u.reputation, u.banned_start_at, COALESCE(u.banned_day_num, 0), u.banned_count,
 COALESCE(r.name, '') as role_name, COALESCE(r.front_id, '') AS role_front_id,
+ARRAY(
+  SELECT c.front_id FROM category_moderators cm
+  JOIN categories c ON c.id = cm.category_id AND c.deleted = false
+  WHERE cm.user_id = u.id
+) AS moderated_categories,
+ARRAY(
+  SELECT ub.target_user_id FROM user_blocks ub
+  WHERE ub.user_id = u.id AND ub.type = 'block'
+) AS blocked_user_ids, u.mention_notify, u.totp_enabled_at IS NOT NULL AS totp_enabled, u.password IS NOT NULL AS has_password, u.deletion_requested_at,
 COALESCE(p.id, 0) AS p_id, COALESCE(p.name, '') AS p_name, COALESCE(p.front_id, '') AS p_front_id, COALESCE(p.module, 'user') AS p_module, COALESCE(p.created_at, NOW()) AS p_created_at
 FROM users u
 LEFT JOIN user_roles ur ON ur.user_id = u.id
@@ -246,6 +526,12 @@ LEFT JOIN permissions p ON p.id = rp.permission_id WHERE ` + conditionStr
 			&uItem.BannedCount,
 			&uItem.RoleName,
 			&uItem.RoleFrontId,
+			&uItem.ModeratedCategories,
+			&uItem.BlockedUserIds,
+			&uItem.MentionNotify,
+			&uItem.TotpEnabled,
+			&uItem.HasPassword,
+			&uItem.NullDeletionRequestedAt,
 			&pItem.Id,
 			&pItem.Name,
 			&pItem.FrontId,
@@ -310,9 +596,72 @@ func (u *User) ItemWithUsernameEmail(usernameEmail string) (*model.User, error)
 	}
 }
 
+func (u *User) ListBlocks(userId int) ([]*model.UserBlock, error) {
+	rows, err := u.dbPool.Query(context.Background(), `SELECT ub.user_id, ub.target_user_id, u.username, ub.type, ub.created_at
+FROM user_blocks ub
+JOIN users u ON u.id = ub.target_user_id
+WHERE ub.user_id = $1
+ORDER BY ub.created_at DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var list []*model.UserBlock
+	for rows.Next() {
+		var item model.UserBlock
+		err := rows.Scan(
+			&item.UserId,
+			&item.TargetUserId,
+			&item.TargetUserName,
+			&item.Type,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &item)
+	}
+
+	return list, nil
+}
+
+// Block or mute the target user, the type is updated if already exists
+func (u *User) Block(userId, targetUserId int, blockType model.UserBlockType) error {
+	_, err := u.dbPool.Exec(context.Background(), `INSERT INTO user_blocks (user_id, target_user_id, type) VALUES ($1, $2, $3)
+ON CONFLICT (user_id, target_user_id) DO UPDATE SET type = EXCLUDED.type`,
+		userId,
+		targetUserId,
+		blockType,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *User) Unblock(userId, targetUserId int) error {
+	_, err := u.dbPool.Exec(context.Background(), "DELETE FROM user_blocks WHERE user_id = $1 AND target_user_id = $2",
+		userId,
+		targetUserId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (u *User) Exists(email, username string) (int, error) {
 	var id int
-	err := u.dbPool.QueryRow(context.Background(), "SELECT id FROM users WHERE email = $1 OR username = $2", email, username).Scan(&id)
+	// Old usernames still redirecting are reserved for the users
+	err := u.dbPool.QueryRow(context.Background(), `SELECT id FROM users WHERE email = $1 OR username = $2
+UNION ALL
+SELECT user_id FROM username_history WHERE LOWER(old_name) = LOWER($2) AND redirect_expire_at > NOW()
+LIMIT 1`, email, username).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -328,12 +677,218 @@ func (u *User) Delete(id int) error {
 	return nil
 }
 
+// Delete the user, articles and other content shown to others are
+// transferred to the placeholder author so that reply trees are kept, other
+// data of the user is deleted
 func (u *User) DeleteHard(id int) error {
-	_, err := u.dbPool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id)
+	return pgx.BeginFunc(context.Background(), u.dbPool, func(tx pgx.Tx) error {
+		var placeholderId int
+		err := tx.QueryRow(context.Background(), `SELECT id FROM users WHERE username = $1`, model.PlaceholderUsername).Scan(&placeholderId)
+		if err != nil {
+			return err
+		}
+
+		if id == placeholderId {
+			return errors.New("the placeholder user can not be deleted")
+		}
+
+		transferSqls := []string{
+			`UPDATE posts SET author_id = $2 WHERE author_id = $1`,
+			`UPDATE post_history SET operator_id = $2 WHERE operator_id = $1`,
+			`UPDATE categories SET author_id = $2 WHERE author_id = $1`,
+			`UPDATE categories SET reviewer_id = $2 WHERE reviewer_id = $1`,
+			`UPDATE tags SET author_id = $2 WHERE author_id = $1`,
+			`UPDATE post_reports SET resolver_id = $2 WHERE resolver_id = $1`,
+			`UPDATE attachments SET user_id = $2 WHERE user_id = $1`,
+			`UPDATE messages SET sender_id = $2 WHERE sender_id = $1 AND reciever_id <> $1`,
+		}
+		for _, sqlStr := range transferSqls {
+			_, err = tx.Exec(context.Background(), sqlStr, id, placeholderId)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Tables without cascading deletion
+		deleteSqls := []string{
+			`DELETE FROM messages WHERE reciever_id = $1 OR sender_id = $1`,
+			`DELETE FROM post_votes WHERE user_id = $1`,
+			`DELETE FROM post_saves WHERE user_id = $1`,
+			`DELETE FROM post_reacts WHERE user_id = $1`,
+			`DELETE FROM post_subs WHERE user_id = $1`,
+			`DELETE FROM category_subs WHERE user_id = $1`,
+			`DELETE FROM category_ignores WHERE user_id = $1`,
+			`DELETE FROM user_roles WHERE user_id = $1`,
+			`DELETE FROM activities WHERE user_id = $1`,
+			`DELETE FROM reputation_log WHERE user_id = $1`,
+			`DELETE FROM users WHERE id = $1`,
+		}
+		for _, sqlStr := range deleteSqls {
+			_, err = tx.Exec(context.Background(), sqlStr, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *User) RequestDeletion(id int) error {
+	_, err := u.dbPool.Exec(context.Background(), `UPDATE users SET deletion_requested_at = NOW() WHERE id = $1 AND deletion_requested_at IS NULL`, id)
+	return err
+}
+
+func (u *User) CancelDeletion(id int) error {
+	_, err := u.dbPool.Exec(context.Background(), `UPDATE users SET deletion_requested_at = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (u *User) ListDeletionDue(requestedBefore time.Time) ([]int, error) {
+	rows, err := u.dbPool.Query(context.Background(), `SELECT id FROM users
+WHERE deletion_requested_at IS NOT NULL AND deletion_requested_at < $1
+ORDER BY deletion_requested_at`, requestedBefore)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func (u *User) Export(id int) (*model.UserExport, error) {
+	user, err := u.queryItem("id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := u.ListIdentities(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &model.UserExport{
+		Profile: &model.UserExportProfile{
+			Id:            user.Id,
+			Username:      user.Name,
+			Email:         user.Email,
+			Introduction:  user.Introduction,
+			AuthFrom:      user.AuthFrom,
+			RoleName:      user.RoleName,
+			Reputation:    user.Reputation,
+			MentionNotify: user.MentionNotify,
+			RegisteredAt:  user.RegisteredAt,
+		},
+	}
+	for _, item := range identities {
+		data.Profile.Identities = append(data.Profile.Identities, &model.UserExportIdentity{
+			Provider:  item.Provider,
+			Email:     item.Email,
+			CreatedAt: item.CreatedAt,
+		})
+	}
+
+	ctx := context.Background()
+
+	rows, err := u.dbPool.Query(ctx, `SELECT p.id, COALESCE(p.title, ''), COALESCE(p.url, ''), COALESCE(p.content, ''), c.front_id, COALESCE(p.reply_to, 0), p.root_article_id, p.deleted, p.created_at, p.updated_at
+FROM posts p
+JOIN categories c ON c.id = p.category_id
+WHERE p.author_id = $1
+ORDER BY p.created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportArticle, error) {
+		var item model.UserExportArticle
+		err := row.Scan(&item.Id, &item.Title, &item.URL, &item.Content, &item.CategoryFrontId, &item.ReplyToId, &item.RootArticleId, &item.Deleted, &item.CreatedAt, &item.UpdatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range articles {
+		if item.ReplyToId == 0 {
+			item.RootArticleId = 0
+			data.Articles = append(data.Articles, item)
+		} else {
+			data.Replies = append(data.Replies, item)
+		}
+	}
+
+	rows, err = u.dbPool.Query(ctx, `SELECT post_id, type, created_at FROM post_votes WHERE user_id = $1 AND type IS NOT NULL ORDER BY created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Votes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportVote, error) {
+		var item model.UserExportVote
+		err := row.Scan(&item.ArticleId, &item.Type, &item.CreatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = u.dbPool.Query(ctx, `SELECT post_id, created_at FROM post_saves WHERE user_id = $1 ORDER BY created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Saves, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportSave, error) {
+		var item model.UserExportSave
+		err := row.Scan(&item.ArticleId, &item.CreatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = u.dbPool.Query(ctx, `SELECT pr.post_id, r.emoji, r.front_id, pr.created_at
+FROM post_reacts pr
+JOIN reacts r ON r.id = pr.react_id
+WHERE pr.user_id = $1
+ORDER BY pr.created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Reactions, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportReaction, error) {
+		var item model.UserExportReaction
+		err := row.Scan(&item.ArticleId, &item.Emoji, &item.FrontId, &item.CreatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = u.dbPool.Query(ctx, `SELECT m.id, m.type, COALESCE(m.action, ''), u.username, COALESCE(m.source_article_id, 0), COALESCE(m.content_id, 0), COALESCE(m.content, ''), m.is_read, m.created_at
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE m.reciever_id = $1
+ORDER BY m.created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Messages, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportMessage, error) {
+		var item model.UserExportMessage
+		err := row.Scan(&item.Id, &item.Type, &item.Action, &item.SenderName, &item.SourceArticleId, &item.ContentId, &item.Content, &item.IsRead, &item.CreatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = u.dbPool.Query(ctx, `SELECT type, action, COALESCE(target_model, ''), COALESCE(target_id, ''), ip_address, COALESCE(device_info, ''), COALESCE(details, ''), created_at
+FROM activities WHERE user_id = $1 ORDER BY created_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Activities, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.UserExportActivity, error) {
+		var item model.UserExportActivity
+		err := row.Scan(&item.Type, &item.Action, &item.TargetModel, &item.TargetId, &item.IpAddr, &item.DeviceInfo, &item.Details, &item.CreatedAt)
+		return &item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
 }
 
 func (u *User) Ban(username string, bannedDays int) (int, error) {
@@ -7,13 +7,20 @@ import (
 )
 
 type Store struct {
-	Article    ArticleStore
-	User       UserStore
-	Role       RoleStore
-	Permission PermissionStore
-	Activity   ActivityStore
-	Message    MessageStore
-	Category   CategoryStore
+	Article     ArticleStore
+	User        UserStore
+	Role        RoleStore
+	Permission  PermissionStore
+	Activity    ActivityStore
+	Message     MessageStore
+	Category    CategoryStore
+	ApiToken    ApiTokenStore
+	Tag         TagStore
+	Report      ReportStore
+	Attachment  AttachmentStore
+	LinkPreview LinkPreviewStore
+	Poll        PollStore
+	Draft       DraftStore
 }
 
 func New(
@@ -24,6 +31,13 @@ func New(
 	activity ActivityStore,
 	message MessageStore,
 	category CategoryStore,
+	apiToken ApiTokenStore,
+	tag TagStore,
+	report ReportStore,
+	attachment AttachmentStore,
+	linkPreview LinkPreviewStore,
+	poll PollStore,
+	draft DraftStore,
 ) *Store {
 	return &Store{
 		article,
@@ -33,18 +47,28 @@ func New(
 		activity,
 		message,
 		category,
+		apiToken,
+		tag,
+		report,
+		attachment,
+		linkPreview,
+		poll,
+		draft,
 	}
 }
 
 type ArticleStore interface {
-	// pageSize < 0 to list all undeleted data
+	// pageSize < 0 to list all undeleted data, articles in the categories
+	// ignored by the user of ignoreUserId are excluded, 0 to exclude nothing
 	List(page,
 		pageSize int,
 		sortType model.ArticleSortType,
-		categoryFrontId string,
+		categoryFrontId, tagFrontId string,
+		ignoreUserId int,
 		pinned, deleted, includeReplies bool,
 		keywords string,
 	) ([]*model.Article, int, error)
+	Search(query *model.SearchQuery, page, pageSize int) ([]*model.Article, int, error)
 	ListUserState(ids []int, userId int) ([]*model.Article, error)
 	ListLatestCount(start, end time.Time) (int, error)
 	Create(title, url, content string, authorId, replyToId int, categoryFrontId string, pinnedExpireAt time.Time, locked bool) (int, error)
@@ -56,7 +80,7 @@ type ArticleStore interface {
 	ReplyTree(page, pageSize, ariticleId int, sortType model.ArticleSortType, pinned bool) ([]*model.Article, error)
 	ReplyList(page, pageSize, ariticleId int, sortType model.ArticleSortType, pinned bool) ([]*model.Article, error)
 	ItemTreeUserState(ids []int, userId int) ([]*model.Article, error)
-	Count(categoryFrontId string, includePinned bool) (int, error)
+	Count(categoryFrontId, tagFrontId string, ignoreUserId int, includePinned bool) (int, error)
 	CountTotalReply(id int) (int, error)
 	VoteCheck(id, userId int) (error, string)
 	// Return int value, 0 for error, -1 for canceled, 1 for added, 2 for updated
@@ -70,7 +94,17 @@ type ArticleStore interface {
 	Notify(senderUserId, sourceArticleId, contentArticleId int) error
 	GetReactList() ([]*model.ArticleReact, error)
 	ReactItem(int) (*model.ArticleReact, error)
-	Tag(id int, tagFrontId string) error
+	// Replace tags of the article with the given ones
+	Tag(id int, tagFrontIds []string) error
+	// Replace mentioned users of the article, return ids of the newly mentioned ones
+	Mention(id int, userIds []int) ([]int, error)
+	NotifyMention(senderUserId, contentArticleId int, userIds []int) error
+	UpdateNormalizedURL(id int, normalizedURL string) error
+	// Id of the earliest undeleted article with the normalized url, which is
+	// not marked as duplicate, returns pgx.ErrNoRows if there is none
+	DuplicateLink(normalizedURL string, excludeId int) (int, error)
+	// Mark the article as duplicate of the original one, 0 to unmark
+	MarkDuplicate(id, originalId int) error
 	AddHistory(
 		articleId,
 		operatorId int,
@@ -107,6 +141,15 @@ type UserStore interface {
 	ItemWithUsernameEmail(usernameEmail string) (*model.User, error)
 	Exists(email, username string) (int, error)
 	// Delete(int) error
+	// Delete the user, content shown to others is transferred to the
+	// placeholder author
+	DeleteHard(id int) error
+	RequestDeletion(id int) error
+	CancelDeletion(id int) error
+	// Ids of the users requested deletion before the time
+	ListDeletionDue(requestedBefore time.Time) ([]int, error)
+	// Personal data of the user for exporting
+	Export(id int) (*model.UserExport, error)
 	Ban(username string, bannedDays int) (int, error)
 	Unban(username string) (int, error)
 	GetPosts(username string, listType string) ([]*model.Article, error)
@@ -121,6 +164,42 @@ type UserStore interface {
 	AddReputationVal(username string, value int, comment string, isRevert bool) error
 	// UpdateReputation(username string) error
 	GetVotedPosts(username string, voteType model.VoteType) ([]*model.Article, error)
+	ListBlocks(userId int) ([]*model.UserBlock, error)
+	// Block or mute the target user, the type is updated if already exists
+	Block(userId, targetUserId int, blockType model.UserBlockType) error
+	Unblock(userId, targetUserId int) error
+	UpdateMentionNotify(userId int, mentionNotify bool) error
+	// Usernames starting with the prefix, for mention autocompleting
+	ListUsernames(prefix string, limit int) ([]string, error)
+	// Secret of the enabled TOTP, returns pgx.ErrNoRows if it's not enabled
+	GetTotpSecret(userId int) (string, error)
+	// Enable TOTP with the recovery codes, codes created before are replaced
+	EnableTotp(userId int, secret string, recoveryCodeHashes []string) error
+	DisableTotp(userId int) error
+	// Mark the recovery code as used, returns pgx.ErrNoRows if it's not
+	// found or used before
+	UseRecoveryCode(userId int, codeHash string) error
+	CountRecoveryCodes(userId int) (int, error)
+	ReplaceRecoveryCodes(userId int, recoveryCodeHashes []string) error
+	// User linked with the identity, returns model.AppErrUserNotExist if
+	// it's not linked
+	ItemWithIdentity(provider, subject string) (*model.User, error)
+	ListIdentities(userId int) ([]*model.UserIdentity, error)
+	// Returns model.AppErrIdentityAlreadyLinked if the identity is linked to
+	// any user, or the user has linked another identity of the provider
+	LinkIdentity(userId int, provider, subject, email string) error
+	// Returns model.AppErrLastLoginMethod if it's the last login method of
+	// the user, pgx.ErrNoRows if the provider is not linked
+	UnlinkIdentity(userId int, provider string) error
+	// Change the username and keep the old one in history, returns the old
+	// username. Returns model.AppErrUsernameChangeTooOften if it's changed
+	// after changedAfter, model.AppErrUsernameUnavailable if the name is
+	// taken, or reserved by another user until the redirect expires
+	ChangeUsername(userId int, newName string, changedAfter, redirectExpireAt time.Time) (string, error)
+	// Current username of the user who used the old name, returns
+	// pgx.ErrNoRows if there is none or the redirect is expired
+	UsernameRedirect(oldName string) (string, error)
+	ListUsernameHistory(userId int) ([]*model.UsernameHistory, error)
 }
 
 type PermissionStore interface {
@@ -138,10 +217,46 @@ type CategoryStore interface {
 	Create(frontId, name, describe string, authorId int) (int, error)
 	Update(frontId, name, describe string) (int, error)
 	Item(frontId string, loginedUserId int) (*model.Category, error)
-	Approval(frontId string, pass bool, comment string) error
+	Approval(frontId string, pass bool, comment string, reviewerId int) error
 	Delete(frontId string) error
 	Subscribe(frontId string, loginedUserId int) error
+	// Toggle ignore state of the category
+	Ignore(frontId string, loginedUserId int) error
+	ListIgnored(userId int) ([]*model.Category, error)
 	Notify(frontId string, senderUserId, contentArticleId int) error
+	ListModerators() ([]*model.CategoryModerator, error)
+	AddModerator(frontId string, userId int) error
+	RemoveModerator(frontId string, userId int) error
+}
+
+type TagStore interface {
+	List() ([]*model.Tag, error)
+	Item(frontId string) (*model.Tag, error)
+	Create(frontId, name, describe string, authorId int) (int, error)
+	Update(frontId, name, describe string) (int, error)
+	Delete(frontId string) error
+}
+
+type AttachmentStore interface {
+	Create(item *model.Attachment) (int, error)
+	Item(id int) (*model.Attachment, error)
+	// Undeleted attachments of the articles
+	List(articleIds []int) ([]*model.Attachment, error)
+	// Delete attachments of the article, return the deleted ones
+	Delete(articleId int, ids []int) ([]*model.Attachment, error)
+	// Total size of the undeleted attachments uploaded by the user
+	UsedSize(userId int) (int64, error)
+}
+
+type ReportStore interface {
+	// Create a pending report, the reason is updated if the user reported the
+	// article before
+	Create(articleId, reporterId int, reason model.ReportReason, comment string) (int, error)
+	// List reports aggregated by article, categoryFrontIds limits the
+	// categories of articles, nil for all
+	List(resolved bool, categoryFrontIds []string, page, pageSize int) ([]*model.ArticleReport, int, error)
+	// Resolve all pending reports of the article, return the count of resolved
+	Resolve(articleId, resolverId int, resolution model.ReportResolution) (int, error)
 }
 
 type RoleStore interface {
@@ -169,8 +284,44 @@ type ActivityStore interface {
 type MessageStore interface {
 	List(userId int, status string, page, pageSize int) ([]*model.Message, int, error)
 	// Create(senderUserId, reciverUserId, sourceArticleId, contentArticleId int) (int, error)
+	CreateSystem(senderUserId, recieverUserId int, action model.MessageAction, content string, sourceArticleId, sourceCategoryId int) (int, error)
 	Read(messageId int) error
 	ReadMany(messageIds []any) error
 	UnreadCount(loginedUserId int) (int, error)
 	ReadAll(userId int) error
 }
+
+type ApiTokenStore interface {
+	List(userId int) ([]*model.ApiToken, error)
+	Create(userId int, name, tokenHash, prefix string, permissions []string) (int, error)
+	ItemWithHash(tokenHash string) (*model.ApiToken, error)
+	Count(userId int) (int, error)
+	// Delete token only if it belongs to the user
+	Delete(id, userId int) (int, error)
+	DeleteAll(userId int) error
+	UpdateLastUsed(id int) error
+}
+
+type LinkPreviewStore interface {
+	Item(url string) (*model.LinkPreview, error)
+	// Save preview of the url, the old one is replaced
+	Save(item *model.LinkPreview) (int, error)
+}
+
+type PollStore interface {
+	// Create the poll of the article with its options
+	Create(articleId int, poll *model.Poll) (int, error)
+	Item(articleId, loginedUserId int) (*model.Poll, error)
+	// Votes can not be changed, returns pgx.ErrNoRows if the user has voted
+	Vote(pollId, userId int, optionIds []int) error
+}
+
+type DraftStore interface {
+	// Save the draft of the target, the old one is replaced, drafts of the
+	// user updated before expiredBefore are removed
+	Save(item *model.Draft, expiredBefore time.Time) (int, error)
+	// Returns pgx.ErrNoRows if there is no draft updated after the time
+	Item(userId int, targetType model.DraftTargetType, targetId int, updatedAfter time.Time) (*model.Draft, error)
+	List(userId int, updatedAfter time.Time) ([]*model.Draft, error)
+	Delete(userId int, targetType model.DraftTargetType, targetId int) error
+}
@@ -10,22 +10,24 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/oodzchen/dproject/model"
 	"github.com/oodzchen/dproject/utils"
 )
 
 var TmplFuncs = template.FuncMap{
 	// "timeAgo":    formatTimeAgo,
-	"timeFormat":   utils.FormatTime,
-	"intRange":     intRange,
-	"placehold":    placehold,
-	"joinStrArr":   joinStrArr,
-	"upHead":       upCaseHead,
-	"downHead":     downCaseHead,
-	"pageStr":      pageStr,
-	"calcDuration": calcDuration,
-	"replaceLink":  utils.ReplaceLink,
-	"getDomain":    getDomain,
-	"runeLen":      runeLen,
+	"timeFormat":       utils.FormatTime,
+	"intRange":         intRange,
+	"placehold":        placehold,
+	"joinStrArr":       joinStrArr,
+	"upHead":           upCaseHead,
+	"downHead":         downCaseHead,
+	"pageStr":          pageStr,
+	"calcDuration":     calcDuration,
+	"replaceLink":      utils.ReplaceLink,
+	"getDomain":        getDomain,
+	"runeLen":          runeLen,
+	"attachmentLimits": model.GetAttachmentLimits,
 }
 
 func joinStrArr(arr []string, sep string) string {
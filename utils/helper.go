@@ -40,6 +40,19 @@ const maxDisplayURLLength = 100
 var urlRegex = regexp.MustCompile(`https?:\/\/(www\.)?[-a-zA-Z0-9@:%._\+~#=]{1,256}\.[a-zA-Z0-9]{1,63}\b([-a-zA-Z0-9@:%_\+.~#?&//=]*)`)
 
 func ReplaceLink(str string) string {
+	return replaceLink(str, html.EscapeString)
+}
+
+// ReplaceLinkMentions is ReplaceLink with the mentions found by findMentions
+// in the rest of text linked to user pages
+func ReplaceLinkMentions(str string, findMentions MentionFinder) string {
+	return replaceLink(str, func(text string) string {
+		return linkMentionsText(text, findMentions)
+	})
+}
+
+// Text out of the links is escaped by escape
+func replaceLink(str string, escape func(string) string) string {
 	rawStr := html.UnescapeString(str)
 	idxArr := urlRegex.FindAllStringIndex(rawStr, -1)
 
@@ -50,7 +63,7 @@ func ReplaceLink(str string) string {
 
 		for idx, matched := range idxArr {
 			if idx == 0 {
-				result += html.EscapeString(rawStr[0:matched[0]])
+				result += escape(rawStr[0:matched[0]])
 			}
 
 			urlStr := rawStr[matched[0]:matched[1]]
@@ -63,14 +76,14 @@ func ReplaceLink(str string) string {
 
 			if idx < len(idxArr)-1 {
 				nextMatch := idxArr[idx+1]
-				result += html.EscapeString(rawStr[matched[1]:nextMatch[0]])
+				result += escape(rawStr[matched[1]:nextMatch[0]])
 			} else {
-				result += html.EscapeString(rawStr[matched[1]:])
+				result += escape(rawStr[matched[1]:])
 			}
 		}
 		return result
 	} else {
-		return str
+		return escape(rawStr)
 	}
 }
 
@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Images larger than this are rejected before decoding, to avoid running out
+// of memory
+const MaxImagePixels = 25_000_000
+
+// Frames of GIF are decoded all at once, the count and pixels of them are
+// limited too. Pixels of GIF frames take one byte each, so they are allowed
+// more than the other images.
+const (
+	MaxGIFFrames = 1000
+	MaxGIFPixels = 4 * MaxImagePixels
+)
+
+var ErrImageTooLarge = errors.New("image is too large")
+
+// CleanImage re-encodes the image to drop metadata such as EXIF, which may
+// contain location of the photo. Orientation in EXIF is applied to pixels
+// before dropping. All frames of GIF are re-encoded to keep the animation,
+// comments and application data like XMP are dropped. The first frame is
+// returned as the image of GIF.
+func CleanImage(data []byte, mimeType string) ([]byte, image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, nil, ErrImageTooLarge
+	}
+
+	var buf bytes.Buffer
+	switch mimeType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		img = applyOrientation(img, jpegOrientation(data))
+
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+		if err != nil {
+			return nil, nil, err
+		}
+		return buf.Bytes(), img, nil
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = png.Encode(&buf, img)
+		if err != nil {
+			return nil, nil, err
+		}
+		return buf.Bytes(), img, nil
+	case "image/gif":
+		err := checkGIFFrames(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = gif.EncodeAll(&buf, g)
+		if err != nil {
+			return nil, nil, err
+		}
+		return buf.Bytes(), g.Image[0], nil
+	}
+
+	return nil, nil, errors.New("image type not supported: " + mimeType)
+}
+
+// Walk the blocks of GIF to check the count and pixels of frames, before they
+// are allocated by decoding
+func checkGIFFrames(data []byte) error {
+	errFormat := errors.New("gif: invalid format")
+
+	// Header and logical screen descriptor
+	if len(data) < 13 {
+		return errFormat
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	// Skip data sub-blocks, which end with an empty one
+	skipSubBlocks := func() bool {
+		for i < len(data) {
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	var frames, pixels int
+	for i < len(data) {
+		switch data[i] {
+		case 0x21:
+			// Extension label is followed by sub-blocks
+			i += 2
+			if !skipSubBlocks() {
+				return errFormat
+			}
+		case 0x2C:
+			// Image descriptor, local color table and LZW minimum code size
+			if i+10 > len(data) {
+				return errFormat
+			}
+			width := int(binary.LittleEndian.Uint16(data[i+5:]))
+			height := int(binary.LittleEndian.Uint16(data[i+7:]))
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			i++
+
+			frames++
+			pixels += width * height
+			if frames > MaxGIFFrames || pixels > MaxGIFPixels {
+				return ErrImageTooLarge
+			}
+
+			if !skipSubBlocks() {
+				return errFormat
+			}
+		case 0x3B:
+			return nil
+		default:
+			return errFormat
+		}
+	}
+
+	return errFormat
+}
+
+// Thumbnail scales the image down to fit in a square of size, smaller images
+// are not scaled. JPEG is returned for JPEG images, PNG for others to keep the
+// transparency.
+func Thumbnail(img image.Image, size int, mimeType string) ([]byte, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > size || h > size {
+		if w > h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	var err error
+	if mimeType == "image/jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Read orientation from EXIF of JPEG data, 1 is returned if there is none
+func jpegOrientation(data []byte) int {
+	// Skip SOI marker
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF {
+		marker := data[i+1]
+		segLen := int(binary.BigEndian.Uint16(data[i+2:]))
+		// Start of scan, no more metadata
+		if marker == 0xDA || segLen < 2 || i+2+segLen > len(data) {
+			break
+		}
+
+		seg := data[i+4 : i+2+segLen]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + segLen
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:]))
+	if ifdOffset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[ifdOffset:]))
+	for n := 0; n < count; n++ {
+		entry := ifdOffset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o < 1 || o > 8 {
+				return 1
+			}
+			return o
+		}
+	}
+	return 1
+}
+
+// Transform the image by EXIF orientation, so that it's displayed upright
+// without the orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
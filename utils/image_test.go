@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 10), uint8(y * 10), 0, 255})
+		}
+	}
+	return img
+}
+
+// JPEG with an APP1 EXIF segment, which only has orientation tag
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry, 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(seg)+2))
+	app1 = append(app1, seg...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	img := testImage(4, 2)
+	for _, o := range []uint16{1, 3, 6, 8} {
+		data := jpegWithOrientation(t, img, o)
+		if got := jpegOrientation(data); got != int(o) {
+			t.Errorf("want orientation %d, got %d", o, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, img, nil)
+	if got := jpegOrientation(buf.Bytes()); got != 1 {
+		t.Errorf("want default orientation 1, got %d", got)
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	img := testImage(4, 2)
+	tests := []struct {
+		orientation int
+		w, h        int
+		// Source pixel moved to the top left corner
+		srcX, srcY int
+	}{
+		{1, 4, 2, 0, 0},
+		{2, 4, 2, 3, 0},
+		{3, 4, 2, 3, 1},
+		{4, 4, 2, 0, 1},
+		{5, 2, 4, 0, 0},
+		{6, 2, 4, 0, 1},
+		{7, 2, 4, 3, 1},
+		{8, 2, 4, 3, 0},
+	}
+
+	for _, tt := range tests {
+		got := applyOrientation(img, tt.orientation)
+		if got.Bounds().Dx() != tt.w || got.Bounds().Dy() != tt.h {
+			t.Errorf("orientation %d, want size %dx%d, got %v", tt.orientation, tt.w, tt.h, got.Bounds())
+		}
+
+		if got.At(0, 0) != img.At(tt.srcX, tt.srcY) {
+			t.Errorf("orientation %d, want top left pixel from (%d, %d)", tt.orientation, tt.srcX, tt.srcY)
+		}
+	}
+}
+
+func TestCleanImage(t *testing.T) {
+	data := jpegWithOrientation(t, testImage(40, 20), 6)
+
+	out, img, err := CleanImage(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(out, []byte("Exif")) {
+		t.Error("EXIF should be removed")
+	}
+
+	if img.Bounds().Dx() != 20 || img.Bounds().Dy() != 40 {
+		t.Errorf("orientation should be applied, got size %v", img.Bounds())
+	}
+
+	_, _, err = CleanImage(data, "image/png")
+	if err == nil {
+		t.Error("mismatched type should fail")
+	}
+
+	_, _, err = CleanImage([]byte("not image"), "image/png")
+	if err == nil {
+		t.Error("invalid image should fail")
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		desc string
+		w, h int
+		size int
+		want image.Point
+	}{
+		{"landscape", 400, 200, 100, image.Pt(100, 50)},
+		{"portrait", 200, 400, 100, image.Pt(50, 100)},
+		{"small", 40, 20, 100, image.Pt(40, 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			data, err := Thumbnail(testImage(tt.w, tt.h), tt.size, "image/png")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if img.Bounds().Size() != tt.want {
+				t.Errorf("want size %v, got %v", tt.want, img.Bounds().Size())
+			}
+		})
+	}
+}
+
+func TestCleanGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+		},
+		Delay: []int{10, 10},
+	}
+
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Comment extension before the trailer
+	data := buf.Bytes()
+	data = append(data[:len(data)-1:len(data)-1], []byte("\x21\xfe\x06secret\x00\x3b")...)
+
+	out, img, err := CleanImage(data, "image/gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(out, []byte("secret")) {
+		t.Error("comment should be removed")
+	}
+
+	cleaned, err := gif.DecodeAll(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cleaned.Image) != 2 {
+		t.Errorf("want 2 frames, got %d", len(cleaned.Image))
+	}
+	if img.Bounds().Dx() != 4 {
+		t.Errorf("want the first frame, got size %v", img.Bounds())
+	}
+}
+
+// GIF of frames in the size, the frames have no pixel data
+func testGIFData(width, height, frames int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("GIF89a")
+	binary.Write(&buf, binary.LittleEndian, [2]uint16{uint16(width), uint16(height)})
+	buf.Write([]byte{0, 0, 0})
+	for n := 0; n < frames; n++ {
+		buf.WriteByte(0x2C)
+		binary.Write(&buf, binary.LittleEndian, [4]uint16{0, 0, uint16(width), uint16(height)})
+		buf.Write([]byte{0, 2, 0})
+	}
+	buf.WriteByte(0x3B)
+	return buf.Bytes()
+}
+
+func TestCleanGIFTooLarge(t *testing.T) {
+	tests := []struct {
+		desc string
+		data []byte
+	}{
+		{"too many frames", testGIFData(1, 1, MaxGIFFrames+1)},
+		{"too many pixels", testGIFData(5000, 5000, MaxGIFPixels/(5000*5000)+1)},
+	}
+
+	for _, tt := range tests {
+		_, _, err := CleanImage(tt.data, "image/gif")
+		if !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("%s: want ErrImageTooLarge, got %v", tt.desc, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Class names of highlighted code, styles of them are in static/css/highlight.css
+var HighlightClassRegex = regexp.MustCompile(`^[a-z0-9 ]+$`)
+
+var mentionClassRegex = regexp.MustCompile(`^mention$`)
+
+// Raw HTML in markdown is omitted, the output is sanitized after rendering too
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.Linkify,
+		extension.Strikethrough,
+		highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(
+				chromahtml.WithClasses(true),
+			),
+		),
+	),
+	goldmark.WithParserOptions(
+		parser.WithASTTransformers(
+			util.Prioritized(&mentionTransformer{}, 1000),
+		),
+	),
+	goldmark.WithRendererOptions(
+		gmhtml.WithHardWraps(),
+	),
+)
+
+// RenderMarkdown renders the content written in markdown into HTML, content
+// is HTML escaped before saving, so it's unescaped first
+func RenderMarkdown(content string, p *bluemonday.Policy) string {
+	return renderMarkdown(content, p, parser.NewContext())
+}
+
+// RenderMarkdownMentions is RenderMarkdown with the mentions found by
+// findMentions in text linked to user pages
+func RenderMarkdownMentions(content string, p *bluemonday.Policy, findMentions MentionFinder) string {
+	pc := parser.NewContext()
+	pc.Set(mentionFinderKey, findMentions)
+	return renderMarkdown(content, p, pc)
+}
+
+func renderMarkdown(content string, p *bluemonday.Policy, pc parser.Context) string {
+	var buf bytes.Buffer
+	err := markdown.Convert([]byte(html.UnescapeString(content)), &buf, parser.WithContext(pc))
+	if err != nil {
+		fmt.Println("render markdown error: ", err)
+		return content
+	}
+
+	return p.Sanitize(buf.String())
+}
+
+// AllowMarkdown makes policy keep the class names of highlighted code and
+// mention links
+func AllowMarkdown(p *bluemonday.Policy) *bluemonday.Policy {
+	p.AllowAttrs("class").Matching(HighlightClassRegex).OnElements("pre", "code", "span")
+	p.AllowAttrs("class").Matching(mentionClassRegex).OnElements("a")
+	return p
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	policy := AllowMarkdown(bluemonday.UGCPolicy())
+
+	tests := []struct {
+		desc    string
+		in      string
+		want    []string
+		notWant []string
+	}{
+		{
+			"emphasis",
+			"**bold** and *italic*",
+			[]string{"<strong>bold</strong>", "<em>italic</em>"},
+			nil,
+		},
+		{
+			"list",
+			"- a\n- b",
+			[]string{"<ul>", "<li>a</li>", "<li>b</li>"},
+			nil,
+		},
+		{
+			"hard wraps",
+			"line1\nline2",
+			[]string{"line1<br>"},
+			nil,
+		},
+		{
+			"fenced code with highlighting",
+			"```go\nfunc main() {}\n```",
+			[]string{"<pre class=\"chroma\">", "<span class=\"kd\">func</span>"},
+			nil,
+		},
+		{
+			"autolink",
+			"see https://example.com",
+			[]string{"<a href=\"https://example.com\" rel=\"nofollow\">https://example.com</a>"},
+			nil,
+		},
+		{
+			"raw html is omitted",
+			"<script>alert(1)</script>\n\n<b onclick=\"x()\">b</b>",
+			nil,
+			[]string{"<script", "onclick"},
+		},
+		{
+			"javascript link is removed",
+			"[x](javascript:alert(1))",
+			nil,
+			[]string{"javascript:"},
+		},
+		{
+			"escaped content",
+			"a < b && `c > d`",
+			[]string{"a &lt; b &amp;&amp; <code>c &gt; d</code>"},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			// Content is saved escaped
+			got := RenderMarkdown(html.EscapeString(tt.in), policy)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("want %q in %q", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("don't want %q in %q", s, got)
+				}
+			}
+		})
+	}
+}
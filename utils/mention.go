@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// MentionFinder returns the byte ranges of the mentions to be linked in text,
+// each range covers "@" and the username
+type MentionFinder func(text string) [][]int
+
+func mentionLink(username string) string {
+	return "/users/" + username
+}
+
+var mentionFinderKey = parser.NewContextKey()
+
+// Links the mentions in the text nodes, mentions in links and code are left as
+// they are
+type mentionTransformer struct{}
+
+func (t *mentionTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	findMentions, ok := pc.Get(mentionFinderKey).(MentionFinder)
+	if !ok || findMentions == nil {
+		return
+	}
+
+	var parents []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n.Kind() {
+		case ast.KindLink, ast.KindAutoLink, ast.KindImage, ast.KindCodeSpan:
+			return ast.WalkSkipChildren, nil
+		}
+
+		if n.HasChildren() {
+			parents = append(parents, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	source := reader.Source()
+	for _, parent := range parents {
+		for _, run := range textRuns(parent) {
+			linkMentionNodes(parent, run, source, findMentions)
+		}
+	}
+}
+
+// Runs of adjacent text nodes, which are split by the inline parsers, like the
+// "_" in usernames
+func textRuns(parent ast.Node) [][]*ast.Text {
+	var runs [][]*ast.Text
+	var run []*ast.Text
+
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		node, ok := child.(*ast.Text)
+		if !ok || node.IsRaw() {
+			run = nil
+			continue
+		}
+
+		if len(run) > 0 {
+			prev := run[len(run)-1]
+			if prev.SoftLineBreak() || prev.HardLineBreak() || prev.Segment.Stop != node.Segment.Start {
+				run = nil
+			}
+		}
+
+		if len(run) == 0 {
+			runs = append(runs, nil)
+		}
+		run = append(run, node)
+		runs[len(runs)-1] = run
+	}
+
+	return runs
+}
+
+func linkMentionNodes(parent ast.Node, run []*ast.Text, source []byte, findMentions MentionFinder) {
+	start := run[0].Segment.Start
+	last := run[len(run)-1]
+
+	matches := findMentions(string(source[start:last.Segment.Stop]))
+	if len(matches) == 0 {
+		return
+	}
+
+	pos := start
+	for _, match := range matches {
+		if start+match[0] > pos {
+			parent.InsertBefore(parent, run[0], ast.NewTextSegment(text.NewSegment(pos, start+match[0])))
+		}
+
+		username := string(source[start+match[0]+1 : start+match[1]])
+		link := ast.NewLink()
+		link.Destination = []byte(mentionLink(username))
+		link.SetAttributeString("class", []byte("mention"))
+		link.AppendChild(link, ast.NewTextSegment(text.NewSegment(start+match[0], start+match[1])))
+		parent.InsertBefore(parent, run[0], link)
+
+		pos = start + match[1]
+	}
+
+	// Line breaks are kept by the rest of text, which may be empty
+	rest := ast.NewTextSegment(text.NewSegment(pos, last.Segment.Stop))
+	rest.SetSoftLineBreak(last.SoftLineBreak())
+	rest.SetHardLineBreak(last.HardLineBreak())
+	parent.InsertBefore(parent, run[0], rest)
+
+	for _, node := range run {
+		parent.RemoveChild(parent, node)
+	}
+}
+
+// Escape text as HTML, with the mentions found linked to user pages
+func linkMentionsText(str string, findMentions MentionFinder) string {
+	var b strings.Builder
+	pos := 0
+	for _, match := range findMentions(str) {
+		username := str[match[0]+1 : match[1]]
+		b.WriteString(html.EscapeString(str[pos:match[0]]))
+		b.WriteString(`<a class="mention" href="` + mentionLink(username) + `">@` + html.EscapeString(username) + `</a>`)
+		pos = match[1]
+	}
+	b.WriteString(html.EscapeString(str[pos:]))
+
+	return b.String()
+}
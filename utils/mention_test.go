@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var testMentionRegex = regexp.MustCompile(`@([a-zA-Z0-9_]+)`)
+
+func testMentionFinder(usernames ...string) MentionFinder {
+	return func(text string) [][]int {
+		var list [][]int
+		for _, match := range testMentionRegex.FindAllStringSubmatchIndex(text, -1) {
+			if slices.Contains(usernames, text[match[2]:match[3]]) {
+				list = append(list, match[:2])
+			}
+		}
+		return list
+	}
+}
+
+func TestRenderMarkdownMentions(t *testing.T) {
+	policy := AllowMarkdown(bluemonday.UGCPolicy())
+	aliceLink := `<a href="/users/alice" class="mention" rel="nofollow">@alice</a>`
+
+	tests := []struct {
+		desc    string
+		in      string
+		want    []string
+		notWant []string
+	}{
+		{
+			"paragraph",
+			"hi @alice and @bob",
+			[]string{"<p>hi " + aliceLink + " and @bob</p>"},
+			nil,
+		},
+		{
+			"emphasis",
+			"**@alice**",
+			[]string{"<strong>" + aliceLink + "</strong>"},
+			nil,
+		},
+		{
+			"username split by inline parsers",
+			"hi @alice_x",
+			[]string{`<a href="/users/alice_x" class="mention" rel="nofollow">@alice_x</a>`},
+			nil,
+		},
+		{
+			"line breaks are kept",
+			"@alice\nnext",
+			[]string{aliceLink + "<br>\nnext"},
+			nil,
+		},
+		{
+			"code block",
+			"```\n@alice\n```",
+			[]string{"@alice"},
+			[]string{"/users/alice"},
+		},
+		{
+			"inline code",
+			"`@alice`",
+			[]string{"<code>@alice</code>"},
+			[]string{"/users/alice"},
+		},
+		{
+			"link title attribute",
+			`[home](https://example.com "@alice")`,
+			[]string{`<a href="https://example.com" rel="nofollow">home</a></p>`},
+			[]string{"/users/alice"},
+		},
+		{
+			"link text",
+			"[@alice](https://example.com)",
+			[]string{`<a href="https://example.com" rel="nofollow">@alice</a>`},
+			[]string{"/users/alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := RenderMarkdownMentions(html.EscapeString(tt.in), policy, testMentionFinder("alice", "alice_x"))
+			for _, str := range tt.want {
+				if !strings.Contains(got, str) {
+					t.Errorf("want %q in %q", str, got)
+				}
+			}
+			for _, str := range tt.notWant {
+				if strings.Contains(got, str) {
+					t.Errorf("not want %q in %q", str, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceLinkMentions(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{
+			"mention",
+			"hi @alice",
+			`hi <a class="mention" href="/users/alice">@alice</a>`,
+		},
+		{
+			"text is escaped",
+			"<b>@alice</b>",
+			`&lt;b&gt;<a class="mention" href="/users/alice">@alice</a>&lt;/b&gt;`,
+		},
+		{
+			"link attribute",
+			"see https://example.com/@alice",
+			`see <a title="https://example.com/@alice" href="https://example.com/@alice">https://example.com/@alice</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := ReplaceLinkMentions(html.EscapeString(tt.in), testMentionFinder("alice"))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL returns the key of a link for finding duplicated submissions,
+// the scheme, "www.", default port, fragment, trailing slash and utm_*
+// parameters are removed, other parameters are sorted. Returns empty string
+// if the link can't be parsed.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	result := host + strings.TrimRight(u.EscapedPath(), "/")
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+	return result
+}
@@ -0,0 +1,1208 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+	"github.com/jackc/pgx/v5"
+	mdw "github.com/oodzchen/dproject/middleware"
+	"github.com/oodzchen/dproject/model"
+)
+
+const (
+	ApiMaxPageSize int   = 100
+	ApiMaxBodySize int64 = 1 << 20
+)
+
+type ApiResource struct {
+	*Renderer
+	articleRs *ArticleResource
+}
+
+type ApiError struct {
+	Status  int    `json:"status"`
+	Code    int    `json:"code,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+type ApiResponse struct {
+	Data  any       `json:"data,omitempty"`
+	Error *ApiError `json:"error,omitempty"`
+}
+
+type ApiList[T any] struct {
+	List      []T `json:"list"`
+	Total     int `json:"total"`
+	Page      int `json:"page"`
+	PageSize  int `json:"page_size"`
+	TotalPage int `json:"total_page"`
+}
+
+func NewApiResource(renderer *Renderer, ar *ArticleResource) *ApiResource {
+	return &ApiResource{
+		renderer,
+		ar,
+	}
+}
+
+func (ar *ApiResource) Routes() http.Handler {
+	rt := chi.NewRouter()
+
+	rt.Use(mdw.TokenAuth(ar.store, ar.srv.ApiToken, ar))
+	rt.Use(ar.parseBody)
+
+	rt.NotFound(ar.NotFound)
+	rt.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		ar.Error("", nil, w, r, http.StatusMethodNotAllowed)
+	})
+
+	// Profile of the token owner is allowed with any API token
+	rt.With(ar.authCheck).Get("/me", ar.Me)
+	rt.Get("/reacts", ar.ReactList)
+
+	rt.Route("/articles", func(r chi.Router) {
+		r.Get("/", ar.ArticleList)
+		r.With(
+			ar.authCheck,
+			mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.create",
+			}, ar),
+			mdw.UserLogger(ar.uLogger, model.AcTypeUser, model.AcActionCreateArticle, model.AcModelArticle, mdw.ULogNewArticleId),
+			httprate.Limit(
+				6,
+				1*time.Minute,
+				httprate.WithKeyByIP(),
+				httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+					ar.Error("", nil, w, r, http.StatusTooManyRequests)
+				}),
+			),
+		).Post("/", ar.ArticleCreate)
+
+		r.Route("/{articleId}", func(r chi.Router) {
+			r.Get("/", ar.ArticleItem)
+			r.Get("/replies", ar.ReplyList)
+
+			r.With(ar.authCheck, mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.reply",
+			}, ar), mdw.UserLogger(
+				ar.uLogger, model.AcTypeUser, model.AcActionReplyArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/replies", ar.ReplyCreate)
+
+			r.With(ar.authCheck, mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.vote_up",
+				"article.vote_down",
+			}, ar), mdw.UserLogger(
+				ar.uLogger, model.AcTypeUser, model.AcActionVoteArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/vote", ar.Vote)
+
+			r.With(ar.authCheck, mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.react",
+			}, ar), mdw.UserLogger(
+				ar.uLogger, model.AcTypeUser, model.AcActionReactArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/react", ar.React)
+
+			r.With(ar.authCheck, mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.save",
+			}, ar), mdw.UserLogger(
+				ar.uLogger, model.AcTypeUser, model.AcActionSaveArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/save", ar.Save)
+
+			r.With(ar.authCheck, mdw.PermitCheck(ar.srv.Permission, []string{
+				"article.subscribe",
+			}, ar), mdw.UserLogger(
+				ar.uLogger, model.AcTypeUser, model.AcActionSubscribeArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/subscribe", ar.Subscribe)
+		})
+	})
+
+	rt.Route("/categories", func(r chi.Router) {
+		r.Get("/", ar.CategoryList)
+		r.Get("/{categoryFrontId}", ar.CategoryItem)
+		r.With(ar.authCheck, ar.tokenPermitCheck("category.subscribe")).Post("/{categoryFrontId}/subscribe", ar.CategorySubscribe)
+		r.With(ar.authCheck, ar.tokenPermitCheck("category.subscribe")).Post("/{categoryFrontId}/ignore", ar.CategoryIgnore)
+	})
+
+	rt.Get("/tags", ar.TagList)
+	// Allowed with any API token like /me, the usernames are public
+	rt.With(ar.authCheck).Get("/usernames", ar.UsernameList)
+
+	rt.With(ar.authCheck, ar.tokenPermitCheck("user.access_messages")).Route("/messages", func(r chi.Router) {
+		r.Get("/", ar.MessageList)
+		r.Get("/unread_count", ar.MessageUnreadCount)
+		r.Post("/read", ar.MessageReadAll)
+	})
+
+	return rt
+}
+
+// Decode JSON request body into r.PostForm, so that form based middlewares,
+// such as UserLogger, keep working with API requests.
+// The API is not covered by the CSRF token check, so requests authenticated by
+// cookie must have the JSON content type even without body, which can't be
+// sent by cross-site forms. Requests with API token can omit the body.
+func (ar *ApiResource) parseBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Authorization header is verified by TokenAuth before
+		withToken := r.Header.Get("Authorization") != ""
+		contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+		if (r.ContentLength != 0 || !withToken) && !strings.EqualFold(contentType, "application/json") {
+			ar.Error("", errors.New("content type not supported: "+contentType), w, r, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		form, err := parseJSONForm(http.MaxBytesReader(w, r.Body, ApiMaxBodySize))
+		if err != nil {
+			ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+			return
+		}
+
+		r.PostForm = form
+		r.Form = make(url.Values)
+		for k, v := range r.URL.Query() {
+			r.Form[k] = v
+		}
+		for k, v := range form {
+			r.Form[k] = append(v, r.Form[k]...)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func parseJSONForm(body io.Reader) (url.Values, error) {
+	form := make(url.Values)
+
+	var data map[string]any
+	err := json.NewDecoder(body).Decode(&data)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return form, nil
+		}
+		return nil, errors.New("invalid json body")
+	}
+
+	for key, val := range data {
+		switch v := val.(type) {
+		case []any:
+			for _, item := range v {
+				str, ok := jsonValueToString(item)
+				if !ok {
+					return nil, fmt.Errorf("invalid value of field: %s", key)
+				}
+				form.Add(key, str)
+			}
+		default:
+			str, ok := jsonValueToString(v)
+			if !ok {
+				return nil, fmt.Errorf("invalid value of field: %s", key)
+			}
+			form.Set(key, str)
+		}
+	}
+
+	return form, nil
+}
+
+// Convert JSON value to the same string that html form posts,
+// boolean value true converted to "1", as checkbox value in templates
+func jsonValueToString(val any) (string, bool) {
+	switch v := val.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+func (ar *ApiResource) authCheck(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ar.currUserId(r) == 0 {
+			ar.Unauthorized(nil, w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Requests with API token must be granted the permission, requests with
+// session are not limited, the same as the pages of the features
+func (ar *ApiResource) tokenPermitCheck(permissionId string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := ar.GetLoginedUserData(r); user != nil && !user.TokenPermit(permissionId) {
+				ar.Forbidden(nil, w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (ar *ApiResource) currUserId(r *http.Request) int {
+	if user := ar.GetLoginedUserData(r); user != nil {
+		return user.Id
+	}
+	return 0
+}
+
+func (ar *ApiResource) JSON(w http.ResponseWriter, r *http.Request, data any, code int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+
+	err := json.NewEncoder(w).Encode(&ApiResponse{Data: data})
+	if err != nil {
+		fmt.Println("encode json response error:", err)
+	}
+}
+
+func (ar *ApiResource) Error(msg string, err error, w http.ResponseWriter, r *http.Request, code int) {
+	fmt.Printf("api err: %+v\n", err)
+
+	apiErr := &ApiError{
+		Status:  code,
+		Message: msg,
+	}
+
+	var appErr *model.AppError
+	if errors.As(err, &appErr) {
+		apiErr.Code = int(appErr.ErrCode)
+		apiErr.Name = appErr.ErrCode.String()
+		if apiErr.Message == "" {
+			apiErr.Message = appErr.ErrCode.Text(false, ar.Localizer(r))
+		}
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(code)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+
+	err = json.NewEncoder(w).Encode(&ApiResponse{Error: apiErr})
+	if err != nil {
+		fmt.Println("encode json error response error:", err)
+	}
+}
+
+func (ar *ApiResource) ServerErrorp(msg string, err error, w http.ResponseWriter, r *http.Request) {
+	ar.Error(msg, err, w, r, http.StatusInternalServerError)
+}
+
+func (ar *ApiResource) NotFound(w http.ResponseWriter, r *http.Request) {
+	ar.Error("", nil, w, r, http.StatusNotFound)
+}
+
+func (ar *ApiResource) Forbidden(err error, w http.ResponseWriter, r *http.Request) {
+	ar.Error("", err, w, r, http.StatusForbidden)
+}
+
+func (ar *ApiResource) Unauthorized(err error, w http.ResponseWriter, r *http.Request) {
+	ar.Error("", err, w, r, http.StatusUnauthorized)
+}
+
+// Respond store and service errors with matched http status
+func (ar *ApiResource) StoreError(err error, w http.ResponseWriter, r *http.Request) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows),
+		errors.Is(err, model.AppErrArticleNotExist),
+		errors.Is(err, model.AppErrUserNotExist):
+		ar.Error("", err, w, r, http.StatusNotFound)
+	case errors.Is(err, model.AppErrArticleValidFailed),
+		errors.Is(err, model.AppErrCategoryValidFailed),
+		errors.Is(err, model.AppErrUserValidFailed):
+		ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+	default:
+		ar.ServerErrorp("", err, w, r)
+	}
+}
+
+func (ar *ApiResource) paginationData(r *http.Request) (int, int) {
+	page, pageSize := ar.GetPaginationData(r)
+	if pageSize > ApiMaxPageSize {
+		pageSize = ApiMaxPageSize
+	}
+	return page, pageSize
+}
+
+func (ar *ApiResource) articleIdParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+	if err != nil {
+		ar.Error("", errors.New("get article id failed"), w, r, http.StatusBadRequest)
+		return 0, false
+	}
+	return articleId, true
+}
+
+type apiUser struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	Introduction string    `json:"introduction"`
+	RoleFrontId  string    `json:"role_front_id"`
+	RoleName     string    `json:"role_name"`
+	Reputation   int       `json:"reputation"`
+	Banned       bool      `json:"banned"`
+	RegisteredAt time.Time `json:"registered_at"`
+	Permissions  []string  `json:"permissions,omitempty"`
+}
+
+func toApiUser(u *model.User) *apiUser {
+	return &apiUser{
+		Id:           u.Id,
+		Name:         u.Name,
+		Introduction: u.Introduction,
+		RoleFrontId:  u.RoleFrontId,
+		RoleName:     u.RoleName,
+		Reputation:   u.Reputation,
+		Banned:       u.Banned,
+		RegisteredAt: u.RegisteredAt,
+	}
+}
+
+type apiCategory struct {
+	Id                int    `json:"id"`
+	FrontId           string `json:"front_id"`
+	Name              string `json:"name"`
+	Describe          string `json:"describe"`
+	TotalArticleCount int    `json:"total_article_count"`
+	Subscribed        bool   `json:"subscribed"`
+	Ignored           bool   `json:"ignored"`
+}
+
+func toApiCategory(c *model.Category) *apiCategory {
+	if c == nil {
+		return nil
+	}
+
+	item := &apiCategory{
+		Id:                c.Id,
+		FrontId:           c.FrontId,
+		Name:              c.Name,
+		Describe:          c.Describe,
+		TotalArticleCount: c.TotalArticleCount,
+	}
+
+	if c.UserState != nil {
+		item.Subscribed = c.UserState.Subscribed
+		item.Ignored = c.UserState.Ignored
+	}
+
+	return item
+}
+
+type apiTag struct {
+	FrontId           string `json:"front_id"`
+	Name              string `json:"name"`
+	Describe          string `json:"describe"`
+	TotalArticleCount int    `json:"total_article_count"`
+}
+
+func toApiTag(t *model.Tag) *apiTag {
+	return &apiTag{
+		FrontId:           t.FrontId,
+		Name:              t.Name,
+		Describe:          t.Describe,
+		TotalArticleCount: t.TotalArticleCount,
+	}
+}
+
+type apiUserState struct {
+	VoteType     model.VoteType `json:"vote_type"`
+	Saved        bool           `json:"saved"`
+	ReactFrontId string         `json:"react_front_id"`
+	Subscribed   bool           `json:"subscribed"`
+}
+
+type apiArticle struct {
+	Id                 int                      `json:"id"`
+	Title              string                   `json:"title"`
+	Link               string                   `json:"link"`
+	Content            string                   `json:"content"`
+	ContentFormat      model.ContentFormat      `json:"content_format"`
+	AuthorId           int                      `json:"author_id"`
+	AuthorName         string                   `json:"author_name"`
+	CategoryFrontId    string                   `json:"category_front_id"`
+	Tags               []string                 `json:"tags"`
+	ReplyToId          int                      `json:"reply_to_id"`
+	ReplyRootArticleId int                      `json:"reply_root_article_id"`
+	ReplyDepth         int                      `json:"reply_depth"`
+	ChildrenCount      int                      `json:"children_count"`
+	TotalReplyCount    int                      `json:"total_reply_count"`
+	VoteUp             *int                     `json:"vote_up,omitempty"`
+	VoteDown           *int                     `json:"vote_down,omitempty"`
+	ReactCounts        model.ArticleReactCounts `json:"react_counts"`
+	Deleted            bool                     `json:"deleted"`
+	Locked             bool                     `json:"locked"`
+	Pinned             bool                     `json:"pinned"`
+	PinnedExpireAt     *time.Time               `json:"pinned_expire_at,omitempty"`
+	FadeOut            bool                     `json:"fade_out"`
+	AuthorBlocked      bool                     `json:"author_blocked"`
+	CreatedAt          time.Time                `json:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+	CurrUserState      *apiUserState            `json:"curr_user_state,omitempty"`
+}
+
+func toApiArticle(a *model.Article) *apiArticle {
+	item := &apiArticle{
+		Id:                 a.Id,
+		Title:              a.Title,
+		Link:               a.Link,
+		Content:            a.Content,
+		ContentFormat:      a.ContentFormat,
+		AuthorId:           a.AuthorId,
+		AuthorName:         a.AuthorName,
+		CategoryFrontId:    a.CategoryFrontId,
+		ReplyToId:          a.ReplyToId,
+		ReplyRootArticleId: a.ReplyRootArticleId,
+		ReplyDepth:         a.ReplyDepth,
+		ChildrenCount:      a.ChildrenCount,
+		TotalReplyCount:    a.TotalReplyCount,
+		ReactCounts:        a.ReactCounts,
+		Deleted:            a.Deleted,
+		Locked:             a.Locked,
+		Pinned:             a.Pinned,
+		FadeOut:            a.FadeOut,
+		AuthorBlocked:      a.AuthorBlocked,
+		CreatedAt:          a.CreatedAt,
+		UpdatedAt:          a.UpdatedAt,
+	}
+
+	if a.Deleted {
+		item.Title = ""
+		item.Link = ""
+		item.Content = ""
+	}
+
+	// Keep the same rule of score display with html pages
+	if a.ShowScore {
+		item.VoteUp = &a.VoteUp
+		item.VoteDown = &a.VoteDown
+	}
+
+	if a.Pinned && !a.PinnedExpireAt.IsZero() {
+		item.PinnedExpireAt = &a.PinnedExpireAt
+	}
+
+	if item.CategoryFrontId == "" && a.Category != nil {
+		item.CategoryFrontId = a.Category.FrontId
+	}
+
+	item.Tags = make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		item.Tags = append(item.Tags, tag.FrontId)
+	}
+
+	if a.CurrUserState != nil {
+		item.CurrUserState = &apiUserState{
+			VoteType:     a.CurrUserState.VoteType,
+			Saved:        a.CurrUserState.Saved,
+			ReactFrontId: a.CurrUserState.ReactFrontId,
+			Subscribed:   a.CurrUserState.Subscribed,
+		}
+	}
+
+	return item
+}
+
+func toApiArticleList(list []*model.Article) []*apiArticle {
+	res := make([]*apiArticle, 0, len(list))
+	for _, item := range list {
+		res = append(res, toApiArticle(item))
+	}
+	return res
+}
+
+type apiReact struct {
+	Id       int    `json:"id"`
+	Emoji    string `json:"emoji"`
+	FrontId  string `json:"front_id"`
+	Describe string `json:"describe"`
+}
+
+type apiMessage struct {
+	Id               int                 `json:"id"`
+	Type             model.MessageType   `json:"type"`
+	Action           model.MessageAction `json:"action,omitempty"`
+	Content          string              `json:"content"`
+	SenderUserId     int                 `json:"sender_user_id"`
+	SenderUserName   string              `json:"sender_user_name"`
+	SourceArticleId  int                 `json:"source_article_id,omitempty"`
+	SourceCategoryId string              `json:"source_category_front_id,omitempty"`
+	ContentArticle   *apiArticle         `json:"content_article,omitempty"`
+	IsRead           bool                `json:"is_read"`
+	CreatedAt        *time.Time          `json:"created_at"`
+}
+
+func toApiMessage(m *model.Message) *apiMessage {
+	item := &apiMessage{
+		Id:             m.Id,
+		Type:           m.Type,
+		Action:         m.Action,
+		Content:        m.Content,
+		SenderUserId:   m.SenderUserId,
+		SenderUserName: m.SenderUserName,
+		IsRead:         m.IsRead,
+		CreatedAt:      m.CreatedAt,
+	}
+
+	if m.SourceArticle != nil {
+		item.SourceArticleId = m.SourceArticle.Id
+	}
+
+	if m.SourceCategory != nil {
+		item.SourceCategoryId = m.SourceCategory.FrontId
+	}
+
+	if m.ContentArticle != nil {
+		item.ContentArticle = toApiArticle(m.ContentArticle)
+	}
+
+	return item
+}
+
+func (ar *ApiResource) Me(w http.ResponseWriter, r *http.Request) {
+	user := ar.GetLoginedUserData(r)
+
+	item := toApiUser(user)
+	item.Permissions = ar.srv.Permission.GetEnabledIdList(user)
+
+	ar.JSON(w, r, item, http.StatusOK)
+}
+
+func (ar *ApiResource) ArticleList(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := ar.paginationData(r)
+	categoryFrontId := r.URL.Query().Get("category")
+	tagFrontId := r.URL.Query().Get("tag")
+	currUserId := ar.currUserId(r)
+
+	sortType := model.DefaultArticleListSortType
+	if sort := r.URL.Query().Get("sort"); model.ValidArticleSort(sort) {
+		sortType = model.ArticleSortType(sort)
+	}
+
+	startTime := time.Now()
+
+	var wg sync.WaitGroup
+	ch := make(chan any, 3)
+	var total int
+	var list []*model.Article
+	var pinnedList []*model.Article
+
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		total, err := ar.store.Article.Count(categoryFrontId, tagFrontId, listIgnoreUserId(categoryFrontId, currUserId), false)
+		if err != nil {
+			ch <- err
+			return
+		}
+		ch <- total
+	}()
+
+	go ar.articleRs.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, tagFrontId, currUserId, startTime, ch, false)
+
+	if page == 1 {
+		go ar.articleRs.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, tagFrontId, currUserId, startTime, ch, true)
+	} else {
+		wg.Done()
+	}
+
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	for res := range ch {
+		switch v := res.(type) {
+		case error:
+			ar.StoreError(v, w, r)
+			return
+		case int:
+			total = v
+		case *aList:
+			if v.Pinned {
+				pinnedList = v.List
+			} else {
+				list = v.List
+			}
+		}
+	}
+
+	if page == 1 {
+		list = append(pinnedList, list...)
+	}
+
+	list = ar.filterBlocked(list, currUserId, r)
+
+	ar.articleRs.updateAuthorBlockedState(list, r)
+	list = filterArray(list, func(item *model.Article) bool {
+		return !item.AuthorBlocked
+	})
+
+	ar.JSON(w, r, &ApiList[*apiArticle]{
+		List:      toApiArticleList(list),
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: CeilInt(total, pageSize),
+	}, http.StatusOK)
+}
+
+// Filter out articles blocked in request region, same as the html pages
+func (ar *ApiResource) filterBlocked(list []*model.Article, currUserId int, r *http.Request) []*model.Article {
+	requestRegionCode := r.Context().Value("region_country_iso_code")
+	for _, item := range list {
+		item.CheckShowScore(currUserId)
+
+		if code, ok := requestRegionCode.(string); ok {
+			item.UpdateBlockedState(code)
+		}
+	}
+
+	canEditOthers := ar.CheckPermit(r, "article", "edit_others")
+	return filterArray(list, func(item *model.Article) bool {
+		return canEditOthers || !item.Blocked
+	})
+}
+
+func (ar *ApiResource) ArticleItem(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	currUserId := ar.currUserId(r)
+
+	article, err := ar.store.Article.Item(articleId, currUserId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	if article == nil || article.Id == 0 {
+		ar.NotFound(w, r)
+		return
+	}
+
+	if code, ok := r.Context().Value("region_country_iso_code").(string); ok {
+		article.UpdateBlockedState(code)
+	}
+
+	if article.Blocked && !ar.CheckPermit(r, "article", "edit_others") {
+		ar.Error("", nil, w, r, http.StatusUnavailableForLegalReasons)
+		return
+	}
+
+	article.CheckShowScore(currUserId)
+	ar.articleRs.updateAuthorBlockedState([]*model.Article{article}, r)
+
+	totalReplyCount, err := ar.store.Article.CountTotalReply(articleId)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+	article.TotalReplyCount = totalReplyCount
+
+	ar.JSON(w, r, toApiArticle(article), http.StatusOK)
+}
+
+func (ar *ApiResource) ReplyList(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	page, pageSize := ar.paginationData(r)
+	currUserId := ar.currUserId(r)
+
+	sortType := model.DefaultReplyListSortType
+	if sort := r.URL.Query().Get("sort"); model.ValidArticleSort(sort) {
+		sortType = model.ArticleSortType(sort)
+	}
+
+	_, err := ar.store.Article.Item(articleId, 0)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	startTime := time.Now()
+
+	var wg sync.WaitGroup
+	ch := make(chan any, 3)
+	var total int
+	var list []*model.Article
+	var pinnedList []*model.Article
+
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		total, err := ar.store.Article.CountTotalReply(articleId)
+		if err != nil {
+			ch <- err
+			return
+		}
+		ch <- total
+	}()
+
+	go ar.articleRs.getReplyList(articleId, currUserId, page, pageSize, sortType, ArticlePageDetail, &wg, ch, startTime, model.RepliesLayoutTile, false)
+
+	if page == 1 {
+		go ar.articleRs.getReplyList(articleId, currUserId, page, pageSize, sortType, ArticlePageDetail, &wg, ch, startTime, model.RepliesLayoutTile, true)
+	} else {
+		wg.Done()
+	}
+
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	for res := range ch {
+		switch v := res.(type) {
+		case error:
+			ar.StoreError(v, w, r)
+			return
+		case int:
+			total = v
+		case *aList:
+			if v.Pinned {
+				pinnedList = v.List
+			} else {
+				list = v.List
+			}
+		}
+	}
+
+	if page == 1 {
+		list = append(pinnedList, list...)
+	}
+
+	list = ar.filterBlocked(list, currUserId, r)
+	ar.articleRs.updateAuthorBlockedState(list, r)
+
+	ar.JSON(w, r, &ApiList[*apiArticle]{
+		List:      toApiArticleList(list),
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: CeilInt(total, pageSize),
+	}, http.StatusOK)
+}
+
+func (ar *ApiResource) ArticleCreate(w http.ResponseWriter, r *http.Request) {
+	ar.handleCreate(w, r, false)
+}
+
+func (ar *ApiResource) ReplyCreate(w http.ResponseWriter, r *http.Request) {
+	ar.handleCreate(w, r, true)
+}
+
+func (ar *ApiResource) handleCreate(w http.ResponseWriter, r *http.Request, isReply bool) {
+	title := r.PostForm.Get("title")
+	link := r.PostForm.Get("url")
+	content := r.PostForm.Get("content")
+	categoryFrontId := r.PostForm.Get("category_front_id")
+
+	var pinnedExpireAt time.Time
+	var locked bool
+
+	if pinnedExpireAtStr := r.PostForm.Get("pinned_expire_at"); pinnedExpireAtStr != "" {
+		t, err := time.Parse(time.RFC3339, pinnedExpireAtStr)
+		if err != nil {
+			ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "PinExpireTime")), err, w, r, http.StatusBadRequest)
+			return
+		}
+		pinnedExpireAt = t.UTC()
+	}
+
+	if r.PostForm.Get("locked") == "1" {
+		locked = true
+	}
+
+	authorId := ar.currUserId(r)
+
+	var replyToId int
+	if isReply {
+		var ok bool
+		replyToId, ok = ar.articleIdParam(w, r)
+		if !ok {
+			return
+		}
+
+		err := ar.articleRs.checkLocked(replyToId, r)
+		if err != nil {
+			ar.StoreError(err, w, r)
+			return
+		}
+	}
+
+	permitted, err := ar.articleRs.checkPinLock(!pinnedExpireAt.IsZero(), locked, categoryFrontId, replyToId, r)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+	if !permitted {
+		ar.Forbidden(errors.New("no permission to pin or lock article"), w, r)
+		return
+	}
+
+	var id int
+	if isReply {
+		id, err = ar.srv.Article.Reply(replyToId, content, authorId, pinnedExpireAt, locked)
+	} else {
+		id, err = ar.srv.Article.Create(title, link, content, authorId, 0, categoryFrontId, r.PostForm["tags"], pinnedExpireAt, locked)
+	}
+
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "article_id", id)
+	*r = *r.WithContext(ctx)
+
+	article, err := ar.store.Article.Item(id, authorId)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+	article.CheckShowScore(authorId)
+
+	ar.JSON(w, r, toApiArticle(article), http.StatusCreated)
+}
+
+func (ar *ApiResource) Vote(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	voteType := r.PostForm.Get("type")
+	if !model.IsValidVoteType(model.VoteType(voteType)) {
+		ar.Error("vote type not valid", errors.New("vote type not valid: "+voteType), w, r, http.StatusBadRequest)
+		return
+	}
+
+	if !ar.checkLocked(articleId, w, r) {
+		return
+	}
+
+	userId := ar.currUserId(r)
+	code, err := ar.store.Article.ToggleVote(articleId, userId, voteType)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	go ar.articleRs.updateVoteReputation(articleId, userId, voteType, code)
+
+	ar.userState(articleId, w, r)
+}
+
+func (ar *ApiResource) React(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	reactId, err := strconv.Atoi(r.PostForm.Get("react_id"))
+	if err != nil {
+		ar.Error("react id not valid", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	if !ar.checkLocked(articleId, w, r) {
+		return
+	}
+
+	reactItem, err := ar.store.Article.ReactItem(reactId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.Error("react id not valid", err, w, r, http.StatusBadRequest)
+			return
+		}
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	userId := ar.currUserId(r)
+	code, prevFrontId, err := ar.store.Article.ToggleReact(articleId, userId, reactId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	go ar.articleRs.updateReactReputation(articleId, userId, reactItem, code, prevFrontId)
+
+	ar.userState(articleId, w, r)
+}
+
+func (ar *ApiResource) Save(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if !ar.checkLocked(articleId, w, r) {
+		return
+	}
+
+	err := ar.store.Article.ToggleSave(articleId, ar.currUserId(r))
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	ar.userState(articleId, w, r)
+}
+
+func (ar *ApiResource) Subscribe(w http.ResponseWriter, r *http.Request) {
+	articleId, ok := ar.articleIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if !ar.checkLocked(articleId, w, r) {
+		return
+	}
+
+	err := ar.store.Article.ToggleSubscribe(articleId, ar.currUserId(r))
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	ar.userState(articleId, w, r)
+}
+
+func (ar *ApiResource) checkLocked(articleId int, w http.ResponseWriter, r *http.Request) bool {
+	err := ar.articleRs.checkLocked(articleId, r)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.NotFound(w, r)
+		} else {
+			ar.Forbidden(err, w, r)
+		}
+		return false
+	}
+	return true
+}
+
+// Respond current user state of the article after toggle actions
+func (ar *ApiResource) userState(articleId int, w http.ResponseWriter, r *http.Request) {
+	list, err := ar.store.Article.ItemTreeUserState([]int{articleId}, ar.currUserId(r))
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	state := &apiUserState{}
+	for _, item := range list {
+		if item.Id == articleId && item.CurrUserState != nil {
+			state.VoteType = item.CurrUserState.VoteType
+			state.Saved = item.CurrUserState.Saved
+			state.ReactFrontId = item.CurrUserState.ReactFrontId
+			state.Subscribed = item.CurrUserState.Subscribed
+		}
+	}
+
+	ar.JSON(w, r, state, http.StatusOK)
+}
+
+func (ar *ApiResource) ReactList(w http.ResponseWriter, r *http.Request) {
+	list, err := ar.store.Article.GetReactList()
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	res := make([]*apiReact, 0, len(list))
+	for _, item := range list {
+		res = append(res, &apiReact{item.Id, item.Emoji, item.FrontId, item.Describe})
+	}
+
+	ar.JSON(w, r, res, http.StatusOK)
+}
+
+func (ar *ApiResource) CategoryList(w http.ResponseWriter, r *http.Request) {
+	list, err := ar.store.Category.List(model.CategoryStateApproved)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	res := make([]*apiCategory, 0, len(list))
+	for _, item := range list {
+		res = append(res, toApiCategory(item))
+	}
+
+	ar.JSON(w, r, res, http.StatusOK)
+}
+
+func (ar *ApiResource) CategoryItem(w http.ResponseWriter, r *http.Request) {
+	category, err := ar.store.Category.Item(chi.URLParam(r, "categoryFrontId"), ar.currUserId(r))
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	if !category.Approved {
+		ar.NotFound(w, r)
+		return
+	}
+
+	ar.JSON(w, r, toApiCategory(category), http.StatusOK)
+}
+
+func (ar *ApiResource) TagList(w http.ResponseWriter, r *http.Request) {
+	list, err := ar.store.Tag.List()
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	res := make([]*apiTag, 0, len(list))
+	for _, item := range list {
+		res = append(res, toApiTag(item))
+	}
+
+	ar.JSON(w, r, res, http.StatusOK)
+}
+
+// Max count of usernames returned for mention autocompleting
+const ApiUsernameListSize = 8
+
+func (ar *ApiResource) UsernameList(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimPrefix(strings.TrimSpace(r.URL.Query().Get("prefix")), "@")
+	if prefix == "" || len(prefix) > model.MaxUsernameLen {
+		ar.JSON(w, r, []string{}, http.StatusOK)
+		return
+	}
+
+	list, err := ar.store.User.ListUsernames(prefix, ApiUsernameListSize)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	if list == nil {
+		list = []string{}
+	}
+
+	ar.JSON(w, r, list, http.StatusOK)
+}
+
+func (ar *ApiResource) CategorySubscribe(w http.ResponseWriter, r *http.Request) {
+	categoryFrontId := chi.URLParam(r, "categoryFrontId")
+	userId := ar.currUserId(r)
+
+	err := ar.store.Category.Subscribe(categoryFrontId, userId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	category, err := ar.store.Category.Item(categoryFrontId, userId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	ar.JSON(w, r, toApiCategory(category), http.StatusOK)
+}
+
+func (ar *ApiResource) CategoryIgnore(w http.ResponseWriter, r *http.Request) {
+	categoryFrontId := chi.URLParam(r, "categoryFrontId")
+	userId := ar.currUserId(r)
+
+	err := ar.store.Category.Ignore(categoryFrontId, userId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	category, err := ar.store.Category.Item(categoryFrontId, userId)
+	if err != nil {
+		ar.StoreError(err, w, r)
+		return
+	}
+
+	ar.JSON(w, r, toApiCategory(category), http.StatusOK)
+}
+
+func (ar *ApiResource) MessageList(w http.ResponseWriter, r *http.Request) {
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	if _, ok := MessageStatusMap[MessageStatus(status)]; !ok {
+		status = string(MessageStatusAll)
+	}
+
+	page, pageSize := ar.paginationData(r)
+
+	list, total, err := ar.store.Message.List(ar.currUserId(r), status, page, pageSize)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	res := make([]*apiMessage, 0, len(list))
+	for _, item := range list {
+		res = append(res, toApiMessage(item))
+	}
+
+	ar.JSON(w, r, &ApiList[*apiMessage]{
+		List:      res,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: CeilInt(total, pageSize),
+	}, http.StatusOK)
+}
+
+func (ar *ApiResource) MessageUnreadCount(w http.ResponseWriter, r *http.Request) {
+	count, err := ar.store.Message.UnreadCount(ar.currUserId(r))
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	ar.JSON(w, r, map[string]int{"count": count}, http.StatusOK)
+}
+
+func (ar *ApiResource) MessageReadAll(w http.ResponseWriter, r *http.Request) {
+	err := ar.store.Message.ReadAll(ar.currUserId(r))
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	ar.JSON(w, r, map[string]int{"count": 0}, http.StatusOK)
+}
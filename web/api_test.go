@@ -0,0 +1,166 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oodzchen/dproject/config"
+	i18nc "github.com/oodzchen/dproject/i18n"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/service"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseJSONForm(t *testing.T) {
+	tests := []struct {
+		desc    string
+		body    string
+		want    url.Values
+		wantErr bool
+	}{
+		{
+			"empty body",
+			"",
+			url.Values{},
+			false,
+		},
+		{
+			"string, number and boolean values",
+			`{"title": "hello", "react_id": 3, "pinned": true, "locked": false, "score": 1.5}`,
+			url.Values{
+				"title":    {"hello"},
+				"react_id": {"3"},
+				"pinned":   {"1"},
+				"locked":   {""},
+				"score":    {"1.5"},
+			},
+			false,
+		},
+		{
+			"array values",
+			`{"regions": ["us", "in"]}`,
+			url.Values{
+				"regions": {"us", "in"},
+			},
+			false,
+		},
+		{
+			"nested object",
+			`{"article": {"title": "hello"}}`,
+			nil,
+			true,
+		},
+		{
+			"not an object",
+			`["hello"]`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := parseJSONForm(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseBodyContentType(t *testing.T) {
+	ar := &ApiResource{Renderer: &Renderer{}}
+	handler := ar.parseBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		desc        string
+		body        string
+		contentType string
+		auth        string
+		want        int
+	}{
+		{"cookie without body", "", "", "", http.StatusUnsupportedMediaType},
+		{"cookie with form body", "a=1", "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"cookie with json type", "", "application/json", "", http.StatusNoContent},
+		{"cookie with json body", `{"a": 1}`, "application/json; charset=utf-8", "", http.StatusNoContent},
+		{"token without body", "", "", "Bearer token", http.StatusNoContent},
+		{"token with form body", "a=1", "application/x-www-form-urlencoded", "Bearer token", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/articles/1/save", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("want status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestApiTokenScope(t *testing.T) {
+	if config.Config == nil {
+		config.Config = &config.AppConfig{}
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	srv := &service.Service{
+		Permission: &service.Permission{PermissionData: &config.PermissionData{}},
+		ApiToken:   &service.ApiToken{},
+	}
+	rd := NewRenderer(nil, service.NewSessionStore(rdb, []byte("test-session-key")), nil, nil, nil, i18nc.New([]string{}), srv, rdb, nil)
+	handler := NewApiResource(rd, nil).Routes()
+
+	tests := []struct {
+		desc        string
+		method      string
+		path        string
+		permissions []string
+		want        int
+	}{
+		// Always allowed with any API token
+		{"me", http.MethodGet, "/me", nil, http.StatusOK},
+		{"messages without permission", http.MethodGet, "/messages", nil, http.StatusForbidden},
+		{"read messages without permission", http.MethodPost, "/messages/read", []string{"article.save"}, http.StatusForbidden},
+		{"subscribe category without permission", http.MethodPost, "/categories/golang/subscribe", nil, http.StatusForbidden},
+		{"ignore category without permission", http.MethodPost, "/categories/golang/ignore", []string{"user.access_messages"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			user := &model.User{Id: 1, Name: "alice"}
+			user.ScopeToken(tt.permissions)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), "user_data", user))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("want status %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
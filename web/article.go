@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"html"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httprate"
@@ -63,6 +65,19 @@ func (ar *ArticleResource) Routes() http.Handler {
 	}, ar),
 	).Get("/new", ar.FormPage)
 
+	rt.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
+		"article.create",
+		"article.reply",
+		"article.edit_mine",
+		"article.edit_others",
+	}, ar),
+	).Post("/preview", ar.Preview)
+
+	rt.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
+		"article.create",
+	}, ar),
+	).Post("/link_title", ar.LinkTitle)
+
 	rt.Route("/{articleId}", func(r chi.Router) {
 		r.Get("/", ar.Item)
 
@@ -76,12 +91,11 @@ func (ar *ArticleResource) Routes() http.Handler {
 			).Post("/edit", ar.Update)
 		})
 
-		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
-			"article.edit_mine",
-			"article.edit_others",
-		}, ar)).Group(func(r chi.Router) {
+		// Permissions of these moderation routes are checked against the category
+		// of article, so that category moderators can access them
+		r.With(mdw.AuthCheck(ar.sessStore)).Group(func(r chi.Router) {
 			r.Get("/block_regions", ar.BlockRegionsPage)
-			r.With(mdw.UserLogger(
+			r.With(ar.articlePermitCheck("article", "block_regions"), mdw.UserLogger(
 				ar.uLogger, model.AcTypeManage, model.AcActionBlockRegions, model.AcModelArticle, mdw.ULogURLArticleId),
 			).Post("/block_regions", ar.BlockRegions)
 		})
@@ -125,6 +139,15 @@ func (ar *ArticleResource) Routes() http.Handler {
 			).Post("/reply", ar.SubmitReply)
 		})
 
+		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
+			"article.report",
+		}, ar)).Group(func(r chi.Router) {
+			r.Get("/report", ar.ReportPage)
+			r.With(
+				mdw.UserLogger(ar.uLogger, model.AcTypeUser, model.AcActionReportArticle, model.AcModelArticle, mdw.ULogURLArticleId),
+			).Post("/report", ar.Report)
+		})
+
 		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
 			"article.vote_up",
 			"article.vote_down",
@@ -150,6 +173,13 @@ func (ar *ArticleResource) Routes() http.Handler {
 			ar.uLogger, model.AcTypeUser, model.AcActionSubscribeArticle, model.AcModelArticle, mdw.ULogURLArticleId),
 		).Post("/subscribe", ar.Subscribe)
 
+		// Polls are voted by users who are able to create articles
+		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
+			"article.create",
+		}, ar), mdw.UserLogger(
+			ar.uLogger, model.AcTypeUser, model.AcActionVotePoll, model.AcModelArticle, mdw.ULogURLArticleId),
+		).Post("/poll_vote", ar.VotePoll)
+
 		r.Get("/history", ar.HistoryPage)
 
 		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
@@ -158,30 +188,34 @@ func (ar *ArticleResource) Routes() http.Handler {
 			ar.uLogger, model.AcTypeManage, model.AcActionToggleHideHistory, model.AcModelArticle, mdw.ULogURLArticleId),
 		).Post("/history/{historyId}/toggle_hide", ar.ToggleHideHistory)
 
-		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
-			"article.delete_others",
-		}, ar), mdw.UserLogger(
+		r.With(mdw.AuthCheck(ar.sessStore), ar.articlePermitCheck("article", "delete_others"), mdw.UserLogger(
 			ar.uLogger, model.AcTypeManage, model.AcActionRecover, model.AcModelArticle, mdw.ULogURLArticleId),
 		).Post("/recover", ar.Recover)
 
 		r.Get("/share", ar.Share)
 
-		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
-			"article.edit_others",
-		}, ar), mdw.UserLogger(
+		r.With(mdw.AuthCheck(ar.sessStore), ar.articlePermitCheck("article", "lock"), mdw.UserLogger(
 			ar.uLogger, model.AcTypeManage, model.AcActionLockArticle, model.AcModelArticle, mdw.ULogURLArticleId),
 		).Post("/lock", ar.ToggleLock)
 
-		r.With(mdw.AuthCheck(ar.sessStore), mdw.PermitCheck(ar.srv.Permission, []string{
-			"article.edit_others",
-		}, ar), mdw.UserLogger(
+		r.With(mdw.AuthCheck(ar.sessStore), ar.articlePermitCheck("article", "fade_out"), mdw.UserLogger(
 			ar.uLogger, model.AcTypeManage, model.AcActionFadeOutArticle, model.AcModelArticle, mdw.ULogURLArticleId),
 		).Post("/fade_out", ar.ToggleFadeOut)
+
+		r.With(mdw.AuthCheck(ar.sessStore), ar.articlePermitCheck("article", "mark_duplicate"), mdw.UserLogger(
+			ar.uLogger, model.AcTypeManage, model.AcActionMarkDuplicate, model.AcModelArticle, mdw.ULogURLArticleId),
+		).Post("/duplicate", ar.MarkDuplicate)
 	})
 
 	return rt
 }
 
+// Uploaded files beyond the size are stored in temporary files while parsing
+const attachmentFormMemory = 32 << 20
+
+// Max size of the article form body, attachments and the other fields
+const MaxArticleFormSize = model.MaxArticleAttachmentCount*model.MaxAttachmentSize + 1<<20
+
 var oldestStartTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
 
 func filterArray[K any](arr []K, filterFunc func(K) bool) []K {
@@ -200,6 +234,7 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 	paramPage := r.Form.Get("page")
 	sort := r.Form.Get("sort")
 	categoryFrontId := chi.URLParam(r, "categoryFrontId")
+	tagFrontId := chi.URLParam(r, "tagFrontId")
 
 	currUserId := ar.GetLoginedUserId(w, r)
 
@@ -215,6 +250,25 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 			ar.ServerErrorp("", err, w, r)
 			return
 		}
+
+		// Proposed categories are only visible to the reviewers before approved
+		if !category.Approved && !ar.CheckPermit(r, "category", "approve") {
+			ar.NotFound(w, r)
+			return
+		}
+	}
+
+	var tag *model.Tag
+	if tagFrontId != "" {
+		tag, err = ar.store.Tag.Item(tagFrontId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ar.Error("", err, w, r, http.StatusNotFound)
+				return
+			}
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
 	}
 
 	var sortType model.ArticleSortType
@@ -253,7 +307,7 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 
 	go func() {
 		defer wg.Done()
-		total, err := ar.store.Article.Count(categoryFrontId, false)
+		total, err := ar.store.Article.Count(categoryFrontId, tagFrontId, listIgnoreUserId(categoryFrontId, currUserId), false)
 		if err != nil {
 			ch <- err
 			return
@@ -262,10 +316,10 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("get article count duration: %dms\n", time.Since(startTime).Milliseconds())
 	}()
 
-	go ar.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, currUserId, startTime, ch, false)
+	go ar.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, tagFrontId, currUserId, startTime, ch, false)
 
 	if page == 1 {
-		go ar.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, currUserId, startTime, ch, true)
+		go ar.getArticleList(&wg, page, pageSize, sortType, categoryFrontId, tagFrontId, currUserId, startTime, ch, true)
 	} else {
 		wg.Done()
 	}
@@ -318,6 +372,11 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 		return canEditOthers || !item.Blocked
 	})
 
+	ar.updateAuthorBlockedState(list, r)
+	list = filterArray(list, func(item *model.Article) bool {
+		return !item.AuthorBlocked
+	})
+
 	// total, err := ar.store.Article.Count()
 	// if err != nil {
 	// 	ar.Error("", err, w, r, http.StatusInternalServerError)
@@ -333,8 +392,11 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 		SortType        model.ArticleSortType
 		DefaultSortType model.ArticleSortType
 		Category        *model.Category
+		Tag             *model.Tag
 		SortTabList     []model.ArticleSortType
 		SortTabNames    map[model.ArticleSortType]string
+		ReportReasons   []model.ReportReason
+		MaxCommentLen   int
 	}
 
 	pageData := &model.PageData{
@@ -347,8 +409,11 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 			sortType,
 			defaultSort,
 			category,
+			tag,
 			model.GetSortTypeList(false, defaultSort),
-			model.GetSortTypeNames(ar.i18nCustom),
+			model.GetSortTypeNames(ar.Localizer(r)),
+			model.ReportReasons,
+			model.MaxReportCommentLen,
 		},
 	}
 
@@ -363,15 +428,40 @@ func (ar *ArticleResource) List(w http.ResponseWriter, r *http.Request) {
 		pageData.Description = category.Describe
 	}
 
+	if tagFrontId != "" && tag != nil {
+		pageData.Title = tag.Name
+		pageData.BreadCrumbs = []*model.BreadCrumb{
+			{
+				Path: "/tags",
+				Name: ar.Local(r, "Tag", "Count", 2),
+			},
+			{
+				Path: fmt.Sprintf("/tags/%s", tag.FrontId),
+				Name: tag.Name,
+			},
+		}
+		pageData.Description = tag.Describe
+	}
+
 	ar.Render(w, r, "article_list", pageData)
 }
 
+// Ignored categories of current user are excluded from the article lists
+// except the list of a category
+func listIgnoreUserId(categoryFrontId string, currUserId int) int {
+	if categoryFrontId != "" {
+		return 0
+	}
+	return currUserId
+}
+
 func (ar *ArticleResource) getArticleList(
 	wg *sync.WaitGroup,
 	page,
 	pageSize int,
 	sortType model.ArticleSortType,
-	categoryFrontId string,
+	categoryFrontId,
+	tagFrontId string,
 	currUserId int,
 	startTime time.Time,
 	ch chan<- any,
@@ -379,7 +469,7 @@ func (ar *ArticleResource) getArticleList(
 ) {
 	defer wg.Done()
 	// list, err := ar.getArticleList(page, pageSize, currUserId, sortType)
-	list, _, err := ar.store.Article.List(page, pageSize, sortType, categoryFrontId, pinned, false, false, "")
+	list, _, err := ar.store.Article.List(page, pageSize, sortType, categoryFrontId, tagFrontId, listIgnoreUserId(categoryFrontId, currUserId), pinned, false, false, "")
 	if err != nil {
 		ch <- err
 		return
@@ -472,19 +562,15 @@ func (ar *ArticleResource) FormPage(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "articleId")
 	var pageTitle string
 	var data *model.Article
+	var draftTarget model.DraftTargetType = model.DraftTargetEdit
 
-	categoryList, err := ar.store.Category.List(model.CategoryStateAll)
-	if err != nil {
-		ar.ServerErrorp("", err, w, r)
-		return
-	}
-
-	var moduleTitle = ar.Local("AddContent")
+	var moduleTitle = ar.Local(r, "AddContent")
 	if id == "" {
-		pageTitle = ar.i18nCustom.LocalTpl("AddNew")
+		pageTitle = ar.Local(r, "AddNew")
 		data = &model.Article{}
+		draftTarget = model.DraftTargetNew
 	} else {
-		moduleTitle = ar.Local("EditContent")
+		moduleTitle = ar.Local(r, "EditContent")
 		rId, err := strconv.Atoi(id)
 
 		if err != nil {
@@ -524,23 +610,77 @@ func (ar *ArticleResource) FormPage(w http.ResponseWriter, r *http.Request) {
 
 		if article.ReplyToId != 0 {
 			article.GenSummary(100)
-			pageTitle = fmt.Sprintf("%s - %s", ar.i18nCustom.LocalTpl("BtnEdit"), article.Summary)
+			pageTitle = fmt.Sprintf("%s - %s", ar.Local(r, "BtnEdit"), article.Summary)
 		} else {
-			pageTitle = fmt.Sprintf("%s - %s", ar.i18nCustom.LocalTpl("BtnEdit"), article.Title)
+			pageTitle = fmt.Sprintf("%s - %s", ar.Local(r, "BtnEdit"), article.Title)
 		}
 
-		article.UpdateDisplayTitle()
 		data = article
 	}
 
+	// Unsaved draft of the form is restored
+	draft, err := ar.srv.Draft.Item(ar.GetLoginedUserId(w, r), draftTarget, data.Id)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	if draft != nil {
+		data.Content = draft.Content
+		if data.ReplyDepth == 0 {
+			data.Title = draft.Title
+			data.Link = draft.Link
+			if draft.CategoryFrontId != "" {
+				data.CategoryFrontId = draft.CategoryFrontId
+			}
+		}
+	}
+
 	ar.SavePrevPage(w, r)
 
+	ar.renderForm(w, r, data, pageTitle, moduleTitle, nil, draft)
+}
+
+// Render the article form, duplicate is the existing article with the same
+// link of the submitting one, draft is the restored one of the form
+func (ar *ArticleResource) renderForm(w http.ResponseWriter, r *http.Request, data *model.Article, pageTitle, moduleTitle string, duplicate *model.Article, draft *model.Draft) {
+	categoryList, err := ar.store.Category.List(model.CategoryStateApproved)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	tagList, err := ar.store.Tag.List()
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	checkedTags := make(map[string]bool)
+	for _, tag := range data.Tags {
+		checkedTags[tag.FrontId] = true
+	}
+	for _, tagFrontId := range data.TagFrontIds {
+		checkedTags[tagFrontId] = true
+	}
+	if tagFrontId := r.URL.Query().Get("tag"); tagFrontId != "" {
+		checkedTags[tagFrontId] = true
+	}
+
 	type PageData struct {
 		MaxTitleLen         int
 		MaxContentLen       int
+		MaxTagCount         int
+		MinPollOptionCount  int
+		MaxPollOptionCount  int
+		MaxPollOptionLen    int
 		Article             *model.Article
 		Categories          []*model.Category
 		CurrCategoryFrontId string
+		Tags                []*model.Tag
+		CheckedTags         map[string]bool
+		DuplicateArticle    *model.Article
+		Draft               *model.Draft
 	}
 
 	ar.Render(w, r, "create", &model.PageData{
@@ -548,9 +688,17 @@ func (ar *ArticleResource) FormPage(w http.ResponseWriter, r *http.Request) {
 		Data: &PageData{
 			MaxTitleLen:         model.MAX_ARTICLE_TITLE_LEN,
 			MaxContentLen:       model.MAX_ARTICLE_CONTENT_LEN,
+			MaxTagCount:         model.MaxArticleTagCount,
+			MinPollOptionCount:  model.MinPollOptionCount,
+			MaxPollOptionCount:  model.MaxPollOptionCount,
+			MaxPollOptionLen:    model.MaxPollOptionLen,
 			Article:             data,
 			Categories:          categoryList,
 			CurrCategoryFrontId: r.URL.Query().Get("category"),
+			Tags:                tagList,
+			CheckedTags:         checkedTags,
+			DuplicateArticle:    duplicate,
+			Draft:               draft,
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
@@ -561,9 +709,9 @@ func (ar *ArticleResource) FormPage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (ar *ArticleResource) handleSubmit(w http.ResponseWriter, r *http.Request, isReply bool) {
-	err := r.ParseForm()
+	err := parseArticleForm(w, r)
 	if err != nil {
-		ar.Error("", err, w, r, http.StatusBadRequest)
+		ar.Error("", err, w, r, formErrorStatus(err))
 		return
 	}
 
@@ -590,11 +738,11 @@ func (ar *ArticleResource) handleSubmit(w http.ResponseWriter, r *http.Request,
 			// fmt.Println("pinned expires at2:", pinnedExpireAtStr)
 			pinnedExpireAt, err = time.Parse(time.DateTime, pinnedExpireAtStr)
 			if err != nil {
-				ar.Error(ar.Local("FormatError", "FieldNames", ar.Local("PinExpireTime")), err, w, r, http.StatusBadRequest)
+				ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "PinExpireTime")), err, w, r, http.StatusBadRequest)
 				return
 			}
 		} else {
-			ar.Error(ar.Local("Required", "FieldNames", ar.Local("PinExpireTime")), errors.New("pinned expires time is required"), w, r, http.StatusBadRequest)
+			ar.Error(ar.Local(r, "Required", "FieldNames", ar.Local(r, "PinExpireTime")), errors.New("pinned expires time is required"), w, r, http.StatusBadRequest)
 			return
 		}
 	}
@@ -627,6 +775,16 @@ func (ar *ArticleResource) handleSubmit(w http.ResponseWriter, r *http.Request,
 		}
 	}
 
+	permitted, err := ar.checkPinLock(pinned == "1", locked, categoryFrontId, replyToId, r)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+	if !permitted {
+		ar.Forbidden(errors.New("no permission to pin or lock article"), w, r)
+		return
+	}
+
 	authorId, err := GetLoginUserId(ar.sessStore, w, r)
 	if err != nil {
 		sess, err := ar.sessStore.Get(r, "one")
@@ -643,29 +801,94 @@ func (ar *ArticleResource) handleSubmit(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	var poll *model.Poll
+	if !isReply {
+		poll, err = parsePollForm(r)
+		if err != nil {
+			if errors.Is(err, model.AppErrPollValidFailed) {
+				ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+			} else {
+				ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "PollClosedAt")), err, w, r, http.StatusBadRequest)
+			}
+			return
+		}
+	}
+
+	// Submitter is offered to join the discussion of the existing article
+	// with the same link, unless it's confirmed to submit anyway
+	if !isReply && url != "" && r.Form.Get("submit_duplicate") != "1" {
+		duplicate, err := ar.articleSrv.DuplicateLink(url, 0)
+		if err != nil {
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
+
+		if duplicate != nil {
+			data := &model.Article{
+				Title:           title,
+				Link:            url,
+				Content:         content,
+				CategoryFrontId: categoryFrontId,
+				TagFrontIds:     model.CleanTagFrontIds(r.Form["tags"]),
+				Poll:            poll,
+			}
+			data.TrimSpace()
+			data.Sanitize(ar.sanitizePolicy)
+
+			ar.renderForm(w, r, data, ar.Local(r, "AddNew"), ar.Local(r, "AddContent"), duplicate, nil)
+			return
+		}
+	}
+
+	attachments, err := ar.srv.Attachment.Prepare(ar.GetLoginedUserData(r), attachmentFiles(r), 0)
+	if err != nil {
+		ar.attachmentError(err, w, r)
+		return
+	}
+
 	// id, err := ar.store.Article.Create(article.Title, article.Content, authorId, replyToId)
 	var id int
 	if isReply {
 		id, err = ar.articleSrv.Reply(replyToId, content, authorId, pinnedExpireAt, locked)
 	} else {
-		id, err = ar.articleSrv.Create(title, url, content, authorId, 0, categoryFrontId, pinnedExpireAt, locked)
+		id, err = ar.articleSrv.Create(title, url, content, authorId, 0, categoryFrontId, r.Form["tags"], pinnedExpireAt, locked)
 	}
 	// id, err := ar.articleSrv.Create(title, content, authorId, replyToId)
 	if err != nil {
 		if errors.Is(err, model.AppErrArticleValidFailed) {
-			ar.Error(err.Error(), err, w, r, http.StatusBadRequest)
+			ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		} else {
 			ar.Error("", err, w, r, http.StatusInternalServerError)
 		}
 		return
 	}
 
+	err = ar.srv.Attachment.Save(id, authorId, attachments)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	if poll != nil {
+		_, err = ar.store.Poll.Create(id, poll)
+		if err != nil {
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
+	}
+
+	if isReply {
+		ar.deleteDraft(authorId, model.DraftTargetReply, replyToId)
+	} else {
+		ar.deleteDraft(authorId, model.DraftTargetNew, 0)
+	}
+
 	ssOne := ar.Session("one", w, r)
 
 	ctx := context.WithValue(r.Context(), "article_id", id)
 	*r = *r.WithContext(ctx)
 
-	ssOne.Flash(ar.Local("PublishSuccess"))
+	ssOne.Flash(ar.Local(r, "PublishSuccess"))
 
 	if isReply {
 		if ssOne.GetStringValue("prev_url") != "" {
@@ -686,13 +909,144 @@ func (ar *ArticleResource) SubmitReply(w http.ResponseWriter, r *http.Request) {
 	ar.handleSubmit(w, r, true)
 }
 
-func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
+// Status code of the error of parsing form
+func formErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// Article forms are multipart when there are attachments, the body is limited
+// to MaxArticleFormSize. CSRF token of them is sent in the query, so that the
+// body is parsed here after the permission is checked.
+func parseArticleForm(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxArticleFormSize)
+	err := r.ParseMultipartForm(attachmentFormMemory)
+	if errors.Is(err, http.ErrNotMultipart) {
+		return r.ParseForm()
+	}
+	return err
+}
+
+func attachmentFiles(r *http.Request) []*multipart.FileHeader {
+	if r.MultipartForm == nil {
+		return nil
+	}
+	return r.MultipartForm.File["attachments"]
+}
+
+// Poll of the article form, options are separated by lines, nil if there is
+// no option
+func parsePollForm(r *http.Request) (*model.Poll, error) {
+	poll := &model.Poll{
+		Multiple:  r.Form.Get("poll_multiple") == "1",
+		Anonymous: r.Form.Get("poll_public") != "1",
+	}
+
+	for _, line := range strings.Split(r.Form.Get("poll_options"), "\n") {
+		poll.Options = append(poll.Options, &model.PollOption{Content: line})
+	}
+
+	poll.TrimSpace()
+	if len(poll.Options) == 0 {
+		return nil, nil
+	}
+
+	if closedAtStr := r.Form.Get("poll_closed_at"); closedAtStr != "" {
+		closedAt, err := time.Parse(time.DateTime, strings.Join(strings.Split(closedAtStr, "T"), " ")+":00")
+		if err != nil {
+			return nil, err
+		}
+		poll.ClosedAt = closedAt
+	}
+
+	err := poll.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	poll.Sanitize()
+
+	return poll, nil
+}
+
+// Draft of the submitted form is no longer needed, the submitting is not
+// failed if it's not deleted
+func (ar *ArticleResource) deleteDraft(userId int, targetType model.DraftTargetType, targetId int) {
+	err := ar.srv.Draft.Delete(userId, targetType, targetId)
+	if err != nil {
+		fmt.Println("delete draft error:", err)
+	}
+}
+
+func (ar *ArticleResource) attachmentError(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, model.AppErrAttachmentValidFailed) || errors.Is(err, model.AppErrAttachmentQuotaExceeded) {
+		ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+	} else {
+		ar.ServerErrorp("", err, w, r)
+	}
+}
+
+// Preview renders the markdown content of the article form, the rendered HTML
+// is fetched by the create page
+func (ar *ArticleResource) Preview(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	content := strings.TrimSpace(r.Form.Get("content"))
+	if utf8.RuneCountInString(content) > model.MAX_ARTICLE_CONTENT_LEN {
+		ar.Error(ar.Local(r, "NotExceed", "FieldNames", ar.Local(r, "ArticleContent"), "Num", model.MAX_ARTICLE_CONTENT_LEN), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, utils.RenderMarkdown(html.EscapeString(content), ar.sanitizePolicy))
+}
+
+// LinkTitle responds the title of the linked page, which is fetched by the
+// create page to fill the blank title
+func (ar *ArticleResource) LinkTitle(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		ar.Error("", err, w, r, http.StatusBadRequest)
 		return
 	}
 
+	article := &model.Article{Link: r.Form.Get("url")}
+	article.TrimSpace()
+	article.Sanitize(ar.sanitizePolicy)
+
+	if !model.ValidArticleURL(article.Link) {
+		ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "URL")), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	var title string
+	if ar.srv.LinkPreview != nil {
+		preview, err := ar.srv.LinkPreview.Fetch(article.Link)
+		if err != nil {
+			fmt.Println("fetch link preview error: ", err)
+		} else {
+			title = html.UnescapeString(preview.ArticleTitle())
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, title)
+}
+
+func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
+	err := parseArticleForm(w, r)
+	if err != nil {
+		ar.Error("", err, w, r, formErrorStatus(err))
+		return
+	}
+
 	// rId, err := strconv.Atoi(r.Form.Get("id"))
 	id, err := strconv.Atoi(chi.URLParam(r, "articleId"))
 	if err != nil {
@@ -732,11 +1086,11 @@ func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
 			fmt.Println("pinned expires at2:", pinnedExpireAtStr)
 			pinnedExpireAt, err = time.Parse(time.DateTime, pinnedExpireAtStr)
 			if err != nil {
-				ar.Error(ar.Local("FormatError", "FieldNames", ar.Local("PinExpireTime")), err, w, r, http.StatusBadRequest)
+				ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "PinExpireTime")), err, w, r, http.StatusBadRequest)
 				return
 			}
 		} else {
-			ar.Error(ar.Local("Required", "FieldNames", ar.Local("PinExpireTime")), errors.New("pinned expires time is required"), w, r, http.StatusBadRequest)
+			ar.Error(ar.Local(r, "Required", "FieldNames", ar.Local(r, "PinExpireTime")), errors.New("pinned expires time is required"), w, r, http.StatusBadRequest)
 			return
 		}
 	}
@@ -768,7 +1122,7 @@ func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = article.Valid(true)
 	if err != nil {
-		ar.Error(err.Error(), err, w, r, http.StatusBadRequest)
+		ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -796,6 +1150,72 @@ func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var categoryArticleId int
+	if isReply {
+		categoryArticleId = id
+	}
+
+	permitted, err := ar.checkPinLock(pinned == "1", locked, article.CategoryFrontId, categoryArticleId, r)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+	if !permitted {
+		ar.Forbidden(errors.New("no permission to pin or lock article"), w, r)
+		return
+	}
+
+	if !isReply && article.CategoryFrontId != oldArticle.CategoryFrontId {
+		err = ar.articleSrv.CheckCategory(article.CategoryFrontId)
+		if err != nil {
+			if errors.Is(err, model.AppErrArticleValidFailed) {
+				ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+			} else {
+				ar.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+	}
+
+	if !isReply {
+		err = ar.articleSrv.Tag(id, r.Form["tags"])
+		if err != nil {
+			if errors.Is(err, model.AppErrArticleValidFailed) {
+				ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+			} else {
+				ar.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+	}
+
+	removeAttachmentIds := make(map[int]bool)
+	for _, idStr := range r.Form["remove_attachments"] {
+		attachmentId, err := strconv.Atoi(idStr)
+		if err != nil {
+			ar.Error("", err, w, r, http.StatusBadRequest)
+			return
+		}
+		removeAttachmentIds[attachmentId] = true
+	}
+
+	var removeAttachments []int
+	for _, item := range oldArticle.Attachments {
+		if removeAttachmentIds[item.Id] {
+			removeAttachments = append(removeAttachments, item.Id)
+		}
+	}
+
+	attachments, err := ar.srv.Attachment.Prepare(
+		ar.GetLoginedUserData(r),
+		attachmentFiles(r),
+		len(oldArticle.Attachments)-len(removeAttachments),
+	)
+	if err != nil {
+		ar.attachmentError(err, w, r)
+		return
+	}
+
 	if isReply {
 		_, err = ar.store.Article.UpdateReply(id, article.Content, pinnedExpireAt, locked)
 	} else {
@@ -818,21 +1238,51 @@ func (ar *ArticleResource) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go ar.addHistoryLog(article.Id, oldArticle, currUserId, isReply, isHideEditHisotry)
-
-	ssOne := ar.Session("one", w, r)
-
-	ssOne.Flash(ar.Local("PublishSuccess"))
-
-	if isReply && ssOne.GetStringValue("prev_url") != "" {
-		ar.ToPrevPage(w, r)
-	} else {
-		http.Redirect(w, r, fmt.Sprintf("/articles/%d", id), http.StatusFound)
+	err = ar.articleSrv.Mention(id, oldArticle.AuthorId, article.Content)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
 	}
-}
 
-func (ar *ArticleResource) addHistoryLog(articleId int, oldArticle *model.Article, currUserId int, isReply bool, isHidden bool) {
-	article, err := ar.store.Article.Item(articleId, 0)
+	err = ar.srv.Attachment.Delete(id, removeAttachments)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	err = ar.srv.Attachment.Save(id, currUserId, attachments)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	go ar.addHistoryLog(article.Id, oldArticle, currUserId, isReply, isHideEditHisotry)
+
+	ar.deleteDraft(currUserId, model.DraftTargetEdit, id)
+
+	if !isReply && article.Link != oldArticle.Link {
+		err = ar.articleSrv.SaveNormalizedLink(id, article.Link)
+		if err != nil {
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
+
+		go ar.articleSrv.RefreshLinkPreview(article.Link)
+	}
+
+	ssOne := ar.Session("one", w, r)
+
+	ssOne.Flash(ar.Local(r, "PublishSuccess"))
+
+	if isReply && ssOne.GetStringValue("prev_url") != "" {
+		ar.ToPrevPage(w, r)
+	} else {
+		http.Redirect(w, r, fmt.Sprintf("/articles/%d", id), http.StatusFound)
+	}
+}
+
+func (ar *ArticleResource) addHistoryLog(articleId int, oldArticle *model.Article, currUserId int, isReply bool, isHidden bool) {
+	article, err := ar.store.Article.Item(articleId, 0)
 	if err != nil {
 		fmt.Println("get latest article data when add history error:", err)
 		return
@@ -892,6 +1342,7 @@ const (
 	ArticlePageReply                        = "reply"
 	ArticlePageDetail                       = "detail"
 	ArticlePageBlockRegions                 = "block_regions"
+	ArticlePageReport                       = "report"
 )
 
 // {{- $regions := list "mainland_china" "us" "in" -}}
@@ -957,17 +1408,17 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 	if pageType == ArticlePageBlockRegions {
 		regionList = []*Region{
 			{
-				ar.Local("MainlandChina"),
+				ar.Local(r, "MainlandChina"),
 				"mainland_china",
 				false,
 			},
 			{
-				ar.Local("UnitedStates"),
+				ar.Local(r, "UnitedStates"),
 				"us",
 				false,
 			},
 			{
-				ar.Local("India"),
+				ar.Local(r, "India"),
 				"in",
 				false,
 			},
@@ -1097,6 +1548,11 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 
 	articleList = append(articleList, rootArticle)
 
+	// Replies of blocked users are collapsed instead of removed to keep the
+	// reply tree complete
+	ar.updateAuthorBlockedState(articleList, r)
+	ar.updateAuthorBlockedState(pinnedList, r)
+
 	start1 := time.Now()
 
 	for _, item := range articleList {
@@ -1132,13 +1588,23 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 		// 	return
 		// }
 
-		if (rootArticle.AuthorId != currUserId && !ar.CheckPermit(r, "article", "delete_others")) || !ar.CheckPermit(r, "article", "delete_mine") {
+		if (rootArticle.AuthorId != currUserId && !ar.CheckCategoryPermit(r, rootArticle.CategoryFrontId, "article", "delete_others")) || !ar.CheckPermit(r, "article", "delete_mine") {
 			// http.Redirect(w, r, fmt.Sprintf("/articles/%d", articleId), http.StatusFound)
 			ar.Error("", err, w, r, http.StatusForbidden)
 			return
 		}
 	}
 
+	if pageType == ArticlePageBlockRegions && !ar.CheckCategoryPermit(r, rootArticle.CategoryFrontId, "article", "block_regions") {
+		ar.Forbidden(errors.New("no permission"), w, r)
+		return
+	}
+
+	if pageType == ArticlePageReport && rootArticle.AuthorId == currUserId {
+		ar.Error("", errors.New("can't report article of yourself"), w, r, http.StatusBadRequest)
+		return
+	}
+
 	for _, item := range articleList {
 		item.FormatDeleted()
 	}
@@ -1164,8 +1630,6 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 	// rootArticle = sortArticleTree(rootArticle, sortType)
 	// rootArticle = pagingArticleTree(rootArticle, page)
 
-	rootArticle.UpdateDisplayTitle()
-
 	if pageType == ArticlePageBlockRegions {
 		for _, region := range regionList {
 			for _, blockedRegion := range rootArticle.BlockedRegionsISOCode {
@@ -1200,6 +1664,16 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 	// 	w.WriteHeader(http.StatusGone)
 	// }
 
+	// Unsaved reply is restored on the reply page
+	var draft *model.Draft
+	if pageType == ArticlePageReply {
+		draft, err = ar.srv.Draft.Item(currUserId, model.DraftTargetReply, rootArticle.Id)
+		if err != nil {
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
+	}
+
 	type itemPageData struct {
 		Article *model.Article
 		// DelPage  bool
@@ -1212,10 +1686,13 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 		DefaultSortType model.ArticleSortType
 		SortTabList     []model.ArticleSortType
 		SortTabNames    map[model.ArticleSortType]string
+		ReportReasons   []model.ReportReason
+		MaxCommentLen   int
+		Draft           *model.Draft
 	}
 
 	ar.Render(w, r, "article", &model.PageData{
-		Title:       rootArticle.DisplayTitle,
+		Title:       rootArticle.DisplayTitle(ar.Localizer(r)),
 		Description: rootArticle.Summary,
 		Data: &itemPageData{
 			rootArticle,
@@ -1228,7 +1705,10 @@ func (ar *ArticleResource) handleItem(w http.ResponseWriter, r *http.Request, pa
 			regionMap,
 			defaultSort,
 			model.GetSortTypeList(true, defaultSort),
-			model.GetSortTypeNames(ar.i18nCustom),
+			model.GetSortTypeNames(ar.Localizer(r)),
+			model.ReportReasons,
+			model.MaxReportCommentLen,
+			draft,
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
@@ -1451,7 +1931,7 @@ func (ar *ArticleResource) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if (article.AuthorId != currUser.Id && !ar.CheckPermit(r, "article", "delete_others")) || !ar.CheckPermit(r, "article", "delete_mine") {
+	if (article.AuthorId != currUser.Id && !ar.CheckCategoryPermit(r, article.CategoryFrontId, "article", "delete_others")) || !ar.CheckPermit(r, "article", "delete_mine") {
 		// http.Redirect(w, r, fmt.Sprintf("/articles/%d", rId), http.StatusFound)
 		ar.Error("", err, w, r, http.StatusForbidden)
 		return
@@ -1475,7 +1955,7 @@ func (ar *ArticleResource) Delete(w http.ResponseWriter, r *http.Request) {
 	// 	}()
 	// }
 
-	ar.Session("one", w, r).Flash(ar.Local("DeleteSuccess"))
+	ar.Session("one", w, r).Flash(ar.Local(r, "DeleteSuccess"))
 	ar.Session("one", w, r).SetValue("deleted_article_author_id", article.AuthorId)
 
 	http.Redirect(w, r, fmt.Sprintf("/articles/%d", rootArticleId), http.StatusFound)
@@ -1541,47 +2021,7 @@ func (ar *ArticleResource) Vote(w http.ResponseWriter, r *http.Request) {
 		// 	}
 		// }()
 
-		go func() {
-			article, err := ar.store.Article.Item(articleId, 0)
-			if err != nil {
-				fmt.Println("add reputation error", err)
-				return
-			}
-
-			if article.AuthorId == user.Id {
-				return
-			}
-
-			changeType := model.RPCTypeUpvoted
-			if voteType == "down" {
-				changeType = model.RPCTypeDownvoted
-			}
-			var isRevert bool
-			if code == -1 {
-				isRevert = true
-			}
-
-			if code == 2 {
-				var prevChangeType model.ReputationChangeType
-				if changeType == model.RPCTypeUpvoted {
-					prevChangeType = model.RPCTypeDownvoted
-				} else {
-					prevChangeType = model.RPCTypeUpvoted
-				}
-
-				err = ar.store.User.AddReputation(article.AuthorName, prevChangeType, true)
-				if err != nil {
-					fmt.Println("add reputation error", err)
-					return
-				}
-			}
-
-			err = ar.store.User.AddReputation(article.AuthorName, changeType, isRevert)
-			if err != nil {
-				fmt.Println("add reputation error", err)
-				return
-			}
-		}()
+		go ar.updateVoteReputation(articleId, user.Id, voteType, code)
 	} else {
 		ar.ToLogin(w, r)
 		return
@@ -1596,6 +2036,48 @@ func (ar *ArticleResource) Vote(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (ar *ArticleResource) updateVoteReputation(articleId, userId int, voteType string, code int) {
+	article, err := ar.store.Article.Item(articleId, 0)
+	if err != nil {
+		fmt.Println("add reputation error", err)
+		return
+	}
+
+	if article.AuthorId == userId {
+		return
+	}
+
+	changeType := model.RPCTypeUpvoted
+	if voteType == "down" {
+		changeType = model.RPCTypeDownvoted
+	}
+	var isRevert bool
+	if code == -1 {
+		isRevert = true
+	}
+
+	if code == 2 {
+		var prevChangeType model.ReputationChangeType
+		if changeType == model.RPCTypeUpvoted {
+			prevChangeType = model.RPCTypeDownvoted
+		} else {
+			prevChangeType = model.RPCTypeUpvoted
+		}
+
+		err = ar.store.User.AddReputation(article.AuthorName, prevChangeType, true)
+		if err != nil {
+			fmt.Println("add reputation error", err)
+			return
+		}
+	}
+
+	err = ar.store.User.AddReputation(article.AuthorName, changeType, isRevert)
+	if err != nil {
+		fmt.Println("add reputation error", err)
+		return
+	}
+}
+
 func (ar *ArticleResource) Save(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 
@@ -1719,60 +2201,62 @@ func (ar *ArticleResource) React(w http.ResponseWriter, r *http.Request) {
 		// 	}
 		// }()
 
-		go func() {
-			article, err := ar.store.Article.Item(articleId, 0)
-			if err != nil {
-				fmt.Println("add reputation error", err)
-				return
-			}
-
-			if article.AuthorId == userId {
-				return
-			}
+		go ar.updateReactReputation(articleId, userId, reactItem, code, prevFrontId)
+	} else {
+		ar.ToLogin(w, r)
+		return
+	}
 
-			var changeType model.ReputationChangeType
-			var isRevert bool
+	referer := r.Referer()
+	refererUrl, _ := url.Parse(r.Referer())
+	// fmt.Println("referer: ", referer)
+	// fmt.Println("refererUrl: ", refererUrl)
+	if IsRegisterdPage(refererUrl, ar.router) && rootId != "" && rootId != "0" && rootId != articleIdS {
+		http.Redirect(w, r, fmt.Sprintf("%s#ar_%s", referer, articleIdS), http.StatusFound)
+	} else {
+		http.Redirect(w, r, referer, http.StatusFound)
+	}
+}
 
-			switch code {
-			case -1:
-				if !reactCountRPC(reactItem.FrontId) {
-					return
-				}
-				changeType = reactToChangeType(reactItem.FrontId)
-				isRevert = true
-			case 1:
-				if !reactCountRPC(reactItem.FrontId) {
-					return
-				}
-				changeType = reactToChangeType(reactItem.FrontId)
-				isRevert = false
-			case 2:
-				if !reactCountRPC(prevFrontId) && !reactCountRPC(reactItem.FrontId) {
-					return
-				} else if reactCountRPC(prevFrontId) && !reactCountRPC(reactItem.FrontId) {
-					changeType = reactToChangeType(prevFrontId)
-					isRevert = true
-				} else if !reactCountRPC(prevFrontId) && reactCountRPC(reactItem.FrontId) {
-					changeType = reactToChangeType(reactItem.FrontId)
-					isRevert = false
-				} else {
-					changeType = reactToChangeType(prevFrontId)
-					isRevert = true
-					if string(changeType) != "" {
-						err = ar.store.User.AddReputation(article.AuthorName, changeType, isRevert)
-						if err != nil {
-							fmt.Println("add reputation error", err)
-							return
-						}
-					}
+func (ar *ArticleResource) updateReactReputation(articleId, userId int, reactItem *model.ArticleReact, code int, prevFrontId string) {
+	article, err := ar.store.Article.Item(articleId, 0)
+	if err != nil {
+		fmt.Println("add reputation error", err)
+		return
+	}
 
-					changeType = reactToChangeType(reactItem.FrontId)
-					isRevert = false
-				}
-			}
+	if article.AuthorId == userId {
+		return
+	}
 
-			// fmt.Println("react changeType:", changeType)
+	var changeType model.ReputationChangeType
+	var isRevert bool
 
+	switch code {
+	case -1:
+		if !reactCountRPC(reactItem.FrontId) {
+			return
+		}
+		changeType = reactToChangeType(reactItem.FrontId)
+		isRevert = true
+	case 1:
+		if !reactCountRPC(reactItem.FrontId) {
+			return
+		}
+		changeType = reactToChangeType(reactItem.FrontId)
+		isRevert = false
+	case 2:
+		if !reactCountRPC(prevFrontId) && !reactCountRPC(reactItem.FrontId) {
+			return
+		} else if reactCountRPC(prevFrontId) && !reactCountRPC(reactItem.FrontId) {
+			changeType = reactToChangeType(prevFrontId)
+			isRevert = true
+		} else if !reactCountRPC(prevFrontId) && reactCountRPC(reactItem.FrontId) {
+			changeType = reactToChangeType(reactItem.FrontId)
+			isRevert = false
+		} else {
+			changeType = reactToChangeType(prevFrontId)
+			isRevert = true
 			if string(changeType) != "" {
 				err = ar.store.User.AddReputation(article.AuthorName, changeType, isRevert)
 				if err != nil {
@@ -1780,20 +2264,20 @@ func (ar *ArticleResource) React(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 			}
-		}()
-	} else {
-		ar.ToLogin(w, r)
-		return
+
+			changeType = reactToChangeType(reactItem.FrontId)
+			isRevert = false
+		}
 	}
 
-	referer := r.Referer()
-	refererUrl, _ := url.Parse(r.Referer())
-	// fmt.Println("referer: ", referer)
-	// fmt.Println("refererUrl: ", refererUrl)
-	if IsRegisterdPage(refererUrl, ar.router) && rootId != "" && rootId != "0" && rootId != articleIdS {
-		http.Redirect(w, r, fmt.Sprintf("%s#ar_%s", referer, articleIdS), http.StatusFound)
-	} else {
-		http.Redirect(w, r, referer, http.StatusFound)
+	// fmt.Println("react changeType:", changeType)
+
+	if string(changeType) != "" {
+		err = ar.store.User.AddReputation(article.AuthorName, changeType, isRevert)
+		if err != nil {
+			fmt.Println("add reputation error", err)
+			return
+		}
 	}
 }
 
@@ -1924,7 +2408,6 @@ func (ar *ArticleResource) HistoryPage(w http.ResponseWriter, r *http.Request) {
 
 	article.Content = html.EscapeString(article.Content)
 	article.FormatNullValues()
-	article.UpdateDisplayTitle()
 
 	type pageData struct {
 		Article     *model.Article
@@ -1933,7 +2416,7 @@ func (ar *ArticleResource) HistoryPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ar.Render(w, r, "article_history", &model.PageData{
-		Title: ar.Local("EditHistoryTitle", "Title", article.DisplayTitle),
+		Title: ar.Local(r, "EditHistoryTitle", "Title", article.DisplayTitle(ar.Localizer(r))),
 		Data: &pageData{
 			Article:     article,
 			List:        logList,
@@ -1952,13 +2435,114 @@ func (ar *ArticleResource) checkLocked(articleId int, r *http.Request) error {
 	// fmt.Println("locked:", locked)
 	// fmt.Println("lock permission:", ar.CheckPermit(r, "article", "lock"))
 
-	if !ar.CheckPermit(r, "article", "lock") && locked {
+	if !locked {
+		return nil
+	}
+
+	permitted, err := ar.checkArticlePermit(articleId, r, "article", "lock")
+	if err != nil {
+		return err
+	}
+
+	if !permitted {
 		return errors.New("article is locked, you have no permission to update it")
 	}
 
 	return nil
 }
 
+// Mark articles of which the author is blocked by current user
+func (ar *ArticleResource) updateAuthorBlockedState(list []*model.Article, r *http.Request) {
+	currUser := ar.GetLoginedUserData(r)
+	if currUser == nil || len(currUser.BlockedUserIds) == 0 {
+		return
+	}
+
+	for _, item := range list {
+		item.UpdateAuthorBlockedState(currUser.BlockedUserIds)
+	}
+}
+
+// Check permission against the category of the article, moderators of the
+// category are permitted with the scoped permissions
+func (ar *ArticleResource) checkArticlePermit(articleId int, r *http.Request, module, action string) (bool, error) {
+	if ar.CheckPermit(r, module, action) {
+		return true, nil
+	}
+
+	article, err := ar.store.Article.Item(articleId, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return ar.CheckCategoryPermit(r, article.CategoryFrontId, module, action), nil
+}
+
+// Pinning and locking are permitted to the moderators of category too, the
+// category of replies is found by categoryArticleId
+func (ar *ArticleResource) checkPinLock(pinned, locked bool, categoryFrontId string, categoryArticleId int, r *http.Request) (bool, error) {
+	if !pinned && !locked {
+		return true, nil
+	}
+
+	if categoryArticleId > 0 {
+		article, err := ar.store.Article.Item(categoryArticleId, 0)
+		if err != nil {
+			return false, err
+		}
+		categoryFrontId = article.CategoryFrontId
+	}
+
+	if pinned && !ar.CheckCategoryPermit(r, categoryFrontId, "article", "pin") {
+		return false, nil
+	}
+
+	if locked && !ar.CheckCategoryPermit(r, categoryFrontId, "article", "lock") {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Respond forbidden if the user has no permission on the article
+func (ar *ArticleResource) articlePermitted(articleId int, module, action string, w http.ResponseWriter, r *http.Request) bool {
+	permitted, err := ar.checkArticlePermit(articleId, r, module, action)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.NotFound(w, r)
+		} else {
+			ar.ServerErrorp("", err, w, r)
+		}
+		return false
+	}
+
+	if !permitted {
+		ar.Forbidden(errors.New("no permission"), w, r)
+		return false
+	}
+
+	return true
+}
+
+// Moderation routes of the article are permitted to the moderators of its
+// category too, the permission is checked before the user activity is logged
+func (ar *ArticleResource) articlePermitCheck(module, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+			if err != nil {
+				ar.Error("", err, w, r, http.StatusBadRequest)
+				return
+			}
+
+			if !ar.articlePermitted(articleId, module, action, w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func (ar *ArticleResource) ToggleHideHistory(w http.ResponseWriter, r *http.Request) {
 	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
 	if err != nil {
@@ -2023,14 +2607,14 @@ func (ar *ArticleResource) Share(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ar.Render(w, r, "article_share", &model.PageData{
-		Title: ar.Local("Share") + " - " + article.DisplayTitle,
+		Title: ar.Local(r, "Share") + " - " + article.DisplayTitle(ar.Localizer(r)),
 		Data: &pageData{
 			Article:    article,
 			RefererURL: r.Referer(),
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: ar.Local("Share"),
+				Name: ar.Local(r, "Share"),
 			},
 		},
 	})
@@ -2070,6 +2654,71 @@ func (ar *ArticleResource) BlockRegions(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, fmt.Sprintf("/articles/%d", articleId), http.StatusFound)
 }
 
+func (ar *ArticleResource) ReportPage(w http.ResponseWriter, r *http.Request) {
+	ar.handleItem(w, r, ArticlePageReport)
+}
+
+func (ar *ArticleResource) Report(w http.ResponseWriter, r *http.Request) {
+	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	article, err := ar.store.Article.Item(articleId, 0)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.NotFound(w, r)
+		} else {
+			ar.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	currUserId := ar.GetLoginedUserId(w, r)
+	if article.Deleted || article.AuthorId == currUserId {
+		ar.Error("", errors.New("article is deleted or written by yourself"), w, r, http.StatusBadRequest)
+		return
+	}
+
+	report := &model.Report{
+		ArticleId:  articleId,
+		ReporterId: currUserId,
+		Reason:     model.ReportReason(r.Form.Get("reason")),
+		Comment:    r.Form.Get("comment"),
+	}
+
+	report.TrimSpace()
+
+	err = report.Valid()
+	if err != nil {
+		ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	report.Sanitize()
+
+	_, err = ar.store.Report.Create(report.ArticleId, report.ReporterId, report.Reason, report.Comment)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	ar.Session("one", w, r).Flash(ar.Local(r, "ReportSuccess"))
+
+	if article.ReplyDepth > 0 {
+		http.Redirect(w, r, fmt.Sprintf("/articles/%d#ar_%d", article.ReplyRootArticleId, articleId), http.StatusFound)
+	} else {
+		http.Redirect(w, r, fmt.Sprintf("/articles/%d", articleId), http.StatusFound)
+	}
+}
+
 func (ar *ArticleResource) ToggleLock(w http.ResponseWriter, r *http.Request) {
 	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
 	if err != nil {
@@ -2152,3 +2801,114 @@ func (ar *ArticleResource) ToggleFadeOut(w http.ResponseWriter, r *http.Request)
 
 	ar.toReplyAnchor(rootId, articleId, w, r)
 }
+
+// MarkDuplicate marks the article as duplicate of an older one, an empty
+// original id unmarks it
+func (ar *ArticleResource) MarkDuplicate(w http.ResponseWriter, r *http.Request) {
+	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	var originalId int
+	if originalIdStr := strings.TrimSpace(r.Form.Get("original_id")); originalIdStr != "" {
+		originalId, err = strconv.Atoi(originalIdStr)
+		if err != nil {
+			ar.Error(ar.Local(r, "FormatError", "FieldNames", ar.Local(r, "OriginalArticleId")), err, w, r, http.StatusBadRequest)
+			return
+		}
+
+		article, err := ar.store.Article.Item(articleId, 0)
+		if err != nil {
+			ar.ServerErrorp("", err, w, r)
+			return
+		}
+
+		original, err := ar.store.Article.Item(originalId, 0)
+		if err != nil {
+			if errors.Is(err, model.AppErrArticleNotExist) {
+				ar.Error(ar.Local(r, "NotExist", "FieldNames", ar.Local(r, "OriginalArticleId")), err, w, r, http.StatusBadRequest)
+			} else {
+				ar.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+
+		// Only older undeleted articles can be the original one
+		if article.ReplyToId != 0 || original.ReplyToId != 0 || original.Deleted || !original.CreatedAt.Before(article.CreatedAt) {
+			err = errors.New("original article should be an older undeleted article")
+			ar.Error(ar.Local(r, "DuplicateOriginalTip"), err, w, r, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err = ar.store.Article.MarkDuplicate(articleId, originalId)
+	if err != nil {
+		ar.ServerErrorp("", err, w, r)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/articles/%d", articleId), http.StatusFound)
+}
+
+func (ar *ArticleResource) VotePoll(w http.ResponseWriter, r *http.Request) {
+	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		ar.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = ar.checkLocked(articleId, r)
+	if err != nil {
+		ar.Forbidden(err, w, r)
+		return
+	}
+
+	currUserId := ar.GetLoginedUserId(w, r)
+	poll, err := ar.store.Poll.Item(articleId, currUserId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.NotFound(w, r)
+		} else {
+			ar.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	var optionIds []int
+	for _, item := range r.Form["option_id"] {
+		id, err := strconv.Atoi(item)
+		if err != nil {
+			ar.Error("", err, w, r, http.StatusBadRequest)
+			return
+		}
+		optionIds = append(optionIds, id)
+	}
+
+	err = poll.ValidVote(optionIds)
+	if err != nil {
+		ar.Error(ar.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = ar.store.Poll.Vote(poll.Id, currUserId, optionIds)
+	if err != nil {
+		// The user voted at the same time in another request
+		if errors.Is(err, pgx.ErrNoRows) {
+			ar.Error(ar.Local(r, "PollAlreadyVoted"), err, w, r, http.StatusBadRequest)
+		} else {
+			ar.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	ar.Session("one", w, r).Flash(ar.Local(r, "PollVoteSuccess"))
+	http.Redirect(w, r, fmt.Sprintf("/articles/%d", articleId), http.StatusFound)
+}
@@ -1,7 +1,11 @@
 package web
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/oodzchen/dproject/model"
@@ -135,3 +139,60 @@ func TestGenArticleTree(t *testing.T) {
 		})
 	}
 }
+
+func TestListIgnoreUserId(t *testing.T) {
+	tests := []struct {
+		desc            string
+		categoryFrontId string
+		currUserId      int
+		want            int
+	}{
+		{"home list", "", 10, 10},
+		{"category list", "golang", 10, 0},
+		{"not logined", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := listIgnoreUserId(tt.categoryFrontId, tt.currUserId)
+			if got != tt.want {
+				t.Errorf("want %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestParseArticleFormTooLarge(t *testing.T) {
+	boundary := "article-form"
+	newRequest := func(size int64) *http.Request {
+		body := io.MultiReader(
+			strings.NewReader("--"+boundary+"\r\nContent-Disposition: form-data; name=\"attachments\"; filename=\"a.png\"\r\nContent-Type: image/png\r\n\r\n"),
+			io.LimitReader(zeroReader{}, size),
+			strings.NewReader("\r\n--"+boundary+"--\r\n"),
+		)
+		req := httptest.NewRequest(http.MethodPost, "/articles", body)
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		return req
+	}
+
+	req := newRequest(1024)
+	err := parseArticleForm(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachmentFiles(req)) != 1 {
+		t.Errorf("want 1 attachment, got %d", len(attachmentFiles(req)))
+	}
+
+	err = parseArticleForm(httptest.NewRecorder(), newRequest(MaxArticleFormSize))
+	if got := formErrorStatus(err); got != http.StatusRequestEntityTooLarge {
+		t.Errorf("want status %d, got %d, error: %v", http.StatusRequestEntityTooLarge, got, err)
+	}
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/oodzchen/dproject/storage"
+)
+
+type AttachmentResource struct {
+	*Renderer
+}
+
+func NewAttachmentResource(renderer *Renderer) *AttachmentResource {
+	return &AttachmentResource{
+		renderer,
+	}
+}
+
+func (atr *AttachmentResource) Routes() http.Handler {
+	rt := chi.NewRouter()
+
+	rt.Get("/{attachmentId}", atr.File)
+	rt.Get("/{attachmentId}/thumb", atr.Thumb)
+
+	return rt
+}
+
+func (atr *AttachmentResource) File(w http.ResponseWriter, r *http.Request) {
+	atr.serve(w, r, false)
+}
+
+func (atr *AttachmentResource) Thumb(w http.ResponseWriter, r *http.Request) {
+	atr.serve(w, r, true)
+}
+
+func (atr *AttachmentResource) serve(w http.ResponseWriter, r *http.Request, thumb bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "attachmentId"))
+	if err != nil {
+		atr.NotFound(w, r)
+		return
+	}
+
+	item, err := atr.store.Attachment.Item(id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			atr.NotFound(w, r)
+		} else {
+			atr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	key := item.StorageKey
+	contentType := item.MimeType
+	if thumb {
+		if item.ThumbKey == "" {
+			atr.NotFound(w, r)
+			return
+		}
+		key = item.ThumbKey
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+
+	file, err := atr.srv.Attachment.Storage.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			atr.NotFound(w, r)
+		} else {
+			atr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+	defer file.Close()
+
+	// Only images are displayed in the browser, other files are downloaded
+	disposition := "attachment"
+	if item.IsImage() {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{
+		"filename": html.UnescapeString(item.FileName),
+	}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Files are never changed after uploading
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	_, err = io.Copy(w, file)
+	if err != nil {
+		fmt.Println("write attachment response error:", err)
+	}
+}
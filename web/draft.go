@@ -0,0 +1,119 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	mdw "github.com/oodzchen/dproject/middleware"
+	"github.com/oodzchen/dproject/model"
+)
+
+type DraftResource struct {
+	*Renderer
+}
+
+func NewDraftResource(renderer *Renderer) *DraftResource {
+	return &DraftResource{
+		renderer,
+	}
+}
+
+func (dr *DraftResource) Routes() http.Handler {
+	rt := chi.NewRouter()
+
+	rt.With(mdw.AuthCheck(dr.sessStore)).Group(func(r chi.Router) {
+		r.Post("/", dr.Save)
+		r.Post("/delete", dr.Delete)
+	})
+
+	return rt
+}
+
+func (dr *DraftResource) parseTarget(r *http.Request) (model.DraftTargetType, int, error) {
+	targetType := r.Form.Get("target_type")
+	if !model.ValidDraftTargetType(targetType) {
+		return "", 0, errors.New("invalid draft target type: " + targetType)
+	}
+
+	var targetId int
+	if targetIdStr := r.Form.Get("target_id"); targetIdStr != "" {
+		var err error
+		targetId, err = strconv.Atoi(targetIdStr)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	return model.DraftTargetType(targetType), targetId, nil
+}
+
+// Save is requested by the autosaving of forms, nothing is responded if saved
+func (dr *DraftResource) Save(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		dr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	targetType, targetId, err := dr.parseTarget(r)
+	if err != nil {
+		dr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	draft := &model.Draft{
+		UserId:          dr.GetLoginedUserId(w, r),
+		TargetType:      targetType,
+		TargetId:        targetId,
+		Title:           r.Form.Get("title"),
+		Link:            r.Form.Get("url"),
+		Content:         r.Form.Get("content"),
+		CategoryFrontId: r.Form.Get("category_front_id"),
+	}
+
+	err = dr.srv.Draft.Save(draft)
+	if err != nil {
+		if errors.Is(err, model.AppErrDraftValidFailed) {
+			dr.Error(dr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		} else {
+			dr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (dr *DraftResource) Delete(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		dr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	targetType, targetId, err := dr.parseTarget(r)
+	if err != nil {
+		dr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	user := dr.GetLoginedUserData(r)
+	err = dr.srv.Draft.Delete(user.Id, targetType, targetId)
+	if err != nil {
+		dr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	dr.Session("one", w, r).Flash(dr.Local(r, "DraftDeleted"))
+
+	// Back to the form page or the draft list
+	if r.Form.Get("from") == "list" {
+		http.Redirect(w, r, fmt.Sprintf("/users/%s?tab=drafts", user.Name), http.StatusFound)
+	} else {
+		draft := &model.Draft{TargetType: targetType, TargetId: targetId}
+		http.Redirect(w, r, draft.FormPath(), http.StatusFound)
+	}
+}
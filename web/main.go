@@ -6,9 +6,9 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -23,8 +23,10 @@ import (
 	"github.com/oodzchen/dproject/config"
 	mdw "github.com/oodzchen/dproject/middleware"
 	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/oauth"
 	"github.com/oodzchen/dproject/service"
 	"github.com/oodzchen/dproject/utils"
+	"github.com/pquerna/otp"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -71,6 +73,14 @@ func (mr *MainResource) Routes() http.Handler {
 	rt.With(mdw.UserLogger(
 		mr.uLogger, model.AcTypeUser, model.AcActionLogin, model.AcModelEmpty, mdw.ULogEmpty),
 	).Post("/login", mr.Login)
+	rt.Get("/login/2fa", mr.LoginTwoFactorPage)
+	rt.With(mdw.UserLogger(
+		mr.uLogger, model.AcTypeUser, model.AcActionLogin, model.AcModelEmpty, mdw.ULogRecoveryCodeUsed),
+	).Post("/login/2fa", mr.LoginTwoFactor)
+	rt.Get("/login/link", mr.LoginLinkPage)
+	rt.With(mdw.UserLogger(
+		mr.uLogger, model.AcTypeUser, model.AcActionLinkLoginMethod, model.AcModelEmpty, mdw.ULogEmpty),
+	).Post("/login/link", mr.LoginLink)
 	rt.With(mdw.AuthCheck(mr.sessStore), mdw.UserLogger(
 		mr.uLogger, model.AcTypeUser, model.AcActionLogout, "", mdw.ULogEmpty),
 	).Post("/logout", mr.Logout)
@@ -97,18 +107,90 @@ func (mr *MainResource) Routes() http.Handler {
 		).Post("/account", mr.SaveAccountSettings)
 		r.Get("/ui", mr.SettingsUIPage)
 		r.Post("/ui", mr.SaveUISettings)
+
+		r.With(mdw.AuthCheck(mr.sessStore)).Group(func(r chi.Router) {
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionCreateApiToken, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/tokens", mr.CreateApiToken)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionRevokeApiToken, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/tokens/{tokenId}/revoke", mr.RevokeApiToken)
+
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionUpdateNotifySettings, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/notify", mr.SaveNotifySettings)
+
+			r.Get("/account/sessions", mr.SettingsSessionsPage)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionRevokeSession, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/sessions/{sessionId}/revoke", mr.RevokeSession)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionRevokeOtherSessions, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/sessions/revoke_others", mr.RevokeOtherSessions)
+
+			r.Get("/account/2fa", mr.SettingsTwoFactorPage)
+			r.Get("/account/2fa/qrcode", mr.TwoFactorQRCode)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionEnable2Fa, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/2fa/enable", mr.EnableTwoFactor)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionDisable2Fa, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/2fa/disable", mr.DisableTwoFactor)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionRegenerateRecoveryCodes, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/2fa/recovery_codes", mr.RegenerateRecoveryCodes)
+
+			r.Get("/account/logins", mr.SettingsLoginMethodsPage)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionLinkLoginMethod, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/logins/{provider}/link", mr.LinkLoginMethod)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionUnlinkLoginMethod, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/logins/{provider}/unlink", mr.UnlinkLoginMethod)
+
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionChangeUsername, model.AcModelUser, mdw.ULogOldUsername),
+			).Post("/account/username", mr.ChangeUsername)
+
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionExportAccountData, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/export", mr.ExportAccountData)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionRequestAccountDeletion, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/delete", mr.RequestAccountDeletion)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionCancelAccountDeletion, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/account/delete/cancel", mr.CancelAccountDeletion)
+
+			r.Get("/blocks", mr.SettingsBlocksPage)
+			r.Get("/ignores", mr.SettingsIgnoresPage)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionBlockUser, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/blocks", mr.BlockUser)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionUnblockUser, model.AcModelEmpty, mdw.ULogEmpty),
+			).Post("/blocks/{userId}/remove", mr.UnblockUser)
+		})
 	})
 
-	rt.Get("/login_auth", mr.LoginWithOAuth)
-	rt.Get("/auth_callback", mr.LoginAuthCallback)
-	rt.Get("/auth_callback_github", mr.LoginAuthCallbackGithub)
+	rt.Get("/auth/{provider}", mr.LoginWithOAuth)
+	rt.Get("/auth/{provider}/callback", mr.LoginAuthCallback)
 
 	rt.With(mdw.AuthCheck(mr.sessStore)).Get("/messages", mr.MessageList)
 
 	rt.Route("/categories", func(r chi.Router) {
 		r.Get("/", mr.CategoryList)
+		r.With(mdw.AuthCheck(mr.sessStore), mdw.PermitCheck(mr.srv.Permission, []string{
+			"category.propose",
+		}, mr)).Group(func(r chi.Router) {
+			r.Get("/new", mr.CategoryProposePage)
+			r.With(mdw.UserLogger(
+				mr.uLogger, model.AcTypeUser, model.AcActionProposeCategory, model.AcModelCategory, mdw.ULogNewCategoryFrontId),
+			).Post("/", mr.CategoryPropose)
+		})
 		r.Get("/{categoryFrontId}", mr.CategoryArticleList)
 		r.Post("/{categoryFrontId}/subscribe", mr.CategorySubscribe)
+		r.Post("/{categoryFrontId}/ignore", mr.CategoryIgnore)
 	})
 
 	rt.Get("/about", mr.About)
@@ -207,7 +289,7 @@ func (mr *MainResource) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mr.Render(w, r, "register", &model.PageData{
-		Title: mr.Local("Register"),
+		Title: mr.Local(r, "Register"),
 		Data: &PageData{
 			Human: isHuman,
 			// VerifiedOnce: verifiedOnce,
@@ -215,7 +297,7 @@ func (mr *MainResource) RegisterPage(w http.ResponseWriter, r *http.Request) {
 		BreadCrumbs: []*model.BreadCrumb{
 			{
 				Path: "/register",
-				Name: mr.Local("Register"),
+				Name: mr.Local(r, "Register"),
 			},
 		},
 	})
@@ -245,9 +327,9 @@ func (mr *MainResource) Register(w http.ResponseWriter, r *http.Request) {
 	user.Sanitize(mr.sanitizePolicy)
 	err := user.Valid(true)
 	if err != nil {
-		errStr := err.Error()
+		errStr := mr.LocalError(r, err)
 		if errors.Is(err, model.ErrEmailValidFailed) {
-			errStr = model.ErrEmailValidFailed.Error()
+			errStr = model.EmailValidFailedText(mr.Localizer(r))
 		}
 		mr.Error(errStr, err, w, r, http.StatusBadRequest)
 		return
@@ -260,8 +342,8 @@ func (mr *MainResource) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		if userId > 0 {
-			// alreadyExistsTip := mr.Local("AlreadyExists", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email")))
-			mr.Error(model.ErrEmailValidFailed.Error(), err, w, r, http.StatusBadRequest)
+			// alreadyExistsTip := mr.Local(r, "AlreadyExists", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email")))
+			mr.Error(model.EmailValidFailedText(mr.Localizer(r)), err, w, r, http.StatusBadRequest)
 			return
 		}
 	}
@@ -276,7 +358,7 @@ func (mr *MainResource) Register(w http.ResponseWriter, r *http.Request) {
 
 	err = mr.rdb.Set(context.Background(), "register_pass_"+user.Email, user.Password, service.DefaultCodeLifeTime).Err()
 	if err != nil {
-		mr.Error(err.Error(), err, w, r, http.StatusBadRequest)
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		return
 	}
 	mr.Session("one", w, r).SetValue("register_email", user.Email)
@@ -312,7 +394,7 @@ func (mr *MainResource) VerifyRegisterPage(w http.ResponseWriter, r *http.Reques
 	}
 
 	mr.Render(w, r, "register_verify", &model.PageData{
-		Title: mr.Local("EmailVerify") + " - " + mr.Local("Register"),
+		Title: mr.Local(r, "EmailVerify") + " - " + mr.Local(r, "Register"),
 		Data: &PageData{
 			Email: email,
 			// Username: username,
@@ -320,7 +402,7 @@ func (mr *MainResource) VerifyRegisterPage(w http.ResponseWriter, r *http.Reques
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("EmailVerify"),
+				Name: mr.Local(r, "EmailVerify"),
 			},
 		},
 	})
@@ -348,6 +430,11 @@ func (mr *MainResource) ResendVerifyEmail(w http.ResponseWriter, r *http.Request
 	case service.VerifCodeResetPassword:
 		email = mr.Session("one", w, r).GetStringValue("email_reset_pass")
 		redirectPath = "reset_password"
+	case service.VerifCodeLinkIdentity:
+		if il := mr.identityLink(w, r); il != nil {
+			email = il.Email
+		}
+		redirectPath = "/login/link"
 	}
 
 	if email == "" {
@@ -370,7 +457,7 @@ func (mr *MainResource) ResendVerifyEmail(w http.ResponseWriter, r *http.Request
 		if !isRegistered {
 			go mr.sendVerifyCode(email, codeType, w, r)
 		}
-	case service.VerifCodeResetPassword:
+	case service.VerifCodeResetPassword, service.VerifCodeLinkIdentity:
 		if isRegistered {
 			go mr.sendVerifyCode(email, codeType, w, r)
 		}
@@ -401,7 +488,7 @@ func (mr *MainResource) sendVerifyCode(email string, codeType service.VerifCodeT
 	// }
 	// fmt.Println("get code from redis: ", savedCode)
 
-	err = mr.srv.Mail.SendVerificationCode(email, code, codeType)
+	err = mr.srv.Mail.SendVerificationCode(email, code, codeType, mr.Localizer(r).CurrLang)
 	if err != nil {
 		fmt.Println("send verification code error: ", err)
 		return
@@ -448,9 +535,9 @@ func (mr *MainResource) VerifyRegister(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.Is(err, model.AppErrUserValidFailed) {
-			mr.Error(err.Error(), err, w, r, http.StatusBadRequest)
+			mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		} else if errors.As(err, &pgErr) && pgErr.Code == PGErrUniqueViolation {
-			alreadyExistsTip := mr.Local("AlreadyExists", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email")))
+			alreadyExistsTip := mr.Local(r, "AlreadyExists", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email")))
 			mr.Error(alreadyExistsTip, err, w, r, http.StatusBadRequest)
 		} else {
 			mr.Error("", err, w, r, http.StatusInternalServerError)
@@ -462,7 +549,7 @@ func (mr *MainResource) VerifyRegister(w http.ResponseWriter, r *http.Request) {
 	mr.Session("one", w, r).SetValue("register_email", "")
 	mr.Session("one", w, r).SetValue("register_username", "")
 
-	mr.Session("one", w, r).Flash(mr.i18nCustom.MustLocalize("AccountCreateSuccess", "", ""))
+	mr.Session("one", w, r).Flash(mr.Localizer(r).MustLocalize("AccountCreateSuccess", "", ""))
 
 	http.Redirect(w, r, "/login", http.StatusFound)
 }
@@ -478,7 +565,7 @@ func (mr *MainResource) verifyMailCode(email, code string, codeType service.Veri
 
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			mr.Error(mr.Local("VerificationExpired"), err, w, r, http.StatusBadRequest)
+			mr.Error(mr.Local(r, "VerificationExpired"), err, w, r, http.StatusBadRequest)
 		} else {
 			mr.ServerErrorp("", errors.Join(err, errors.New("get code failed")), w, r)
 		}
@@ -487,7 +574,7 @@ func (mr *MainResource) verifyMailCode(email, code string, codeType service.Veri
 
 	err = mr.srv.Verifier.VerifyCode(code, savedCode)
 	if err != nil {
-		mr.Error(mr.Local("VerificationIncorrect"), err, w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "VerificationIncorrect"), err, w, r, http.StatusBadRequest)
 		return errors.Join(ErrCodeVerifyIncorrect, err)
 	}
 
@@ -520,7 +607,8 @@ func (mr *MainResource) LoginPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type PageData struct {
-		Human bool
+		Human          bool
+		OAuthProviders []oauth.Provider
 	}
 
 	isHuman, err := mr.isHuman(w, r)
@@ -530,14 +618,15 @@ func (mr *MainResource) LoginPage(w http.ResponseWriter, r *http.Request) {
 
 	mr.Session("one", w, r).SetValue("target_url", targetUrl)
 	mr.Render(w, r, "login", &model.PageData{
-		Title: mr.i18nCustom.LocalTpl("Login"),
+		Title: mr.Local(r, "Login"),
 		Data: &PageData{
-			Human: isHuman,
+			Human:          isHuman,
+			OAuthProviders: mr.srv.OAuth.List(),
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
 				Path: "/login",
-				Name: mr.Local("Login"),
+				Name: mr.Local(r, "Login"),
 			},
 		},
 	})
@@ -547,37 +636,39 @@ func (mr *MainResource) Login(w http.ResponseWriter, r *http.Request) {
 	username := strings.TrimSpace(r.PostFormValue("username"))
 	password := strings.TrimSpace(r.PostFormValue("password"))
 
-	mr.doLogin(w, r, username, password)
-
-	mr.ToTargetUrl(w, r)
+	if mr.doLogin(w, r, username, password) {
+		mr.ToTargetUrl(w, r)
+	}
 }
 
-func (mr *MainResource) doLogin(w http.ResponseWriter, r *http.Request, username, password string) {
+// Check the password and login, returns true if logined, false if failed or
+// waiting for the second step
+func (mr *MainResource) doLogin(w http.ResponseWriter, r *http.Request, username, password string) bool {
 	if username == "" {
-		userNameRequiredTip := mr.Local("Required", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email")))
+		userNameRequiredTip := mr.Local(r, "Required", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email")))
 		mr.Error(userNameRequiredTip, nil, w, r, http.StatusBadRequest)
-		return
+		return false
 	}
 
 	if password == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Password")), nil, w, r, http.StatusBadRequest)
-		return
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Password")), nil, w, r, http.StatusBadRequest)
+		return false
 	}
 
-	// mr.Local("NotRegistered", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email")))
-	loginFailedTip := mr.Local("Or", "A", mr.Local("NotRegistered", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email"))), "B", mr.Local("Incorrect", "FieldNames", mr.Local("Password")))
+	// mr.Local(r, "NotRegistered", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email")))
+	loginFailedTip := mr.Local(r, "Or", "A", mr.Local(r, "NotRegistered", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email"))), "B", mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Password")))
 
 	if regexp.MustCompile(`@`).Match([]byte(username)) {
 		if err := model.ValidateEmail(username); err != nil {
-			emailValidTip := mr.Local("Incorrect", "FieldNames", mr.Local("Or", "A", mr.Local("Email"), "B", mr.Local("Password")))
+			emailValidTip := mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Email"), "B", mr.Local(r, "Password")))
 			mr.Error(emailValidTip, err, w, r, http.StatusBadRequest)
-			return
+			return false
 		}
 	} else {
 		if err := model.ValidUsername(username); err != nil {
 			usernameValidTip := loginFailedTip
 			mr.Error(usernameValidTip, err, w, r, http.StatusBadRequest)
-			return
+			return false
 		}
 	}
 
@@ -586,62 +677,63 @@ func (mr *MainResource) doLogin(w http.ResponseWriter, r *http.Request, username
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			// notRegisteredTip := mr.Local("NotRegistered", "FieldNames", mr.Local("Or", "A", mr.Local("Username"), "B", mr.Local("Email")))
+			// notRegisteredTip := mr.Local(r, "NotRegistered", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Username"), "B", mr.Local(r, "Email")))
 			notRegisteredTip := loginFailedTip
 			mr.Error(notRegisteredTip, err, w, r, http.StatusBadRequest)
 		} else {
 			mr.Error(loginFailedTip, err, w, r, http.StatusBadRequest)
 		}
 
-		return
+		return false
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(hashedPwd), []byte(password))
 	if err != nil {
 		mr.Error(loginFailedTip, err, w, r, http.StatusBadRequest)
-		return
+		return false
 	}
 
 	user, err := mr.store.User.ItemWithUsernameEmail(username)
 	if err != nil {
 		mr.Error("", err, w, r, http.StatusInternalServerError)
+		return false
 	}
 
-	mr.saveUserInfo(w, r, user)
+	return mr.startLogin(w, r, user)
 }
 
-func (mr *MainResource) doLoginOAuth(w http.ResponseWriter, r *http.Request, email string) {
-	if email == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Email")), nil, w, r, http.StatusBadRequest)
-		return
-	}
-
-	if err := model.ValidateEmail(email); err != nil {
-		emailValidTip := mr.Local("Incorrect", "FieldNames", mr.Local("Or", "A", mr.Local("Email"), "B", mr.Local("Password")))
-		mr.Error(emailValidTip, err, w, r, http.StatusBadRequest)
-		return
-	}
-
-	user, err := mr.store.User.ItemWithEmail(email)
-	if err != nil {
-		mr.Error("", err, w, r, http.StatusInternalServerError)
+// Login the user, users with two-factor authentication enabled are sent to
+// the second step instead, returns true if logined
+func (mr *MainResource) startLogin(w http.ResponseWriter, r *http.Request, user *model.User) bool {
+	if !user.TotpEnabled {
+		return mr.saveUserInfo(w, r, user)
 	}
 
-	mr.saveUserInfo(w, r, user)
+	mr.Session("one", w, r).SetValue("two_factor_login", model.TwoFactorLogin{
+		UserId:    user.Id,
+		StartedAt: time.Now(),
+	})
+	http.Redirect(w, r, "/login/2fa", http.StatusFound)
+	return false
 }
 
-func (mr *MainResource) saveUserInfo(w http.ResponseWriter, r *http.Request, user *model.User) {
+func (mr *MainResource) saveUserInfo(w http.ResponseWriter, r *http.Request, user *model.User) bool {
 	// mr.srv.Permission.SetLoginedUser(user)
 
 	sess, err := mr.sessStore.Get(r, "one")
 	if err != nil {
 		logSessError("one", err)
 		mr.Error("", err, w, r, http.StatusInternalServerError)
-		return
+		return false
 	}
 
 	sess.Values["user_id"] = user.Id
 	sess.Values["user_name"] = user.Name
+	delete(sess.Values, "two_factor_login")
+	if !user.DeletionRequestedAt.IsZero() {
+		deletionTime := mr.srv.Account.DeletionTime(user.DeletionRequestedAt)
+		sess.AddFlash(mr.Local(r, "AccountDeletionPendingTip", "Time", deletionTime.Format(time.DateOnly)))
+	}
 	// gob.Register([]string{})
 	// sess.Values["user_permitted_id_list"] = permittedIdList
 
@@ -653,36 +745,45 @@ func (mr *MainResource) saveUserInfo(w http.ResponseWriter, r *http.Request, use
 	err = sess.Save(r, w)
 	if err != nil {
 		mr.Error("", err, w, r, http.StatusInternalServerError)
-		return
+		return false
 	}
 
 	ctx := context.WithValue(r.Context(), "user_data", user)
 	*r = *r.WithContext(ctx)
+	return true
 }
 
-func (mr *MainResource) doRegisterWithOAuth(w http.ResponseWriter, r *http.Request, email string, authType model.AuthType) {
-	if email == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Email")), nil, w, r, http.StatusBadRequest)
-		return
-	}
-
+func (mr *MainResource) doRegisterWithOAuth(w http.ResponseWriter, r *http.Request, provider oauth.Provider, userInfo *oauth.UserInfo) {
+	email := userInfo.Email
 	if err := model.ValidateEmail(email); err != nil {
-		emailValidTip := mr.Local("Incorrect", "FieldNames", mr.Local("Or", "A", mr.Local("Email"), "B", mr.Local("Password")))
+		emailValidTip := mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Email"), "B", mr.Local(r, "Password")))
 		mr.Error(emailValidTip, err, w, r, http.StatusBadRequest)
 		return
 	}
 
 	username := model.ExtractNameFromEmail(email)
 
-	_, err := mr.store.User.CreateWithOAuth(email, username, string(model.DefaultUserRoleCommon), string(authType))
+	userId, err := mr.store.User.CreateWithOAuth(email, username, string(model.DefaultUserRoleCommon), provider.AuthType())
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	err = mr.store.User.LinkIdentity(userId, provider.Name(), userInfo.Subject, email)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
 
-	mr.doLoginOAuth(w, r, email)
+	user, err := mr.store.User.Item(userId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
 
-	mr.ToTargetUrl(w, r)
+	if mr.startLogin(w, r, user) {
+		mr.ToTargetUrl(w, r)
+	}
 }
 
 func (mr *MainResource) LoginDebug(w http.ResponseWriter, r *http.Request) {
@@ -690,9 +791,10 @@ func (mr *MainResource) LoginDebug(w http.ResponseWriter, r *http.Request) {
 	password := config.Config.DB.UserDefaultPassword
 	fmt.Println("debug-user-email: ", email)
 
-	mr.doLogin(w, r, email, password)
-	// mr.ToPrevPage(w, r)
-	mr.ToRefererUrl(w, r)
+	if mr.doLogin(w, r, email, password) {
+		// mr.ToPrevPage(w, r)
+		mr.ToRefererUrl(w, r)
+	}
 }
 
 func (mr *MainResource) Logout(w http.ResponseWriter, r *http.Request) {
@@ -728,8 +830,8 @@ func (mr *MainResource) SaveUISettings(w http.ResponseWriter, r *http.Request) {
 
 	if lang, err := model.ParseLang(lang); err == nil {
 		uiSettings.Lang = lang
-		mr.i18nCustom.SwitchLang(string(lang))
-		model.UpdateErrI18n()
+		// Tip of saving is shown in the new language
+		r = r.WithContext(context.WithValue(r.Context(), "i18n_custom", mr.i18nCustom.WithLang(string(lang))))
 	}
 
 	if regexp.MustCompile(`^light|dark|system|matrix$`).Match([]byte(theme)) {
@@ -789,7 +891,7 @@ func (mr *MainResource) SaveUISettings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	oneSess := mr.Session("one", w, r)
-	oneSess.Raw.AddFlash(mr.i18nCustom.MustLocalize("UISaveSuccess", "", 0))
+	oneSess.Raw.AddFlash(mr.Localizer(r).MustLocalize("UISaveSuccess", "", 0))
 	oneSess.Raw.Save(r, w)
 
 	ctx := context.WithValue(r.Context(), "ui_settings", uiSettings)
@@ -808,8 +910,13 @@ func (mr *MainResource) SettingsPage(w http.ResponseWriter, r *http.Request) {
 type SettingsPageKey string
 
 const (
-	SettingsPageKeyUI      SettingsPageKey = "ui"
-	SettingsPageKeyAccount                 = "account"
+	SettingsPageKeyUI           SettingsPageKey = "ui"
+	SettingsPageKeyAccount                      = "account"
+	SettingsPageKeyBlocks                       = "blocks"
+	SettingsPageKeyIgnores                      = "ignores"
+	SettingsPageKeySessions                     = "sessions"
+	SettingsPageKeyTwoFactor                    = "2fa"
+	SettingsPageKeyLoginMethods                 = "logins"
 )
 
 type SettingsPageData struct {
@@ -819,13 +926,57 @@ type SettingsPageData struct {
 	ArticleSortTabList []model.ArticleSortType
 	ReplySortTabList   []model.ArticleSortType
 	SortTabNames       map[model.ArticleSortType]string
+	ApiTokenList       []*model.ApiToken
+	ApiTokenPermList   []*model.PermissionListItem
+	NewApiToken        string
+	UserBlockList      []*model.UserBlock
+	IgnoredCategories  []*model.Category
+	Sessions           []*model.SessionDevice
+	TwoFactor          *TwoFactorSettings
+	LoginMethods       *LoginMethodSettings
+	// Zero if the account deletion is not requested
+	AccountDeletionAt   time.Time
+	AccountDeletionDays int
+	// Zero if the username can be changed now
+	UsernameChangeAt     time.Time
+	UsernameCooldownDays int
+	UsernameRedirectDays int
+}
+
+type LoginMethodItem struct {
+	Provider    string
+	DisplayName string
+	// Nil if the provider is not linked
+	Identity *model.UserIdentity
+}
+
+type LoginMethodSettings struct {
+	HasPassword bool
+	// False if there is only one login method left
+	CanUnlink bool
+	List      []*LoginMethodItem
+}
+
+type TwoFactorSettings struct {
+	Enabled bool
+	// Two-factor authentication is required by the role
+	Required bool
+	// Secret of the key being enrolled, for entering manually
+	PendingSecret     string
+	RecoveryCodeCount int
+	NewRecoveryCodes  []string
 }
 
 func (mr *MainResource) handleSettingsPage(w http.ResponseWriter, r *http.Request, pageKey SettingsPageKey) {
 	// fmt.Println("ui setting page type: ", pageKey)
 	settingsTitleMap := map[SettingsPageKey]string{
-		SettingsPageKeyUI:      mr.Local("UI"),
-		SettingsPageKeyAccount: mr.Local("Account"),
+		SettingsPageKeyUI:           mr.Local(r, "UI"),
+		SettingsPageKeyAccount:      mr.Local(r, "Account"),
+		SettingsPageKeyBlocks:       mr.Local(r, "UserBlock", "Count", 2),
+		SettingsPageKeyIgnores:      mr.Local(r, "IgnoredCategory", "Count", 2),
+		SettingsPageKeySessions:     mr.Local(r, "LoginSession", "Count", 2),
+		SettingsPageKeyTwoFactor:    mr.Local(r, "TwoFactorAuth"),
+		SettingsPageKeyLoginMethods: mr.Local(r, "LoginMethod", "Count", 2),
 	}
 
 	var langStrEnums []model.StringEnum
@@ -838,7 +989,7 @@ func (mr *MainResource) handleSettingsPage(w http.ResponseWriter, r *http.Reques
 		LanguageOptions:    langOptions,
 		ArticleSortTabList: model.GetSortTypeList(false, model.DefaultArticleListSortType),
 		ReplySortTabList:   model.GetSortTypeList(true, model.DefaultReplyListSortType),
-		SortTabNames:       model.GetSortTypeNames(mr.i18nCustom),
+		SortTabNames:       model.GetSortTypeNames(mr.Localizer(r)),
 	}
 
 	if pageKey == SettingsPageKeyAccount {
@@ -851,13 +1002,125 @@ func (mr *MainResource) handleSettingsPage(w http.ResponseWriter, r *http.Reques
 				return
 			}
 			pageData.AccountData = user
+			pageData.AccountDeletionDays = mr.srv.Account.GracePeriodDays()
+			pageData.UsernameCooldownDays = mr.srv.User.UsernameCooldownDays()
+			pageData.UsernameRedirectDays = mr.srv.User.UsernameRedirectDays()
+
+			usernameChangeAt, err := mr.srv.User.UsernameChangeAvailableAt(userId)
+			if err != nil {
+				mr.ServerErrorp("", err, w, r)
+				return
+			}
+			pageData.UsernameChangeAt = usernameChangeAt
+			if !user.DeletionRequestedAt.IsZero() {
+				pageData.AccountDeletionAt = mr.srv.Account.DeletionTime(user.DeletionRequestedAt)
+			}
+
+			tokenList, err := mr.store.ApiToken.List(userId)
+			if err != nil {
+				mr.Error("", err, w, r, http.StatusInternalServerError)
+				return
+			}
+			pageData.ApiTokenList = tokenList
+
+			permissionList, err := mr.getApiTokenPermissionList(r)
+			if err != nil {
+				mr.Error("", err, w, r, http.StatusInternalServerError)
+				return
+			}
+			pageData.ApiTokenPermList = formatPermissionList(permissionList, mr.srv.Permission.PermissionData.GetModuleList())
+
+			if newToken, ok := r.Context().Value("new_api_token").(string); ok {
+				pageData.NewApiToken = newToken
+			}
 		} else {
 			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+	}
+
+	if pageKey == SettingsPageKeyBlocks {
+		user := mr.GetLoginedUserData(r)
+		if user == nil {
+			mr.ToLogin(w, r)
+			return
+		}
+
+		blockList, err := mr.store.User.ListBlocks(user.Id)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+		pageData.UserBlockList = blockList
+	}
+
+	if pageKey == SettingsPageKeyIgnores {
+		user := mr.GetLoginedUserData(r)
+		if user == nil {
+			mr.ToLogin(w, r)
+			return
+		}
+
+		ignoredList, err := mr.store.Category.ListIgnored(user.Id)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+		pageData.IgnoredCategories = ignoredList
+	}
+
+	if pageKey == SettingsPageKeySessions {
+		user := mr.GetLoginedUserData(r)
+		if user == nil {
+			mr.ToLogin(w, r)
+			return
 		}
+
+		sessionList, err := mr.sessStore.List(user.Id)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+
+		currPublicId := service.SessionPublicId(mr.Session("one", w, r).Raw.ID)
+		for _, item := range sessionList {
+			item.Current = item.PublicId == currPublicId
+		}
+		pageData.Sessions = sessionList
+	}
+
+	if pageKey == SettingsPageKeyTwoFactor {
+		user := mr.GetLoginedUserData(r)
+		if user == nil {
+			mr.ToLogin(w, r)
+			return
+		}
+
+		twoFactor, err := mr.twoFactorSettings(w, r, user)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+		pageData.TwoFactor = twoFactor
+	}
+
+	if pageKey == SettingsPageKeyLoginMethods {
+		user := mr.GetLoginedUserData(r)
+		if user == nil {
+			mr.ToLogin(w, r)
+			return
+		}
+
+		loginMethods, err := mr.loginMethodSettings(user)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+		pageData.LoginMethods = loginMethods
 	}
 
 	// mr.Session("one-cookie", w, r).SetValue("next_url", r.Referer())
-	settingsText := mr.i18nCustom.MustLocalize("Settings", "", 2)
+	settingsText := mr.Localizer(r).MustLocalize("Settings", "", 2)
 	mr.Render(w, r, "settings", &model.PageData{
 		Title: settingsTitleMap[pageKey] + " " + settingsText,
 		Data:  pageData,
@@ -878,6 +1141,26 @@ func (mr *MainResource) SettingsUIPage(w http.ResponseWriter, r *http.Request) {
 	mr.handleSettingsPage(w, r, SettingsPageKeyUI)
 }
 
+func (mr *MainResource) SettingsBlocksPage(w http.ResponseWriter, r *http.Request) {
+	mr.handleSettingsPage(w, r, SettingsPageKeyBlocks)
+}
+
+func (mr *MainResource) SettingsIgnoresPage(w http.ResponseWriter, r *http.Request) {
+	mr.handleSettingsPage(w, r, SettingsPageKeyIgnores)
+}
+
+func (mr *MainResource) SettingsSessionsPage(w http.ResponseWriter, r *http.Request) {
+	mr.handleSettingsPage(w, r, SettingsPageKeySessions)
+}
+
+func (mr *MainResource) SettingsTwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	mr.handleSettingsPage(w, r, SettingsPageKeyTwoFactor)
+}
+
+func (mr *MainResource) SettingsLoginMethodsPage(w http.ResponseWriter, r *http.Request) {
+	mr.handleSettingsPage(w, r, SettingsPageKeyLoginMethods)
+}
+
 func (mr *MainResource) SaveAccountSettings(w http.ResponseWriter, r *http.Request) {
 	introduction := r.FormValue("introduction")
 
@@ -896,7 +1179,7 @@ func (mr *MainResource) SaveAccountSettings(w http.ResponseWriter, r *http.Reque
 		}
 
 		oneSess := mr.Session("one", w, r)
-		oneSess.Raw.AddFlash(mr.i18nCustom.MustLocalize("AccountSaveSuccess", "", ""))
+		oneSess.Raw.AddFlash(mr.Localizer(r).MustLocalize("AccountSaveSuccess", "", ""))
 		oneSess.Raw.Save(r, w)
 
 		http.Redirect(w, r, "/settings/account", http.StatusFound)
@@ -905,312 +1188,1119 @@ func (mr *MainResource) SaveAccountSettings(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-type MessageStatus string
+func (mr *MainResource) SaveNotifySettings(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
 
-const (
-	MessageStatusUnread MessageStatus = "unread"
-	MessageStatusRead                 = "read"
-	MessageStatusAll                  = "all"
-)
+	err := mr.store.User.UpdateMentionNotify(user.Id, r.FormValue("mention_notify") == "1")
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
 
-var MessageStatusMap = map[MessageStatus]bool{
-	MessageStatusUnread: true,
-	MessageStatusRead:   true,
-	MessageStatusAll:    true,
+	mr.Session("one", w, r).Flash(mr.Local(r, "AccountSaveSuccess"))
+	http.Redirect(w, r, "/settings/account", http.StatusFound)
 }
 
-type MessageQueryData struct {
-	*queryData
-	Tab string
-}
-type MessagePageData struct {
-	List  []*model.Message
-	Query *MessageQueryData
+// Permissions that current user can grant to API token
+func (mr *MainResource) getApiTokenPermissionList(r *http.Request) ([]*model.Permission, error) {
+	permissionList, err := mr.store.Permission.List(1, 999, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	enabledIdMap := make(map[string]bool)
+	for _, frontId := range mr.srv.Permission.GetEnabledIdList(mr.GetLoginedUserData(r)) {
+		enabledIdMap[frontId] = true
+	}
+
+	var list []*model.Permission
+	for _, item := range permissionList {
+		if enabledIdMap[item.FrontId] {
+			list = append(list, item)
+		}
+	}
+
+	return list, nil
 }
 
-func (mr *MainResource) MessageList(w http.ResponseWriter, r *http.Request) {
-	tab := strings.TrimSpace(r.URL.Query().Get("tab"))
+func (mr *MainResource) CreateApiToken(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
 
-	page, pageSize := mr.GetPaginationData(r)
+	name := r.PostForm.Get("name")
+	permissions := r.PostForm["permissions"]
 
-	if _, ok := MessageStatusMap[MessageStatus(tab)]; !ok {
-		tab = string(MessageStatusAll)
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
 	}
 
-	userId := mr.GetLoginedUserId(w, r)
-
-	list, total, err := mr.store.Message.List(userId, tab, page, pageSize)
+	permissionList, err := mr.getApiTokenPermissionList(r)
 	if err != nil {
-		mr.Error("", err, w, r, http.StatusInternalServerError)
+		mr.ServerErrorp("", err, w, r)
 		return
 	}
 
-	var messageIds []any
-	for _, item := range list {
-		item.SourceArticle.FormatDeleted()
-		item.SourceArticle.UpdateDisplayTitle()
-		messageIds = append(messageIds, item.Id)
+	permittedMap := make(map[string]bool)
+	for _, item := range permissionList {
+		permittedMap[item.FrontId] = true
 	}
 
-	if len(messageIds) > 0 {
-		// err = mr.store.Message.ReadMany(messageIds)
-		err = mr.store.Message.ReadAll(userId)
-		if err != nil {
-			mr.Error("", err, w, r, http.StatusInternalServerError)
+	for _, frontId := range permissions {
+		if !permittedMap[frontId] {
+			mr.Forbidden(errors.New("permission not granted to user: "+frontId), w, r)
 			return
 		}
 	}
 
-	title := mr.Local("List", "Name", mr.Local("Message"))
-
-	mr.Render(w, r, "message", &model.PageData{
-		Title: title,
-		Data: &MessagePageData{
-			List: list,
-			Query: &MessageQueryData{
-				&queryData{
-					Total: total, Page: page, TotalPage: CeilInt(total, pageSize),
-				},
-				tab,
-			},
-		},
-		BreadCrumbs: []*model.BreadCrumb{
-			{
-				Name: title,
-			},
-		},
-	})
-}
-
-type AuthType string
+	token, err := mr.srv.ApiToken.Create(user.Id, name, permissions)
+	if err != nil {
+		if errors.Is(err, model.AppErrApiTokenValidFailed) {
+			mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
 
-const (
-	AuthTypeGoogle    AuthType = "google"
-	AuthTypeGithub             = "github"
-	AuthTypeMicrosoft          = "microsoft"
-)
+	// Raw token is shown only in this response, it's not kept anywhere else
+	ctx := context.WithValue(r.Context(), "new_api_token", token)
+	*r = *r.WithContext(ctx)
 
-var authTypeMap = map[AuthType]bool{
-	AuthTypeGoogle:    true,
-	AuthTypeGithub:    true,
-	AuthTypeMicrosoft: true,
+	w.Header().Set("Cache-Control", "no-store")
+	mr.handleSettingsPage(w, r, SettingsPageKeyAccount)
 }
 
-func (mr *MainResource) LoginWithOAuth(w http.ResponseWriter, r *http.Request) {
-	authType := AuthType(r.URL.Query().Get("type"))
-	if _, ok := authTypeMap[authType]; !ok {
-		mr.Error("", errors.New("auth type not exist"), w, r, http.StatusBadRequest)
+func (mr *MainResource) RevokeApiToken(w http.ResponseWriter, r *http.Request) {
+	tokenId, err := strconv.Atoi(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
 		return
 	}
 
-	var authUrl string
-	switch authType {
-	case AuthTypeGoogle:
-		authUrl = getGoogleAuthUrl()
-	case AuthTypeGithub:
-		authUrl = getGithubAuthUrl()
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
 	}
 
-	state, err := genCSRFToken()
+	_, err = mr.store.ApiToken.Delete(tokenId, user.Id)
 	if err != nil {
-		mr.ServerErrorp("", err, w, r)
+		if errors.Is(err, pgx.ErrNoRows) {
+			mr.NotFound(w, r)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
 		return
 	}
 
-	mr.Session("one", w, r).SetValue("auth_state", state)
-
-	authUrl += fmt.Sprintf("&state=%s", state)
-
-	// fmt.Println("authUrl", authUrl)
-
-	http.Redirect(w, r, authUrl, http.StatusFound)
-}
+	mr.Session("one", w, r).Flash(mr.Local(r, "ApiTokenRevokeSuccess"))
 
-func getGoogleAuthUrl() string {
-	return fmt.Sprintf(
-		"https://accounts.google.com/o/oauth2/v2/auth?client_id=%s&redirect_uri=%s&response_type=%s&scope=%s",
-		config.Config.GoogleClientID,
-		config.Config.GetServerURL()+"/auth_callback",
-		"code",
-		"https://www.googleapis.com/auth/userinfo.email",
-	)
+	http.Redirect(w, r, "/settings/account", http.StatusFound)
 }
 
-func getGithubAuthUrl() string {
-	return fmt.Sprintf(
-		"https://github.com/login/oauth/authorize?client_id=%s&redirect_uri=%s&scope=%s",
-		config.Config.GithubClientID,
-		config.Config.GetServerURL()+"/auth_callback_github",
-		"user:email",
-	)
-}
+func (mr *MainResource) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
 
-func genCSRFToken() (string, error) {
-	randomData := make([]byte, 1024)
-	// fmt.Println("randomData: ", randomData)
+	publicId := chi.URLParam(r, "sessionId")
+	oneSess := mr.Session("one", w, r)
+	isCurrent := publicId == service.SessionPublicId(oneSess.Raw.ID)
 
-	_, err := rand.Read(randomData)
+	err := mr.sessStore.Revoke(user.Id, publicId)
 	if err != nil {
-		fmt.Println("read random data failed", err)
-		return "", err
+		if errors.Is(err, service.ErrSessionNotFound) {
+			mr.NotFound(w, r)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
 	}
 
-	// fmt.Println("randomData after read: ", randomData)
-
-	hash := sha256.New()
-	hash.Write(randomData)
-	hashInBytes := hash.Sum(nil)
+	oneSess.Flash(mr.Local(r, "LoginSessionRevoked"))
 
-	hashString := hex.EncodeToString(hashInBytes)
+	// Current session is revoked, the flash is kept in a new session
+	if isCurrent {
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
 
-	return hashString, nil
+	http.Redirect(w, r, "/settings/account/sessions", http.StatusFound)
 }
 
-type GoogleTokenResponse struct {
-	AccessToken      string `json:"access_token"`
-	ExpiresInSeconds int    `json:"expires_in"`
-	Scope            string `json:"scope"`
-	TokenType        string `json:"Bearer"`
-	IdToken          string `json:"id_token"`
-}
+func (mr *MainResource) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	err := mr.sessStore.RevokeAll(user.Id, mr.Session("one", w, r).Raw.ID)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "OtherLoginSessionsRevoked"))
+	http.Redirect(w, r, "/settings/account/sessions", http.StatusFound)
+}
+
+// Login methods of the user, including the enabled providers which are not
+// linked, and the linked ones which are disabled later
+func (mr *MainResource) loginMethodSettings(user *model.User) (*LoginMethodSettings, error) {
+	identities, err := mr.store.User.ListIdentities(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &LoginMethodSettings{
+		HasPassword: user.HasPassword,
+		CanUnlink:   model.CanUnlinkIdentity(user.HasPassword, len(identities)),
+	}
+
+	linked := make(map[string]*model.UserIdentity)
+	for _, item := range identities {
+		linked[item.Provider] = item
+	}
+
+	for _, provider := range mr.srv.OAuth.List() {
+		settings.List = append(settings.List, &LoginMethodItem{
+			Provider:    provider.Name(),
+			DisplayName: provider.DisplayName(),
+			Identity:    linked[provider.Name()],
+		})
+		delete(linked, provider.Name())
+	}
+
+	for _, item := range identities {
+		if _, ok := linked[item.Provider]; ok {
+			settings.List = append(settings.List, &LoginMethodItem{
+				Provider:    item.Provider,
+				DisplayName: item.Provider,
+				Identity:    item,
+			})
+		}
+	}
+
+	return settings, nil
+}
+
+func (mr *MainResource) LinkLoginMethod(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	provider, err := mr.srv.OAuth.Get(chi.URLParam(r, "provider"))
+	if err != nil {
+		mr.NotFound(w, r)
+		return
+	}
+
+	mr.redirectToProvider(w, r, provider, user.Id)
+}
+
+func (mr *MainResource) UnlinkLoginMethod(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	providerName := chi.URLParam(r, "provider")
+	err := mr.store.User.UnlinkIdentity(user.Id, providerName)
+	if err != nil {
+		if errors.Is(err, model.AppErrLastLoginMethod) {
+			mr.Session("one", w, r).Flash(mr.Local(r, "LastLoginMethodTip"))
+			http.Redirect(w, r, "/settings/account/logins", http.StatusFound)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			mr.NotFound(w, r)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	if provider, err := mr.srv.OAuth.Get(providerName); err == nil {
+		providerName = provider.DisplayName()
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "LoginMethodUnlinked", "Name", providerName))
+	http.Redirect(w, r, "/settings/account/logins", http.StatusFound)
+}
+
+func (mr *MainResource) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	if user.Name == model.PlaceholderUsername {
+		mr.Forbidden(errors.New("the placeholder user can not be renamed"), w, r)
+		return
+	}
+
+	r.ParseForm()
+
+	newName := strings.TrimSpace(r.PostForm.Get("username"))
+	if newName == user.Name {
+		http.Redirect(w, r, "/settings/account", http.StatusFound)
+		return
+	}
+
+	oldName, err := mr.srv.User.ChangeUsername(user.Id, newName)
+	if err != nil {
+		if errors.Is(err, model.AppErrUserValidFailed) || errors.Is(err, model.AppErrUsernameUnavailable) || errors.Is(err, model.AppErrUsernameChangeTooOften) {
+			mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "old_username", oldName)
+	*r = *r.WithContext(ctx)
+
+	oneSess := mr.Session("one", w, r)
+	oneSess.Raw.Values["user_name"] = newName
+	oneSess.Flash(mr.Local(r, "UsernameChanged", "Name", newName))
+	http.Redirect(w, r, "/settings/account", http.StatusFound)
+}
+
+// Download the personal data of the user as a zip archive
+func (mr *MainResource) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	var buf bytes.Buffer
+	err := mr.srv.Account.Export(user.Id, &buf)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.zip", user.Name, time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fileName,
+	}))
+	w.Write(buf.Bytes())
+}
+
+// Request to delete the account, the username is required to confirm, and
+// the password too if it's set
+func (mr *MainResource) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	if user.Name == model.PlaceholderUsername {
+		mr.Forbidden(errors.New("the placeholder user can not be deleted"), w, r)
+		return
+	}
+
+	if !user.DeletionRequestedAt.IsZero() {
+		http.Redirect(w, r, "/settings/account", http.StatusFound)
+		return
+	}
+
+	r.ParseForm()
+
+	if r.PostForm.Get("username") != user.Name {
+		mr.Error(mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Username")), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	if user.HasPassword {
+		hashedPwd, err := mr.store.User.GetPassword(user.Name)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(hashedPwd), []byte(r.PostForm.Get("password")))
+		if err != nil {
+			mr.Error(mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Password")), err, w, r, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := mr.srv.Account.RequestDeletion(user.Id)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	deletionTime := mr.srv.Account.DeletionTime(time.Now())
+	mr.Session("one", w, r).Flash(mr.Local(r, "AccountDeletionRequested", "Time", deletionTime.Format(time.DateOnly)))
+	http.Redirect(w, r, "/settings/account", http.StatusFound)
+}
+
+func (mr *MainResource) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	err := mr.srv.Account.CancelDeletion(user.Id)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
 
-type GoogleUserInfo struct {
-	SubId         string `json:"sub"`
-	Picture       string `json:"picture"`
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
+	mr.Session("one", w, r).Flash(mr.Local(r, "AccountDeletionCanceled"))
+	http.Redirect(w, r, "/settings/account", http.StatusFound)
 }
 
-func (mr *MainResource) LoginAuthCallback(w http.ResponseWriter, r *http.Request) {
+// Settings of two-factor authentication, a new key is generated if it's not
+// enabled, which is kept in session until it's confirmed
+func (mr *MainResource) twoFactorSettings(w http.ResponseWriter, r *http.Request, user *model.User) (*TwoFactorSettings, error) {
+	settings := &TwoFactorSettings{
+		Enabled:  user.TotpEnabled,
+		Required: mr.srv.Permission.RoleData.Require2FA(user.RoleFrontId),
+	}
+
+	oneSess := mr.Session("one", w, r)
+	if codes := oneSess.GetStringValue("new_recovery_codes"); codes != "" {
+		settings.NewRecoveryCodes = strings.Fields(codes)
+		oneSess.SetValue("new_recovery_codes", "")
+	}
+
+	if user.TotpEnabled {
+		count, err := mr.srv.TwoFactor.CountRecoveryCodes(user.Id)
+		if err != nil {
+			return nil, err
+		}
+		settings.RecoveryCodeCount = count
+		return settings, nil
+	}
+
+	key, err := pendingTotpKey(oneSess)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		key, err = mr.srv.TwoFactor.GenerateKey(user.Name)
+		if err != nil {
+			return nil, err
+		}
+		oneSess.SetValue("totp_pending_url", key.URL())
+	}
+	settings.PendingSecret = key.Secret()
+
+	return settings, nil
+}
+
+// Key being enrolled, nil if there is none
+func pendingTotpKey(oneSess *Session) (*otp.Key, error) {
+	provisioningUri := oneSess.GetStringValue("totp_pending_url")
+	if provisioningUri == "" {
+		return nil, nil
+	}
+	return otp.NewKeyFromURL(provisioningUri)
+}
+
+func (mr *MainResource) TwoFactorQRCode(w http.ResponseWriter, r *http.Request) {
+	provisioningUri := mr.Session("one", w, r).GetStringValue("totp_pending_url")
+	if provisioningUri == "" {
+		mr.NotFound(w, r)
+		return
+	}
+
+	img, err := service.TotpQRCode(provisioningUri)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(img)
+}
+
+func (mr *MainResource) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	oneSess := mr.Session("one", w, r)
+	key, err := pendingTotpKey(oneSess)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	if user.TotpEnabled || key == nil {
+		http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		return
+	}
+
+	codes, err := mr.srv.TwoFactor.Enable(user.Id, key.Secret(), r.PostFormValue("two_factor_code"))
+	if err != nil {
+		if errors.Is(err, service.ErrTwoFactorCodeInvalid) {
+			oneSess.Flash(mr.Local(r, "TwoFactorCodeInvalid"))
+			http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	delete(oneSess.Raw.Values, "totp_pending_url")
+	oneSess.SetValue("new_recovery_codes", strings.Join(codes, " "))
+	oneSess.Flash(mr.Local(r, "TwoFactorEnabled"))
+	http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+}
+
+// Verify the code of the logined user before changing the settings, returns
+// false if the response is written
+func (mr *MainResource) verifyTwoFactorCode(w http.ResponseWriter, r *http.Request, user *model.User) bool {
+	_, err := mr.srv.TwoFactor.Verify(user.Id, r.PostFormValue("two_factor_code"))
+	if err != nil {
+		if errors.Is(err, service.ErrTwoFactorCodeInvalid) {
+			mr.Session("one", w, r).Flash(mr.Local(r, "TwoFactorCodeInvalid"))
+			http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return false
+	}
+	return true
+}
+
+func (mr *MainResource) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	if !user.TotpEnabled {
+		http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		return
+	}
+
+	if mr.srv.Permission.RoleData.Require2FA(user.RoleFrontId) {
+		mr.Error(mr.Local(r, "TwoFactorRequiredTip"), nil, w, r, http.StatusForbidden)
+		return
+	}
+
+	if !mr.verifyTwoFactorCode(w, r, user) {
+		return
+	}
+
+	err := mr.srv.TwoFactor.Disable(user.Id)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "TwoFactorDisabled"))
+	http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+}
+
+func (mr *MainResource) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	if !user.TotpEnabled {
+		http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+		return
+	}
+
+	if !mr.verifyTwoFactorCode(w, r, user) {
+		return
+	}
+
+	codes, err := mr.srv.TwoFactor.RegenerateRecoveryCodes(user.Id)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).SetValue("new_recovery_codes", strings.Join(codes, " "))
+	http.Redirect(w, r, "/settings/account/2fa", http.StatusFound)
+}
+
+// Login waiting for the second step, nil if there is none or it's expired
+func (mr *MainResource) twoFactorLogin(w http.ResponseWriter, r *http.Request) *model.TwoFactorLogin {
+	tl, ok := mr.Session("one", w, r).GetValue("two_factor_login").(model.TwoFactorLogin)
+	if !ok || tl.Expired() {
+		return nil
+	}
+	return &tl
+}
+
+func (mr *MainResource) LoginTwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	if IsLogin(mr.sessStore, w, r) {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	if mr.twoFactorLogin(w, r) == nil {
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	mr.Render(w, r, "login_2fa", &model.PageData{
+		Title: mr.Local(r, "TwoFactorAuth"),
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/login",
+				Name: mr.Local(r, "Login"),
+			},
+			{
+				Name: mr.Local(r, "TwoFactorAuth"),
+			},
+		},
+	})
+}
+
+func (mr *MainResource) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	oneSess := mr.Session("one", w, r)
+	tl := mr.twoFactorLogin(w, r)
+	if tl == nil {
+		delete(oneSess.Raw.Values, "two_factor_login")
+		oneSess.Flash(mr.Local(r, "TwoFactorLoginExpired"))
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	err := service.CountAttempt(mr.rdb, "two_factor_login", tl.UserId, model.TwoFactorLoginMaxAttempts, model.TwoFactorLoginLifeTime)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyAttempts) {
+			delete(oneSess.Raw.Values, "two_factor_login")
+			oneSess.Flash(mr.Local(r, "TwoFactorLoginExpired"))
+			http.Redirect(w, r, "/login", http.StatusFound)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	usedRecoveryCode, err := mr.srv.TwoFactor.Verify(tl.UserId, r.PostFormValue("two_factor_code"))
+	if err != nil {
+		if errors.Is(err, service.ErrTwoFactorCodeInvalid) {
+			oneSess.Flash(mr.Local(r, "TwoFactorCodeInvalid"))
+			http.Redirect(w, r, "/login/2fa", http.StatusFound)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	err = service.ResetAttempts(mr.rdb, "two_factor_login", tl.UserId)
+	if err != nil {
+		fmt.Println("reset two-factor login attempts error:", err)
+	}
+
+	user, err := mr.store.User.Item(tl.UserId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	if !mr.saveUserInfo(w, r, user) {
+		return
+	}
+
+	if usedRecoveryCode {
+		ctx := context.WithValue(r.Context(), "recovery_code_used", true)
+		*r = *r.WithContext(ctx)
+		mr.Session("one", w, r).Flash(mr.Local(r, "RecoveryCodeUsedTip"))
+	}
+
+	mr.ToTargetUrl(w, r)
+}
+
+func (mr *MainResource) BlockUser(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	username := strings.TrimSpace(r.PostForm.Get("username"))
+	if username == "" {
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Username")), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	targetUser, err := mr.store.User.ItemWithUsername(username)
+	if err != nil {
+		if errors.Is(err, model.AppErrUserNotExist) {
+			mr.Error(mr.Local(r, "NotExist", "FieldNames", mr.Local(r, "User", "Count", 1)), err, w, r, http.StatusBadRequest)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	userBlock := &model.UserBlock{
+		UserId:       user.Id,
+		TargetUserId: targetUser.Id,
+		Type:         model.UserBlockType(r.PostForm.Get("type")),
+	}
+
+	err = userBlock.Valid()
+	if err != nil {
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = mr.store.User.Block(userBlock.UserId, userBlock.TargetUserId, userBlock.Type)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "UserBlockSaveSuccess"))
+
+	http.Redirect(w, r, "/settings/blocks", http.StatusFound)
+}
+
+func (mr *MainResource) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	targetUserId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	user := mr.GetLoginedUserData(r)
+	if user == nil {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	err = mr.store.User.Unblock(user.Id, targetUserId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "UserBlockSaveSuccess"))
+
+	http.Redirect(w, r, "/settings/blocks", http.StatusFound)
+}
+
+type MessageStatus string
+
+const (
+	MessageStatusUnread MessageStatus = "unread"
+	MessageStatusRead                 = "read"
+	MessageStatusAll                  = "all"
+)
+
+var MessageStatusMap = map[MessageStatus]bool{
+	MessageStatusUnread: true,
+	MessageStatusRead:   true,
+	MessageStatusAll:    true,
+}
+
+type MessageQueryData struct {
+	*queryData
+	Tab string
+}
+type MessagePageData struct {
+	List  []*model.Message
+	Query *MessageQueryData
+}
+
+func (mr *MainResource) MessageList(w http.ResponseWriter, r *http.Request) {
+	tab := strings.TrimSpace(r.URL.Query().Get("tab"))
+
+	page, pageSize := mr.GetPaginationData(r)
+
+	if _, ok := MessageStatusMap[MessageStatus(tab)]; !ok {
+		tab = string(MessageStatusAll)
+	}
+
+	userId := mr.GetLoginedUserId(w, r)
+
+	list, total, err := mr.store.Message.List(userId, tab, page, pageSize)
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusInternalServerError)
+		return
+	}
+
+	var messageIds []any
+	for _, item := range list {
+		item.SourceArticle.FormatDeleted()
+		messageIds = append(messageIds, item.Id)
+	}
+
+	if len(messageIds) > 0 {
+		// err = mr.store.Message.ReadMany(messageIds)
+		err = mr.store.Message.ReadAll(userId)
+		if err != nil {
+			mr.Error("", err, w, r, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	title := mr.Local(r, "List", "Name", mr.Local(r, "Message"))
+
+	mr.Render(w, r, "message", &model.PageData{
+		Title: title,
+		Data: &MessagePageData{
+			List: list,
+			Query: &MessageQueryData{
+				&queryData{
+					Total: total, Page: page, TotalPage: CeilInt(total, pageSize),
+				},
+				tab,
+			},
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Name: title,
+			},
+		},
+	})
+}
+
+func (mr *MainResource) LoginWithOAuth(w http.ResponseWriter, r *http.Request) {
+	provider, err := mr.srv.OAuth.Get(chi.URLParam(r, "provider"))
+	if err != nil {
+		mr.NotFound(w, r)
+		return
+	}
+
+	mr.redirectToProvider(w, r, provider, 0)
+}
+
+// Redirect to the consent page of the provider, the identity is linked to
+// the user of linkUserId in the callback, 0 to login
+func (mr *MainResource) redirectToProvider(w http.ResponseWriter, r *http.Request, provider oauth.Provider, linkUserId int) {
+	state, err := genCSRFToken()
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	verifier := oauth.NewVerifier()
+	authUrl, err := provider.AuthCodeURL(r.Context(), state, verifier)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	oneSess := mr.Session("one", w, r)
+	oneSess.Raw.Values["auth_provider"] = provider.Name()
+	oneSess.Raw.Values["auth_code_verifier"] = verifier
+	oneSess.Raw.Values["auth_link_user_id"] = linkUserId
+	oneSess.SetValue("auth_state", state)
+
+	http.Redirect(w, r, authUrl, http.StatusFound)
+}
+
+func (mr *MainResource) LoginAuthCallback(w http.ResponseWriter, r *http.Request) {
+	provider, err := mr.srv.OAuth.Get(chi.URLParam(r, "provider"))
+	if err != nil {
+		mr.NotFound(w, r)
+		return
+	}
+
 	state := r.URL.Query().Get("state")
 	code := r.URL.Query().Get("code")
 
-	savedState := mr.Session("one", w, r).GetStringValue("auth_state")
-	// fmt.Println("saved state: ", savedState)
-	if state == "" || state != savedState {
+	oneSess := mr.Session("one", w, r)
+	savedState := oneSess.GetStringValue("auth_state")
+	savedProvider := oneSess.GetStringValue("auth_provider")
+	verifier := oneSess.GetStringValue("auth_code_verifier")
+	linkUserId, _ := oneSess.GetValue("auth_link_user_id").(int)
+
+	// The state is used only once
+	delete(oneSess.Raw.Values, "auth_provider")
+	delete(oneSess.Raw.Values, "auth_code_verifier")
+	delete(oneSess.Raw.Values, "auth_link_user_id")
+	oneSess.SetValue("auth_state", "")
+
+	if state == "" || state != savedState || savedProvider != provider.Name() {
 		mr.Error("state is incorrect", errors.New("state is incorrect"), w, r, http.StatusBadRequest)
 		return
 	}
 
+	if authErr := r.URL.Query().Get("error"); authErr != "" {
+		mr.Error("", fmt.Errorf("%s authorization failed: %s", provider.Name(), authErr), w, r, http.StatusBadRequest)
+		return
+	}
+
 	if code == "" {
 		mr.Error("", errors.New("code is required"), w, r, http.StatusBadRequest)
 		return
 	}
 
-	// fmt.Println("google auth code: ", code)
-	// fmt.Fprintf(w, "google auth code: %s", code)
+	userInfo, err := provider.Exchange(r.Context(), code, verifier)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
 
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+	if userInfo.Email == "" {
+		mr.Error("can't get the email", errors.New("can't get the email"), w, r, http.StatusBadRequest)
+		return
 	}
 
-	payload := []byte(`{
-		"client_id":"` + config.Config.GoogleClientID + `",
-		"client_secret":"` + config.Config.GoogleClientSecret + `",
-		"code":"` + code + `",
-		"grant_type": "authorization_code",
-		"redirect_uri": "` + config.Config.GetServerURL() + `/auth_callback",
-	}`)
+	if !userInfo.EmailVerified {
+		mr.Error("email is not verified", errors.New("email is not verified"), w, r, http.StatusBadRequest)
+		return
+	}
 
-	// fmt.Println("payload: ", string(payload))
+	if linkUserId > 0 {
+		mr.linkIdentity(w, r, provider, userInfo, linkUserId)
+		return
+	}
 
-	req, err := http.NewRequest("POST", "https://oauth2.googleapis.com/token", bytes.NewBuffer(payload))
-	if err != nil {
+	userData, err := mr.store.User.ItemWithIdentity(provider.Name(), userInfo.Subject)
+	if err == nil {
+		if mr.startLogin(w, r, userData) {
+			mr.ToTargetUrl(w, r)
+		}
+		return
+	} else if !errors.Is(err, model.AppErrUserNotExist) {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	userData, err = mr.store.User.ItemWithEmail(userInfo.Email)
+	if err != nil {
+		if errors.Is(err, model.AppErrUserNotExist) {
+			mr.doRegisterWithOAuth(w, r, provider, userInfo)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
 
-	resp, err := client.Do(req)
+	identities, err := mr.store.User.ListIdentities(userData.Id)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
-	defer resp.Body.Close()
 
-	// fmt.Println("Response status: ", resp.Status)
+	for _, item := range identities {
+		// Another account of the provider is linked
+		if item.Provider == provider.Name() {
+			oneSess.Flash(mr.Local(r, "AcountExistsTip"))
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+	}
 
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-	// fmt.Println("response body: ", buf.String())
+	// Registered with the provider before the identities were recorded
+	if userData.AuthFrom == model.AuthType(provider.AuthType()) {
+		err = mr.store.User.LinkIdentity(userData.Id, provider.Name(), userInfo.Subject, userInfo.Email)
+		if err != nil {
+			mr.ServerErrorp("", err, w, r)
+			return
+		}
 
-	var tokenData GoogleTokenResponse
-	err = json.Unmarshal(buf.Bytes(), &tokenData)
-	if err != nil {
-		mr.ServerErrorp("", err, w, r)
+		if mr.startLogin(w, r, userData) {
+			mr.ToTargetUrl(w, r)
+		}
 		return
 	}
-	// fmt.Println("tokenData: ", tokenData)
 
-	if tokenData.AccessToken == "" {
-		fmt.Println("google token response body: ", buf.String())
-		mr.Error("get access token failed", errors.Join(err, errors.New("get google access token failed")), w, r, http.StatusBadRequest)
+	// The email is registered with another login method, the identity is
+	// linked after the code sent to the email is verified
+	oneSess.SetValue("identity_link", model.IdentityLink{
+		UserId:    userData.Id,
+		Provider:  provider.Name(),
+		Subject:   userInfo.Subject,
+		Email:     userData.Email,
+		StartedAt: time.Now(),
+	})
+	go mr.sendVerifyCode(userData.Email, service.VerifCodeLinkIdentity, w, r)
+
+	http.Redirect(w, r, "/login/link", http.StatusFound)
+}
+
+// Link the identity to the logined user who started linking in the settings
+func (mr *MainResource) linkIdentity(w http.ResponseWriter, r *http.Request, provider oauth.Provider, userInfo *oauth.UserInfo, linkUserId int) {
+	user := mr.GetLoginedUserData(r)
+	if user == nil || user.Id != linkUserId {
+		mr.ToLogin(w, r)
 		return
 	}
 
-	// err := mr.rdb.Set(context.Background(), "google_token", value interface{}, expiration time.Duration)
-	req, err = http.NewRequest("GET", "https://www.googleapis.com/oauth2/v3/userinfo", nil)
-	req.Header.Set("Authorization", "Bearer "+tokenData.AccessToken)
-
-	resp, err = client.Do(req)
+	oneSess := mr.Session("one", w, r)
+	err := mr.store.User.LinkIdentity(user.Id, provider.Name(), userInfo.Subject, userInfo.Email)
 	if err != nil {
-		mr.ServerErrorp("", err, w, r)
+		if errors.Is(err, model.AppErrIdentityAlreadyLinked) {
+			oneSess.Flash(mr.Local(r, "LoginMethodLinkedBefore", "Name", provider.DisplayName()))
+			http.Redirect(w, r, "/settings/account/logins", http.StatusFound)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
 		return
 	}
-	defer resp.Body.Close()
 
-	// fmt.Println("user info response status: ", resp.Status)
+	oneSess.Flash(mr.Local(r, "LoginMethodLinked", "Name", provider.DisplayName()))
+	http.Redirect(w, r, "/settings/account/logins", http.StatusFound)
+}
 
-	buf = new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-	// fmt.Println("user info response body: ", buf.String())
+func (mr *MainResource) identityLink(w http.ResponseWriter, r *http.Request) *model.IdentityLink {
+	il, ok := mr.Session("one", w, r).GetValue("identity_link").(model.IdentityLink)
+	if !ok || il.Expired() {
+		return nil
+	}
+	return &il
+}
 
-	var userInfo GoogleUserInfo
-	err = json.Unmarshal(buf.Bytes(), &userInfo)
-	if err != nil {
-		mr.ServerErrorp("", err, w, r)
+func (mr *MainResource) LoginLinkPage(w http.ResponseWriter, r *http.Request) {
+	if IsLogin(mr.sessStore, w, r) {
+		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
 
-	if userInfo.Email == "" {
-		mr.Error("can't get the email", err, w, r, http.StatusBadRequest)
+	il := mr.identityLink(w, r)
+	if il == nil {
+		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 
-	if !userInfo.EmailVerified {
-		mr.Error("email is not verified", err, w, r, http.StatusBadRequest)
+	providerName := il.Provider
+	if provider, err := mr.srv.OAuth.Get(il.Provider); err == nil {
+		providerName = provider.DisplayName()
+	}
+
+	type PageData struct {
+		Email        string
+		Provider     string
+		CodeLifeTime int
+	}
+
+	mr.Render(w, r, "login_link", &model.PageData{
+		Title: mr.Local(r, "LinkLoginMethod"),
+		Data: &PageData{
+			Email:        il.Email,
+			Provider:     providerName,
+			CodeLifeTime: int(service.DefaultCodeLifeTime.Minutes()),
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/login",
+				Name: mr.Local(r, "Login"),
+			},
+			{
+				Name: mr.Local(r, "LinkLoginMethod"),
+			},
+		},
+	})
+}
+
+func (mr *MainResource) LoginLink(w http.ResponseWriter, r *http.Request) {
+	oneSess := mr.Session("one", w, r)
+	il := mr.identityLink(w, r)
+	if il == nil {
+		delete(oneSess.Raw.Values, "identity_link")
+		oneSess.Flash(mr.Local(r, "LinkLoginMethodExpired"))
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	code := strings.TrimSpace(r.PostFormValue("code"))
+	if code == "" {
+		mr.Error("lack of code", errors.New("lack of code"), w, r, http.StatusBadRequest)
 		return
 	}
 
-	// userId, err := mr.store.User.Exists(userInfo.Email, "")
-	userData, err := mr.store.User.ItemWithEmail(userInfo.Email)
+	err := service.CountAttempt(mr.rdb, "identity_link", il.UserId, model.IdentityLinkMaxAttempts, model.IdentityLinkLifeTime)
 	if err != nil {
-		if errors.Is(err, model.AppErrUserNotExist) {
-			mr.doRegisterWithOAuth(w, r, userInfo.Email, model.AuthTypeGoogle)
+		if errors.Is(err, service.ErrTooManyAttempts) {
+			delete(oneSess.Raw.Values, "identity_link")
+			oneSess.Flash(mr.Local(r, "LinkLoginMethodExpired"))
+			http.Redirect(w, r, "/login", http.StatusFound)
 		} else {
 			mr.ServerErrorp("", err, w, r)
 		}
 		return
 	}
 
-	// fmt.Printf("user data: %#v", userData)
+	err = mr.verifyMailCode(il.Email, code, service.VerifCodeLinkIdentity, w, r)
+	if err != nil {
+		return
+	}
+
+	err = service.ResetAttempts(mr.rdb, "identity_link", il.UserId)
+	if err != nil {
+		fmt.Println("reset identity link attempts error:", err)
+	}
+
+	err = mr.store.User.LinkIdentity(il.UserId, il.Provider, il.Subject, il.Email)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+	// Session is saved on login
+	delete(oneSess.Raw.Values, "identity_link")
+
+	user, err := mr.store.User.Item(il.UserId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
 
-	if userData.AuthFrom == model.AuthTypeGoogle {
-		mr.doLoginOAuth(w, r, userInfo.Email)
+	if mr.startLogin(w, r, user) {
 		mr.ToTargetUrl(w, r)
-	} else {
-		mr.Session("one", w, r).Flash(mr.Local("AcountExistsTip"))
-		http.Redirect(w, r, "/login", http.StatusFound)
 	}
 }
 
+func genCSRFToken() (string, error) {
+	randomData := make([]byte, 1024)
+	// fmt.Println("randomData: ", randomData)
+
+	_, err := rand.Read(randomData)
+	if err != nil {
+		fmt.Println("read random data failed", err)
+		return "", err
+	}
+
+	// fmt.Println("randomData after read: ", randomData)
+
+	hash := sha256.New()
+	hash.Write(randomData)
+	hashInBytes := hash.Sum(nil)
+
+	hashString := hex.EncodeToString(hashInBytes)
+
+	return hashString, nil
+}
+
 func (mr *MainResource) RetrievePasswordPage(w http.ResponseWriter, r *http.Request) {
 	mr.Render(w, r, "retrieve_password", &model.PageData{
-		Title: mr.Local("RetrievePassword"),
+		Title: mr.Local(r, "RetrievePassword"),
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("RetrievePassword"),
+				Name: mr.Local(r, "RetrievePassword"),
 			},
 		},
 	})
@@ -1220,12 +2310,12 @@ func (mr *MainResource) RetrievePassword(w http.ResponseWriter, r *http.Request)
 	email := r.Form.Get("email")
 
 	if email == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Email")), nil, w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Email")), nil, w, r, http.StatusBadRequest)
 		return
 	}
 
 	if err := model.ValidateEmail(email); err != nil {
-		emailValidTip := mr.Local("Incorrect", "FieldNames", mr.Local("Or", "A", mr.Local("Email"), "B", mr.Local("Password")))
+		emailValidTip := mr.Local(r, "Incorrect", "FieldNames", mr.Local(r, "Or", "A", mr.Local(r, "Email"), "B", mr.Local(r, "Password")))
 		mr.Error(emailValidTip, err, w, r, http.StatusBadRequest)
 		return
 	}
@@ -1250,7 +2340,7 @@ func (mr *MainResource) ResetPasswordPage(w http.ResponseWriter, r *http.Request
 	}
 
 	if email == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Email")), nil, w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Email")), nil, w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -1260,14 +2350,14 @@ func (mr *MainResource) ResetPasswordPage(w http.ResponseWriter, r *http.Request
 	}
 
 	mr.Render(w, r, "reset_password", &model.PageData{
-		Title: mr.Local("ResetPassword"),
+		Title: mr.Local(r, "ResetPassword"),
 		Data: &PageData{
 			Email:        email,
 			CodeLifeTime: int(mr.srv.Verifier.CodeLifeTime.Minutes()),
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("ResetPassword"),
+				Name: mr.Local(r, "ResetPassword"),
 			},
 		},
 	})
@@ -1295,18 +2385,18 @@ func (mr *MainResource) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	confirmPassword := r.Form.Get("confirm-password")
 
 	if password == "" || confirmPassword == "" {
-		mr.Error(mr.Local("Required", "FieldNames", mr.Local("Password")), errors.New("password is empty"), w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Password")), errors.New("password is empty"), w, r, http.StatusBadRequest)
 		return
 	}
 
 	err = model.ValidPassword(password)
 	if err != nil {
-		mr.Error(mr.Local("FormatError", "FieldNames", mr.Local("Password")), errors.New("password format error"), w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "FormatError", "FieldNames", mr.Local(r, "Password")), errors.New("password format error"), w, r, http.StatusBadRequest)
 		return
 	}
 
 	if confirmPassword != password {
-		mr.Error(mr.Local("PasswordConfirmError"), errors.New("password confirm error"), w, r, http.StatusBadRequest)
+		mr.Error(mr.Local(r, "PasswordConfirmError"), errors.New("password confirm error"), w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -1318,157 +2408,31 @@ func (mr *MainResource) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	// fmt.Println("email: ", email)
 
-	_, err = mr.store.User.UpdatePassword(email, encryptPassword)
+	userId, err := mr.store.User.UpdatePassword(email, encryptPassword)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
 
-	mr.Session("one", w, r).SetValue("target_url", "/")
-	mr.Session("one", w, r).SetValue("email_reset_pass", "")
-	mr.Session("one", w, r).Flash(mr.Local("PassResetSuccess"))
-
-	http.Redirect(w, r, "/login", http.StatusFound)
-}
-
-type GithubTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	Scope       string `json:"scope"`
-	TokenType   string `json:"bearer"`
-}
-
-type GithubUserInfo struct {
-	LoginId   string `json:"login"`
-	AvatarUrl string `json:"avatar_url"`
-	HtmlUrl   string `json:"html_url"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-}
-
-func (mr *MainResource) LoginAuthCallbackGithub(w http.ResponseWriter, r *http.Request) {
-	state := r.URL.Query().Get("state")
-	code := r.URL.Query().Get("code")
-
-	savedState := mr.Session("one", w, r).GetStringValue("auth_state")
-	// fmt.Println("saved state: ", savedState)
-	if state == "" || state != savedState {
-		mr.Error("state is incorrect", errors.New("state is incorrect"), w, r, http.StatusBadRequest)
-		return
-	}
-
-	if code == "" {
-		mr.Error("", errors.New("code is required"), w, r, http.StatusBadRequest)
-		return
-	}
-
-	// fmt.Println("github auth code: ", code)
-	// fmt.Fprintf(w, "github auth code: %s", code)
-
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	payload := []byte(`{
-		"client_id":"` + config.Config.GithubClientID + `",
-		"client_secret":"` + config.Config.GithubClientSecret + `",
-		"code":"` + code + `",
-                "redirect_uri": "` + config.Config.GetServerURL() + `/auth_callback_github"
-	}`)
-
-	// fmt.Println("payload: ", string(payload))
-
-	req, err := http.NewRequest("POST", "https://github.com/login/oauth/access_token", bytes.NewBuffer(payload))
-	if err != nil {
-		mr.ServerErrorp("", err, w, r)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		mr.ServerErrorp("", err, w, r)
-		return
-	}
-	defer resp.Body.Close()
-
-	// fmt.Println("github token response status: ", resp.Status)
-
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-
-	// fmt.Println("github token response body: ", buf.String())
-
-	// fmt.Fprint(w, buf.String())
-
-	var tokenData GithubTokenResponse
-	err = json.Unmarshal(buf.Bytes(), &tokenData)
-	if err != nil {
-		mr.ServerErrorp("", err, w, r)
-		return
-	}
-
-	if tokenData.AccessToken == "" {
-		fmt.Println("github token response body: ", buf.String())
-		mr.Error("get access token failed", errors.Join(err, errors.New("get github access token failed")), w, r, http.StatusBadRequest)
-		return
-	}
-	// fmt.Println("tokenData: ", tokenData)
-
-	req, err = http.NewRequest("GET", "https://api.github.com/user", nil)
-	req.Header.Set("Authorization", "Bearer "+tokenData.AccessToken)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-	resp, err = client.Do(req)
+	// Sessions and API tokens created with the old password are no longer
+	// trusted
+	err = mr.sessStore.RevokeAll(userId, "")
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
-	defer resp.Body.Close()
-
-	// fmt.Println("user info response status: ", resp.Status)
 
-	buf = new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-
-	// fmt.Println("user info response body: ", buf.String())
-	// fmt.Fprint(w, buf.String())
-
-	var userInfo GithubUserInfo
-	err = json.Unmarshal(buf.Bytes(), &userInfo)
+	err = mr.store.ApiToken.DeleteAll(userId)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
 	}
 
-	// fmt.Printf("response user info: %#v", userInfo)
-
-	if userInfo.Email == "" {
-		mr.Error("can't get the email, this may cause by your github privacy setting", err, w, r, http.StatusBadRequest)
-		return
-	}
-
-	userData, err := mr.store.User.ItemWithEmail(userInfo.Email)
-	if err != nil {
-		if errors.Is(err, model.AppErrUserNotExist) {
-			mr.doRegisterWithOAuth(w, r, userInfo.Email, model.AuthTypeGithub)
-		} else {
-			mr.ServerErrorp("", err, w, r)
-		}
-		return
-	}
-
-	// fmt.Printf("user data: %#v", userData)
+	mr.Session("one", w, r).SetValue("target_url", "/")
+	mr.Session("one", w, r).SetValue("email_reset_pass", "")
+	mr.Session("one", w, r).Flash(mr.Local(r, "PassResetSuccess"))
 
-	if userData.AuthFrom == model.AuthTypeGithub {
-		mr.doLoginOAuth(w, r, userInfo.Email)
-		mr.ToTargetUrl(w, r)
-	} else {
-		mr.Session("one", w, r).Flash(mr.Local("AcountExistsTip"))
-		http.Redirect(w, r, "/login", http.StatusFound)
-	}
+	http.Redirect(w, r, "/login", http.StatusFound)
 }
 
 func (mr *MainResource) CategoryArticleList(w http.ResponseWriter, r *http.Request) {
@@ -1496,19 +2460,38 @@ func (mr *MainResource) CategorySubscribe(w http.ResponseWriter, r *http.Request
 	http.Redirect(w, r, referer, http.StatusFound)
 }
 
+func (mr *MainResource) CategoryIgnore(w http.ResponseWriter, r *http.Request) {
+	categoryFrontId := chi.URLParam(r, "categoryFrontId")
+
+	userId := mr.GetLoginedUserId(w, r)
+	if userId == 0 {
+		mr.ToLogin(w, r)
+		return
+	}
+
+	err := mr.store.Category.Ignore(categoryFrontId, userId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	referer := r.Referer()
+	http.Redirect(w, r, referer, http.StatusFound)
+}
+
 func (mr *MainResource) About(w http.ResponseWriter, r *http.Request) {
 	mr.Render(w, r, "about", &model.PageData{
-		Title: mr.Local("About"),
+		Title: mr.Local(r, "About"),
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("About"),
+				Name: mr.Local(r, "About"),
 			},
 		},
 	})
 }
 
 func (mr *MainResource) CategoryList(w http.ResponseWriter, r *http.Request) {
-	categoryList, err := mr.store.Category.List(model.CategoryStateAll)
+	categoryList, err := mr.store.Category.List(model.CategoryStateApproved)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
@@ -1519,24 +2502,94 @@ func (mr *MainResource) CategoryList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mr.Render(w, r, "category-list", &model.PageData{
-		Title: mr.Local("Category", "Count", 2),
+		Title: mr.Local(r, "Category", "Count", 2),
 		Data: &pageData{
 			CategoryList: categoryList,
 		},
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("Category", "Count", 2),
+				Name: mr.Local(r, "Category", "Count", 2),
+			},
+		},
+	})
+}
+
+func (mr *MainResource) CategoryProposePage(w http.ResponseWriter, r *http.Request) {
+	type pageData struct {
+		Category       *model.Category
+		MaxFrontIdLen  int
+		MaxNameLen     int
+		MaxDescribeLen int
+	}
+
+	mr.Render(w, r, "category_form", &model.PageData{
+		Title: mr.Local(r, "ProposeCategory"),
+		Data: &pageData{
+			Category:       &model.Category{},
+			MaxFrontIdLen:  model.MaxCategoryFrontIdLen,
+			MaxNameLen:     model.MaxCategoryNameLen,
+			MaxDescribeLen: model.MaxCategoryDescribeLen,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/categories",
+				Name: mr.Local(r, "Category", "Count", 2),
+			},
+			{
+				Name: mr.Local(r, "ProposeCategory"),
 			},
 		},
 	})
 }
 
+// CategoryPropose creates an unapproved category, which will be listed after
+// reviewed by moderators
+func (mr *MainResource) CategoryPropose(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	category := &model.Category{
+		FrontId:  r.Form.Get("front_id"),
+		Name:     r.Form.Get("name"),
+		Describe: r.Form.Get("describe"),
+	}
+
+	category.TrimSpace()
+
+	err = category.Valid()
+	if err != nil {
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	category.Sanitize()
+
+	_, err = mr.store.Category.Create(category.FrontId, category.Name, category.Describe, mr.GetLoginedUserId(w, r))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			mr.Error(mr.Local(r, "AlreadyExists", "FieldNames", mr.Local(r, "CategoryFrontId")), err, w, r, http.StatusBadRequest)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "category_front_id", category.FrontId)
+	*r = *r.WithContext(ctx)
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "CategoryProposeSuccess"))
+	http.Redirect(w, r, "/categories", http.StatusFound)
+}
+
 func (mr *MainResource) Guide(w http.ResponseWriter, r *http.Request) {
 	mr.Render(w, r, "guide", &model.PageData{
-		Title: mr.Local("Guide"),
+		Title: mr.Local(r, "Guide"),
 		BreadCrumbs: []*model.BreadCrumb{
 			{
-				Name: mr.Local("Guide"),
+				Name: mr.Local(r, "Guide"),
 			},
 		},
 	})
@@ -1,7 +1,9 @@
 package web
 
 import (
+	"expvar"
 	"fmt"
+	"html"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	mdw "github.com/oodzchen/dproject/middleware"
 	"github.com/oodzchen/dproject/model"
@@ -32,6 +35,28 @@ func NewManageResource(renderer *Renderer, ur *UserResource) *ManageResource {
 func (mr *ManageResource) Routes() http.Handler {
 	rt := chi.NewRouter()
 
+	// Moderators review proposed categories here without access to other manage pages
+	rt.With(mdw.AuthCheck(mr.sessStore), mdw.PermitCheck(mr.srv.Permission, []string{
+		"category.approve",
+	}, mr)).Route("/categories", func(r chi.Router) {
+		r.Get("/", mr.CategoryReviewPage)
+		r.With(mdw.UserLogger(
+			mr.uLogger, model.AcTypeManage, model.AcActionApproveCategory, model.AcModelCategory, mdw.ULogURLCategoryFrontId),
+		).Post("/{categoryFrontId}/approve", mr.CategoryApprove)
+		r.With(mdw.UserLogger(
+			mr.uLogger, model.AcTypeManage, model.AcActionRejectCategory, model.AcModelCategory, mdw.ULogURLCategoryFrontId),
+		).Post("/{categoryFrontId}/reject", mr.CategoryReject)
+	})
+
+	// Reports are resolved by moderators of the reported categories too, so the
+	// permission is checked in handlers
+	rt.With(mdw.AuthCheck(mr.sessStore)).Route("/reports", func(r chi.Router) {
+		r.Get("/", mr.ReportListPage)
+		r.With(mdw.UserLogger(
+			mr.uLogger, model.AcTypeManage, model.AcActionResolveReport, model.AcModelArticle, mdw.ULogURLArticleId),
+		).Post("/{articleId}/resolve", mr.ReportResolve)
+	})
+
 	rt.With(mdw.AuthCheck(mr.sessStore), mdw.PermitCheck(mr.srv.Permission, []string{
 		"manage.access",
 	}, mr)).Route("/", func(r chi.Router) {
@@ -85,6 +110,24 @@ func (mr *ManageResource) Routes() http.Handler {
 
 		r.Get("/trash", mr.TrashPage)
 
+		// Runtime stats published by expvar, such as hit counts of the data cache
+		r.Handle("/vars", expvar.Handler())
+
+		r.Route("/moderators", func(r chi.Router) {
+			r.Get("/", mr.ModeratorListPage)
+
+			r.With(mdw.PermitCheck(mr.srv.Permission, []string{
+				"user.set_moderator",
+			}, mr)).Group(func(r chi.Router) {
+				r.With(mdw.UserLogger(
+					mr.uLogger, model.AcTypeManage, model.AcActionAddCategoryModerator, model.AcModelCategory, mdw.ULogURLCategoryFrontId),
+				).Post("/{categoryFrontId}", mr.ModeratorAdd)
+				r.With(mdw.UserLogger(
+					mr.uLogger, model.AcTypeManage, model.AcActionRemoveCategoryModerator, model.AcModelCategory, mdw.ULogURLCategoryFrontId),
+				).Post("/{categoryFrontId}/{userId}/remove", mr.ModeratorRemove)
+			})
+		})
+
 		rootDir, _ := os.Getwd()
 		manageStaticPath := filepath.Join(rootDir, "/manage_static")
 		fmt.Println("manage static path:", manageStaticPath)
@@ -173,7 +216,7 @@ func (mr *ManageResource) handlePermissionList(w http.ResponseWriter, r *http.Re
 		CurrTab       string
 	}
 
-	title := mr.Local("List", "Name", mr.Local("Permission", "Count", 1))
+	title := mr.Local(r, "List", "Name", mr.Local(r, "Permission", "Count", 1))
 	breadCrumbs := []*model.BreadCrumb{
 		{
 			Path: "/manage/permissions",
@@ -182,7 +225,7 @@ func (mr *ManageResource) handlePermissionList(w http.ResponseWriter, r *http.Re
 	}
 
 	if pageType == PermissionPageCreate {
-		title = mr.Local("AddItem", "Name", mr.Local("Permission", "Count", 1))
+		title = mr.Local(r, "AddItem", "Name", mr.Local(r, "Permission", "Count", 1))
 		breadCrumbs = append(breadCrumbs, &model.BreadCrumb{
 			Path: "",
 			Name: title,
@@ -232,7 +275,7 @@ func (mr *ManageResource) PermissionSubmit(w http.ResponseWriter, r *http.Reques
 
 	err := permission.Valid()
 	if err != nil {
-		mr.Error(err.Error(), err, w, r, http.StatusBadRequest)
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -289,7 +332,7 @@ func (mr *ManageResource) RoleListPage(w http.ResponseWriter, r *http.Request) {
 		PageSize  int
 	}
 
-	title := mr.Local("List", "Name", mr.Local("Role", "Count", 1))
+	title := mr.Local(r, "List", "Name", mr.Local(r, "Role", "Count", 1))
 	breadCrumbs := []*model.BreadCrumb{
 		{
 			Path: "/manage/roles",
@@ -341,8 +384,8 @@ func (mr *ManageResource) RoleCreatePage(w http.ResponseWriter, r *http.Request)
 
 	formattedPermissionList := formatPermissionList(filteredPermissionList, mr.srv.Permission.PermissionData.GetModuleList())
 
-	upLevelTitle := mr.Local("List", "Name", mr.Local("Role", "Count", 1))
-	title := mr.Local("AddItem", "Name", mr.Local("Role", "Count", 1))
+	upLevelTitle := mr.Local(r, "List", "Name", mr.Local(r, "Role", "Count", 1))
+	title := mr.Local(r, "AddItem", "Name", mr.Local(r, "Role", "Count", 1))
 	breadCrumbs := []*model.BreadCrumb{
 		{
 			Path: "/manage/roles",
@@ -412,7 +455,7 @@ func (mr *ManageResource) RoleSubmit(w http.ResponseWriter, r *http.Request) {
 
 	err := role.Valid(false)
 	if err != nil {
-		mr.Error(err.Error(), err, w, r, http.StatusBadRequest)
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -516,8 +559,8 @@ func (mr *ManageResource) RoleEditPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	upLevelTitle := mr.Local("List", "Name", mr.Local("Role", "Count", 1))
-	title := mr.Local("EditItem", "Name", mr.Local("Role", "Count", 1))
+	upLevelTitle := mr.Local(r, "List", "Name", mr.Local(r, "Role", "Count", 1))
+	title := mr.Local(r, "EditItem", "Name", mr.Local(r, "Role", "Count", 1))
 	breadCrumbs := []*model.BreadCrumb{
 		{
 			Path: "/manage/roles",
@@ -579,7 +622,7 @@ func (mr *ManageResource) RoleEditSubmit(w http.ResponseWriter, r *http.Request)
 
 	err = role.Valid(true)
 	if err != nil {
-		mr.Error(err.Error(), err, w, r, http.StatusBadRequest)
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -651,7 +694,7 @@ func (mr *ManageResource) ActivityList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, item := range list {
-		item.Format(mr.i18nCustom)
+		item.Format(mr.Localizer(r))
 	}
 
 	type QueryData struct {
@@ -671,15 +714,15 @@ func (mr *ManageResource) ActivityList(w http.ResponseWriter, r *http.Request) {
 	for _, item := range model.AcTypeValues() {
 		acTypeStrEnums = append(acTypeStrEnums, item)
 	}
-	acTypeOptons := model.ConvertEnumToOPtions(acTypeStrEnums, true, "AcType", mr.i18nCustom)
+	acTypeOptons := model.ConvertEnumToOPtions(acTypeStrEnums, true, "AcType", mr.Localizer(r))
 
 	var acActionStrEnums []model.StringEnum
 	for _, item := range model.AcActionValues() {
 		acActionStrEnums = append(acActionStrEnums, item)
 	}
-	acActionOptons := model.ConvertEnumToOPtions(acActionStrEnums, true, "AcAction", mr.i18nCustom)
+	acActionOptons := model.ConvertEnumToOPtions(acActionStrEnums, true, "AcAction", mr.Localizer(r))
 
-	title := mr.Local("List", "Name", mr.Local("Activity", "Count", 1))
+	title := mr.Local(r, "List", "Name", mr.Local(r, "Activity", "Count", 1))
 	breadCrumbs := []*model.BreadCrumb{
 		{
 			Path: "/manage/activities",
@@ -729,7 +772,7 @@ func (mr *ManageResource) TrashPage(w http.ResponseWriter, r *http.Request) {
 		sortType = model.ListSortLatest
 	}
 
-	deletedList, total, err := mr.store.Article.List(page, pageSize, sortType, categoryFrontId, false, true, true, keywords)
+	deletedList, total, err := mr.store.Article.List(page, pageSize, sortType, categoryFrontId, "", 0, false, true, true, keywords)
 	if err != nil {
 		mr.ServerErrorp("", err, w, r)
 		return
@@ -780,7 +823,7 @@ func (mr *ManageResource) TrashPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mr.Render(w, r, "trash", &model.PageData{
-		Title: mr.Local("Trash"),
+		Title: mr.Local(r, "Trash"),
 		Data: &pageData{
 			CurrPage:        page,
 			PageSize:        pageSize,
@@ -796,8 +839,382 @@ func (mr *ManageResource) TrashPage(w http.ResponseWriter, r *http.Request) {
 		BreadCrumbs: []*model.BreadCrumb{
 			{
 				Path: "/manage/trash",
-				Name: mr.Local("Trash"),
+				Name: mr.Local(r, "Trash"),
+			},
+		},
+	})
+}
+
+func (mr *ManageResource) CategoryReviewPage(w http.ResponseWriter, r *http.Request) {
+	tab := r.URL.Query().Get("tab")
+	state := model.CategoryState(model.CategoryStateUnapproved)
+	if tab == model.CategoryStateRejected {
+		state = model.CategoryStateRejected
+	}
+
+	list, err := mr.store.Category.List(state)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	type pageData struct {
+		List          []*model.Category
+		Tab           string
+		MaxCommentLen int
+	}
+
+	mr.Render(w, r, "category_review", &model.PageData{
+		Title: mr.Local(r, "CategoryReview"),
+		Data: &pageData{
+			List:          list,
+			Tab:           string(state),
+			MaxCommentLen: model.MaxCategoryApprovalComment,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/manage/categories",
+				Name: mr.Local(r, "CategoryReview"),
+			},
+		},
+	})
+}
+
+func (mr *ManageResource) CategoryApprove(w http.ResponseWriter, r *http.Request) {
+	mr.reviewCategory(true, w, r)
+}
+
+func (mr *ManageResource) CategoryReject(w http.ResponseWriter, r *http.Request) {
+	mr.reviewCategory(false, w, r)
+}
+
+// Approve or reject the proposed category, then tell the proposer the result
+// with a system message
+func (mr *ManageResource) reviewCategory(pass bool, w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	comment := strings.TrimSpace(r.Form.Get("comment"))
+	err = model.ValidApprovalComment(comment)
+	if err != nil {
+		mr.Error(mr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+	comment = html.EscapeString(comment)
+
+	category, err := mr.store.Category.Item(chi.URLParam(r, "categoryFrontId"), 0)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			mr.NotFound(w, r)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	// Approved categories may already have articles, so they can't be reviewed again
+	if category.Approved {
+		mr.Error(mr.Local(r, "CategoryAlreadyApproved"), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	reviewerId := mr.GetLoginedUserId(w, r)
+	err = mr.store.Category.Approval(category.FrontId, pass, comment, reviewerId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	action := model.MessageAction(model.MessageActionCategoryRejected)
+	if pass {
+		action = model.MessageActionCategoryApproved
+	}
+
+	authorId, _ := strconv.Atoi(category.AuthorId)
+	_, err = mr.store.Message.CreateSystem(reviewerId, authorId, action, comment, 0, category.Id)
+	if err != nil {
+		fmt.Println("send category review message error: ", err)
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "CategoryReviewSuccess"))
+	http.Redirect(w, r, "/manage/categories", http.StatusFound)
+}
+
+// ModeratorListPage shows moderators of every category
+func (mr *ManageResource) ModeratorListPage(w http.ResponseWriter, r *http.Request) {
+	categoryList, err := mr.store.Category.List(model.CategoryStateApproved)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	moderatorList, err := mr.store.Category.ListModerators()
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	moderatorMap := make(map[string][]*model.CategoryModerator)
+	for _, item := range moderatorList {
+		moderatorMap[item.CategoryFrontId] = append(moderatorMap[item.CategoryFrontId], item)
+	}
+
+	for _, item := range categoryList {
+		item.Moderators = moderatorMap[item.FrontId]
+	}
+
+	type pageData struct {
+		CategoryList []*model.Category
+	}
+
+	mr.Render(w, r, "moderator_list", &model.PageData{
+		Title: mr.Local(r, "CategoryModerator", "Count", 2),
+		Data: &pageData{
+			CategoryList: categoryList,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/manage/moderators",
+				Name: mr.Local(r, "CategoryModerator", "Count", 2),
 			},
 		},
 	})
 }
+
+func (mr *ManageResource) ModeratorAdd(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	username := strings.TrimSpace(r.Form.Get("username"))
+	if username == "" {
+		mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "Username")), nil, w, r, http.StatusBadRequest)
+		return
+	}
+
+	user, err := mr.store.User.ItemWithUsername(username)
+	if err != nil {
+		if errors.Is(err, model.AppErrUserNotExist) {
+			mr.Error(mr.Local(r, "NotExist", "FieldNames", mr.Local(r, "User", "Count", 1)), err, w, r, http.StatusBadRequest)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	err = mr.store.Category.AddModerator(chi.URLParam(r, "categoryFrontId"), user.Id)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "ModeratorSaveSuccess"))
+	http.Redirect(w, r, "/manage/moderators", http.StatusFound)
+}
+
+func (mr *ManageResource) ModeratorRemove(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = mr.store.Category.RemoveModerator(chi.URLParam(r, "categoryFrontId"), userId)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "ModeratorSaveSuccess"))
+	http.Redirect(w, r, "/manage/moderators", http.StatusFound)
+}
+
+// Categories of which the reports can be viewed by current user, nil means all
+// categories
+func (mr *ManageResource) reportCategories(r *http.Request) ([]string, bool) {
+	if mr.CheckPermit(r, "article", "resolve_report") {
+		return nil, true
+	}
+
+	currUser := mr.GetLoginedUserData(r)
+	if currUser == nil || len(currUser.ModeratedCategories) == 0 {
+		return nil, false
+	}
+
+	return currUser.ModeratedCategories, true
+}
+
+func (mr *ManageResource) ReportListPage(w http.ResponseWriter, r *http.Request) {
+	categoryFrontIds, ok := mr.reportCategories(r)
+	if !ok {
+		mr.Forbidden(errors.New("no permission"), w, r)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < DefaultPage {
+		page = DefaultPage
+	}
+
+	tab := r.URL.Query().Get("tab")
+	resolved := tab == "resolved"
+	if !resolved {
+		tab = "pending"
+	}
+
+	list, total, err := mr.store.Report.List(resolved, categoryFrontIds, page, DefaultPageSize)
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	type pageData struct {
+		List                []*model.ArticleReport
+		Tab                 string
+		ReportReasons       []model.ReportReason
+		ReportResolutions   []model.ReportResolution
+		CurrPage, TotalPage int
+	}
+
+	mr.Render(w, r, "report_list", &model.PageData{
+		Title: mr.Local(r, "ReportQueue"),
+		Data: &pageData{
+			List:              list,
+			Tab:               tab,
+			ReportReasons:     model.ReportReasons,
+			ReportResolutions: model.ReportResolutions,
+			CurrPage:          page,
+			TotalPage:         CeilInt(total, DefaultPageSize),
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/manage/reports",
+				Name: mr.Local(r, "ReportQueue"),
+			},
+		},
+	})
+}
+
+// ReportResolve applies the resolution to the reported article, then marks
+// all pending reports of it as resolved
+func (mr *ManageResource) ReportResolve(w http.ResponseWriter, r *http.Request) {
+	articleId, err := strconv.Atoi(chi.URLParam(r, "articleId"))
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		mr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	resolution := r.Form.Get("resolution")
+	if !model.ValidReportResolution(resolution) {
+		mr.Error("", errors.New("invalid resolution"), w, r, http.StatusBadRequest)
+		return
+	}
+
+	article, err := mr.store.Article.Item(articleId, 0)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			mr.NotFound(w, r)
+		} else {
+			mr.ServerErrorp("", err, w, r)
+		}
+		return
+	}
+
+	permitted := func(module, action string) bool {
+		if mr.CheckCategoryPermit(r, article.CategoryFrontId, module, action) {
+			return true
+		}
+		mr.Forbidden(errors.New("no permission"), w, r)
+		return false
+	}
+
+	if !permitted("article", "resolve_report") {
+		return
+	}
+
+	switch model.ReportResolution(resolution) {
+	case model.ReportResolutionDelete:
+		if !permitted("article", "delete_others") {
+			return
+		}
+
+		if !article.Deleted {
+			_, err = mr.store.Article.Delete(articleId)
+		}
+	case model.ReportResolutionLock:
+		if !permitted("article", "lock") {
+			return
+		}
+
+		if !article.Locked {
+			err = mr.store.Article.ToggleLock(articleId)
+		}
+	case model.ReportResolutionFadeOut:
+		if !permitted("article", "fade_out") {
+			return
+		}
+
+		if !article.FadeOut {
+			_, err = mr.store.Article.ToggleFadeOut(articleId)
+			if err == nil {
+				go func() {
+					err := mr.store.User.AddReputation(article.AuthorName, model.RPCTypeFadeOut, false)
+					if err != nil {
+						fmt.Println("add reputation error", err)
+					}
+				}()
+			}
+		}
+	case model.ReportResolutionBan:
+		// Banning affects the whole site, so it's not scoped to category
+		if !mr.CheckPermit(r, "user", "ban") {
+			mr.Forbidden(errors.New("no permission"), w, r)
+			return
+		}
+
+		dayNum, convErr := strconv.Atoi(r.Form.Get("banned_days"))
+		if convErr != nil || dayNum == 0 {
+			mr.Error(mr.Local(r, "Required", "FieldNames", mr.Local(r, "BannedDuration")), convErr, w, r, http.StatusBadRequest)
+			return
+		}
+
+		var userId int
+		userId, err = mr.store.User.Ban(article.AuthorName, dayNum)
+		if err == nil {
+			err = mr.sessStore.RevokeAll(userId, "")
+		}
+		if err == nil {
+			go func() {
+				err := mr.store.User.AddReputation(article.AuthorName, model.RPCTypeBanned, false)
+				if err != nil {
+					fmt.Println("add reputation error", err)
+				}
+			}()
+		}
+	}
+
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	_, err = mr.store.Report.Resolve(articleId, mr.GetLoginedUserId(w, r), model.ReportResolution(resolution))
+	if err != nil {
+		mr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	mr.Session("one", w, r).Flash(mr.Local(r, "ReportResolveSuccess"))
+	http.Redirect(w, r, "/manage/reports", http.StatusFound)
+}
@@ -28,7 +28,7 @@ import (
 
 type Renderer struct {
 	tmpl           *template.Template
-	sessStore      *sessions.CookieStore
+	sessStore      *service.SessionStore
 	router         *chi.Mux
 	store          *store.Store
 	uLogger        *service.UserLogger
@@ -41,7 +41,7 @@ type Renderer struct {
 
 func NewRenderer(
 	tmpl *template.Template,
-	sessStore *sessions.CookieStore,
+	sessStore *service.SessionStore,
 	router *chi.Mux,
 	store *store.Store,
 	sp *bluemonday.Policy,
@@ -88,6 +88,10 @@ func (rd *Renderer) Forbidden(err error, w http.ResponseWriter, r *http.Request)
 	rd.Error("", err, w, r, http.StatusForbidden)
 }
 
+func (rd *Renderer) Unauthorized(err error, w http.ResponseWriter, r *http.Request) {
+	rd.Error("", err, w, r, http.StatusUnauthorized)
+}
+
 func (rd *Renderer) GetLoginedUserId(w http.ResponseWriter, r *http.Request) int {
 	userId := rd.Session("one", w, r).GetValue("user_id")
 	if userId, ok := userId.(int); ok {
@@ -107,6 +111,12 @@ func (rd *Renderer) CheckPermit(r *http.Request, module, action string) bool {
 	return rd.srv.Permission.Permit(rd.GetLoginedUserData(r), module, action)
 }
 
+// Check permission on articles under the category, which is also granted to
+// the moderators of the category
+func (rd *Renderer) CheckCategoryPermit(r *http.Request, categoryFrontId, module, action string) bool {
+	return rd.srv.Permission.PermitCategory(rd.GetLoginedUserData(r), categoryFrontId, module, action)
+}
+
 func (rd *Renderer) Error(msg string, err error, w http.ResponseWriter, r *http.Request, code int) {
 	fmt.Printf("render err: %+v\n", err)
 	fmt.Println("msg: ", msg)
@@ -181,6 +191,7 @@ func (rd *Renderer) doRender(w http.ResponseWriter, r *http.Request, name string
 	// fmt.Println("currLang: ", rd.i18nCustom.CurrLang)
 
 	data.CSRFField = string(csrf.TemplateField(r))
+	data.CSRFToken = csrf.Token(r)
 	data.RoutePath = r.URL.Path
 	data.Debug = config.Config.Debug
 	data.BrandDomainName = config.Config.BrandDomainName
@@ -207,15 +218,30 @@ func (rd *Renderer) doRender(w http.ResponseWriter, r *http.Request, name string
 
 	// fmt.Println("start time before render: ", startTime)
 
-	rd.tmpl = rd.tmpl.Funcs(template.FuncMap{
+	// Functions bound to current request are added to a copy of templates, to
+	// avoid affecting other requests
+	tmpl, err := rd.tmpl.Clone()
+	if err != nil {
+		HttpError("", errors.WithStack(err), w, http.StatusInternalServerError)
+		return
+	}
+
+	ic := rd.Localizer(r)
+	tmpl = tmpl.Funcs(template.FuncMap{
 		"permit": func(module, action string) bool {
 			return rd.srv.Permission.Permit(data.LoginedUser, module, action)
 		},
-		"local":   rd.i18nCustom.LocalTpl,
-		"timeAgo": rd.i18nCustom.TimeAgo.Format,
+		"permitCategory": func(categoryFrontId, module, action string) bool {
+			return rd.srv.Permission.PermitCategory(data.LoginedUser, categoryFrontId, module, action)
+		},
+		"local":   ic.LocalTpl,
+		"timeAgo": ic.TimeAgo.Format,
+		"displayTitle": func(article *model.Article) string {
+			return article.DisplayTitle(ic)
+		},
 	})
 
-	data.BrandName = rd.Local("BrandName")
+	data.BrandName = ic.LocalTpl("BrandName")
 	if data.Title != "" {
 		data.Title += fmt.Sprintf(" - %s", data.BrandName)
 	} else {
@@ -264,7 +290,7 @@ func (rd *Renderer) doRender(w http.ResponseWriter, r *http.Request, name string
 	data.RespStart = startTime
 	data.RenderStart = time.Now()
 
-	err = rd.tmpl.ExecuteTemplate(w, name, data)
+	err = tmpl.ExecuteTemplate(w, name, data)
 	if err != nil {
 		HttpError("", errors.WithStack(err), w, http.StatusInternalServerError)
 	}
@@ -424,8 +450,22 @@ func (rd *Renderer) ToPrevPage(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
-func (rd *Renderer) Local(id string, data ...any) string {
-	return rd.i18nCustom.LocalTpl(id, data...)
+// Localizer returns the localizer of the request language, the default one is
+// returned if the request has none
+func (rd *Renderer) Localizer(r *http.Request) *i18nc.I18nCustom {
+	if ic, ok := r.Context().Value("i18n_custom").(*i18nc.I18nCustom); ok {
+		return ic
+	}
+	return rd.i18nCustom
+}
+
+func (rd *Renderer) Local(r *http.Request, id string, data ...any) string {
+	return rd.Localizer(r).LocalTpl(id, data...)
+}
+
+// Message of err in the language of request
+func (rd *Renderer) LocalError(r *http.Request, err error) string {
+	return model.LocalizeError(err, rd.Localizer(r))
 }
 
 func (rd *Renderer) GetPaginationData(r *http.Request) (int, int) {
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/oodzchen/dproject/config"
+	i18nc "github.com/oodzchen/dproject/i18n"
+	"github.com/oodzchen/dproject/model"
+	"github.com/oodzchen/dproject/service"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRenderLocalTitle(t *testing.T) {
+	if config.Config == nil {
+		config.Config = &config.AppConfig{}
+	}
+
+	ic := i18nc.New([]string{"../i18n/active.ja.toml"})
+	tmpl := template.Must(template.New("base").Funcs(template.FuncMap{
+		"local": ic.LocalTpl,
+	}).Parse(`{{define "page"}}{{.Title}}{{end}}`))
+
+	// Sessions fail to save without redis, which is logged only
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	srv := &service.Service{
+		Permission: &service.Permission{PermissionData: &config.PermissionData{}},
+	}
+	rd := NewRenderer(tmpl, service.NewSessionStore(rdb, []byte("test-session-key")), nil, nil, nil, ic, srv, rdb, nil)
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", ic.WithLang("en").LocalTpl("Login")},
+		{"ja", ic.WithLang("ja").LocalTpl("Login")},
+	}
+
+	if tests[0].want == tests[1].want {
+		t.Fatal("translation of ja is not loaded")
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "i18n_custom", ic.WithLang(tt.lang)))
+		rec := httptest.NewRecorder()
+
+		rd.Render(rec, req, "page", &model.PageData{
+			Title: rd.Local(req, "Login"),
+		})
+
+		if !strings.HasPrefix(rec.Body.String(), tt.want+" - ") {
+			t.Errorf("%s: want title starts with %q, got %q", tt.lang, tt.want, rec.Body.String())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/feeds"
+	"github.com/jackc/pgx/v5"
 	"github.com/oodzchen/dproject/config"
 	"github.com/oodzchen/dproject/model"
 	"github.com/oodzchen/dproject/utils"
@@ -49,8 +51,24 @@ func (rr *RSSResource) Atom(w http.ResponseWriter, r *http.Request) {
 		sortType = defaultSort
 	}
 
+	var tag *model.Tag
+	tagFrontId := r.URL.Query().Get("tag")
+	if tagFrontId != "" {
+		var err error
+		tag, err = rr.store.Tag.Item(tagFrontId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				rr.NotFound(w, r)
+				return
+			}
+			rr.ServerErrorp("", err, w, r)
+			return
+		}
+	}
+
 	wg.Add(1)
-	go rr.articleResource.getArticleList(&wg, 1, DefaultPageSize, sortType, "", 0, time.Now(), ch, false)
+	// Ignored categories are excluded from the feed of logined user
+	go rr.articleResource.getArticleList(&wg, 1, DefaultPageSize, sortType, "", tagFrontId, rr.GetLoginedUserId(w, r), time.Now(), ch, false)
 
 	go func() {
 		wg.Wait()
@@ -70,17 +88,27 @@ func (rr *RSSResource) Atom(w http.ResponseWriter, r *http.Request) {
 
 	serverUrl := config.Config.GetServerURL()
 	feed := &feeds.Feed{
-		Title:   rr.Local("BrandName") + " - " + model.GetSortTypeNames(rr.i18nCustom)[sortType],
+		Title:   rr.Local(r, "BrandName") + " - " + model.GetSortTypeNames(rr.Localizer(r))[sortType],
 		Link:    &feeds.Link{Href: fmt.Sprintf("%s/articles?sort=%s", serverUrl, sortType)},
 		Created: time.Now(),
 	}
 
+	if tag != nil {
+		feed.Title = rr.Local(r, "BrandName") + " - " + html.UnescapeString(tag.Name) + " - " + model.GetSortTypeNames(rr.Localizer(r))[sortType]
+		feed.Link = &feeds.Link{Href: fmt.Sprintf("%s/tags/%s?sort=%s", serverUrl, tag.FrontId, sortType)}
+	}
+
 	for _, item := range list {
 		item.GenSummary(100)
 
 		var sourceHTML string
 		if item.Link != "" {
-			sourceHTML = "<p>" + rr.Local("Source") + ": " + fmt.Sprintf("<a href=\"%s\">%s</a></p>", item.Link, item.Link)
+			sourceHTML = "<p>" + rr.Local(r, "Source") + ": " + fmt.Sprintf("<a href=\"%s\">%s</a></p>", item.Link, item.Link)
+		}
+
+		contentHTML := utils.NewLine2BR(utils.ReplaceLink(item.Content))
+		if item.ContentFormat == model.ContentFormatMarkdown {
+			contentHTML = utils.RenderMarkdown(item.Content, rr.sanitizePolicy)
 		}
 
 		feed.Items = append(feed.Items, &feeds.Item{
@@ -92,7 +120,7 @@ func (rr *RSSResource) Atom(w http.ResponseWriter, r *http.Request) {
 			Author:      &feeds.Author{Name: item.AuthorName},
 			Created:     item.CreatedAt,
 			Updated:     item.UpdatedAt,
-			Content:     sourceHTML + utils.NewLine2BR(utils.ReplaceLink(item.Content)),
+			Content:     sourceHTML + contentHTML,
 		})
 	}
 
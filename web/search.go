@@ -0,0 +1,171 @@
+package web
+
+import (
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+	"github.com/oodzchen/dproject/model"
+)
+
+const searchDateLayout = "2006-01-02"
+
+type SearchResource struct {
+	*Renderer
+}
+
+func NewSearchResource(renderer *Renderer) *SearchResource {
+	return &SearchResource{
+		renderer,
+	}
+}
+
+func (sr *SearchResource) Routes() http.Handler {
+	rt := chi.NewRouter()
+
+	rt.With(httprate.Limit(
+		30,
+		1*time.Minute,
+		httprate.WithKeyByIP(),
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			sr.Error("", nil, w, r, http.StatusTooManyRequests)
+		}),
+	)).Get("/", sr.SearchPage)
+
+	return rt
+}
+
+type SearchPageData struct {
+	*queryData
+	Keywords        string
+	CategoryFrontId string
+	AuthorName      string
+	StartDate       string
+	EndDate         string
+	Type            string
+	Lang            string
+	List            []*model.Article
+	CategoryList    []*model.Category
+	LanguageOptions []*model.OptionItem
+	Searched        bool
+}
+
+func (sr *SearchResource) SearchPage(w http.ResponseWriter, r *http.Request) {
+	urlQuery := r.URL.Query()
+	page, pageSize := sr.GetPaginationData(r)
+
+	searchType := urlQuery.Get("type")
+	if !model.ValidSearchType(searchType) {
+		searchType = string(model.SearchTypeAll)
+	}
+
+	lang := model.LangEn
+	if uiSettings, ok := r.Context().Value("ui_settings").(*model.UISettings); ok {
+		lang = uiSettings.Lang
+	}
+	if l, err := model.ParseLang(urlQuery.Get("lang")); err == nil {
+		lang = l
+	}
+
+	startDateStr := strings.TrimSpace(urlQuery.Get("start_date"))
+	endDateStr := strings.TrimSpace(urlQuery.Get("end_date"))
+	startDate, err := time.Parse(searchDateLayout, startDateStr)
+	if err != nil {
+		startDateStr = ""
+	}
+	endDate, err := time.Parse(searchDateLayout, endDateStr)
+	if err != nil {
+		endDateStr = ""
+	}
+
+	query := &model.SearchQuery{
+		Keywords:        urlQuery.Get("keywords"),
+		Lang:            lang,
+		CategoryFrontId: urlQuery.Get("category"),
+		AuthorName:      urlQuery.Get("author"),
+		StartDate:       startDate,
+		EndDate:         endDate,
+		Type:            model.SearchType(searchType),
+	}
+	query.TrimSpace()
+
+	categoryList, err := sr.store.Category.List(model.CategoryStateApproved)
+	if err != nil {
+		sr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	var langStrEnums []model.StringEnum
+	for _, item := range model.LangValues() {
+		langStrEnums = append(langStrEnums, item)
+	}
+	langOptions := model.ConvertEnumToOPtions(langStrEnums, true, "", nil)
+
+	var list []*model.Article
+	var total int
+	searched := query.Keywords != ""
+	if searched {
+		err = query.Valid()
+		if err != nil {
+			sr.Error("", err, w, r, http.StatusBadRequest)
+			return
+		}
+
+		list, total, err = sr.store.Article.Search(query, page, pageSize)
+		if err != nil {
+			sr.ServerErrorp("", err, w, r)
+			return
+		}
+
+		currUserId := sr.GetLoginedUserId(w, r)
+		requestRegionCode := r.Context().Value("region_country_iso_code")
+		for _, item := range list {
+			item.CheckShowScore(currUserId)
+
+			if code, ok := requestRegionCode.(string); ok {
+				item.UpdateBlockedState(code)
+			}
+		}
+
+		canEditOthers := sr.CheckPermit(r, "article", "edit_others")
+		list = filterArray(list, func(item *model.Article) bool {
+			return canEditOthers || !item.Blocked
+		})
+	}
+
+	title := sr.Local(r, "Search")
+	if searched {
+		title = sr.Local(r, "SearchResultOf", "Keywords", html.EscapeString(query.Keywords))
+	}
+
+	sr.Render(w, r, "search", &model.PageData{
+		Title: title,
+		Data: &SearchPageData{
+			queryData: &queryData{
+				Total:     total,
+				Page:      page,
+				TotalPage: CeilInt(total, pageSize),
+			},
+			Keywords:        html.EscapeString(query.Keywords),
+			CategoryFrontId: query.CategoryFrontId,
+			AuthorName:      html.EscapeString(query.AuthorName),
+			StartDate:       startDateStr,
+			EndDate:         endDateStr,
+			Type:            searchType,
+			Lang:            string(lang),
+			List:            list,
+			CategoryList:    categoryList,
+			LanguageOptions: langOptions,
+			Searched:        searched,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/search",
+				Name: sr.Local(r, "Search"),
+			},
+		},
+	})
+}
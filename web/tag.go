@@ -0,0 +1,188 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	mdw "github.com/oodzchen/dproject/middleware"
+	"github.com/oodzchen/dproject/model"
+)
+
+type TagResource struct {
+	*Renderer
+	articleRs *ArticleResource
+}
+
+func NewTagResource(renderer *Renderer, ar *ArticleResource) *TagResource {
+	return &TagResource{
+		renderer,
+		ar,
+	}
+}
+
+func (tr *TagResource) Routes() http.Handler {
+	rt := chi.NewRouter()
+
+	rt.Get("/", tr.ListPage)
+
+	rt.With(mdw.AuthCheck(tr.sessStore), mdw.PermitCheck(tr.srv.Permission, []string{
+		"tag.manage",
+	}, tr)).Group(func(r chi.Router) {
+		r.Get("/new", tr.FormPage)
+		r.With(mdw.UserLogger(
+			tr.uLogger, model.AcTypeManage, model.AcActionCreateTag, model.AcModelEmpty, mdw.ULogEmpty),
+		).Post("/", tr.Submit)
+		r.Get("/{tagFrontId}/edit", tr.FormPage)
+		r.With(mdw.UserLogger(
+			tr.uLogger, model.AcTypeManage, model.AcActionEditTag, model.AcModelEmpty, mdw.ULogEmpty),
+		).Post("/{tagFrontId}/edit", tr.Submit)
+		r.With(mdw.UserLogger(
+			tr.uLogger, model.AcTypeManage, model.AcActionDeleteTag, model.AcModelEmpty, mdw.ULogEmpty),
+		).Post("/{tagFrontId}/delete", tr.Delete)
+	})
+
+	rt.Get("/{tagFrontId}", tr.articleRs.List)
+
+	return rt
+}
+
+func (tr *TagResource) ListPage(w http.ResponseWriter, r *http.Request) {
+	tagList, err := tr.store.Tag.List()
+	if err != nil {
+		tr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	type pageData struct {
+		TagList []*model.Tag
+	}
+
+	tr.Render(w, r, "tag_list", &model.PageData{
+		Title: tr.Local(r, "Tag", "Count", 2),
+		Data: &pageData{
+			TagList: tagList,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Name: tr.Local(r, "Tag", "Count", 2),
+			},
+		},
+	})
+}
+
+func (tr *TagResource) FormPage(w http.ResponseWriter, r *http.Request) {
+	tagFrontId := chi.URLParam(r, "tagFrontId")
+
+	tag := &model.Tag{}
+	title := tr.Local(r, "AddItem", "Name", tr.Local(r, "Tag", "Count", 1))
+	if tagFrontId != "" {
+		var err error
+		tag, err = tr.store.Tag.Item(tagFrontId)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				tr.NotFound(w, r)
+			} else {
+				tr.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+		title = tr.Local(r, "EditItem", "Name", tr.Local(r, "Tag", "Count", 1))
+	}
+
+	type pageData struct {
+		Tag            *model.Tag
+		IsEdit         bool
+		MaxFrontIdLen  int
+		MaxNameLen     int
+		MaxDescribeLen int
+	}
+
+	tr.Render(w, r, "tag_form", &model.PageData{
+		Title: title,
+		Data: &pageData{
+			Tag:            tag,
+			IsEdit:         tagFrontId != "",
+			MaxFrontIdLen:  model.MaxTagFrontIdLen,
+			MaxNameLen:     model.MaxTagNameLen,
+			MaxDescribeLen: model.MaxTagDescribeLen,
+		},
+		BreadCrumbs: []*model.BreadCrumb{
+			{
+				Path: "/tags",
+				Name: tr.Local(r, "Tag", "Count", 2),
+			},
+			{
+				Name: title,
+			},
+		},
+	})
+}
+
+func (tr *TagResource) Submit(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		tr.Error("", err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	tagFrontId := chi.URLParam(r, "tagFrontId")
+	isEdit := tagFrontId != ""
+
+	tag := &model.Tag{
+		FrontId:  r.Form.Get("front_id"),
+		Name:     r.Form.Get("name"),
+		Describe: r.Form.Get("describe"),
+	}
+	if isEdit {
+		tag.FrontId = tagFrontId
+	}
+
+	tag.TrimSpace()
+
+	err = tag.Valid()
+	if err != nil {
+		tr.Error(tr.LocalError(r, err), err, w, r, http.StatusBadRequest)
+		return
+	}
+
+	tag.Sanitize()
+
+	if isEdit {
+		_, err = tr.store.Tag.Update(tag.FrontId, tag.Name, tag.Describe)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				tr.NotFound(w, r)
+			} else {
+				tr.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+	} else {
+		_, err = tr.store.Tag.Create(tag.FrontId, tag.Name, tag.Describe, tr.GetLoginedUserId(w, r))
+		if err != nil {
+			// Conflict with an undeleted tag returns no rows
+			if errors.Is(err, pgx.ErrNoRows) {
+				tr.Error(tr.Local(r, "AlreadyExists", "FieldNames", tr.Local(r, "TagFrontId")), err, w, r, http.StatusBadRequest)
+			} else {
+				tr.ServerErrorp("", err, w, r)
+			}
+			return
+		}
+	}
+
+	tr.Session("one", w, r).Flash(tr.Local(r, "TagSaveSuccess"))
+	http.Redirect(w, r, "/tags", http.StatusFound)
+}
+
+func (tr *TagResource) Delete(w http.ResponseWriter, r *http.Request) {
+	err := tr.store.Tag.Delete(chi.URLParam(r, "tagFrontId"))
+	if err != nil {
+		tr.ServerErrorp("", err, w, r)
+		return
+	}
+
+	tr.Session("one", w, r).Flash(tr.Local(r, "DeleteSuccess"))
+	http.Redirect(w, r, "/tags", http.StatusFound)
+}
@@ -32,8 +32,11 @@ type userProfile struct {
 	// PermissionIdList []string
 	PermissionData map[string][]*model.Permission
 	Activities     []*model.Activity
-	Query          *queryData
-	PageType       string
+	Drafts         []*model.Draft
+	// Only visible to moderators
+	UsernameHistory []*model.UsernameHistory
+	Query           *queryData
+	PageType        string
 }
 
 func NewUserResource(renderer *Renderer) *UserResource {
@@ -187,7 +190,7 @@ func (ur *UserResource) List(w http.ResponseWriter, r *http.Request) {
 		BreadCrumbs: []*model.BreadCrumb{
 			{
 				Path: "/users",
-				Name: ur.Local("UserList"),
+				Name: ur.Local(r, "UserList"),
 			},
 		},
 	})
@@ -236,7 +239,7 @@ func (ur *UserResource) handleItemPage(w http.ResponseWriter, r *http.Request, p
 	user, err := ur.store.User.ItemWithUsername(username)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, model.AppErrUserNotExist) {
-			ur.Error("", nil, w, r, http.StatusNotFound)
+			ur.redirectOldUsername(w, r, username)
 		} else {
 			ur.Error("", errors.WithStack(err), w, r, http.StatusInternalServerError)
 		}
@@ -245,15 +248,24 @@ func (ur *UserResource) handleItemPage(w http.ResponseWriter, r *http.Request, p
 
 	var postList []*model.Article
 	var activityList []*model.Activity
+	var draftList []*model.Draft
 	var total int
-	if tab != "activity" {
-		postList, err = ur.userSrv.GetPosts(username, service.UserListType(tab))
-	} else {
+	switch tab {
+	case "activity":
 		if !ur.CheckPermit(r, "user", "access_activity") {
 			ur.Error("", nil, w, r, http.StatusForbidden)
 			return
 		}
 		activityList, total, err = ur.store.Activity.List(user.Id, "", "", "", page, pageSize)
+	case service.UserListDrafts:
+		// Drafts are only visible to the user self
+		if ur.GetLoginedUserId(w, r) != user.Id {
+			http.Redirect(w, r, fmt.Sprintf("/users/%s", username), http.StatusFound)
+			return
+		}
+		draftList, err = ur.srv.Draft.List(user.Id)
+	default:
+		postList, err = ur.userSrv.GetPosts(username, service.UserListType(tab))
 	}
 	if err != nil {
 		ur.Error("", errors.WithStack(err), w, r, http.StatusInternalServerError)
@@ -261,12 +273,24 @@ func (ur *UserResource) handleItemPage(w http.ResponseWriter, r *http.Request, p
 	}
 
 	for _, article := range postList {
-		article.UpdateDisplayTitle()
 		article.GenSummary(200)
 	}
 
 	for _, activity := range activityList {
-		activity.Format(ur.i18nCustom)
+		activity.Format(ur.Localizer(r))
+	}
+
+	for _, draft := range draftList {
+		draft.GenSummary(200)
+	}
+
+	var usernameHistory []*model.UsernameHistory
+	if ur.CheckPermit(r, "user", "manage") {
+		usernameHistory, err = ur.store.User.ListUsernameHistory(user.Id)
+		if err != nil {
+			ur.Error("", errors.WithStack(err), w, r, http.StatusInternalServerError)
+			return
+		}
 	}
 
 	// var permissionIdList []string
@@ -285,9 +309,11 @@ func (ur *UserResource) handleItemPage(w http.ResponseWriter, r *http.Request, p
 			CurrTab:  service.UserListType(tab),
 			// PermissionNames:  permissionNames,
 			// PermissionIdList: permissionIdList,
-			PermissionData: permissionData,
-			Activities:     activityList,
-			PageType:       pageType,
+			PermissionData:  permissionData,
+			Activities:      activityList,
+			Drafts:          draftList,
+			UsernameHistory: usernameHistory,
+			PageType:        pageType,
 			Query: &queryData{
 				Total:     total,
 				Page:      page,
@@ -303,6 +329,26 @@ func (ur *UserResource) handleItemPage(w http.ResponseWriter, r *http.Request, p
 	})
 }
 
+// Redirect to the current username of the user who used the old one, or
+// not found if there is none
+func (ur *UserResource) redirectOldUsername(w http.ResponseWriter, r *http.Request, oldName string) {
+	username, err := ur.store.User.UsernameRedirect(oldName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ur.Error("", nil, w, r, http.StatusNotFound)
+		} else {
+			ur.Error("", errors.WithStack(err), w, r, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	targetUrl := fmt.Sprintf("/users/%s", username)
+	if r.URL.RawQuery != "" {
+		targetUrl += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, targetUrl, http.StatusFound)
+}
+
 func (ur *UserResource) SetRole(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
 	if username == "" {
@@ -513,7 +559,7 @@ func (ur *UserResource) UpdateUserProfile(w http.ResponseWriter, r *http.Request
 	}
 
 	oneSess := ur.Session("one", w, r)
-	oneSess.Raw.AddFlash(ur.i18nCustom.MustLocalize("AccountSaveSuccess", "", ""))
+	oneSess.Raw.AddFlash(ur.Localizer(r).MustLocalize("AccountSaveSuccess", "", ""))
 	oneSess.Raw.Save(r, w)
 
 	http.Redirect(w, r, fmt.Sprintf("/users/%s", username), http.StatusFound)
@@ -536,7 +582,7 @@ func (ur *UserResource) BanPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if user.Banned {
-		ur.Session("one", w, r).Flash(ur.Local("AlreadyBan"))
+		ur.Session("one", w, r).Flash(ur.Local(r, "AlreadyBan"))
 		http.Redirect(w, r, fmt.Sprintf("/users/%d", user.Id), http.StatusFound)
 		return
 	}
@@ -546,7 +592,7 @@ func (ur *UserResource) BanPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ur.Render(w, r, "user_ban", &model.PageData{
-		Title: ur.Local("ConfirmBan", "Name", user.Name),
+		Title: ur.Local(r, "ConfirmBan", "Name", user.Name),
 		Data: &pageData{
 			UserData: user,
 		},
@@ -566,7 +612,7 @@ func (ur *UserResource) Ban(w http.ResponseWriter, r *http.Request) {
 
 	if bannedDays == "" {
 		ur.Error(
-			ur.Local("Required", "FieldNames", ur.Local("BannedDuration")),
+			ur.Local(r, "Required", "FieldNames", ur.Local(r, "BannedDuration")),
 			errors.New("banned_days is required"),
 			w,
 			r,
@@ -577,7 +623,7 @@ func (ur *UserResource) Ban(w http.ResponseWriter, r *http.Request) {
 
 	if comment == "" {
 		ur.Error(
-			ur.Local("Required", "FieldNames", ur.Local("Reason")),
+			ur.Local(r, "Required", "FieldNames", ur.Local(r, "Reason")),
 			errors.New("comment is required"),
 			w,
 			r,
@@ -596,7 +642,13 @@ func (ur *UserResource) Ban(w http.ResponseWriter, r *http.Request) {
 	// fmt.Println("banned days:", bannedDays)
 	// fmt.Println("comment:", comment)
 
-	_, err = ur.store.User.Ban(username, dayNum)
+	userId, err := ur.store.User.Ban(username, dayNum)
+	if err != nil {
+		ur.ServerErrorp("", err, w, r)
+		return
+	}
+
+	err = ur.sessStore.RevokeAll(userId, "")
 	if err != nil {
 		ur.ServerErrorp("", err, w, r)
 		return
@@ -630,7 +682,7 @@ func (ur *UserResource) UnbanPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !user.Banned {
-		ur.Session("one", w, r).Flash(ur.Local("AlreadyUnban"))
+		ur.Session("one", w, r).Flash(ur.Local(r, "AlreadyUnban"))
 		http.Redirect(w, r, fmt.Sprintf("/users/%d", user.Id), http.StatusFound)
 		return
 	}
@@ -640,7 +692,7 @@ func (ur *UserResource) UnbanPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ur.Render(w, r, "user_unban", &model.PageData{
-		Title: ur.Local("ConfirmUnban", "Name", user.Name),
+		Title: ur.Local(r, "ConfirmUnban", "Name", user.Name),
 		Data: &pageData{
 			UserData: user,
 		},
@@ -662,7 +714,7 @@ func (ur *UserResource) Unban(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ur.Session("one", w, r).Flash(ur.Local("UnbanSuccessTip"))
+	ur.Session("one", w, r).Flash(ur.Local(r, "UnbanSuccessTip"))
 
 	http.Redirect(w, r, fmt.Sprintf("/users/%s", username), http.StatusFound)
 }
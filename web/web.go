@@ -8,7 +8,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/gorilla/sessions"
+	"github.com/oodzchen/dproject/service"
 	"github.com/oodzchen/dproject/utils"
 	"github.com/pkg/errors"
 )
@@ -36,7 +36,7 @@ func logSessError(sessName string, err error) {
 	}
 }
 
-func ClearSession(cookStore *sessions.CookieStore, w http.ResponseWriter, r *http.Request) {
+func ClearSession(cookStore *service.SessionStore, w http.ResponseWriter, r *http.Request) {
 	sess, err := cookStore.Get(r, "one")
 	if err != nil {
 		fmt.Println("clear session get session error: ", err)
@@ -65,7 +65,7 @@ func ClearSession(cookStore *sessions.CookieStore, w http.ResponseWriter, r *htt
 	http.SetCookie(w, csrfExpiredCookie)
 }
 
-func GetLoginUserId(sessStore *sessions.CookieStore, w http.ResponseWriter, r *http.Request) (int, error) {
+func GetLoginUserId(sessStore *service.SessionStore, w http.ResponseWriter, r *http.Request) (int, error) {
 	sess, err := sessStore.Get(r, "one")
 	if err != nil {
 		logSessError("one", errors.WithStack(err))
@@ -79,7 +79,7 @@ func GetLoginUserId(sessStore *sessions.CookieStore, w http.ResponseWriter, r *h
 	return 0, errors.WithStack(errors.New("no user id in session"))
 }
 
-func IsLogin(sessStore *sessions.CookieStore, w http.ResponseWriter, r *http.Request) bool {
+func IsLogin(sessStore *service.SessionStore, w http.ResponseWriter, r *http.Request) bool {
 	_, err := GetLoginUserId(sessStore, w, r)
 	return err == nil
 }